@@ -2,6 +2,54 @@
 // sources:
 // database/migrations/000001_init.down.sql
 // database/migrations/000001_init.up.sql
+// database/migrations/000002_topics.down.sql
+// database/migrations/000002_topics.up.sql
+// database/migrations/000003_default_branch_head.down.sql
+// database/migrations/000003_default_branch_head.up.sql
+// database/migrations/000004_review_transitions.down.sql
+// database/migrations/000004_review_transitions.up.sql
+// database/migrations/000005_assignment_events.down.sql
+// database/migrations/000005_assignment_events.up.sql
+// database/migrations/000006_pull_request_mergeable_state.down.sql
+// database/migrations/000006_pull_request_mergeable_state.up.sql
+// database/migrations/000007_mentions.down.sql
+// database/migrations/000007_mentions.up.sql
+// database/migrations/000008_body_truncated.down.sql
+// database/migrations/000008_body_truncated.up.sql
+// database/migrations/000009_repository_rulesets.down.sql
+// database/migrations/000009_repository_rulesets.up.sql
+// database/migrations/000010_audit_log_entries.down.sql
+// database/migrations/000010_audit_log_entries.up.sql
+// database/migrations/000011_task_lists.down.sql
+// database/migrations/000011_task_lists.up.sql
+// database/migrations/000012_closing_references.down.sql
+// database/migrations/000012_closing_references.up.sql
+// database/migrations/000013_merge_method.down.sql
+// database/migrations/000013_merge_method.up.sql
+// database/migrations/000014_locks.down.sql
+// database/migrations/000014_locks.up.sql
+// database/migrations/000015_templates.down.sql
+// database/migrations/000015_templates.up.sql
+// database/migrations/000016_lock_node_id.down.sql
+// database/migrations/000016_lock_node_id.up.sql
+// database/migrations/000017_project_statuses.down.sql
+// database/migrations/000017_project_statuses.up.sql
+// database/migrations/000018_readmes.down.sql
+// database/migrations/000018_readmes.up.sql
+// database/migrations/000019_org_projects.down.sql
+// database/migrations/000019_org_projects.up.sql
+// database/migrations/000020_timeline_events.down.sql
+// database/migrations/000020_timeline_events.up.sql
+// database/migrations/000021_open_graph.down.sql
+// database/migrations/000021_open_graph.up.sql
+// database/migrations/000022_reactions.down.sql
+// database/migrations/000022_reactions.up.sql
+// database/migrations/000023_pull_request_commits.down.sql
+// database/migrations/000023_pull_request_commits.up.sql
+// database/migrations/000024_milestones.down.sql
+// database/migrations/000024_milestones.up.sql
+// database/migrations/000025_last_edited.down.sql
+// database/migrations/000025_last_edited.up.sql
 package database
 
 import (
@@ -118,6 +166,966 @@ func _000001_initUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __000002_topicsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x2f\xce\x2c\xc9\x2f\xaa\x8c\x2f\xc9\x2f\xc8\x4c\x2e\xb6\xc6\xaa\x0c\x26\x07\x91\x0c\x71\x74\xf2\x71\xc5\x67\x48\x7c\x59\x6a\x51\x71\x66\x7e\x5e\x6a\x8a\x35\x76\x1d\x98\xca\xb8\x9c\xfd\x7d\x7d\x3d\x43\xac\xb9\x00\xff\xfe\x25\xdb\xae\x00\x00\x00")
+
+func _000002_topicsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000002_topicsDownSql,
+		"000002_topics.down.sql",
+	)
+}
+
+func _000002_topicsDownSql() (*asset, error) {
+	bytes, err := _000002_topicsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000002_topics.down.sql", size: 174, mode: os.FileMode(420), modTime: time.Unix(1792088536, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000002_topicsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb5\x90\x4d\x0f\x01\x31\x18\x84\xef\xfd\x15\xef\xd1\x26\x4e\x82\x8b\x53\x51\xd2\xd8\xed\x4a\x55\xc2\x49\x36\xab\xa1\x07\xad\xb4\xf5\xf5\xef\x35\x45\x10\xac\x38\xb8\x76\xe6\xed\x3c\x33\x5d\x32\xa4\xac\x83\x50\x8f\x13\x2c\x08\x08\xdc\x4d\x09\xd0\x01\xb0\x5c\x00\x99\xd1\x89\x98\x80\x37\x5b\x55\xba\xc5\x5e\x5a\xa7\x8c\x96\x4b\xa8\x21\x00\xb7\xdb\x34\x5a\x6d\x28\xd7\x85\x2d\x4a\x2f\x2d\xec\x0b\x7b\x52\x7a\x55\x6b\x37\x13\x18\x73\x9a\x61\x3e\x87\x11\x99\xd7\x83\xf7\x7a\xe9\x40\x69\x2f\x57\xc1\x8b\x39\xc7\x41\x09\x92\x2e\x36\x12\xbc\x3c\xfa\x18\xc8\xa6\x69\x8a\x92\x3b\x0d\x65\x7d\x32\xab\xa4\x71\x90\xb3\x37\x80\x37\x35\xa9\x6e\x66\xe5\xd6\x38\xe5\x8d\x3d\x2d\xfe\x59\xf2\x21\xe6\xb5\x6f\xfd\xd9\x60\x0e\x3a\xdc\xbe\x38\x22\xde\x6f\x43\x7d\x2c\x17\x37\xab\xac\xfe\x30\xdf\x4f\x01\x17\xca\x6f\xbf\xc7\x97\x08\x9f\x67\x19\x15\x1d\x74\x06\x7f\x7b\x7d\xea\x84\x02\x00\x00")
+
+func _000002_topicsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000002_topicsUpSql,
+		"000002_topics.up.sql",
+	)
+}
+
+func _000002_topicsUpSql() (*asset, error) {
+	bytes, err := _000002_topicsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000002_topics.up.sql", size: 644, mode: os.FileMode(420), modTime: time.Unix(1792088536, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000003_default_branch_headDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x2f\xce\x2c\xc9\x2f\xca\x4c\x2d\x06\x2a\x71\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x45\x91\x8a\x2f\x4b\x2d\x2a\xce\xcc\xcf\x4b\x4d\xe1\x52\x50\x00\x9b\xe3\xec\xef\x13\xea\xeb\x87\x64\x52\x4a\x6a\x5a\x62\x69\x4e\x49\x7c\x52\x51\x62\x5e\x72\x46\x7c\x71\x46\xa2\x0e\xb1\x6a\x93\xf3\x73\x73\x33\x4b\x4a\x52\x53\xe2\x13\x4b\x80\x8e\x70\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\x1c\x44\x28\x9b\xb8\x00\x00\x00")
+
+func _000003_default_branch_headDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000003_default_branch_headDownSql,
+		"000003_default_branch_head.down.sql",
+	)
+}
+
+func _000003_default_branch_headDownSql() (*asset, error) {
+	bytes, err := _000003_default_branch_headDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000003_default_branch_head.down.sql", size: 184, mode: os.FileMode(420), modTime: time.Unix(1792088598, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000003_default_branch_headUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\xce\x4b\x0a\xc3\x20\x10\x00\xd0\xbd\xa7\x98\x03\xf4\x06\x59\x99\xc4\x16\xc1\x0f\x34\x16\xba\x13\x1b\xa7\x44\xa8\x31\xe8\x34\x84\x9e\xbe\xbd\x42\x0f\xf0\xe0\xf5\xe2\x22\x4d\xc7\x18\x57\x4e\x5c\xc1\xf1\x5e\x09\xa8\xb8\x95\x96\xa8\xd4\x84\xcd\xef\x58\x5b\x2a\x2b\x46\x06\xc0\xc7\x11\x06\xab\x6e\xda\x80\x3c\x83\xb1\x0e\xc4\x5d\x4e\x6e\x82\x88\xcf\xf0\x7e\x91\x7f\xd4\xb0\xce\x8b\x6f\x4b\x00\xc2\x83\x4e\x7f\x98\xb9\xe4\x9c\x88\x30\xfa\x40\x40\x29\x63\xa3\x90\x37\xfa\xfc\x6e\x83\xd5\x5a\xba\x8e\x7d\x01\xb0\xde\x6c\x9b\xac\x00\x00\x00")
+
+func _000003_default_branch_headUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000003_default_branch_headUpSql,
+		"000003_default_branch_head.up.sql",
+	)
+}
+
+func _000003_default_branch_headUpSql() (*asset, error) {
+	bytes, err := _000003_default_branch_headUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000003_default_branch_head.up.sql", size: 172, mode: os.FileMode(420), modTime: time.Unix(1792088598, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000004_review_transitionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x28\xcd\xc9\x89\x2f\x4a\x2d\x2c\x4d\x2d\x2e\x01\xd2\x65\x99\xa9\xe5\xf1\x25\x45\x89\x79\xc5\x99\x25\x99\xf9\x79\xc5\x30\x5d\x21\x8e\x4e\x3e\xae\xc4\x6b\x8b\x2f\x4b\x2d\x2a\x06\x32\x52\x53\x80\x06\x38\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\x3e\x89\xf7\x76\x87\x00\x00\x00")
+
+func _000004_review_transitionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000004_review_transitionsDownSql,
+		"000004_review_transitions.down.sql",
+	)
+}
+
+func _000004_review_transitionsDownSql() (*asset, error) {
+	bytes, err := _000004_review_transitionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000004_review_transitions.down.sql", size: 135, mode: os.FileMode(420), modTime: time.Unix(1792088656, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000004_review_transitionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9d\x91\xcd\x4e\xc3\x30\x10\x84\xef\x79\x8a\x3d\xb6\x12\x27\x04\xbd\xf4\x94\x82\x41\x11\xf9\x41\x69\x90\xda\x93\xe5\x24\x4b\xb0\x14\xdb\xc1\xde\xa4\x94\xa7\xc7\xa9\x82\xa0\x6a\x7b\x80\x93\x2d\xcd\x37\x3b\xb3\xda\x15\x7b\x8c\xd2\x65\x10\xdc\xe5\x2c\x2c\x18\x14\xe1\x2a\x66\x10\x3d\x40\x9a\x15\xc0\x36\xd1\xba\x58\x43\xd7\xb7\x2d\xb7\xf8\xde\xa3\x23\xff\x0e\x12\x77\x9c\xac\xd0\x4e\x92\x34\xda\xf1\x01\xad\xf3\x1f\xac\x61\x16\x00\xb8\x5e\x5d\xdf\x2e\xa0\x7a\x13\x56\x54\x84\x16\x06\x61\xf7\x52\x37\xb3\xc5\xcd\x1c\x9e\xf3\x28\x09\xf3\x2d\x3c\xb1\xed\x95\x67\x27\xa7\x03\xa9\x09\x1b\xcf\x86\x79\x1e\x7a\xc5\x4b\xaf\xd6\x28\xee\x48\x10\x02\xe1\x07\x1d\xfa\xa4\x2f\x71\x3c\xda\x8e\x0a\xe9\x5e\x95\xde\x59\xca\xc6\x0f\xb9\x8c\x4d\xbd\x65\x3d\x91\x23\x60\xb1\x33\x7e\x09\x63\xf7\x5c\x0b\x75\x26\xe8\x17\x60\x76\xda\xa7\x9c\x10\x6e\x1c\xaf\x2b\x3c\x97\xef\xfa\x52\x49\x22\xac\xb9\x20\x20\xa9\x7c\x0d\xa1\x3a\xfa\x1c\x35\x32\x97\x96\xeb\x1d\x5a\xde\x1a\x3f\xed\x58\x0b\xe6\x3f\x57\x8a\xd2\x7b\xb6\xf9\xdf\x95\x1c\x64\xe9\x5f\x2e\xfa\x6d\x3b\xa4\x67\x49\x12\x15\xcb\xe0\x0b\x90\x16\x94\xe7\x34\x02\x00\x00")
+
+func _000004_review_transitionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000004_review_transitionsUpSql,
+		"000004_review_transitions.up.sql",
+	)
+}
+
+func _000004_review_transitionsUpSql() (*asset, error) {
+	bytes, err := _000004_review_transitionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000004_review_transitions.up.sql", size: 564, mode: os.FileMode(420), modTime: time.Unix(1792088656, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000005_assignment_eventsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\x2c\x2e\xce\x4c\xcf\xcb\x4d\xcd\x2b\x89\x4f\x2d\x03\x92\xc5\x30\x75\x21\x8e\x4e\x3e\xae\xf8\x14\xc6\x97\xa5\x16\x15\x67\xe6\xe7\xa5\xa6\x00\xb5\x38\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\x13\x08\x69\xd7\x6b\x00\x00\x00")
+
+func _000005_assignment_eventsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000005_assignment_eventsDownSql,
+		"000005_assignment_events.down.sql",
+	)
+}
+
+func _000005_assignment_eventsDownSql() (*asset, error) {
+	bytes, err := _000005_assignment_eventsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000005_assignment_events.down.sql", size: 107, mode: os.FileMode(420), modTime: time.Unix(1792089134, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000005_assignment_eventsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x90\x41\x4b\x03\x31\x14\x84\xef\xf9\x15\xef\xd8\x82\x27\xd1\x5e\x7a\x4a\x35\x4a\x70\x37\x2b\xe9\x0a\xed\x29\xa4\xbb\x8f\x35\x60\x92\x92\xa4\xab\xf5\xd7\x9b\x46\x45\x4b\xa9\x78\x09\x3c\xe6\x9b\xc9\x30\x0b\x76\xcf\xc5\x9c\x90\x1b\xc9\x68\xcb\xa0\xa5\x8b\x8a\x01\xbf\x03\xd1\xb4\xc0\x56\x7c\xd9\x2e\x41\xc7\x68\x06\x67\xd1\x25\x85\x63\x7e\xa3\x1a\x31\x44\xe3\x1d\xf6\x30\x21\x00\x71\x67\x2f\xaf\x67\xd0\x3d\xeb\xa0\xbb\x84\x01\x46\x1d\xf6\xc6\x0d\x93\xd9\xd5\x14\x1e\x25\xaf\xa9\x5c\xc3\x03\x5b\x5f\x64\xf6\xcb\x19\xc1\xb8\x84\x43\x66\xa9\x94\x34\x2b\x59\xca\x5e\x1f\xd4\x8b\x1f\x8c\x83\x84\x6f\xa9\x54\x10\x4f\x55\x75\xf0\x7d\x76\x40\x3c\xa7\x77\x01\x75\xc2\x5e\xe9\x04\xc9\x58\x8c\x49\xdb\x6d\x7a\x3f\x28\xa5\xf2\xa9\xc1\xf9\x1e\x95\xe9\x8b\x50\xee\x9d\xdd\xe4\x3a\x1b\x93\xe3\x8f\xc9\x80\x5b\x1f\x4d\xee\xb6\x57\x4e\x5b\x3c\x8d\xfa\x05\xf8\x57\x97\x43\x8e\x08\x32\xfd\x59\x97\x8b\x5b\xb6\xfa\xef\xba\x11\x1a\xf1\xf7\xf6\xdf\x60\xf9\xa1\xa9\x6b\xde\xce\xc9\x07\xe4\x02\x00\x4b\xd0\x01\x00\x00")
+
+func _000005_assignment_eventsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000005_assignment_eventsUpSql,
+		"000005_assignment_events.up.sql",
+	)
+}
+
+func _000005_assignment_eventsUpSql() (*asset, error) {
+	bytes, err := _000005_assignment_eventsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000005_assignment_events.up.sql", size: 464, mode: os.FileMode(420), modTime: time.Unix(1792089134, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000006_pull_request_mergeable_stateDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x75\x8e\x4b\x0e\xc2\x20\x14\x45\xe7\xac\xe2\x2d\xc0\x1d\x74\xd4\x56\x34\x24\x50\x4c\x8b\x9f\xd9\x0b\xea\x8b\x92\x40\xa9\x40\x5d\xbf\x4d\x47\x3a\x70\x7c\xcf\x3d\x39\x0d\xdf\x8b\xae\x62\x6c\xdb\xeb\x03\x9c\x04\x3f\x83\xd8\x01\xbf\x88\xc1\x0c\x30\xcd\xde\x63\xa2\xd7\x4c\xb9\xe4\x85\xa9\xa5\xe1\x3d\x98\xba\x91\xfc\x77\xc3\x37\xa5\xec\xe2\x48\x77\x06\xb0\x9a\x5a\x2d\x8f\xaa\xfb\x72\x05\x4a\x0f\xb2\x57\x4f\x98\x8b\x2d\xb4\xf9\x0b\x4e\xb1\xd0\x58\x9c\xf5\xb8\x5e\xf0\x16\x43\x70\x05\xf3\xd3\x2e\x05\xad\x56\x4a\x98\x8a\x7d\x00\xd4\x71\xf8\x0a\xb6\x00\x00\x00")
+
+func _000006_pull_request_mergeable_stateDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000006_pull_request_mergeable_stateDownSql,
+		"000006_pull_request_mergeable_state.down.sql",
+	)
+}
+
+func _000006_pull_request_mergeable_stateDownSql() (*asset, error) {
+	bytes, err := _000006_pull_request_mergeable_stateDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000006_pull_request_mergeable_state.down.sql", size: 182, mode: os.FileMode(420), modTime: time.Unix(1792089332, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000006_pull_request_mergeable_stateUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x7d\xce\x4b\x0a\xc2\x30\x10\x00\xd0\x7d\x4e\x31\x07\xf0\x06\x5d\xa5\x6d\x94\x40\x3e\xd0\x46\x70\x17\xa2\x0e\x1a\x48\x9a\x9a\x4c\xc5\xe3\x2b\x3d\x80\x07\x78\xf0\x7a\x71\x92\xa6\x63\x8c\x2b\x27\x26\x70\xbc\x57\x02\xd6\x2d\x25\x5f\xf1\xb5\x61\xa3\xe6\xdf\x58\x5b\x2c\x0b\xde\x19\x00\x1f\x47\x18\xac\x3a\x6b\x03\xf2\x08\xc6\x3a\x10\x17\x39\xbb\x19\x32\xd6\x07\x86\x6b\x42\xdf\x28\x10\x02\xe1\x87\x0e\xff\xc0\x5a\x08\x17\x8a\x21\xf9\x9d\xfa\x5b\xc9\x39\x92\x6f\xcf\xb0\xdb\xdf\x68\xb0\x5a\x4b\xd7\xb1\x2f\x98\xb2\xee\xb4\xa2\x00\x00\x00")
+
+func _000006_pull_request_mergeable_stateUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000006_pull_request_mergeable_stateUpSql,
+		"000006_pull_request_mergeable_state.up.sql",
+	)
+}
+
+func _000006_pull_request_mergeable_stateUpSql() (*asset, error) {
+	bytes, err := _000006_pull_request_mergeable_stateUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000006_pull_request_mergeable_state.up.sql", size: 162, mode: os.FileMode(420), modTime: time.Unix(1792089332, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000007_mentionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x4d\xcd\x2b\xc9\xcc\xcf\x2b\x86\x49\x87\x38\x3a\xf9\xb8\x62\x91\x8f\x2f\x4b\x2d\x2a\x06\x32\x52\x53\x80\x2a\x9d\xfd\x7d\x7d\x3d\x43\xac\xb9\x00\x6d\x39\x37\x11\x59\x00\x00\x00")
+
+func _000007_mentionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000007_mentionsDownSql,
+		"000007_mentions.down.sql",
+	)
+}
+
+func _000007_mentionsDownSql() (*asset, error) {
+	bytes, err := _000007_mentionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000007_mentions.down.sql", size: 89, mode: os.FileMode(420), modTime: time.Unix(1792089402, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000007_mentionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x8f\x4f\x0b\x82\x30\x18\xc6\xef\xfb\x14\xef\x31\xc1\x53\x94\x17\x4f\xb3\x56\x8c\x74\xc6\x5c\xa0\x27\x31\x1d\xb6\xc8\x09\x6e\x49\x7d\xfb\x86\x18\x11\x04\xdd\x5e\xf8\x3d\xef\xf3\x27\x22\x7b\xca\x42\x84\x36\x9c\x60\x41\x40\xe0\x28\x26\x40\x77\xc0\x52\x01\x24\xa7\x99\xc8\xa0\x93\xda\xaa\x5e\x9b\x72\x94\x83\x71\x87\x6c\x60\x81\x00\xcc\xbd\x5b\xae\x03\xa8\x2f\xd5\x50\xd5\x56\x0e\x30\x56\xc3\x53\xe9\x76\x11\xac\x3c\x38\x72\x9a\x60\x5e\xc0\x81\x14\xbe\xd3\xce\x9f\x06\x94\xb6\xb2\x75\x5a\xcc\x39\x76\xc4\xa1\xd9\x5d\x36\xe5\xad\x6f\x95\x06\x2b\x1f\x76\x4a\x67\xa7\x38\xf6\xa7\x9c\xf3\x55\xd6\xb6\x54\xcd\x37\x43\xde\xa7\x36\x65\x5b\x92\xff\xa9\x6d\x20\x65\x3f\xb7\xbc\xf9\xe4\x97\x26\x09\x15\x21\x7a\x01\xbd\xa8\x26\xe2\x17\x01\x00\x00")
+
+func _000007_mentionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000007_mentionsUpSql,
+		"000007_mentions.up.sql",
+	)
+}
+
+func _000007_mentionsUpSql() (*asset, error) {
+	bytes, err := _000007_mentionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000007_mentions.up.sql", size: 279, mode: os.FileMode(420), modTime: time.Unix(1792089402, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000008_body_truncatedDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x2c\x2e\x2e\x4d\x2d\xb6\xc6\x2d\x17\x9f\x9c\x9f\x9b\x9b\x9a\x57\x82\x43\x4d\x41\x69\x4e\x4e\x7c\x51\x6a\x21\xd0\x10\x62\x94\x00\xe9\xb2\xcc\xd4\x72\x62\x54\x22\xec\xe5\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x85\x3a\x38\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x85\x4b\x41\x01\x6c\x94\xb3\xbf\x4f\xa8\xaf\x1f\x92\x61\x49\xf9\x29\x95\xf1\x25\x45\xa5\x79\xc9\x89\x25\xa9\x29\xd8\x4c\x81\x5b\x41\xa9\x69\x28\x81\x40\x4d\xc3\x60\xc1\x45\x55\x33\x29\xf1\xb5\xb3\xbf\xaf\xaf\x67\x88\x35\x17\x00\xea\xa0\xa6\x76\x53\x02\x00\x00")
+
+func _000008_body_truncatedDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000008_body_truncatedDownSql,
+		"000008_body_truncated.down.sql",
+	)
+}
+
+func _000008_body_truncatedDownSql() (*asset, error) {
+	bytes, err := _000008_body_truncatedDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000008_body_truncated.down.sql", size: 595, mode: os.FileMode(420), modTime: time.Unix(1792089473, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000008_body_truncatedUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xbd\xd0\x4d\x0a\x83\x30\x10\x05\xe0\x7d\x4e\x31\xf7\x70\x15\x35\x96\x40\x8c\x50\x13\xe8\x2e\x58\x9d\x82\x10\x13\x9b\x1f\x4b\x6f\xdf\xd0\x75\xb7\x75\x35\x3c\x78\xf0\xf1\xa6\x66\x17\x2e\x2b\x42\xa8\x50\xec\x0a\x8a\xd6\x82\xc1\x1a\x63\xc6\x68\x0e\x0c\x71\xf5\x0e\x17\x02\x40\xdb\x16\x9a\x41\xe8\x5e\x02\xef\x40\x0e\x0a\xd8\x8d\x8f\x6a\x84\xbb\x5f\xde\x26\x85\xec\xe6\x29\xe1\x52\xa2\xb7\x38\xb9\x6f\x43\x6a\x21\xa0\x65\x1d\xd5\x42\xc1\x63\xb2\x11\x7f\x39\x66\xf6\xdb\x86\x2e\x9d\xe0\xed\xd9\x5a\x13\xf0\x59\xc6\x9d\xcd\x95\x7b\xac\xf8\x3a\x5b\xfd\xef\x6f\x9b\xa1\xef\xb9\xaa\xc8\x07\x5e\x7a\x8f\xc4\x44\x02\x00\x00")
+
+func _000008_body_truncatedUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000008_body_truncatedUpSql,
+		"000008_body_truncated.up.sql",
+	)
+}
+
+func _000008_body_truncatedUpSql() (*asset, error) {
+	bytes, err := _000008_body_truncatedUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000008_body_truncated.up.sql", size: 580, mode: os.FileMode(420), modTime: time.Unix(1792089473, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000009_repository_rulesetsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x2f\xce\x2c\xc9\x2f\xaa\x8c\x2f\x2a\xcd\x49\x2d\x4e\x2d\x29\x86\xa9\x0c\x71\x74\xf2\x71\xc5\xaf\x34\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x05\xa8\xc9\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\xbc\x7d\x36\x1a\x6f\x00\x00\x00")
+
+func _000009_repository_rulesetsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000009_repository_rulesetsDownSql,
+		"000009_repository_rulesets.down.sql",
+	)
+}
+
+func _000009_repository_rulesetsDownSql() (*asset, error) {
+	bytes, err := _000009_repository_rulesetsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000009_repository_rulesets.down.sql", size: 111, mode: os.FileMode(420), modTime: time.Unix(1792089541, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000009_repository_rulesetsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x91\x4d\x4f\xc3\x30\x0c\x86\xef\xfd\x15\x3e\x6e\xd2\x4e\x08\x76\xd9\xa9\x83\x80\x2a\xfa\x81\xba\x22\x6d\x42\x53\x14\x1a\x53\x22\xad\x49\x95\xb8\x63\xe5\xd7\x93\x56\xec\x4b\x0c\xc1\xd1\x7e\x1f\xcb\xaf\xfd\xce\xd9\x43\x94\xce\x82\xe0\x36\x67\x61\xc1\xa0\x08\xe7\x31\x83\xe8\x1e\xd2\xac\x00\xb6\x8c\x16\xc5\x02\x2c\x36\xc6\x29\x32\xb6\xe3\xb6\xdd\xa0\x43\x72\x7c\x8b\xd6\x29\xa3\x51\xc2\x28\x00\x70\x6d\x7d\x75\x33\x85\xf2\x5d\x58\x51\x12\x5a\xd8\x0a\xdb\x29\x5d\x8d\xa6\xd7\x63\x78\xca\xa3\x24\xcc\x57\xf0\xc8\x56\x13\xcf\x7e\x4f\x3a\x50\x9a\xb0\xf2\x6c\x98\xe7\xa1\x57\xbc\x54\x1a\x2d\x15\xf5\x22\xc7\x5d\xb9\x69\x25\x02\xe1\x8e\x5e\xd6\x83\x9b\xf4\x39\x8e\x27\xe7\x94\xd2\xbf\x53\x16\x05\xa1\xe4\x82\x80\x54\x8d\x8e\x44\xdd\xd0\x67\xaf\xa0\x7e\x33\xb6\xc4\x1a\x35\x0d\x83\x7d\x4f\x49\x78\x55\x95\x77\xd4\x17\x5a\xd4\x78\x50\xb4\x91\xc8\xbd\xbc\xaf\x4f\x9e\x71\xe0\xce\x16\x9f\x00\xe6\x43\xfb\xfb\x7e\x12\xfe\x89\x9c\xba\x06\xdd\x25\xe3\x24\x6c\x85\x47\x67\x6d\x23\x2f\xdc\x11\x8c\x8f\x91\x45\xe9\x1d\x5b\xfe\x3f\x32\x07\x59\xfa\x57\xa4\x7b\x74\xd8\x92\x25\x49\x54\xcc\x82\x2f\xa6\x2b\xcb\x03\x29\x02\x00\x00")
+
+func _000009_repository_rulesetsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000009_repository_rulesetsUpSql,
+		"000009_repository_rulesets.up.sql",
+	)
+}
+
+func _000009_repository_rulesetsUpSql() (*asset, error) {
+	bytes, err := _000009_repository_rulesetsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000009_repository_rulesets.up.sql", size: 553, mode: os.FileMode(420), modTime: time.Unix(1792089541, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000010_audit_log_entriesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\x2c\x4d\xc9\x2c\x89\xcf\xc9\x4f\x8f\x4f\xcd\x2b\x29\xca\x4c\x2d\x86\xa9\x0b\x71\x74\xf2\x71\xc5\xa7\x30\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x05\xa8\xc5\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\xf5\xcd\x82\x86\x6b\x00\x00\x00")
+
+func _000010_audit_log_entriesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000010_audit_log_entriesDownSql,
+		"000010_audit_log_entries.down.sql",
+	)
+}
+
+func _000010_audit_log_entriesDownSql() (*asset, error) {
+	bytes, err := _000010_audit_log_entriesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000010_audit_log_entries.down.sql", size: 107, mode: os.FileMode(420), modTime: time.Unix(1792089666, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000010_audit_log_entriesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x90\xcd\x4e\xc3\x30\x10\x84\xef\x79\x8a\x3d\xb6\x52\x4f\x08\x7a\xe9\x29\x05\x83\x2c\xf2\x83\x5c\x23\xb5\x27\xcb\x8a\x57\xc1\x12\x71\x22\x7b\x53\x91\x3e\x3d\x4e\x0a\x0d\x08\x09\xf5\x3a\xdf\xec\xec\x68\xb6\xec\x89\x17\x9b\x24\xb9\x17\x2c\x95\x0c\x64\xba\xcd\x18\xf0\x47\x28\x4a\x09\x6c\xcf\x77\x72\x07\xba\x37\x96\xd4\x7b\x5b\x2b\x74\xe4\x2d\x06\x75\x44\x1f\x6c\xeb\xd0\xc0\x22\x01\x08\x7d\x73\x73\xb7\x86\xea\x4d\x7b\x5d\x11\x7a\x38\x6a\x3f\x58\x57\x2f\xd6\xb7\x4b\x78\x11\x3c\x4f\xc5\x01\x9e\xd9\x61\x15\xbd\x5f\x97\x01\xac\x23\xac\xa3\x37\x15\x22\x8d\x24\xa2\x78\x1b\x09\x10\x7e\xd0\xf4\xbd\x78\xcd\xb2\xd5\x59\x6f\xbd\xb2\xdd\x44\x66\x21\xf6\xb1\xee\xa2\x55\x1e\x35\xa1\x51\x9a\x80\x6c\x83\x81\x74\xd3\xd1\x69\x24\x63\xe7\x41\xd1\xd0\xe1\xdf\x68\xd7\x1a\x54\xd6\x5c\x52\xda\x0e\xbd\x1e\x5b\xcc\xfe\x49\xf6\xb5\x76\xf6\x74\x26\xf3\xdf\x5f\x51\x7d\xc0\x9f\x9d\x92\xe5\xbc\x29\x2f\x1e\xd8\xfe\xda\x4d\x03\x94\xc5\xff\x8b\x7f\x1b\xa7\x0f\x65\x9e\x73\xb9\x49\x3e\x01\xd2\x68\xbb\x62\xc6\x01\x00\x00")
+
+func _000010_audit_log_entriesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000010_audit_log_entriesUpSql,
+		"000010_audit_log_entries.up.sql",
+	)
+}
+
+func _000010_audit_log_entriesUpSql() (*asset, error) {
+	bytes, err := _000010_audit_log_entriesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000010_audit_log_entries.up.sql", size: 454, mode: os.FileMode(420), modTime: time.Unix(1792089666, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000011_task_listsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x2c\x2e\x2e\x4d\x2d\xb6\xc6\x2a\x57\x50\x9a\x93\x13\x5f\x94\x5a\x08\x54\x50\x02\x54\xc2\xe5\xe8\x13\xe2\x1a\xa4\x10\xe2\xe8\xe4\xe3\x0a\xd5\x17\x5f\x96\x5a\x54\x9c\x99\x9f\x97\x9a\xc2\xa5\xa0\x00\x36\xc2\xd9\xdf\x27\xd4\xd7\x0f\xc9\x90\x92\xc4\xe2\xec\xe2\xf8\x14\xa0\x1a\x1d\x02\x6a\x4a\xf2\x4b\x12\x73\xd0\xac\x41\x71\x02\x4d\x6c\x73\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\xae\xbd\x53\xbd\x25\x01\x00\x00")
+
+func _000011_task_listsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000011_task_listsDownSql,
+		"000011_task_lists.down.sql",
+	)
+}
+
+func _000011_task_listsDownSql() (*asset, error) {
+	bytes, err := _000011_task_listsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000011_task_lists.down.sql", size: 293, mode: os.FileMode(420), modTime: time.Unix(1792107032, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000011_task_listsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcd\xce\xb1\x0a\xc2\x30\x14\x85\xe1\x3d\x4f\x71\x1f\xc0\xc1\xbd\x53\xda\xa4\x12\xb8\x49\xc0\xde\x80\x5b\xa9\x34\x48\x30\xb4\xb5\x37\xf5\xf9\x2d\x8e\x82\xb8\x3a\x9f\xc3\xcf\x57\xeb\x93\x71\x95\x10\x12\x49\x9f\x81\x64\x8d\x1a\x12\xf3\x16\xb9\x7f\xc6\x95\xd3\x3c\xc5\x51\x00\x48\xa5\xa0\xf1\x18\xac\x03\xd3\x82\xf3\x04\xfa\x62\x3a\xea\xa0\x0c\x7c\xe7\x7e\xdc\x6f\x70\x4d\xb7\x34\x95\xf7\xe8\x02\x22\x28\xdd\xca\x80\x04\xc7\xc3\xef\x40\x99\xcb\x90\xbf\x17\x3e\x80\xcb\x96\x73\xbf\xc6\xc7\xae\x2c\xff\xe6\x6c\xbc\xb5\x86\x2a\xf1\x02\xc8\x60\xfe\x8a\x59\x01\x00\x00")
+
+func _000011_task_listsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000011_task_listsUpSql,
+		"000011_task_lists.up.sql",
+	)
+}
+
+func _000011_task_listsUpSql() (*asset, error) {
+	bytes, err := _000011_task_listsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000011_task_lists.up.sql", size: 345, mode: os.FileMode(420), modTime: time.Unix(1792107032, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000012_closing_referencesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xce\xc9\x2f\xce\xcc\x4b\x8f\x2f\x4a\x4d\x4b\x2d\x4a\xcd\x4b\x4e\x2d\x86\x29\x0c\x71\x74\xf2\x71\xc5\xab\x32\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x05\xa8\xc7\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\x7b\xd8\x1b\xdb\x6d\x00\x00\x00")
+
+func _000012_closing_referencesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000012_closing_referencesDownSql,
+		"000012_closing_references.down.sql",
+	)
+}
+
+func _000012_closing_referencesDownSql() (*asset, error) {
+	bytes, err := _000012_closing_referencesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000012_closing_references.down.sql", size: 109, mode: os.FileMode(420), modTime: time.Unix(1792107362, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000012_closing_referencesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x92\x4b\x4f\xc3\x30\x10\x84\xef\xfe\x15\x7b\x6c\x25\x9f\x10\xed\xa5\x27\x17\x4c\x65\x91\x07\x4a\x8d\xd4\x9e\xa2\x34\x2c\xc1\x52\xe2\x14\x3b\x29\xf4\xdf\xb3\x8a\x78\x55\x0d\x09\xbd\x7a\xbf\xdd\x19\xcd\x78\x29\x57\x2a\x5a\x30\x76\x93\x48\xa1\x25\x68\xb1\x0c\x24\xa8\x3b\x88\x62\x0d\x72\xa3\xd6\x7a\x0d\x79\x59\x7b\x63\x8b\xd4\xe1\x33\x3a\xb4\x39\xfa\xf4\x80\xce\x9b\xda\xe2\x13\x4c\x18\x80\x6f\xab\xab\xd9\x1c\xf2\x97\xcc\x65\x79\x83\x0e\x0e\x99\x3b\xd2\xc6\x64\x7e\x3d\x85\x87\x44\x85\x22\xd9\xc2\xbd\xdc\x72\x62\x3f\x37\x3d\x18\xdb\x60\x41\xac\x48\x12\x41\x13\x1a\x19\xef\x5b\x4c\x6d\x5b\xed\xe8\x79\x67\x0a\x22\x3a\x1b\xd1\x63\x10\xf0\xef\xb9\xc3\x3d\xd9\x69\x6a\x77\x4c\x6d\x56\x21\x34\xf8\x3e\x82\xd5\x6f\x96\x0e\x9e\x71\xfb\xb6\x2c\x09\x7b\x6d\xd1\x37\x03\xaa\x27\xd8\xa8\xf8\x5f\x74\x8f\x07\x36\xfd\x89\x5d\x45\xb7\x72\xf3\xef\xd8\x3d\xc4\xd1\x48\x2b\x5f\x24\x69\x5c\x26\xd1\xa5\x37\x7e\xbf\x3f\x64\xde\xdf\x11\x3f\xa9\xf6\x62\x4b\xbf\x33\x1d\x77\x36\xd8\x00\x1f\xac\x93\xf7\xfd\x89\xae\xa5\x38\x0c\x95\x5e\xb0\x0f\x26\xbd\x5a\x58\x2d\x03\x00\x00")
+
+func _000012_closing_referencesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000012_closing_referencesUpSql,
+		"000012_closing_references.up.sql",
+	)
+}
+
+func _000012_closing_referencesUpSql() (*asset, error) {
+	bytes, err := _000012_closing_referencesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000012_closing_references.up.sql", size: 813, mode: os.FileMode(420), modTime: time.Unix(1792107362, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000013_merge_methodDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x28\xcd\xc9\x89\x2f\x4a\x2d\x2c\x4d\x2d\x2e\x29\x06\xaa\x71\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x45\x95\x8b\x2f\x4b\x2d\x2a\xce\xcc\xcf\x4b\x4d\xe1\x52\x50\x00\x9b\xe4\xec\xef\x13\xea\xeb\x87\x64\x56\x6e\x6a\x51\x7a\x6a\x7c\x6e\x6a\x49\x46\x7e\x0a\xd0\x28\x67\x7f\x5f\x5f\xcf\x10\x6b\x2e\x00\xff\x91\x8b\xed\x7f\x00\x00\x00")
+
+func _000013_merge_methodDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000013_merge_methodDownSql,
+		"000013_merge_method.down.sql",
+	)
+}
+
+func _000013_merge_methodDownSql() (*asset, error) {
+	bytes, err := _000013_merge_methodDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000013_merge_method.down.sql", size: 127, mode: os.FileMode(420), modTime: time.Unix(1792107777, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000013_merge_methodUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x1d\xcd\xb1\x0e\xc2\x20\x14\x05\xd0\x9d\xaf\xb8\x5b\x3f\xa2\x13\x2d\xd4\x90\x3c\x20\xb1\x8f\xc4\x8d\xa5\x2f\x6a\xd2\x5a\x05\x6a\xfc\x7c\x8d\xf3\x19\xce\x60\x4f\x2e\xf4\x4a\x69\x62\x7b\x06\xeb\x81\x2c\x9e\xc7\xba\xe6\x22\xaf\x43\x6a\xab\xf9\x2d\xa5\xde\xf7\x87\x2c\x0a\xd0\xc6\x60\x8c\x94\x7c\x80\x9b\x10\x22\xc3\x5e\xdc\xcc\x33\x36\x29\x57\xc9\x9b\xb4\xdb\xbe\xa0\xc9\xa7\xfd\x31\x24\x22\x18\x3b\xe9\x44\x8c\xae\xfb\x35\x63\xf4\xde\x71\xaf\xbe\x64\x38\x5c\xb1\x77\x00\x00\x00")
+
+func _000013_merge_methodUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000013_merge_methodUpSql,
+		"000013_merge_method.up.sql",
+	)
+}
+
+func _000013_merge_methodUpSql() (*asset, error) {
+	bytes, err := _000013_merge_methodUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000013_merge_method.up.sql", size: 119, mode: os.FileMode(420), modTime: time.Unix(1792107777, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000014_locksDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xc9\x4f\xce\x2e\x86\xc9\x85\x38\x3a\xf9\xb8\xa2\x4b\xc6\x97\xa5\x16\x15\x67\xe6\xe7\xa5\xa6\x00\x95\x39\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\x6f\x5a\x0a\x04\x53\x00\x00\x00")
+
+func _000014_locksDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000014_locksDownSql,
+		"000014_locks.down.sql",
+	)
+}
+
+func _000014_locksDownSql() (*asset, error) {
+	bytes, err := _000014_locksDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000014_locks.down.sql", size: 83, mode: os.FileMode(420), modTime: time.Unix(1792107830, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000014_locksUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x90\x41\x4f\xc3\x20\x18\x86\xef\xfc\x8a\xef\xb8\x25\x3d\x19\xdd\x65\x27\xa6\x68\x88\x2d\x35\x0c\x93\xed\xd4\xd0\x96\x54\xe2\x80\x05\xd8\xb4\xfe\x7a\x71\xa9\x71\xae\x8d\x9e\x9f\xf7\x7d\xf8\x78\x57\xe4\x81\xb2\x25\x42\xb7\x9c\x60\x41\x40\xe0\x55\x4e\x80\xde\x03\x2b\x05\x90\x0d\x5d\x8b\x35\xec\x5c\xf3\x1a\xaa\xa3\xf2\x41\x3b\xab\x5a\x98\x21\x80\x70\x30\x57\x37\x0b\x68\x5e\xa4\x97\x4d\x54\x1e\x8e\xd2\xf7\xda\x76\xb3\xc5\xf5\x1c\x9e\x38\x2d\x30\xdf\xc2\x23\xd9\x66\x29\x3b\x34\x03\x68\x1b\x55\x97\xb2\x98\x73\x9c\x48\x42\x5f\xea\xca\x2b\x19\x9c\x85\xa8\xde\xe3\xe9\x59\xf6\x9c\xe7\xd9\x00\x55\x5b\xc9\x08\x51\x1b\x15\xa2\x34\xfb\xf8\x71\x06\xea\xbe\xd2\x2d\xd4\xba\x4b\xde\xa9\x62\xe2\x3b\x97\xe0\xd8\x6c\x0f\xa6\x4e\x77\x4c\x54\xbd\xda\xbb\xa0\xa3\xf3\x7d\x65\xa5\x51\xe3\xea\x59\xc0\xbd\xd9\x24\xf9\x95\x40\xf3\x9f\x29\x29\xbb\x23\x9b\xbf\xa6\x0c\x50\xb2\xf1\xb8\xdf\x30\x99\xfe\x15\x0d\xff\x98\xd2\x5c\xde\x99\x5d\x7e\x2d\x1b\x56\x38\x5d\x5c\x16\x05\x15\x4b\xf4\x09\x72\x7f\xb7\x32\x0d\x02\x00\x00")
+
+func _000014_locksUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000014_locksUpSql,
+		"000014_locks.up.sql",
+	)
+}
+
+func _000014_locksUpSql() (*asset, error) {
+	bytes, err := _000014_locksUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000014_locks.up.sql", size: 525, mode: os.FileMode(420), modTime: time.Unix(1792107830, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000015_templatesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x49\xcd\x2d\xc8\x49\x2c\x49\x2d\x86\xc9\x87\x38\x3a\xf9\xb8\x62\x53\x10\x5f\x96\x5a\x54\x9c\x99\x9f\x97\x9a\x02\x54\xea\xec\xef\xeb\xeb\x19\x62\xcd\x05\x00\x93\x5e\xde\xcc\x5b\x00\x00\x00")
+
+func _000015_templatesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000015_templatesDownSql,
+		"000015_templates.down.sql",
+	)
+}
+
+func _000015_templatesDownSql() (*asset, error) {
+	bytes, err := _000015_templatesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000015_templates.down.sql", size: 91, mode: os.FileMode(420), modTime: time.Unix(1792108012, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000015_templatesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x90\xc1\x6e\xc2\x30\x10\x44\xef\xfe\x8a\x3d\x82\xc4\x09\xb5\x5c\x38\x19\xea\x56\x56\x13\xa7\x32\xae\x04\x27\x64\x88\x01\xab\x89\x8d\xec\x85\x92\xbf\xc7\x45\xad\x90\x12\x10\xbd\xee\xbc\x9d\x9d\x9d\x09\x7b\xe3\x62\x4c\xc8\x54\x32\xaa\x18\x28\x3a\xc9\x18\xf0\x57\x10\x85\x02\x36\xe7\x33\x35\x03\x34\xf5\xbe\xd2\x68\xe2\xf2\x68\x42\xb4\xde\x99\x12\x7a\x04\x20\x1e\xea\xe1\xf3\x08\xd6\x3b\x1d\xf4\x1a\x4d\x80\xa3\x0e\x8d\x75\xdb\xde\xe8\xa9\x0f\x1f\x92\xe7\x54\x2e\xe0\x9d\x2d\x06\x89\xfd\xdd\x8c\x60\x1d\x9a\x6d\x62\xa9\x94\x34\x29\x49\xd2\x2b\x7f\xc0\x74\xe4\x84\x97\xa3\xe2\x33\xcb\x7e\x36\x56\xbe\x6c\xba\xd3\x8d\xad\x8c\xd3\xb5\xe9\x2a\x5f\xd6\x95\xdd\xe9\x6d\x36\x98\xbd\x8f\x16\x7d\x68\x96\x0f\x01\xff\xed\x52\xdc\x0e\x81\x16\xab\xd6\x22\xe9\x5f\x7b\xe4\xe2\x85\xcd\x1f\xf5\x18\xa1\x10\xb7\xdb\xfd\x03\x92\xe3\xbf\x0c\xaf\x79\xef\x5a\xb6\x5f\x1a\xb4\x5b\xb8\xa4\x2f\xf2\x9c\xab\x31\x39\x03\x9f\xee\x16\xfb\x16\x02\x00\x00")
+
+func _000015_templatesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000015_templatesUpSql,
+		"000015_templates.up.sql",
+	)
+}
+
+func _000015_templatesUpSql() (*asset, error) {
+	bytes, err := _000015_templatesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000015_templates.up.sql", size: 534, mode: os.FileMode(420), modTime: time.Unix(1792108012, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000016_lock_node_idDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xc9\x4f\xce\x2e\x06\xca\x39\xfa\x84\xb8\x06\x29\x84\x38\x3a\xf9\xb8\x42\xc4\xe2\xcb\x52\x8b\x8a\x33\xf3\xf3\x52\x53\xb8\x14\x14\xc0\x3a\x9d\xfd\x7d\x42\x7d\xfd\x90\xf4\xe6\xe5\xa7\xa4\xc6\x67\xa6\x00\x75\x3b\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\xea\x4b\x31\xe4\x6a\x00\x00\x00")
+
+func _000016_lock_node_idDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000016_lock_node_idDownSql,
+		"000016_lock_node_id.down.sql",
+	)
+}
+
+func _000016_lock_node_idDownSql() (*asset, error) {
+	bytes, err := _000016_lock_node_idDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000016_lock_node_id.down.sql", size: 106, mode: os.FileMode(420), modTime: time.Unix(1792108107, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000016_lock_node_idUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x1d\xcc\x31\x0a\x85\x30\x10\x05\xc0\x3e\xa7\x78\x9d\x87\xb0\x8a\x66\x95\xc0\x26\x01\xdd\x80\x9d\x85\x49\x21\x8a\x01\x95\xcf\x3f\xbe\x62\x3b\xc5\x34\xd4\x5b\x5f\x2b\xa5\x59\x68\x80\xe8\x86\x09\x7b\x59\xb6\x6b\xfe\xe5\xf3\x5a\xcb\x91\x93\x02\xb4\x31\x68\x03\x47\xe7\x61\x3b\xf8\x20\xa0\xc9\x8e\x32\xe2\x28\x29\xcf\x6b\xc2\x9d\xff\xf7\xe7\x3e\x32\xc3\x50\xa7\x23\x0b\xaa\xea\x9d\xdb\xe0\x9c\x95\x5a\x3d\xbd\x85\x72\xf5\x6a\x00\x00\x00")
+
+func _000016_lock_node_idUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000016_lock_node_idUpSql,
+		"000016_lock_node_id.up.sql",
+	)
+}
+
+func _000016_lock_node_idUpSql() (*asset, error) {
+	bytes, err := _000016_lock_node_idUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000016_lock_node_id.up.sql", size: 106, mode: os.FileMode(420), modTime: time.Unix(1792108107, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000017_project_statusesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x28\xca\xcf\x4a\x4d\x2e\x89\x2f\x2e\x49\x2c\x29\x2d\x4e\x2d\x86\x29\x0b\x71\x74\xf2\x71\xc5\xa3\x2e\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x05\xa8\xc3\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\xfa\xb6\x7f\x23\x69\x00\x00\x00")
+
+func _000017_project_statusesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000017_project_statusesDownSql,
+		"000017_project_statuses.down.sql",
+	)
+}
+
+func _000017_project_statusesDownSql() (*asset, error) {
+	bytes, err := _000017_project_statusesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000017_project_statuses.down.sql", size: 105, mode: os.FileMode(420), modTime: time.Unix(1792108332, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000017_project_statusesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x91\x4f\x4f\xc2\x40\x10\xc5\xef\xfd\x14\x73\x84\xa4\x27\xa3\x5c\x38\x15\x5d\x4d\x63\xff\x98\x52\x13\x38\x6d\x96\x76\x52\xd7\xb4\xdd\xba\x3b\x8b\xe2\xa7\x77\x41\x89\x0d\x14\xd0\xeb\xcc\x6f\xde\xcb\xbc\x37\x63\x0f\x61\x32\xf5\xbc\xdb\x8c\x05\x39\x83\x3c\x98\x45\x0c\xc2\x7b\x48\xd2\x1c\xd8\x22\x9c\xe7\x73\xe8\xb4\x7a\xc5\x82\xb8\x21\x41\xd6\xa0\xe1\x6b\xd4\x46\xaa\x16\x4b\x18\x79\x00\xc6\x36\x57\x37\x13\x28\x5e\x84\x16\x05\xa1\x86\xb5\xd0\x1b\xd9\x56\xa3\xc9\xf5\x18\x9e\xb2\x30\x0e\xb2\x25\x3c\xb2\xa5\xef\xd8\x9f\x4b\x03\xb2\x25\xac\x1c\x1b\x64\x59\xe0\x36\x6e\xd5\xaa\x12\xb9\x2c\x81\xf0\x83\x76\xee\xc9\x73\x14\x6d\x6f\xf6\xf6\x97\x01\xdb\xac\x9c\xe4\x4a\x56\x4e\x7d\x90\x20\x49\x35\x0e\x08\xd8\xba\xe6\x1a\xdf\x2c\x9a\xb3\x2a\x7d\xcc\x76\xa5\x20\x2c\xb9\x20\x20\xd9\xb8\x89\x68\x3a\xfa\xdc\x62\x1a\x3b\x65\x24\x29\xbd\xe1\xad\x68\x06\xec\x7a\x80\x7a\x6f\x9d\xd7\x11\xf1\x9d\xf4\xa9\xf9\x09\x6f\x6f\xfc\x5b\x63\x98\xdc\xb1\xc5\x1f\x6b\x34\x90\x26\x67\x3b\xde\x73\x4e\xff\x3f\xf2\xfd\xb8\x2e\x59\x1c\x46\xe2\x1f\xa6\xe8\x0f\x95\xb4\x7b\x38\x8d\xe3\x30\x9f\x7a\x5f\x17\x89\x17\xab\xc8\x02\x00\x00")
+
+func _000017_project_statusesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000017_project_statusesUpSql,
+		"000017_project_statuses.up.sql",
+	)
+}
+
+func _000017_project_statusesUpSql() (*asset, error) {
+	bytes, err := _000017_project_statusesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000017_project_statuses.up.sql", size: 712, mode: os.FileMode(420), modTime: time.Unix(1792108332, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000018_readmesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x4d\x4c\xc9\x4d\x2d\x86\xc9\x86\x38\x3a\xf9\xb8\x62\x4a\xc7\x97\xa5\x16\x15\x67\xe6\xe7\xa5\xa6\x00\x15\x3a\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\x3c\x84\xcc\xd9\x57\x00\x00\x00")
+
+func _000018_readmesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000018_readmesDownSql,
+		"000018_readmes.down.sql",
+	)
+}
+
+func _000018_readmesDownSql() (*asset, error) {
+	bytes, err := _000018_readmesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000018_readmes.down.sql", size: 87, mode: os.FileMode(420), modTime: time.Unix(1792109189, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000018_readmesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x90\x4f\x4f\x02\x31\x14\xc4\xef\xfd\x14\xef\x08\x09\x27\xa3\x7b\xe1\x54\xa4\x9a\xc6\xdd\x2e\x29\x35\x81\xd3\xa6\xcb\x3e\xd7\x26\x6e\x6b\xda\x0a\xae\x9f\xde\x42\x34\x04\xe4\x8f\xe7\x99\xdf\xbc\x79\x33\x61\x8f\x5c\x8c\x09\xb9\x97\x8c\x2a\x06\x8a\x4e\x72\x06\xfc\x01\x44\xa9\x80\x2d\xf8\x5c\xcd\xc1\xa3\x6e\x3a\x0c\xd5\x1a\x7d\x30\xce\x62\x03\x03\x02\x10\x3e\xba\x9b\xbb\x0c\x56\xaf\xda\xeb\x55\x44\x0f\x6b\xed\x7b\x63\xdb\x41\x76\x3b\x84\x99\xe4\x05\x95\x4b\x78\x62\xcb\x51\xf2\xfe\x90\x01\x8c\x8d\xd8\x26\x2f\x95\x92\x26\x25\x49\xb5\x6b\x7a\x88\xf8\x19\x77\x17\xc5\x73\x9e\x6f\x81\xba\x8f\x58\x05\xf3\x85\x50\x9b\x36\x41\x07\xe2\x8b\x79\x43\xab\x3b\xfc\x8b\x79\x7c\x77\xc1\x44\xe7\xfb\xea\xaa\xc1\x6d\x6c\x2a\x72\xe0\x20\xc3\xfd\x10\x5c\x4c\xd9\xe2\xf2\x10\x01\x4a\x71\x6a\x9c\x5f\x39\xa5\xfd\x23\x6c\x5f\xe9\x4c\xdc\x71\xe7\xd1\xf1\x9b\xbb\xd6\x65\x51\x70\x35\x26\xdf\xc4\xc8\x50\x15\xcf\x01\x00\x00")
+
+func _000018_readmesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000018_readmesUpSql,
+		"000018_readmes.up.sql",
+	)
+}
+
+func _000018_readmesUpSql() (*asset, error) {
+	bytes, err := _000018_readmesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000018_readmes.up.sql", size: 463, mode: os.FileMode(420), modTime: time.Unix(1792109189, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000019_org_projectsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x2f\x4a\x8f\x2f\x28\xca\xcf\x4a\x4d\x2e\x89\xcf\x2c\x49\xcd\x2d\xb6\x26\xa4\xac\x18\x66\x52\x88\xa3\x93\x8f\x2b\x3e\xa3\xe2\xcb\x52\x8b\x8a\x33\xf3\xf3\x52\x53\xac\x09\xea\x40\x51\xcc\xe5\xec\xef\xeb\xeb\x19\x62\xcd\x05\x00\x16\x30\xf2\x37\xba\x00\x00\x00")
+
+func _000019_org_projectsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000019_org_projectsDownSql,
+		"000019_org_projects.down.sql",
+	)
+}
+
+func _000019_org_projectsDownSql() (*asset, error) {
+	bytes, err := _000019_org_projectsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000019_org_projects.down.sql", size: 186, mode: os.FileMode(420), modTime: time.Unix(1792109709, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000019_org_projectsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb5\x92\xcd\x4e\x83\x40\x14\x85\xf7\x3c\xc5\x2c\x6d\xe2\xca\x68\x37\x5d\x51\x45\x43\x6c\xa9\xa1\x98\xb4\x31\x66\x32\x30\x57\x3a\x06\x18\x32\x73\x69\x6c\x9f\xde\x01\x6b\x0b\xa5\x54\xba\x70\x39\x73\xce\x3d\xb9\x3f\xdf\xd8\x79\x72\xbd\x91\x65\xdd\xfb\x8e\x1d\x38\x24\xb0\xc7\x13\x87\xb8\x8f\xc4\x9b\x05\xc4\x59\xb8\xf3\x60\x4e\xa4\x8a\x69\xae\xe4\x27\x44\xa8\xe9\x1a\x94\x16\x32\x03\x4e\xae\x2c\x42\x74\x91\xde\xdc\x0d\x49\xb4\x62\x8a\x45\x08\x8a\xac\x99\xda\x88\x2c\xbe\x1a\xde\x0e\xc8\x8b\xef\x4e\x6d\x7f\x49\x9e\x9d\xe5\xb5\xf1\xee\x2a\x35\x11\x19\x42\x6c\xbc\xb6\xef\xdb\x46\x31\x52\x94\x48\x6d\x12\x43\x29\x13\x60\x59\x69\x8e\x14\x30\x04\x4e\x19\x12\x14\x29\x68\x64\x69\x8e\xdb\x52\xf9\x10\x90\x70\xca\x19\x32\x8a\x9b\x1c\x34\x41\xf8\xc2\xb7\xf7\xaa\x61\xef\x75\x32\x39\x78\x32\x96\x9e\x96\x33\xc9\x81\x0a\x5e\x49\x4d\xa1\x48\x43\xd3\x58\x28\x62\xd3\x63\x43\x31\x3b\x60\x99\xd8\x32\x34\x13\xd0\x44\x1a\xbd\x5d\x9d\x17\x61\x22\xa2\xfa\x14\x7a\x25\x15\x52\x0e\x3a\x52\x22\x2f\x4b\xab\xa2\x52\x41\x81\x09\xb4\x23\x8a\x9c\x77\x8c\x5d\xa8\xa4\xb2\x5b\x83\xc3\xb1\x5c\xef\xc1\x59\xf4\x38\x96\x26\x33\xaf\xf3\x8a\xbf\x1e\x93\xdb\x37\xb6\xbe\x8c\x73\xd1\xed\xa5\x0d\x7a\x93\x46\x05\x42\xfa\x4f\xb8\x31\x15\xad\xc4\xfa\x08\x38\x69\x6c\x19\xd2\x3a\x1b\x8d\xff\x3a\x1a\x75\x41\x41\x2e\xb5\x40\xa9\x36\xad\x9a\xc3\x91\xcf\x23\x5d\x8e\x5a\xc1\x7c\x82\xc8\x2e\x54\x7b\x01\xb9\x5b\x65\x67\xc8\xde\xd0\xc9\xbd\xe9\x12\x0b\xbd\x9f\xe1\xe7\x49\xcf\x60\x7a\x52\xb9\x00\xda\xe6\xdd\x8f\xc9\x6d\x53\x71\x31\xbe\xbb\x88\xdd\xeb\xcf\xfc\xa3\x1d\x56\x83\xcc\xa6\x53\x37\x18\x59\xdf\xac\x04\x40\x3c\x3b\x05\x00\x00")
+
+func _000019_org_projectsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000019_org_projectsUpSql,
+		"000019_org_projects.up.sql",
+	)
+}
+
+func _000019_org_projectsUpSql() (*asset, error) {
+	bytes, err := _000019_org_projectsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000019_org_projects.up.sql", size: 1339, mode: os.FileMode(420), modTime: time.Unix(1792109709, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000020_timeline_eventsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x2c\x2e\x2e\x4d\x8d\x4f\x2d\x4b\xcd\x2b\x29\xb6\xc6\xaa\xa2\xa0\x34\x27\x27\xbe\x28\xb5\xb0\x34\xb5\xb8\x04\xae\x10\xa2\x32\xc4\xd1\xc9\xc7\x15\x87\x61\xf1\x65\xa9\x45\xc5\x99\xf9\x79\xa9\x29\xd6\xd8\x15\x63\x31\x17\x59\x0f\x97\xb3\xbf\xaf\xaf\x67\x88\x35\x17\x00\x99\x6d\x9b\x16\xbe\x00\x00\x00")
+
+func _000020_timeline_eventsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000020_timeline_eventsDownSql,
+		"000020_timeline_events.down.sql",
+	)
+}
+
+func _000020_timeline_eventsDownSql() (*asset, error) {
+	bytes, err := _000020_timeline_eventsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000020_timeline_events.down.sql", size: 190, mode: os.FileMode(420), modTime: time.Unix(1792110431, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000020_timeline_eventsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd5\x91\x3f\x4f\xc3\x30\x10\xc5\x77\x7f\x8a\x1b\x1b\x89\x09\x41\x97\x4e\x29\x18\x64\x91\x3f\x28\x0d\x52\x3b\x59\x6e\x72\x0a\x96\x12\x3b\xd8\x4e\xa0\x7c\x7a\x9c\x00\x82\xb4\x6a\x61\x61\x60\xb4\xde\x7b\x77\xe7\xdf\x5b\xd2\x5b\x96\x2c\x08\xb9\xca\x68\x98\x53\xc8\xc3\x65\x44\x81\xdd\x40\x92\xe6\x40\xd7\x6c\x95\xaf\x40\x5a\xdb\x21\xc7\x1e\x95\xb3\xbc\x47\x63\xa5\x56\x58\xc2\x8c\x00\xd8\xae\x39\xbf\x9c\x43\xf1\x28\x8c\x28\x1c\x1a\xe8\x85\xd9\x49\x55\xcd\xe6\x17\x01\xdc\x67\x2c\x0e\xb3\x0d\xdc\xd1\xcd\x99\xf7\x7e\x24\x2d\x48\xe5\xb0\xf2\xde\x30\xcb\x42\xaf\x78\xc9\x67\xb5\xe1\xb5\xae\xa4\x02\x87\x2f\x6e\xdc\x9e\x3c\x44\xd1\x90\x2b\x0c\x0a\x87\x25\x17\x0e\x9c\x6c\xd0\x3a\xd1\xb4\xee\x75\x50\xc6\x93\x0e\x03\xef\xf7\xaa\xae\xd9\xfa\x25\x5b\xe9\x87\x4e\x75\xa5\x4b\xe4\xb2\x1c\x83\xc3\xdb\x60\xab\xad\xf4\x17\xec\xb8\x12\x0d\x1e\x0e\xfc\x66\xd0\xcf\xca\x0f\x9d\x38\x48\xf0\x85\x8f\x25\xd7\x74\xfd\x0b\x7c\x16\xd2\xe4\x28\xd7\x4f\x4f\x70\xba\x96\xb6\xab\x6b\x6e\xf0\xa9\xf3\x48\xfe\x51\x3b\xfb\xf4\x27\xdf\x38\x5e\xda\x1f\x97\x74\x02\xe6\xd8\xd5\x0f\xb0\x27\x95\xa5\x71\xcc\xf2\x05\x79\x03\x1a\xfd\x4a\x4c\x5a\x03\x00\x00")
+
+func _000020_timeline_eventsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000020_timeline_eventsUpSql,
+		"000020_timeline_events.up.sql",
+	)
+}
+
+func _000020_timeline_eventsUpSql() (*asset, error) {
+	bytes, err := _000020_timeline_eventsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000020_timeline_events.up.sql", size: 858, mode: os.FileMode(420), modTime: time.Unix(1792110431, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000021_open_graphDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x2f\xce\x2c\xc9\x2f\xca\x4c\x2d\x06\x2a\x71\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x45\x91\x8a\x2f\x4b\x2d\x2a\xce\xcc\xcf\x4b\x4d\xe1\x52\x50\x00\x9b\xe3\xec\xef\x13\xea\xeb\x87\x64\x52\x7e\x41\x6a\x5e\x7c\x7a\x51\x62\x41\x46\x7c\x66\x6e\x62\x7a\x6a\x7c\x69\x51\x8e\x0e\x4e\xd5\xa5\xc5\x40\x43\x93\x4b\x8b\x4b\xf2\x73\xe3\xd1\x75\x02\xdd\xe1\xec\xef\xeb\xeb\x19\x62\xcd\x05\x00\x00\x9b\xcd\x47\xbb\x00\x00\x00")
+
+func _000021_open_graphDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000021_open_graphDownSql,
+		"000021_open_graph.down.sql",
+	)
+}
+
+func _000021_open_graphDownSql() (*asset, error) {
+	bytes, err := _000021_open_graphDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000021_open_graph.down.sql", size: 187, mode: os.FileMode(420), modTime: time.Unix(1792110704, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000021_open_graphUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x7d\xce\x4d\x0e\xc2\x20\x10\x40\xe1\x3d\xa7\x98\x03\x78\x83\xae\x68\x8b\x86\x04\x4a\xd2\x62\xe2\x8e\xa0\x4e\x2a\x49\xcb\x10\x7e\x8c\xc7\xb7\x6b\x17\x1e\xe0\x7d\x79\xbd\xb8\xc8\xa9\x63\x8c\x2b\x2b\x66\xb0\xbc\x57\x02\x32\x26\x2a\xa1\x52\x0e\x58\xdc\x1b\x73\x09\x14\xf1\xc9\x00\xf8\x38\xc2\x60\xd4\x55\x4f\x20\xcf\x30\x19\x0b\xe2\x26\x17\xbb\x00\x25\x8c\x6e\xcd\x3e\xbd\x5c\xd8\xfd\x8a\xae\xe5\x0d\x2a\x7e\xea\xe9\x5f\xd5\xca\xe1\x3f\x5a\xa9\xb4\xbb\x5f\x01\xee\x44\x1b\xfa\x78\xac\x0d\x46\x6b\x69\x3b\xf6\x05\x62\x88\xa3\xe4\xab\x00\x00\x00")
+
+func _000021_open_graphUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000021_open_graphUpSql,
+		"000021_open_graph.up.sql",
+	)
+}
+
+func _000021_open_graphUpSql() (*asset, error) {
+	bytes, err := _000021_open_graphUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000021_open_graph.up.sql", size: 171, mode: os.FileMode(420), modTime: time.Unix(1792110704, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000022_reactionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x4d\x4c\x2e\xc9\xcc\xcf\x2b\x86\xc9\x87\x38\x3a\xf9\xb8\x62\x53\x10\x5f\x96\x5a\x54\x0c\x64\xa4\xa6\x00\x95\x3a\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x00\x87\xeb\x5e\xb2\x5b\x00\x00\x00")
+
+func _000022_reactionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000022_reactionsDownSql,
+		"000022_reactions.down.sql",
+	)
+}
+
+func _000022_reactionsDownSql() (*asset, error) {
+	bytes, err := _000022_reactionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000022_reactions.down.sql", size: 91, mode: os.FileMode(420), modTime: time.Unix(1792111360, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000022_reactionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x8f\x3d\x0b\xc2\x30\x10\x86\xf7\xfc\x8a\x1b\x2b\x38\x89\x76\x71\x8a\x1a\x25\xd8\xa6\x12\x23\xd8\xa9\xb4\x69\xa8\x11\x6d\x21\x89\xa2\xff\xde\xa3\xf8\x31\x28\xb8\x1d\xf7\x3c\xf7\x1e\xef\x8c\xad\xb8\x98\x12\x32\x97\x8c\x2a\x06\x8a\xce\x12\x06\x7c\x09\x22\x53\xc0\xf6\x7c\xab\xb6\xe0\x4c\xa9\x83\xed\x5a\x5f\x5c\x8d\xf3\x38\x98\x1a\x22\x02\xe0\x2f\xe7\xd1\x24\x06\x7d\x28\x1d\x0a\xc6\xc1\xb5\x74\x77\xdb\x36\x51\x3c\x1e\xc0\x46\xf2\x94\xca\x1c\xd6\x2c\x1f\xa2\xfb\xbc\xf4\x60\xdb\x60\x1a\x74\xa9\x94\x14\x09\x22\xdd\xe1\xaa\x0d\x10\xcc\x2d\xf4\x6f\xc5\x2e\x49\x86\x7d\x7e\x75\x34\x3a\x14\xb6\xfe\x66\xa1\x0b\xe5\xa9\xd0\xdd\x05\x0f\x2b\xdb\x60\xea\x1b\x93\xc1\xa7\x0e\x17\x0b\xb6\xff\x57\xc7\x43\x26\x7e\x97\x7c\x09\x7d\x62\x96\xa6\x5c\x4d\xc9\x03\xc0\xb1\x04\x94\x31\x01\x00\x00")
+
+func _000022_reactionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000022_reactionsUpSql,
+		"000022_reactions.up.sql",
+	)
+}
+
+func _000022_reactionsUpSql() (*asset, error) {
+	bytes, err := _000022_reactionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000022_reactions.up.sql", size: 305, mode: os.FileMode(420), modTime: time.Unix(1792111360, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000023_pull_request_commitsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x28\xcd\xc9\x89\x2f\x4a\x2d\x2c\x4d\x2d\x2e\x89\x4f\xce\xcf\xcd\xcd\x2c\x29\x86\x29\x0d\x71\x74\xf2\x71\x25\xa0\x36\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x05\xa8\xcb\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\xb6\x01\x7d\xca\x71\x00\x00\x00")
+
+func _000023_pull_request_commitsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000023_pull_request_commitsDownSql,
+		"000023_pull_request_commits.down.sql",
+	)
+}
+
+func _000023_pull_request_commitsDownSql() (*asset, error) {
+	bytes, err := _000023_pull_request_commitsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000023_pull_request_commits.down.sql", size: 113, mode: os.FileMode(420), modTime: time.Unix(1792112160, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000023_pull_request_commitsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x92\x5f\x4f\xc2\x30\x14\xc5\xdf\xf7\x29\xee\x23\x24\x7b\x32\xca\x0b\x4f\x43\xab\x69\xdc\x1f\x33\x66\x02\x4f\x4b\xd9\x9a\xd1\x64\x6d\x67\x7b\x87\xe2\xa7\xb7\x80\x66\x0c\x98\xca\x63\x77\x7e\xeb\x39\xf7\xf4\xce\xc8\x13\x8d\xa7\x9e\x77\x9f\x92\x20\x23\x90\x05\xb3\x90\x00\x7d\x84\x38\xc9\x80\x2c\xe8\x3c\x9b\x43\xd3\xd6\x75\x6e\xf8\x5b\xcb\x2d\xe6\x85\x96\x52\xa0\xcd\x37\xdc\x58\xa1\x15\x2f\x61\xe4\x01\xd8\x56\xde\xdc\x4d\xa0\x58\x33\xc3\x0a\xe4\x06\x36\xcc\x6c\x85\xaa\x46\x93\xdb\x31\xbc\xa4\x34\x0a\xd2\x25\x3c\x93\xa5\xef\xd8\xef\x3f\x2d\x08\x85\xbc\x72\x6c\x90\xa6\x81\x53\x9c\xc4\x5a\x5c\x6b\x93\x73\xc9\x44\x0d\xc8\x3f\x70\x1f\x23\x7e\x0d\x43\xbf\x53\x6b\x5d\x09\x35\xa8\x2a\x26\xf9\x90\xc8\xcb\x9c\x21\xa0\x90\x6e\x10\x26\x1b\xfc\xdc\x49\x87\x81\xf0\x57\x6d\x30\x52\x07\x0c\xa4\xea\x80\xcb\xc1\x9c\x9d\x65\xd5\x41\xd8\x9d\xb5\x28\xcf\xa1\xde\x03\xa8\x56\xae\x5c\x69\x2b\xe1\xfc\xfa\x98\xe1\x8d\xb6\x02\xb5\xd9\x0e\x98\x1d\x01\xfa\x5d\xb9\x4b\x7a\x84\x37\xee\xd6\x80\xc6\x0f\x64\x71\xc5\x1a\x58\x48\xe2\x3f\xf7\xe4\x87\x75\x3e\xd7\xda\x1c\x7f\xfc\x8f\xd5\xe9\xa4\xfe\x69\x39\xfe\xa5\x52\xf7\x05\x24\x51\x44\xb3\xa9\xf7\x05\xc3\x50\xf2\x16\x18\x03\x00\x00")
+
+func _000023_pull_request_commitsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000023_pull_request_commitsUpSql,
+		"000023_pull_request_commits.up.sql",
+	)
+}
+
+func _000023_pull_request_commitsUpSql() (*asset, error) {
+	bytes, err := _000023_pull_request_commitsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000023_pull_request_commits.up.sql", size: 792, mode: os.FileMode(420), modTime: time.Unix(1792112160, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000024_milestonesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x2c\x2e\x2e\x4d\x2d\xb6\xc6\x2a\x57\x50\x9a\x93\x13\x5f\x94\x5a\x08\x54\x50\x02\x54\xc2\xe5\xe8\x13\xe2\x1a\xa4\x10\xe2\xe8\xe4\xe3\x0a\xd5\x17\x5f\x96\x5a\x54\x9c\x99\x9f\x97\x9a\xc2\xa5\xa0\x00\x36\xc2\xd9\xdf\x27\xd4\xd7\x0f\xc9\x90\xdc\xcc\x1c\xa0\x76\xa0\x92\xf8\x94\xd2\xd4\xf8\xfc\x3c\x1d\x22\x54\x16\x97\x24\x96\xa4\xa2\x59\x88\xe2\x18\x1a\xdb\xeb\xec\xef\xeb\xeb\x19\x62\xcd\x05\x00\x26\xda\xc1\xc1\x39\x01\x00\x00")
+
+func _000024_milestonesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000024_milestonesDownSql,
+		"000024_milestones.down.sql",
+	)
+}
+
+func _000024_milestonesDownSql() (*asset, error) {
+	bytes, err := _000024_milestonesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000024_milestones.down.sql", size: 313, mode: os.FileMode(420), modTime: time.Unix(1792112506, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000024_milestonesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcd\x8e\xbd\x0a\xc2\x30\x18\x45\xf7\x3c\xc5\xdd\xba\xf8\x06\x9d\xd2\x36\x95\x40\x9a\x80\x4d\xc1\x2d\x08\xfd\x86\x40\xff\x6c\xbe\x8a\xf8\xf4\x06\x47\x27\x47\xd7\x7b\xcf\x81\x53\xa9\xb3\xb6\xa5\x10\xd2\x78\x75\x81\x97\x95\x51\x88\x29\x1d\x94\xc2\x83\xf6\x14\xd7\x85\x46\x01\xc8\xa6\x41\xed\xcc\xd0\x59\xe8\x16\xd6\x79\xa8\xab\xee\x7d\x8f\x39\x4e\x94\x38\x53\x61\x3c\x28\xac\x0b\x38\xce\x79\xb8\xcd\x1b\xbf\x4e\xbf\x89\x99\x66\x02\xd3\x93\x3f\xbf\x1d\x8c\x41\xa3\x5a\x39\x18\x8f\xa2\xf8\x6a\xdb\x8e\x69\x0a\x3b\xdd\x73\x20\xff\x51\x62\xed\xba\x4e\xfb\x52\xbc\x01\x90\x96\x82\xf4\x4f\x01\x00\x00")
+
+func _000024_milestonesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000024_milestonesUpSql,
+		"000024_milestones.up.sql",
+	)
+}
+
+func _000024_milestonesUpSql() (*asset, error) {
+	bytes, err := _000024_milestonesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000024_milestones.up.sql", size: 335, mode: os.FileMode(420), modTime: time.Unix(1792112506, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000025_last_editedDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\xf3\x74\x0d\x57\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\x2c\x2e\x2e\x4d\x2d\xb6\xc6\x2d\x17\x9f\x9c\x9f\x9b\x9b\x9a\x57\x82\x43\x4d\x41\x69\x4e\x4e\x7c\x51\x6a\x21\xd0\x10\x62\x94\x00\xe9\xb2\xcc\xd4\x72\x62\x54\x22\xec\xe5\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x85\x3a\x38\xbe\x2c\xb5\xa8\x38\x33\x3f\x2f\x35\x85\x4b\x41\x01\x6c\x94\xb3\xbf\x4f\xa8\xaf\x1f\x92\x61\x39\x89\x40\x43\x52\x53\x32\x4b\x52\x53\xe2\x13\x4b\x74\x88\x52\x97\x54\x19\x9f\x99\x42\xb4\xd2\x9c\xfc\xf4\xcc\x3c\x6c\xce\x83\xbb\x7d\xd0\x3a\x13\x25\xda\x86\x84\x2b\x61\x29\x67\x68\x38\x76\x50\x26\x00\x67\x7f\x5f\x5f\xcf\x10\x6b\x2e\x00\xd7\xd4\x15\xa3\x10\x04\x00\x00")
+
+func _000025_last_editedDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000025_last_editedDownSql,
+		"000025_last_edited.down.sql",
+	)
+}
+
+func _000025_last_editedDownSql() (*asset, error) {
+	bytes, err := _000025_last_editedDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000025_last_edited.down.sql", size: 1040, mode: os.FileMode(420), modTime: time.Unix(1792113069, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __000025_last_editedUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xe5\x92\xbb\x0a\x83\x40\x10\x45\x7b\xbf\x62\x3a\x9b\x14\xe9\xad\x7c\xac\x61\x61\x55\x88\x2b\xa4\x5b\x34\x3b\x84\x85\xf5\x11\x77\x34\x8f\xaf\x8f\xa4\xb6\x48\x5a\xad\xa6\xb9\xf7\xc0\xe1\x4e\xc4\x4e\x3c\x0f\x3c\x2f\x14\x92\x9d\x41\x86\x91\x60\x60\x9c\x9b\xd0\xa9\x19\x47\x67\xfa\x0e\xb5\x07\x10\x26\x09\xc4\x85\xa8\xb2\x1c\x78\x0a\x79\x21\x81\x5d\x78\x29\x4b\xb0\xb5\x23\x85\xda\x10\x6a\x55\x13\x90\x69\xd1\x51\xdd\x0e\xf4\x3e\xfc\x5a\x6b\x5e\xca\x68\x68\xcc\xcd\x74\xf4\xcd\xe4\x95\x10\x90\xb0\x34\xac\x84\x84\xe3\x3f\x1c\xdb\x2f\x10\x20\x7c\xae\x80\x7c\x7f\x4d\x53\x5d\xfb\xb6\xc5\x8e\xb6\xaf\x3b\x4c\xd6\xaa\x11\xef\xcb\xb4\x3b\xb3\x5d\xee\x6c\xf0\xb1\x33\xe9\x4d\x3f\x76\x5c\x64\x19\x97\x81\xf7\x01\xe3\x41\xcc\x6f\xbf\x04\x00\x00")
+
+func _000025_last_editedUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000025_last_editedUpSql,
+		"000025_last_edited.up.sql",
+	)
+}
+
+func _000025_last_editedUpSql() (*asset, error) {
+	bytes, err := _000025_last_editedUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000025_last_edited.up.sql", size: 1215, mode: os.FileMode(420), modTime: time.Unix(1792113069, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -170,8 +1178,56 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"000001_init.down.sql": _000001_initDownSql,
-	"000001_init.up.sql":   _000001_initUpSql,
+	"000001_init.down.sql":                         _000001_initDownSql,
+	"000001_init.up.sql":                           _000001_initUpSql,
+	"000002_topics.down.sql":                       _000002_topicsDownSql,
+	"000002_topics.up.sql":                         _000002_topicsUpSql,
+	"000003_default_branch_head.down.sql":          _000003_default_branch_headDownSql,
+	"000003_default_branch_head.up.sql":            _000003_default_branch_headUpSql,
+	"000004_review_transitions.down.sql":           _000004_review_transitionsDownSql,
+	"000004_review_transitions.up.sql":             _000004_review_transitionsUpSql,
+	"000005_assignment_events.down.sql":            _000005_assignment_eventsDownSql,
+	"000005_assignment_events.up.sql":              _000005_assignment_eventsUpSql,
+	"000006_pull_request_mergeable_state.down.sql": _000006_pull_request_mergeable_stateDownSql,
+	"000006_pull_request_mergeable_state.up.sql":   _000006_pull_request_mergeable_stateUpSql,
+	"000007_mentions.down.sql":                     _000007_mentionsDownSql,
+	"000007_mentions.up.sql":                       _000007_mentionsUpSql,
+	"000008_body_truncated.down.sql":               _000008_body_truncatedDownSql,
+	"000008_body_truncated.up.sql":                 _000008_body_truncatedUpSql,
+	"000009_repository_rulesets.down.sql":          _000009_repository_rulesetsDownSql,
+	"000009_repository_rulesets.up.sql":            _000009_repository_rulesetsUpSql,
+	"000010_audit_log_entries.down.sql":            _000010_audit_log_entriesDownSql,
+	"000010_audit_log_entries.up.sql":              _000010_audit_log_entriesUpSql,
+	"000011_task_lists.down.sql":                   _000011_task_listsDownSql,
+	"000011_task_lists.up.sql":                     _000011_task_listsUpSql,
+	"000012_closing_references.down.sql":           _000012_closing_referencesDownSql,
+	"000012_closing_references.up.sql":             _000012_closing_referencesUpSql,
+	"000013_merge_method.down.sql":                 _000013_merge_methodDownSql,
+	"000013_merge_method.up.sql":                   _000013_merge_methodUpSql,
+	"000014_locks.down.sql":                        _000014_locksDownSql,
+	"000014_locks.up.sql":                          _000014_locksUpSql,
+	"000015_templates.down.sql":                    _000015_templatesDownSql,
+	"000015_templates.up.sql":                      _000015_templatesUpSql,
+	"000016_lock_node_id.down.sql":                 _000016_lock_node_idDownSql,
+	"000016_lock_node_id.up.sql":                   _000016_lock_node_idUpSql,
+	"000017_project_statuses.down.sql":             _000017_project_statusesDownSql,
+	"000017_project_statuses.up.sql":               _000017_project_statusesUpSql,
+	"000018_readmes.down.sql":                      _000018_readmesDownSql,
+	"000018_readmes.up.sql":                        _000018_readmesUpSql,
+	"000019_org_projects.down.sql":                 _000019_org_projectsDownSql,
+	"000019_org_projects.up.sql":                   _000019_org_projectsUpSql,
+	"000020_timeline_events.down.sql":              _000020_timeline_eventsDownSql,
+	"000020_timeline_events.up.sql":                _000020_timeline_eventsUpSql,
+	"000021_open_graph.down.sql":                   _000021_open_graphDownSql,
+	"000021_open_graph.up.sql":                     _000021_open_graphUpSql,
+	"000022_reactions.down.sql":                    _000022_reactionsDownSql,
+	"000022_reactions.up.sql":                      _000022_reactionsUpSql,
+	"000023_pull_request_commits.down.sql":         _000023_pull_request_commitsDownSql,
+	"000023_pull_request_commits.up.sql":           _000023_pull_request_commitsUpSql,
+	"000024_milestones.down.sql":                   _000024_milestonesDownSql,
+	"000024_milestones.up.sql":                     _000024_milestonesUpSql,
+	"000025_last_edited.down.sql":                  _000025_last_editedDownSql,
+	"000025_last_edited.up.sql":                    _000025_last_editedUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -215,8 +1271,56 @@ type bintree struct {
 }
 
 var _bintree = &bintree{nil, map[string]*bintree{
-	"000001_init.down.sql": &bintree{_000001_initDownSql, map[string]*bintree{}},
-	"000001_init.up.sql":   &bintree{_000001_initUpSql, map[string]*bintree{}},
+	"000001_init.down.sql":                         &bintree{_000001_initDownSql, map[string]*bintree{}},
+	"000001_init.up.sql":                           &bintree{_000001_initUpSql, map[string]*bintree{}},
+	"000002_topics.down.sql":                       &bintree{_000002_topicsDownSql, map[string]*bintree{}},
+	"000002_topics.up.sql":                         &bintree{_000002_topicsUpSql, map[string]*bintree{}},
+	"000003_default_branch_head.down.sql":          &bintree{_000003_default_branch_headDownSql, map[string]*bintree{}},
+	"000003_default_branch_head.up.sql":            &bintree{_000003_default_branch_headUpSql, map[string]*bintree{}},
+	"000004_review_transitions.down.sql":           &bintree{_000004_review_transitionsDownSql, map[string]*bintree{}},
+	"000004_review_transitions.up.sql":             &bintree{_000004_review_transitionsUpSql, map[string]*bintree{}},
+	"000005_assignment_events.down.sql":            &bintree{_000005_assignment_eventsDownSql, map[string]*bintree{}},
+	"000005_assignment_events.up.sql":              &bintree{_000005_assignment_eventsUpSql, map[string]*bintree{}},
+	"000006_pull_request_mergeable_state.down.sql": &bintree{_000006_pull_request_mergeable_stateDownSql, map[string]*bintree{}},
+	"000006_pull_request_mergeable_state.up.sql":   &bintree{_000006_pull_request_mergeable_stateUpSql, map[string]*bintree{}},
+	"000007_mentions.down.sql":                     &bintree{_000007_mentionsDownSql, map[string]*bintree{}},
+	"000007_mentions.up.sql":                       &bintree{_000007_mentionsUpSql, map[string]*bintree{}},
+	"000008_body_truncated.down.sql":               &bintree{_000008_body_truncatedDownSql, map[string]*bintree{}},
+	"000008_body_truncated.up.sql":                 &bintree{_000008_body_truncatedUpSql, map[string]*bintree{}},
+	"000009_repository_rulesets.down.sql":          &bintree{_000009_repository_rulesetsDownSql, map[string]*bintree{}},
+	"000009_repository_rulesets.up.sql":            &bintree{_000009_repository_rulesetsUpSql, map[string]*bintree{}},
+	"000010_audit_log_entries.down.sql":            &bintree{_000010_audit_log_entriesDownSql, map[string]*bintree{}},
+	"000010_audit_log_entries.up.sql":              &bintree{_000010_audit_log_entriesUpSql, map[string]*bintree{}},
+	"000011_task_lists.down.sql":                   &bintree{_000011_task_listsDownSql, map[string]*bintree{}},
+	"000011_task_lists.up.sql":                     &bintree{_000011_task_listsUpSql, map[string]*bintree{}},
+	"000012_closing_references.down.sql":           &bintree{_000012_closing_referencesDownSql, map[string]*bintree{}},
+	"000012_closing_references.up.sql":             &bintree{_000012_closing_referencesUpSql, map[string]*bintree{}},
+	"000013_merge_method.down.sql":                 &bintree{_000013_merge_methodDownSql, map[string]*bintree{}},
+	"000013_merge_method.up.sql":                   &bintree{_000013_merge_methodUpSql, map[string]*bintree{}},
+	"000014_locks.down.sql":                        &bintree{_000014_locksDownSql, map[string]*bintree{}},
+	"000014_locks.up.sql":                          &bintree{_000014_locksUpSql, map[string]*bintree{}},
+	"000015_templates.down.sql":                    &bintree{_000015_templatesDownSql, map[string]*bintree{}},
+	"000015_templates.up.sql":                      &bintree{_000015_templatesUpSql, map[string]*bintree{}},
+	"000016_lock_node_id.down.sql":                 &bintree{_000016_lock_node_idDownSql, map[string]*bintree{}},
+	"000016_lock_node_id.up.sql":                   &bintree{_000016_lock_node_idUpSql, map[string]*bintree{}},
+	"000017_project_statuses.down.sql":             &bintree{_000017_project_statusesDownSql, map[string]*bintree{}},
+	"000017_project_statuses.up.sql":               &bintree{_000017_project_statusesUpSql, map[string]*bintree{}},
+	"000018_readmes.down.sql":                      &bintree{_000018_readmesDownSql, map[string]*bintree{}},
+	"000018_readmes.up.sql":                        &bintree{_000018_readmesUpSql, map[string]*bintree{}},
+	"000019_org_projects.down.sql":                 &bintree{_000019_org_projectsDownSql, map[string]*bintree{}},
+	"000019_org_projects.up.sql":                   &bintree{_000019_org_projectsUpSql, map[string]*bintree{}},
+	"000020_timeline_events.down.sql":              &bintree{_000020_timeline_eventsDownSql, map[string]*bintree{}},
+	"000020_timeline_events.up.sql":                &bintree{_000020_timeline_eventsUpSql, map[string]*bintree{}},
+	"000021_open_graph.down.sql":                   &bintree{_000021_open_graphDownSql, map[string]*bintree{}},
+	"000021_open_graph.up.sql":                     &bintree{_000021_open_graphUpSql, map[string]*bintree{}},
+	"000022_reactions.down.sql":                    &bintree{_000022_reactionsDownSql, map[string]*bintree{}},
+	"000022_reactions.up.sql":                      &bintree{_000022_reactionsUpSql, map[string]*bintree{}},
+	"000023_pull_request_commits.down.sql":         &bintree{_000023_pull_request_commitsDownSql, map[string]*bintree{}},
+	"000023_pull_request_commits.up.sql":           &bintree{_000023_pull_request_commitsUpSql, map[string]*bintree{}},
+	"000024_milestones.down.sql":                   &bintree{_000024_milestonesDownSql, map[string]*bintree{}},
+	"000024_milestones.up.sql":                     &bintree{_000024_milestonesUpSql, map[string]*bintree{}},
+	"000025_last_edited.down.sql":                  &bintree{_000025_last_editedDownSql, map[string]*bintree{}},
+	"000025_last_edited.up.sql":                    &bintree{_000025_last_editedUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS repository_topics;
+DROP VIEW IF EXISTS topics;
+
+DROP TABLE IF EXISTS repository_topics_versioned;
+DROP TABLE IF EXISTS topics_versioned;
+
+COMMIT;
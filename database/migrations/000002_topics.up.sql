@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS topics_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  name text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS topics_versions ON topics_versioned (versions);
+
+CREATE TABLE IF NOT EXISTS repository_topics_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL,
+  topic text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS repository_topics_versions ON repository_topics_versioned (versions);
+CREATE INDEX IF NOT EXISTS repository_topics_topic ON repository_topics_versioned (topic);
+
+COMMIT;
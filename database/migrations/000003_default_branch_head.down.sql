@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS repositories;
+
+ALTER TABLE repositories_versioned
+  DROP COLUMN IF EXISTS default_branch_sha,
+  DROP COLUMN IF EXISTS default_branch_committed_at;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE repositories_versioned
+  ADD COLUMN IF NOT EXISTS default_branch_sha text,
+  ADD COLUMN IF NOT EXISTS default_branch_committed_at timestamptz;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS pull_request_review_transitions;
+
+DROP TABLE IF EXISTS pull_request_review_transitions_versioned;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS pull_request_review_transitions_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  from_state text NOT NULL,
+  pull_request_number bigint NOT NULL,
+  pull_request_review_id bigint,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL,
+  sequence bigint NOT NULL,
+  submitted_at timestamptz,
+  to_state text NOT NULL,
+  user_login text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS pull_request_review_transitions_versions ON pull_request_review_transitions_versioned (versions);
+
+COMMIT;
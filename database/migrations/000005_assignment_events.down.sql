@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS assignment_events;
+
+DROP TABLE IF EXISTS assignment_events_versioned;
+
+COMMIT;
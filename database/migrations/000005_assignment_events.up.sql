@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS assignment_events_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  actor_login text NOT NULL,
+  assignee_login text NOT NULL,
+  created_at timestamptz,
+  event text NOT NULL,
+  node_id text,
+  number bigint NOT NULL,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS assignment_events_versions ON assignment_events_versioned (versions);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS pull_requests;
+
+ALTER TABLE pull_requests_versioned
+  DROP COLUMN IF EXISTS mergeable_state,
+  DROP COLUMN IF EXISTS potential_merge_commit_sha;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE pull_requests_versioned
+  ADD COLUMN IF NOT EXISTS mergeable_state text,
+  ADD COLUMN IF NOT EXISTS potential_merge_commit_sha text;
+
+COMMIT;
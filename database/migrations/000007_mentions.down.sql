@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS mentions;
+
+DROP TABLE IF EXISTS mentions_versioned;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS mentions_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  mentioned_login text NOT NULL,
+  subject_id text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS mentions_versions ON mentions_versioned (versions);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+ALTER TABLE issues_versioned
+  ADD COLUMN IF NOT EXISTS body_truncated boolean NOT NULL DEFAULT false;
+
+ALTER TABLE issue_comments_versioned
+  ADD COLUMN IF NOT EXISTS body_truncated boolean NOT NULL DEFAULT false;
+
+ALTER TABLE pull_requests_versioned
+  ADD COLUMN IF NOT EXISTS body_truncated boolean NOT NULL DEFAULT false;
+
+ALTER TABLE pull_request_reviews_versioned
+  ADD COLUMN IF NOT EXISTS body_truncated boolean NOT NULL DEFAULT false;
+
+ALTER TABLE pull_request_comments_versioned
+  ADD COLUMN IF NOT EXISTS body_truncated boolean NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS repository_rulesets;
+
+DROP TABLE IF EXISTS repository_rulesets_versioned;
+
+COMMIT;
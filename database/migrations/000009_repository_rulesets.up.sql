@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS repository_rulesets_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  conditions_exclude text[] NOT NULL,
+  conditions_include text[] NOT NULL,
+  created_at timestamptz,
+  enforcement text,
+  id bigint,
+  name text,
+  node_id text,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL,
+  rule_types text[] NOT NULL,
+  target text,
+  updated_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS repository_rulesets_versions ON repository_rulesets_versioned (versions);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS audit_log_entries;
+
+DROP TABLE IF EXISTS audit_log_entries_versioned;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log_entries_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  action text NOT NULL,
+  actor_ip text,
+  actor_login text,
+  created_at timestamptz,
+  entry_type text NOT NULL,
+  node_id text,
+  operation_type text,
+  organization_login text NOT NULL,
+  user_login text
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entries_versions ON audit_log_entries_versioned (versions);
+
+COMMIT;
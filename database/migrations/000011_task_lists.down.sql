@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP VIEW IF EXISTS issues;
+DROP VIEW IF EXISTS pull_requests;
+
+ALTER TABLE issues_versioned
+  DROP COLUMN IF EXISTS tasks_done,
+  DROP COLUMN IF EXISTS tasks_total;
+
+ALTER TABLE pull_requests_versioned
+  DROP COLUMN IF EXISTS tasks_done,
+  DROP COLUMN IF EXISTS tasks_total;
+
+COMMIT;
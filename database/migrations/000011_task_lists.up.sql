@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE issues_versioned
+  ADD COLUMN IF NOT EXISTS tasks_done bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS tasks_total bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE pull_requests_versioned
+  ADD COLUMN IF NOT EXISTS tasks_done bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS tasks_total bigint NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS closing_references;
+
+DROP TABLE IF EXISTS closing_references_versioned;
+
+COMMIT;
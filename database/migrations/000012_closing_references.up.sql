@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS closing_references_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  issue_number bigint NOT NULL,
+  issue_repository_name text NOT NULL,
+  issue_repository_owner text NOT NULL,
+  pull_request_number bigint NOT NULL,
+  pull_request_repository_name text NOT NULL,
+  pull_request_repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS closing_references_versions ON closing_references_versioned (versions);
+CREATE INDEX IF NOT EXISTS closing_references_issue ON closing_references_versioned (issue_repository_owner, issue_repository_name, issue_number);
+CREATE INDEX IF NOT EXISTS closing_references_pull_request ON closing_references_versioned (pull_request_repository_owner, pull_request_repository_name, pull_request_number);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP VIEW IF EXISTS pull_requests;
+
+ALTER TABLE pull_requests_versioned
+  DROP COLUMN IF EXISTS merge_method;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE pull_requests_versioned
+  ADD COLUMN IF NOT EXISTS merge_method text NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS locks;
+
+DROP TABLE IF EXISTS locks_versioned;
+
+COMMIT;
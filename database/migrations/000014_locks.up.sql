@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS locks_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  lock_reason text NOT NULL,
+  locked_at timestamptz,
+  locked_by_id bigint NOT NULL,
+  locked_by_login text NOT NULL,
+  number bigint NOT NULL,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS locks_versions ON locks_versioned (versions);
+CREATE INDEX IF NOT EXISTS locks_number ON locks_versioned (repository_owner, repository_name, number);
+
+COMMIT;
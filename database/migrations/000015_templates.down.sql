@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS templates;
+
+DROP TABLE IF EXISTS templates_versioned;
+
+COMMIT;
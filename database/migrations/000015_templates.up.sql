@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS templates_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  about text NOT NULL,
+  body text NOT NULL,
+  filename text NOT NULL,
+  kind text NOT NULL,
+  name text NOT NULL,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL,
+  title text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS templates_versions ON templates_versioned (versions);
+CREATE INDEX IF NOT EXISTS templates_repository ON templates_versioned (repository_owner, repository_name);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP VIEW IF EXISTS locks;
+
+ALTER TABLE locks_versioned
+  DROP COLUMN IF EXISTS node_id;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE locks_versioned
+  ADD COLUMN IF NOT EXISTS node_id text NOT NULL DEFAULT '';
+
+COMMIT;
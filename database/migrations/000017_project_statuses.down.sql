@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS project_statuses;
+
+DROP TABLE IF EXISTS project_statuses_versioned;
+
+COMMIT;
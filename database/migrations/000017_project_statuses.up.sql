@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS project_statuses_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  node_id text NOT NULL,
+  project_node_id text NOT NULL,
+  project_number bigint NOT NULL,
+  project_title text NOT NULL,
+  pull_request_number bigint NOT NULL,
+  pull_request_updated_at timestamptz,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL,
+  status text NOT NULL,
+  status_updated_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS project_statuses_versions ON project_statuses_versioned (versions);
+CREATE INDEX IF NOT EXISTS project_statuses_pull_request ON project_statuses_versioned (repository_owner, repository_name, pull_request_number);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS readmes;
+
+DROP TABLE IF EXISTS readmes_versioned;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS readmes_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  body text NOT NULL,
+  byte_size bigint NOT NULL,
+  filename text NOT NULL,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS readmes_versions ON readmes_versioned (versions);
+CREATE INDEX IF NOT EXISTS readmes_repository ON readmes_versioned (repository_owner, repository_name);
+
+COMMIT;
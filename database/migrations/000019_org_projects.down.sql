@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS org_project_items;
+DROP VIEW IF EXISTS org_projects;
+
+DROP TABLE IF EXISTS org_project_items_versioned;
+DROP TABLE IF EXISTS org_projects_versioned;
+
+COMMIT;
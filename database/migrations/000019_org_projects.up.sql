@@ -0,0 +1,47 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS org_projects_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  closed boolean,
+  created_at timestamptz,
+  field_data_types text[] NOT NULL,
+  field_names text[] NOT NULL,
+  node_id text NOT NULL,
+  number bigint NOT NULL,
+  organization_login text NOT NULL,
+  public boolean,
+  short_description text,
+  title text NOT NULL,
+  updated_at timestamptz,
+  url text
+);
+
+CREATE INDEX IF NOT EXISTS org_projects_versions ON org_projects_versioned (versions);
+CREATE INDEX IF NOT EXISTS org_projects_organization ON org_projects_versioned (organization_login);
+
+CREATE TABLE IF NOT EXISTS org_project_items_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  archived boolean,
+  content_node_id text,
+  content_number bigint,
+  content_repository text,
+  content_title text,
+  created_at timestamptz,
+  item_type text NOT NULL,
+  node_id text NOT NULL,
+  organization_login text NOT NULL,
+  project_node_id text NOT NULL,
+  project_number bigint NOT NULL,
+  status text,
+  status_updated_at timestamptz,
+  updated_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS org_project_items_versions ON org_project_items_versioned (versions);
+CREATE INDEX IF NOT EXISTS org_project_items_project ON org_project_items_versioned (project_node_id);
+
+COMMIT;
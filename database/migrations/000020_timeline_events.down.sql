@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS issue_events;
+DROP VIEW IF EXISTS pull_request_events;
+
+DROP TABLE IF EXISTS issue_events_versioned;
+DROP TABLE IF EXISTS pull_request_events_versioned;
+
+COMMIT;
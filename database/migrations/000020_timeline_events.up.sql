@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS issue_events_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  actor_login text NOT NULL,
+  created_at timestamptz,
+  event text NOT NULL,
+  issue_number bigint NOT NULL,
+  node_id text,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS issue_events_versions ON issue_events_versioned (versions);
+
+CREATE TABLE IF NOT EXISTS pull_request_events_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  actor_login text NOT NULL,
+  created_at timestamptz,
+  event text NOT NULL,
+  node_id text,
+  pull_request_number bigint NOT NULL,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS pull_request_events_versions ON pull_request_events_versioned (versions);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS repositories;
+
+ALTER TABLE repositories_versioned
+  DROP COLUMN IF EXISTS open_graph_image_url,
+  DROP COLUMN IF EXISTS uses_custom_open_graph_image;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE repositories_versioned
+  ADD COLUMN IF NOT EXISTS open_graph_image_url text,
+  ADD COLUMN IF NOT EXISTS uses_custom_open_graph_image boolean;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS reactions;
+
+DROP TABLE IF EXISTS reactions_versioned;
+
+COMMIT;
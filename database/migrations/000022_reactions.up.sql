@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS reactions_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  content text NOT NULL,
+  subject_id text NOT NULL,
+  total_count bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS reactions_versions ON reactions_versioned (versions);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP VIEW IF EXISTS pull_request_commits;
+
+DROP TABLE IF EXISTS pull_request_commits_versioned;
+
+COMMIT;
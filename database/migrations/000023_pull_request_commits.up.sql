@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS pull_request_commits_versioned (
+  sum256 character varying(64) PRIMARY KEY,
+  versions integer ARRAY,
+
+  author_email text NOT NULL,
+  author_login text NOT NULL,
+  author_name text NOT NULL,
+  authored_at timestamptz,
+  committed_at timestamptz,
+  committer_email text NOT NULL,
+  committer_login text NOT NULL,
+  committer_name text NOT NULL,
+  message text,
+  oid text NOT NULL,
+  pull_request_number bigint NOT NULL,
+  repository_name text NOT NULL,
+  repository_owner text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS pull_request_commits_versions ON pull_request_commits_versioned (versions);
+CREATE INDEX IF NOT EXISTS pull_request_commits_pull_request ON pull_request_commits_versioned (repository_owner, repository_name, pull_request_number);
+
+COMMIT;
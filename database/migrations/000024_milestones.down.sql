@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP VIEW IF EXISTS issues;
+DROP VIEW IF EXISTS pull_requests;
+
+ALTER TABLE issues_versioned
+  DROP COLUMN IF EXISTS milestone_due_on,
+  DROP COLUMN IF EXISTS milestone_state;
+
+ALTER TABLE pull_requests_versioned
+  DROP COLUMN IF EXISTS milestone_due_on,
+  DROP COLUMN IF EXISTS milestone_state;
+
+COMMIT;
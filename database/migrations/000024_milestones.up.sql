@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE issues_versioned
+  ADD COLUMN IF NOT EXISTS milestone_due_on timestamptz,
+  ADD COLUMN IF NOT EXISTS milestone_state text NOT NULL DEFAULT '';
+
+ALTER TABLE pull_requests_versioned
+  ADD COLUMN IF NOT EXISTS milestone_due_on timestamptz,
+  ADD COLUMN IF NOT EXISTS milestone_state text NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+DROP VIEW IF EXISTS issues;
+DROP VIEW IF EXISTS issue_comments;
+DROP VIEW IF EXISTS pull_requests;
+DROP VIEW IF EXISTS pull_request_reviews;
+DROP VIEW IF EXISTS pull_request_comments;
+
+ALTER TABLE issues_versioned
+  DROP COLUMN IF EXISTS last_edited_at,
+  DROP COLUMN IF EXISTS last_edited_by_id,
+  DROP COLUMN IF EXISTS last_edited_by_login;
+
+ALTER TABLE issue_comments_versioned
+  DROP COLUMN IF EXISTS last_edited_at,
+  DROP COLUMN IF EXISTS last_edited_by_id,
+  DROP COLUMN IF EXISTS last_edited_by_login;
+
+ALTER TABLE pull_requests_versioned
+  DROP COLUMN IF EXISTS last_edited_at,
+  DROP COLUMN IF EXISTS last_edited_by_id,
+  DROP COLUMN IF EXISTS last_edited_by_login;
+
+ALTER TABLE pull_request_reviews_versioned
+  DROP COLUMN IF EXISTS last_edited_at,
+  DROP COLUMN IF EXISTS last_edited_by_id,
+  DROP COLUMN IF EXISTS last_edited_by_login;
+
+ALTER TABLE pull_request_comments_versioned
+  DROP COLUMN IF EXISTS last_edited_at,
+  DROP COLUMN IF EXISTS last_edited_by_id,
+  DROP COLUMN IF EXISTS last_edited_by_login;
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+ALTER TABLE issues_versioned
+  ADD COLUMN IF NOT EXISTS last_edited_at timestamptz,
+  ADD COLUMN IF NOT EXISTS last_edited_by_id bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_edited_by_login text NOT NULL DEFAULT '';
+
+ALTER TABLE issue_comments_versioned
+  ADD COLUMN IF NOT EXISTS last_edited_at timestamptz,
+  ADD COLUMN IF NOT EXISTS last_edited_by_id bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_edited_by_login text NOT NULL DEFAULT '';
+
+ALTER TABLE pull_requests_versioned
+  ADD COLUMN IF NOT EXISTS last_edited_at timestamptz,
+  ADD COLUMN IF NOT EXISTS last_edited_by_id bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_edited_by_login text NOT NULL DEFAULT '';
+
+ALTER TABLE pull_request_reviews_versioned
+  ADD COLUMN IF NOT EXISTS last_edited_at timestamptz,
+  ADD COLUMN IF NOT EXISTS last_edited_by_id bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_edited_by_login text NOT NULL DEFAULT '';
+
+ALTER TABLE pull_request_comments_versioned
+  ADD COLUMN IF NOT EXISTS last_edited_at timestamptz,
+  ADD COLUMN IF NOT EXISTS last_edited_by_id bigint NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_edited_by_login text NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// the kinds of GitLab resources with notes, as they appear in the API paths
+const (
+	issuesKind        = "issues"
+	mergeRequestsKind = "merge_requests"
+)
+
+// gitlabClient creates the issues, merge requests and notes of a GitLab
+// project with the REST API v4, see https://docs.gitlab.com/ee/api/
+type gitlabClient struct {
+	httpClient *http.Client
+	// baseURL is the URL of the GitLab instance, e.g. https://gitlab.com
+	baseURL string
+	token   string
+	// project is the ID or the path of the project, e.g. group/project
+	project string
+}
+
+// created is the part of a created issue or merge request used by the
+// migration, its number in the project
+type created struct {
+	IID int `json:"iid"`
+}
+
+// createIssue creates an open issue and returns its number
+func (c *gitlabClient) createIssue(ctx context.Context, title, description string, labels []string) (int, error) {
+	var res created
+	err := c.do(ctx, http.MethodPost, issuesKind, url.Values{
+		"title":       {title},
+		"description": {description},
+		"labels":      {strings.Join(labels, ",")},
+	}, &res)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create issue %q: %v", title, err)
+	}
+
+	return res.IID, nil
+}
+
+// createMergeRequest creates an open merge request of the source branch into
+// the target branch, both must exist in the repository of the project, and
+// returns its number
+func (c *gitlabClient) createMergeRequest(ctx context.Context, source, target, title, description string, labels []string) (int, error) {
+	var res created
+	err := c.do(ctx, http.MethodPost, mergeRequestsKind, url.Values{
+		"source_branch": {source},
+		"target_branch": {target},
+		"title":         {title},
+		"description":   {description},
+		"labels":        {strings.Join(labels, ",")},
+	}, &res)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create merge request %q: %v", title, err)
+	}
+
+	return res.IID, nil
+}
+
+// createNote adds a note to the issue or merge request with the given kind
+// and number
+func (c *gitlabClient) createNote(ctx context.Context, kind string, iid int, body string) error {
+	err := c.do(ctx, http.MethodPost, kind+"/"+strconv.Itoa(iid)+"/notes", url.Values{
+		"body": {body},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create note of %v %v: %v", kind, iid, err)
+	}
+
+	return nil
+}
+
+// close closes the issue or merge request with the given kind and number
+func (c *gitlabClient) close(ctx context.Context, kind string, iid int) error {
+	err := c.do(ctx, http.MethodPut, kind+"/"+strconv.Itoa(iid), url.Values{
+		"state_event": {"close"},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to close %v %v: %v", kind, iid, err)
+	}
+
+	return nil
+}
+
+// do sends a request to the given path of the project with the form params,
+// and decodes the JSON response into out, unless it is nil
+func (c *gitlabClient) do(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	u := fmt.Sprintf("%s/api/v4/projects/%s/%s", strings.TrimSuffix(c.baseURL, "/"), url.PathEscape(c.project), path)
+	req, err := http.NewRequest(method, u, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// the error messages of GitLab are short JSON documents
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%v %v: %v %s", method, path, resp.Status, msg)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
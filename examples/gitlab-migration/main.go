@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/src-d/metadata-retrieval/github"
+	"github.com/src-d/metadata-retrieval/github/store"
+	"golang.org/x/oauth2"
+	"gopkg.in/src-d/go-cli.v0"
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// rewritten during the CI build step
+var (
+	version = "master"
+	build   = "dev"
+)
+
+var app = cli.New("gitlab-migration", version, build, "GitHub issues and PRs to GitLab migration")
+
+func main() {
+	app.AddCommand(&Migrate{})
+	app.RunMain()
+}
+
+type Migrate struct {
+	cli.Command `name:"migrate" short-description:"Recreate the issues and PRs of a GitHub repository in a GitLab project" long-description:"Download the issues and PRs of a GitHub repository and recreate them in a GitLab project, the PRs as merge requests, with their comments and reviews as notes"`
+
+	Token string `long:"token" short:"t" env:"GITHUB_TOKEN" description:"GitHub personal access token" required:"true"`
+	Owner string `long:"owner" required:"true"`
+	Name  string `long:"name" required:"true"`
+
+	GitLabURL   string `long:"gitlab-url" env:"GITLAB_URL" description:"URL of the GitLab instance, e.g. https://gitlab.example.com" required:"true"`
+	GitLabToken string `long:"gitlab-token" env:"GITLAB_TOKEN" description:"GitLab personal access token with the api scope" required:"true"`
+	Project     string `long:"project" description:"ID or path of the GitLab project, e.g. group/project. Its repository must have the branches of the PRs" required:"true"`
+}
+
+func (c *Migrate) Execute(args []string) error {
+	ctx := context.TODO()
+	logger := log.New(log.Fields{"owner": c.Owner, "repo": c.Name, "project": c.Project})
+
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: c.Token},
+	))
+
+	m := store.NewMem()
+	downloader, err := github.NewMemDownloader(client, m)
+	if err != nil {
+		return err
+	}
+
+	// the Mem store does not keep track of versions
+	err = downloader.DownloadRepository(ctx, c.Owner, c.Name, 0)
+	if err != nil {
+		return err
+	}
+
+	gitlab := &gitlabClient{
+		httpClient: http.DefaultClient,
+		baseURL:    c.GitLabURL,
+		token:      c.GitLabToken,
+		project:    c.Project,
+	}
+
+	return migrate(ctx, logger, gitlab, m, c.Owner, c.Name)
+}
+
+// migrate recreates the issues and PRs of the repository saved in m in the
+// GitLab project, in the order of their numbers. The closed and merged ones
+// are closed after adding their notes, GitLab can not merge a merge request
+// whose changes are already in the target branch. The PRs whose branches are
+// unknown, e.g. deleted, are skipped with a warning
+func migrate(ctx context.Context, logger log.Logger, gitlab *gitlabClient, m *store.Mem, owner, name string) error {
+	issues := m.ListIssues(owner, name)
+	for _, issue := range issues {
+		iid, err := gitlab.createIssue(ctx, issue.Title,
+			description(issue.Url, issue.Author, issue.CreatedAt, issue.Body), issue.Labels)
+		if err != nil {
+			return err
+		}
+
+		err = addNotes(ctx, gitlab, issuesKind, iid, issueNotes(issue), issue.State != "OPEN")
+		if err != nil {
+			return err
+		}
+	}
+
+	prs := m.ListPRs(owner, name)
+	var skipped int
+	for _, pr := range prs {
+		if pr.HeadRef.Name == "" || pr.BaseRef.Name == "" {
+			logger.With(log.Fields{"pr": pr.Number}).Warningf("PR skipped, its branches are unknown")
+			skipped++
+			continue
+		}
+
+		iid, err := gitlab.createMergeRequest(ctx, pr.HeadRef.Name, pr.BaseRef.Name, pr.Title,
+			description(pr.Url, pr.Author, pr.CreatedAt, pr.Body), pr.Labels)
+		if err != nil {
+			return err
+		}
+
+		err = addNotes(ctx, gitlab, mergeRequestsKind, iid, pullRequestNotes(pr), pr.State != "OPEN")
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.With(log.Fields{
+		"issues":         len(issues),
+		"merge-requests": len(prs) - skipped,
+		"skipped-prs":    skipped,
+	}).Infof("migration done")
+	return nil
+}
+
+// addNotes adds the notes to the issue or merge request, and closes it if
+// closed is set
+func addNotes(ctx context.Context, gitlab *gitlabClient, kind string, iid int, notes []note, closed bool) error {
+	for _, n := range notes {
+		err := gitlab.createNote(ctx, kind, iid, n.body)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !closed {
+		return nil
+	}
+
+	return gitlab.close(ctx, kind, iid)
+}
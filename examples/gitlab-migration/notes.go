@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+	"github.com/src-d/metadata-retrieval/github/store"
+)
+
+// note is the body of a GitLab note made from a GitHub comment, review or
+// review comment, and the time of the original one. GitLab sets the creation
+// time of the notes, the original author and time are written in the body
+type note struct {
+	at   time.Time
+	body string
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
+
+// description returns the description of the issue or merge request made
+// from a GitHub issue or PR, with a link to the original one
+func description(url string, author graphql.Actor, createdAt time.Time, body string) string {
+	header := fmt.Sprintf("*Migrated from %s, opened by @%s on %s*",
+		url, author.LoginOrGhost(), formatTime(createdAt))
+	if strings.TrimSpace(body) == "" {
+		return header
+	}
+
+	return header + "\n\n" + body
+}
+
+func commentNote(c graphql.IssueComment) note {
+	return note{c.CreatedAt, store.FormatComment(&c)}
+}
+
+// issueNotes returns the notes of the comments of the issue, sorted by time
+func issueNotes(issue store.Issue) []note {
+	var notes []note
+	for _, c := range issue.Comments {
+		notes = append(notes, commentNote(c))
+	}
+
+	sortNotes(notes)
+	return notes
+}
+
+// pullRequestNotes returns the notes of the comments, the reviews and the
+// review comments of the PR, sorted by time, and a last one with the merge
+// if it was merged on GitHub. The pending reviews are skipped, and so are
+// the reviews without a body that only hold review comments
+func pullRequestNotes(pr store.PullRequest) []note {
+	var notes []note
+	for _, c := range pr.Comments {
+		notes = append(notes, commentNote(c))
+	}
+
+	for _, r := range pr.Reviews {
+		if r.SubmittedAt != nil && (r.State != "COMMENTED" || r.Body != "") {
+			notes = append(notes, note{*r.SubmittedAt, store.FormatReview(&r.PullRequestReviewFields)})
+		}
+
+		for _, c := range r.Comments {
+			notes = append(notes, note{c.CreatedAt, store.FormatReviewComment(&c)})
+		}
+	}
+
+	sortNotes(notes)
+
+	if pr.Merged {
+		body := fmt.Sprintf("**@%s** merged this PR on GitHub on %s", pr.MergedBy.LoginOrGhost(), formatTime(pr.MergedAt))
+		if pr.MergeCommit.Oid != "" {
+			body += " as " + pr.MergeCommit.Oid
+		}
+
+		notes = append(notes, note{pr.MergedAt, body})
+	}
+
+	return notes
+}
+
+func sortNotes(notes []note) {
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].at.Before(notes[j].at)
+	})
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// auditTransport logs every request sent to GitHub, with its method, URL,
+// status, duration and GitHub request id. The Authorization header is logged
+// without its credentials, see redactAuthorization
+type auditTransport struct {
+	T      http.RoundTripper
+	Logger log.Logger
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t0 := time.Now()
+	resp, err := t.T.RoundTrip(req)
+
+	fields := log.Fields{
+		"method":        req.Method,
+		"url":           req.URL.String(),
+		"duration":      time.Since(t0),
+		"authorization": redactAuthorization(req.Header.Get("Authorization")),
+	}
+
+	if err != nil {
+		t.Logger.With(fields).Errorf(err, "GitHub request failed")
+		return resp, err
+	}
+
+	fields["status"] = resp.StatusCode
+	fields["request-id"] = resp.Header.Get("X-GitHub-Request-Id")
+	t.Logger.With(fields).Infof("GitHub request")
+	return resp, nil
+}
+
+// redactAuthorization returns the scheme of an Authorization header value,
+// e.g. "bearer", followed by Redacted instead of the credentials
+func redactAuthorization(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if i := strings.IndexByte(value, ' '); i > 0 {
+		return value[:i] + " " + Redacted
+	}
+
+	return Redacted
+}
+
+// WithAuditLog makes the Downloader log every request sent to GitHub with the
+// given logger, including each retry of a failed request. The credentials of
+// the Authorization header are never logged
+func (d *Downloader) WithAuditLog(logger log.Logger) *Downloader {
+	if t, ok := d.httpClient.Transport.(*retryTransport); ok {
+		t.T = &auditTransport{T: t.T, Logger: logger}
+		return d
+	}
+
+	d.httpClient.Transport = &auditTransport{T: d.httpClient.Transport, Logger: logger}
+	return d
+}
@@ -0,0 +1,84 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// recordLogger keeps the messages logged, with their fields
+type recordLogger struct {
+	fields  log.Fields
+	entries *[]string
+}
+
+func (l recordLogger) New(f log.Fields) log.Logger {
+	fields := log.Fields{}
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	for k, v := range f {
+		fields[k] = v
+	}
+
+	return recordLogger{fields: fields, entries: l.entries}
+}
+
+func (l recordLogger) With(f log.Fields) log.Logger { return l.New(f) }
+
+func (l recordLogger) log(level, format string, args ...interface{}) {
+	*l.entries = append(*l.entries, fmt.Sprintf("%v %v %v", level, fmt.Sprintf(format, args...), l.fields))
+}
+
+func (l recordLogger) Debugf(format string, args ...interface{})   { l.log("debug", format, args...) }
+func (l recordLogger) Infof(format string, args ...interface{})    { l.log("info", format, args...) }
+func (l recordLogger) Warningf(format string, args ...interface{}) { l.log("warning", format, args...) }
+func (l recordLogger) Errorf(err error, format string, args ...interface{}) {
+	l.log("error", format+": "+err.Error(), args...)
+}
+
+func TestAuditTransport(t *testing.T) {
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var entries []string
+	client := &http.Client{Transport: &retryTransport{T: http.DefaultTransport}}
+	d := &Downloader{httpClient: client}
+	d.WithAuditLog(recordLogger{entries: &entries})
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/graphql", nil)
+	require.NoError(err)
+	req.Header.Set("Authorization", "bearer secret-token")
+
+	resp, err := client.Do(req)
+	require.NoError(err)
+	resp.Body.Close()
+
+	require.Len(entries, 1)
+	entry := entries[0]
+	require.True(strings.HasPrefix(entry, "info GitHub request "), entry)
+	require.Contains(entry, "method:POST")
+	require.Contains(entry, "url:"+server.URL+"/graphql")
+	require.Contains(entry, "status:200")
+	require.Contains(entry, "request-id:ABCD:1234")
+	require.Contains(entry, "authorization:bearer [REDACTED]")
+	require.NotContains(entry, "secret-token")
+}
+
+func TestRedactAuthorization(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("", redactAuthorization(""))
+	require.Equal("token [REDACTED]", redactAuthorization("token abc"))
+	require.Equal("[REDACTED]", redactAuthorization("abc"))
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"sync"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// Author is an author of the issues, PRs, comments, reviews or review
+// comments of a download, see Downloader.AuthorFunc
+type Author struct {
+	Login string
+	// Bot is set for the GitHub apps, e.g. dependabot
+	Bot bool
+	// Ghost is set for the deleted accounts, their Login is
+	// graphql.GhostLogin
+	Ghost bool
+}
+
+// authorsStorer calls a func with each distinct author of the saved issues,
+// PRs, comments, reviews and review comments, before saving them
+type authorsStorer struct {
+	storer
+	fn func(Author)
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// authorsStorer returns the storer of the Downloader, wrapped in an
+// authorsStorer if AuthorFunc is set. Each call starts a new set of seen
+// authors, it must be called once per download
+func (d Downloader) authorsStorer() storer {
+	if d.AuthorFunc == nil {
+		return d.storer
+	}
+
+	return &authorsStorer{storer: d.storer, fn: d.AuthorFunc, seen: make(map[string]bool)}
+}
+
+func (s *authorsStorer) author(actor *graphql.Actor) {
+	login := actor.LoginOrGhost()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen[login] {
+		return
+	}
+
+	s.seen[login] = true
+	s.fn(Author{
+		Login: login,
+		Bot:   actor.Typename == "Bot",
+		Ghost: login == graphql.GhostLogin,
+	})
+}
+
+func (s *authorsStorer) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	s.author(&issue.Author)
+	return s.storer.SaveIssue(repositoryOwner, repositoryName, issue, assignees, labels)
+}
+
+func (s *authorsStorer) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	s.author(&comment.Author)
+	return s.storer.SaveIssueComment(repositoryOwner, repositoryName, issueNumber, comment)
+}
+
+func (s *authorsStorer) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	s.author(&pr.Author)
+	return s.storer.SavePullRequest(repositoryOwner, repositoryName, pr, assignees, labels)
+}
+
+func (s *authorsStorer) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	s.author(&comment.Author)
+	return s.storer.SavePullRequestComment(repositoryOwner, repositoryName, pullRequestNumber, comment)
+}
+
+func (s *authorsStorer) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	s.author(&review.Author)
+	return s.storer.SavePullRequestReview(repositoryOwner, repositoryName, pullRequestNumber, review)
+}
+
+func (s *authorsStorer) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	s.author(&comment.Author)
+	return s.storer.SavePullRequestReviewComment(repositoryOwner, repositoryName, pullRequestNumber, pullRequestReviewId, comment)
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorFunc(t *testing.T) {
+	d, _ := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 1,
+				"author": {"login": "alice", "__typename": "User"},
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 1, "author": {"login": "bob", "__typename": "User"}},
+					{"databaseId": 2, "author": null}
+				]}
+			}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 2,
+				"author": {"login": "dependabot", "__typename": "Bot"},
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 3, "author": {"login": "alice", "__typename": "User"}}
+				]},
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 10, "state": "APPROVED", "author": {"login": "carol", "__typename": "User"}, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"databaseId": 4, "author": {"login": "dave", "__typename": "User"}}
+					]}}
+				]}
+			}]}
+		}}}`
+	})
+
+	var authors []Author
+	d.AuthorFunc = func(author Author) {
+		authors = append(authors, author)
+	}
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]Author{
+		{Login: "alice"},
+		{Login: "bob"},
+		{Login: "ghost", Ghost: true},
+		{Login: "dependabot", Bot: true},
+		{Login: "carol"},
+		{Login: "dave"},
+	}, authors)
+
+	// each download reports its authors again
+	authors = nil
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 1))
+	require.Len(authors, 6)
+}
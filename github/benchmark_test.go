@@ -0,0 +1,229 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/store"
+)
+
+// The benchmarks download repositories generated by benchmarkRepository from
+// a local test server, so they measure the pagination, decoding and saving of
+// the Downloader without the network. Use the go test flags to profile them,
+// e.g. make bench, and then:
+//
+//	go tool pprof github.test cpu.out
+
+const (
+	benchmarkIssues     = 200
+	benchmarkPRs        = 100
+	benchmarkComments   = 10
+	benchmarkReviews    = 5
+	benchmarkTimestamp  = "2019-10-01T10:00:00Z"
+	benchmarkCommentMD  = "Thanks @alice, this is fixed in #1.\\n\\n- [x] tests\\n- [ ] docs\\n\\n```\\n@notamention\\n```"
+	benchmarkAuthorJSON = `{"login": "alice", "__typename": "User"}`
+)
+
+// benchmarkRepository is a generated repository, served in pages like the
+// GitHub API does
+type benchmarkRepository struct {
+	issuePages []string
+	prPages    []string
+}
+
+// newBenchmarkRepository generates a repository with the given number of
+// issues and PRs. Each issue and PR has benchmarkComments comments, and each
+// PR has benchmarkReviews reviews with a page of review comments
+func newBenchmarkRepository(issues, prs int) *benchmarkRepository {
+	r := &benchmarkRepository{}
+
+	for first := 0; first < issues; first += issuesPage {
+		var nodes []string
+		for i := first; i < first+issuesPage && i < issues; i++ {
+			nodes = append(nodes, fmt.Sprintf(
+				`{"id": "i%[1]v", "number": %[1]v, "title": "Issue %[1]v", "body": "%[2]v", "createdAt": "%[3]v", "updatedAt": "%[3]v", "author": %[4]v,
+				"comments": {"totalCount": %[5]v, "pageInfo": {"hasNextPage": false}, "nodes": [%[6]v]}}`,
+				i+1, benchmarkCommentMD, benchmarkTimestamp, benchmarkAuthorJSON,
+				benchmarkComments, benchmarkCommentNodes(fmt.Sprintf("i%v", i+1), benchmarkComments)))
+		}
+
+		r.issuePages = append(r.issuePages, benchmarkPage(nodes, first+issuesPage < issues, len(r.issuePages)))
+	}
+
+	for first := 0; first < prs; first += pullRequestsPage {
+		var nodes []string
+		for i := first; i < first+pullRequestsPage && i < prs; i++ {
+			id := fmt.Sprintf("pr%v", i+1)
+
+			var reviews []string
+			for j := 0; j < benchmarkReviews; j++ {
+				reviews = append(reviews, fmt.Sprintf(
+					`{"id": "%[1]vr%[2]v", "databaseId": %[2]v, "state": "COMMENTED", "body": "%[3]v", "submittedAt": "%[4]v", "author": %[5]v,
+					"comments": {"totalCount": %[6]v, "pageInfo": {"hasNextPage": false}, "nodes": [%[7]v]}}`,
+					id, j+1, benchmarkCommentMD, benchmarkTimestamp, benchmarkAuthorJSON,
+					pullRequestReviewCommentsPage, benchmarkReviewCommentNodes(fmt.Sprintf("%vr%v", id, j+1))))
+			}
+
+			nodes = append(nodes, fmt.Sprintf(
+				`{"id": "%[1]v", "number": %[2]v, "title": "PR %[2]v", "body": "%[3]v", "createdAt": "%[4]v", "updatedAt": "%[4]v", "author": %[5]v,
+				"comments": {"totalCount": %[6]v, "pageInfo": {"hasNextPage": false}, "nodes": [%[7]v]},
+				"reviews": {"totalCount": %[8]v, "pageInfo": {"hasNextPage": false}, "nodes": [%[9]v]}}`,
+				id, i+1, benchmarkCommentMD, benchmarkTimestamp, benchmarkAuthorJSON,
+				benchmarkComments, benchmarkCommentNodes(id, benchmarkComments),
+				benchmarkReviews, strings.Join(reviews, ",")))
+		}
+
+		r.prPages = append(r.prPages, benchmarkPage(nodes, first+pullRequestsPage < prs, len(r.prPages)))
+	}
+
+	if len(r.issuePages) == 0 {
+		r.issuePages = append(r.issuePages, benchmarkPage(nil, false, 0))
+	}
+	if len(r.prPages) == 0 {
+		r.prPages = append(r.prPages, benchmarkPage(nil, false, 0))
+	}
+
+	return r
+}
+
+// benchmarkPage returns a connection with the given nodes, the cursor of the
+// next page is its index
+func benchmarkPage(nodes []string, hasNextPage bool, index int) string {
+	return fmt.Sprintf(`{"pageInfo": {"hasNextPage": %v, "endCursor": "%v"}, "nodes": [%v]}`,
+		hasNextPage, index+1, strings.Join(nodes, ","))
+}
+
+func benchmarkCommentNodes(parent string, n int) string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf(
+			`{"id": "%[1]vc%[2]v", "databaseId": %[2]v, "body": "%[3]v", "createdAt": "%[4]v", "updatedAt": "%[4]v", "author": %[5]v}`,
+			parent, i+1, benchmarkCommentMD, benchmarkTimestamp, benchmarkAuthorJSON)
+	}
+
+	return strings.Join(nodes, ",")
+}
+
+func benchmarkReviewCommentNodes(parent string) string {
+	nodes := make([]string, pullRequestReviewCommentsPage)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf(
+			`{"id": "%[1]vc%[2]v", "databaseId": %[2]v, "body": "%[3]v", "path": "main.go", "createdAt": "%[4]v", "updatedAt": "%[4]v", "author": %[5]v}`,
+			parent, i+1, benchmarkCommentMD, benchmarkTimestamp, benchmarkAuthorJSON)
+	}
+
+	return strings.Join(nodes, ",")
+}
+
+// page returns the page of the given cursor, the first one for a nil cursor
+func (r *benchmarkRepository) page(pages []string, cursor interface{}) string {
+	if cursor == nil {
+		return pages[0]
+	}
+
+	var i int
+	fmt.Sscan(cursor.(string), &i)
+	return pages[i]
+}
+
+func (r *benchmarkRepository) handler(query string, variables map[string]interface{}) string {
+	switch {
+	case strings.Contains(query, "node(id:$id)") && strings.Contains(query, "issues(first: $issuesPage"):
+		return fmt.Sprintf(`{"data": {"node": {"issues": %v}}}`, r.page(r.issuePages, variables["issuesCursor"]))
+	case strings.Contains(query, "node(id:$id)") && strings.Contains(query, "pullRequests(first: $pullRequestsPage"):
+		return fmt.Sprintf(`{"data": {"node": {"pullRequests": %v}}}`, r.page(r.prPages, variables["pullRequestsCursor"]))
+	}
+
+	return fmt.Sprintf(`{"data": {"repository": {
+		"id": "r1",
+		"name": "basic",
+		"nameWithOwner": "git-fixtures/basic",
+		"owner": {"login": "git-fixtures", "__typename": "Organization"},
+		"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+		"issues": %v,
+		"pullRequests": %v
+	}}}`, r.issuePages[0], r.prPages[0])
+}
+
+// benchmarkDownload downloads the repository b.N times, each time in a new
+// store.Mem
+func benchmarkDownload(b *testing.B, r *benchmarkRepository) {
+	d := &Downloader{client: newTestClient(b, r.handler)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.storer = store.NewMem()
+		err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkIssueSave downloads the issues of a repository, and their comments
+func BenchmarkIssueSave(b *testing.B) {
+	benchmarkDownload(b, newBenchmarkRepository(benchmarkIssues, 0))
+}
+
+// BenchmarkPullRequestSave downloads the PRs of a repository, and their
+// comments, reviews and review comments
+func BenchmarkPullRequestSave(b *testing.B) {
+	benchmarkDownload(b, newBenchmarkRepository(0, benchmarkPRs))
+}
+
+// failingTransport answers the first fails requests with a 502 status, and
+// the rest with a 200
+type failingTransport struct {
+	fails int
+	calls int
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	status := http.StatusOK
+	if t.calls <= t.fails {
+		status = http.StatusBadGateway
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(strings.NewReader(`{"data": {}}`)),
+	}, nil
+}
+
+// BenchmarkRetryTransport measures the overhead of retryTransport, without
+// the delays between the retries
+func BenchmarkRetryTransport(b *testing.B) {
+	for _, fails := range []int{0, 1, 3} {
+		b.Run(fmt.Sprintf("fails=%v", fails), func(b *testing.B) {
+			req, err := http.NewRequest(http.MethodPost, "http://localhost/graphql", nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				ft := &failingTransport{fails: fails}
+				t := &retryTransport{T: ft, clock: &fakeClock{}}
+
+				resp, err := t.RoundTrip(req)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if resp.StatusCode != http.StatusOK {
+					b.Fatal(errors.New(resp.Status))
+				}
+			}
+		})
+	}
+}
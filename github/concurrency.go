@@ -0,0 +1,240 @@
+package github
+
+import (
+	"sync"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+	"github.com/src-d/metadata-retrieval/github/store"
+)
+
+// processNodes calls process with the index of each of the n nodes of a
+// page. With Concurrency, up to Concurrency nodes are processed at the same
+// time, and processNodes returns once all of them are done, so the
+// transaction is never committed with a node in flight. The returned error is
+// the one of the first node that failed in page order, not in time: after a
+// failure no more nodes are started, but the ones before it always are
+func (d Downloader) processNodes(n int, process func(i int) error) error {
+	if d.Concurrency <= 1 {
+		for i := 0; i < n; i++ {
+			err := process(i)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+
+	errs := make([]error, n)
+	workers := make(chan struct{}, d.Concurrency)
+	for i := 0; i < n; i++ {
+		workers <- struct{}{}
+
+		mu.Lock()
+		stop := failed
+		mu.Unlock()
+		if stop {
+			<-workers
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			err := process(i)
+			if err != nil {
+				mu.Lock()
+				errs[i] = err
+				failed = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncStorer serializes the saves of the concurrent workers of a download,
+// see Downloader.Concurrency. The stores are not safe for concurrent use,
+// e.g. store.DB runs the statements of its transaction one at a time.
+// Begin, Commit and Rollback are called once the workers are done
+type syncStorer struct {
+	storer
+	mu sync.Mutex
+}
+
+var _ storer = (*syncStorer)(nil)
+
+func (s *syncStorer) SaveOrganization(organization *graphql.Organization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveOrganization(organization)
+}
+
+func (s *syncStorer) SaveUser(user *graphql.UserExtended) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveUser(user)
+}
+
+func (s *syncStorer) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveRepository(repository, topics)
+}
+
+func (s *syncStorer) SaveTopic(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveTopic(name)
+}
+
+func (s *syncStorer) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveRepositoryTopic(repositoryOwner, repositoryName, topic)
+}
+
+func (s *syncStorer) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveIssue(repositoryOwner, repositoryName, issue, assignees, labels)
+}
+
+func (s *syncStorer) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveIssueComment(repositoryOwner, repositoryName, issueNumber, comment)
+}
+
+func (s *syncStorer) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SavePullRequest(repositoryOwner, repositoryName, pr, assignees, labels)
+}
+
+func (s *syncStorer) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SavePullRequestComment(repositoryOwner, repositoryName, pullRequestNumber, comment)
+}
+
+func (s *syncStorer) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SavePullRequestReview(repositoryOwner, repositoryName, pullRequestNumber, review)
+}
+
+func (s *syncStorer) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SavePullRequestReviewComment(repositoryOwner, repositoryName, pullRequestNumber, pullRequestReviewId, comment)
+}
+
+func (s *syncStorer) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *store.ReviewStateTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveReviewStateTransition(repositoryOwner, repositoryName, pullRequestNumber, transition)
+}
+
+func (s *syncStorer) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SavePullRequestCommit(repositoryOwner, repositoryName, pullRequestNumber, commit)
+}
+
+func (s *syncStorer) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveAssignmentEvent(repositoryOwner, repositoryName, number, event)
+}
+
+func (s *syncStorer) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveIssueEvent(repositoryOwner, repositoryName, issueNumber, event)
+}
+
+func (s *syncStorer) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SavePullRequestEvent(repositoryOwner, repositoryName, pullRequestNumber, event)
+}
+
+func (s *syncStorer) SaveMention(subjectID, mentionedLogin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveMention(subjectID, mentionedLogin)
+}
+
+func (s *syncStorer) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveReactionGroup(subjectID, group)
+}
+
+func (s *syncStorer) SaveClosingReference(reference *store.ClosingReference) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveClosingReference(reference)
+}
+
+func (s *syncStorer) SaveLock(repositoryOwner, repositoryName string, number int, lock *store.Lock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveLock(repositoryOwner, repositoryName, number, lock)
+}
+
+func (s *syncStorer) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *store.ProjectStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveProjectStatus(repositoryOwner, repositoryName, pullRequestNumber, status)
+}
+
+func (s *syncStorer) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveRuleset(repositoryOwner, repositoryName, ruleset)
+}
+
+func (s *syncStorer) SaveTemplate(repositoryOwner, repositoryName string, template *store.Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveTemplate(repositoryOwner, repositoryName, template)
+}
+
+func (s *syncStorer) SaveReadme(repositoryOwner, repositoryName string, readme *store.Readme) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveReadme(repositoryOwner, repositoryName, readme)
+}
+
+func (s *syncStorer) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveAuditLogEntry(organization, entry)
+}
+
+func (s *syncStorer) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storer.SaveOrgProject(organization, project)
+}
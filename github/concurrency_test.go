@@ -0,0 +1,193 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/store"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessNodes(t *testing.T) {
+	require := require.New(t)
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	processed := make([]bool, 20)
+
+	d := Downloader{Concurrency: 4}
+	err := d.processNodes(len(processed), func(i int) error {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		processed[i] = true
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(err)
+	require.True(maxInFlight <= 4, "%v nodes processed at the same time", maxInFlight)
+	for i, ok := range processed {
+		require.True(ok, "node %v", i)
+	}
+
+	// the error is the one of the first failed node in page order, even if a
+	// later one fails first
+	err = d.processNodes(20, func(i int) error {
+		switch i {
+		case 5:
+			time.Sleep(20 * time.Millisecond)
+			return errors.New("node 5")
+		case 7:
+			return errors.New("node 7")
+		}
+
+		return nil
+	})
+	require.EqualError(err, "node 5")
+
+	// without Concurrency the nodes are processed in order, up to the first
+	// error
+	var order []int
+	d = Downloader{}
+	err = d.processNodes(5, func(i int) error {
+		order = append(order, i)
+		if i == 2 {
+			return errors.New("node 2")
+		}
+		return nil
+	})
+	require.EqualError(err, "node 2")
+	require.Equal([]int{0, 1, 2}, order)
+}
+
+func TestConcurrency(t *testing.T) {
+	const issues = 6
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	failed := map[string]bool{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "comments(first: $issueCommentsPage") && variables["issueCommentsCursor"] != nil {
+			id := variables["id"].(string)
+
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			fail := failed[id]
+			mu.Unlock()
+
+			if fail {
+				return `{"errors": [{"message": "Something went wrong while executing your query."}]}`
+			}
+
+			return `{"data": {"node": {"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "` + id + `-c2", "updatedAt": "2019-10-02T10:00:00Z"}
+			]}}}}`
+		}
+
+		var nodes []string
+		for i := 1; i <= issues; i++ {
+			nodes = append(nodes, fmt.Sprintf(`{"id": "i%v", "number": %v, "comments": {"pageInfo": {"hasNextPage": true, "endCursor": "e1"}, "nodes": [
+				{"id": "i%v-c1", "updatedAt": "2019-10-01T10:00:00Z"}
+			]}}`, i, i, i))
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [` + strings.Join(nodes, ",") + `]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	d.Concurrency = 3
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.True(maxInFlight > 1 && maxInFlight <= 3, "%v issues processed at the same time", maxInFlight)
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Len(repo.Issues, issues)
+	for i := 1; i <= issues; i++ {
+		var ids []string
+		for _, c := range repo.Issues[i].Comments {
+			ids = append(ids, c.Id)
+		}
+		require.Equal([]string{fmt.Sprintf("i%v-c1", i), fmt.Sprintf("i%v-c2", i)}, ids)
+	}
+
+	// the error is the one of the first failed issue
+	failed["i2"] = true
+	failed["i5"] = true
+	d, _ = newTestMemDownloader(t, handler)
+	d.Concurrency = 3
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Error(err)
+	require.Contains(err.Error(), "failed to process issue git-fixtures/basic #2")
+}
+
+// TestSyncStorer checks that every Save method of syncStorer takes the lock,
+// instead of being promoted unlocked from the embedded storer
+func TestSyncStorer(t *testing.T) {
+	s := &syncStorer{storer: store.Discard{}}
+	v := reflect.ValueOf(s)
+
+	iface := reflect.TypeOf((*store.Storer)(nil)).Elem()
+	for i := 0; i < iface.NumMethod(); i++ {
+		name := iface.Method(i).Name
+		if !strings.HasPrefix(name, "Save") {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			m := v.MethodByName(name)
+			args := make([]reflect.Value, m.Type().NumIn())
+			for j := range args {
+				args[j] = reflect.Zero(m.Type().In(j))
+			}
+
+			s.mu.Lock()
+			done := make(chan struct{})
+			go func() {
+				m.Call(args)
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				s.mu.Unlock()
+				t.Fatalf("%v does not lock the storer", name)
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			s.mu.Unlock()
+			<-done
+		})
+	}
+}
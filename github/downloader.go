@@ -3,64 +3,440 @@ package github
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/src-d/metadata-retrieval/github/graphql"
 	"github.com/src-d/metadata-retrieval/github/store"
 
 	"github.com/shurcooL/githubv4"
+	"gopkg.in/src-d/go-log.v1"
 )
 
 const (
 	assigneesPage                 = 2
+	auditLogPage                  = 100
+	assignmentEventsPage          = 10
+	closingReferencesPage         = 5
 	issueCommentsPage             = 10
 	issuesPage                    = 50
 	labelsPage                    = 2
 	membersWithRolePage           = 100
+	nodeIDsPage                   = 100
+	orgProjectItemsPage           = 50
+	orgProjectsPage               = 10
+	pullRequestCommitsPage        = 50
 	pullRequestReviewCommentsPage = 5
 	pullRequestReviewsPage        = 5
 	pullRequestsPage              = 50
+	repositoriesPage              = 100
 	repositoryTopicsPage          = 50
+	rulesetsPage                  = 10
+	timelineItemsPage             = 25
+	// rulesPage is the maximum page size. The rules of a ruleset are not
+	// paginated, each rule type can only appear once in a ruleset
+	rulesPage = 100
 )
 
+// defaultEndpoint is the GraphQL URL of the public GitHub API, used by
+// githubv4.NewClient
+const defaultEndpoint = "https://api.github.com/graphql"
+
+// storer is where the Downloader saves the data, see store.Storer
 type storer interface {
-	SaveOrganization(organization *graphql.Organization) error
-	SaveUser(user *graphql.UserExtended) error
-	SaveRepository(repository *graphql.RepositoryFields, topics []string) error
-	SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error
-	SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error
-	SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error
-	SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error
-	SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error
-	SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error
-
-	Begin() error
-	Commit() error
-	Rollback() error
-	Version(v int)
-	SetActiveVersion(v int) error
-	Cleanup(currentVersion int) error
+	store.Storer
 }
 
 // Downloader fetches GitHub data using the v4 API
 type Downloader struct {
 	storer
-	client *githubv4.Client
+	client     *githubv4.Client
+	httpClient *http.Client
+	// endpoint is the GraphQL URL set by SetEndpoint, defaultEndpoint if empty
+	endpoint string
+	// throttle spaces the queries, see WithBaseDelay. Nil means no delay
+	throttle *throttle
+	// rateGuard waits for the rate limit reset, see MinRemaining. Nil
+	// outside of a download, or if MinRemaining is not set
+	rateGuard *rateGuard
+	// clock is the source of time of rateGuard and of the mergeable retries,
+	// realClock if nil. It is replaced in tests
+	clock clock
+	// users are the users saved in the current download, so each one is
+	// saved once. Nil outside of a download
+	users *nodeCache
+	// incremental is the state of the current download if it is
+	// incremental, see DownloadRepositoryIncremental. Nil otherwise
+	incremental *incremental
+	// resume is the state of the current download if it is resumable, see
+	// ResumeRepository. Nil otherwise
+	resume *resume
+	// dryRun counts the cost of the queries, see WithDryRun. Nil otherwise
+	dryRun *dryRun
+	// skipped are the issues and PRs skipped by the current download, see
+	// ErrorPolicy. Nil outside of a download, or with Abort
+	skipped *skipped
+
+	// BodiesOmitted saves the comments and reviews with an empty body
+	BodiesOmitted bool
+	// MergeableRetryDelay, if set, is waited before querying again the open
+	// PRs with an UNKNOWN mergeable state
+	MergeableRetryDelay time.Duration
+	// AuditLogIncluded makes DownloadOrganization download the audit log
+	AuditLogIncluded bool
+	// OrgProjectsIncluded makes DownloadOrganization download the projects
+	// of the organization, with their fields and items
+	OrgProjectsIncluded bool
+	// SampleSize, if set, is the number of issues and PRs downloaded, chosen
+	// at random, see sampleOffsets
+	SampleSize int
+	// CommitAuthorsIncluded saves the users that authored the PR commits
+	CommitAuthorsIncluded bool
+	// PullRequestCommitsIncluded saves the commits of each PR
+	PullRequestCommitsIncluded bool
+	// TimelineEventsIncluded saves the timeline events of issues and PRs
+	TimelineEventsIncluded bool
+	// CommentWatermarks maps issue and PR ids to the id of the newest comment
+	// already saved, where the download of their comments stops
+	CommentWatermarks map[string]string
+	// AutoVersion makes a zero version mean the next one in the store
+	AutoVersion bool
+	// CommentsUpdatedSince, if set, skips the comments not updated after it
+	CommentsUpdatedSince time.Time
+	// ProjectStatusesIncluded saves the project statuses of each PR
+	ProjectStatusesIncluded bool
+	// BodyTransformer, if set, is applied to every body before saving it
+	BodyTransformer func(body string) string
+	// CompleteCommentsSkipped skips the comments of the issues and PRs that
+	// are unchanged and complete in the store, see SavedComments
+	CompleteCommentsSkipped bool
+	// PullRequestStates, if set, are the only PR states downloaded
+	PullRequestStates []githubv4.PullRequestState
+	// ReadmeIncluded saves the README of the default branch
+	ReadmeIncluded bool
+	// PageSizes changes the number of nodes of each page, see PageSizes
+	PageSizes PageSizes
+	// ProgressFunc, if set, is called with the progress of the issues and PRs
+	ProgressFunc func(ev ProgressEvent)
+	// AuthorFunc, if set, is called once per download with each author
+	AuthorFunc func(author Author)
+	// ErrorPolicy decides what happens when an issue or PR fails
+	ErrorPolicy ErrorPolicy
+	// MinRemaining, if set, waits for the rate limit reset when fewer points
+	// remain, see rateGuard
+	MinRemaining int
+	// CommentAuthor, if set, saves only the comments and reviews of this login
+	CommentAuthor string
+	// UnchangedSkipped skips the repositories not changed since the latest
+	// version in the store
+	UnchangedSkipped bool
+	// UnchangedCopied copies the skipped repositories to the new version
+	UnchangedCopied bool
+	// Concurrency, if greater than 1, is the number of issues or PRs of a
+	// page processed at the same time. The queries share the rate limit
+	Concurrency int
+}
+
+// PageSizes are the number of nodes requested in each page of the paginated
+// connections, at most 100. A zero size means the default one
+type PageSizes struct {
+	Assignees                 int
+	AssignmentEvents          int
+	AuditLog                  int
+	ClosingReferences         int
+	IssueComments             int
+	Issues                    int
+	Labels                    int
+	MembersWithRole           int
+	NodeIDs                   int
+	OrgProjectItems           int
+	OrgProjects               int
+	PullRequestCommits        int
+	PullRequestReviewComments int
+	PullRequestReviews        int
+	PullRequests              int
+	Repositories              int
+	RepositoryTopics          int
+	Rulesets                  int
+	TimelineItems             int
+}
+
+// pageSize returns size, or the default page size if it is not set
+func (d Downloader) pageSize(size int, defaultSize int) githubv4.Int {
+	if size > 0 {
+		return githubv4.Int(size)
+	}
+
+	return githubv4.Int(defaultSize)
+}
+
+// versioner is implemented by the stores that know their versions, see
+// Downloader.AutoVersion
+type versioner interface {
+	NextVersion() (int, error)
+}
+
+// querier is implemented by the stores that can be queried for the data they
+// saved, see Downloader.CompleteCommentsSkipped
+type querier interface {
+	SavedComments(repositoryOwner, repositoryName string, number int) (time.Time, int, error)
+}
+
+// copier is implemented by the stores that can copy the issues and PRs of a
+// version forward, see DownloadRepositoryDelta
+type copier interface {
+	LatestUpdate(repositoryOwner, repositoryName string, version int) (time.Time, error)
+	CopyForward(repositoryOwner, repositoryName string, from int) error
+}
+
+// repositoryCopier is implemented by the stores that can report the latest
+// saved version of a repository, and copy it forward, see
+// Downloader.UnchangedSkipped
+type repositoryCopier interface {
+	LatestRepository(repositoryOwner, repositoryName string) (version int, pushedAt time.Time, updatedAt time.Time, err error)
+	CopyRepositoryForward(repositoryOwner, repositoryName string, from int) error
+}
+
+// nextVersion returns the next version after the ones in the store
+func (d Downloader) nextVersion() (int, error) {
+	v, ok := d.storer.(versioner)
+	if !ok {
+		return 0, fmt.Errorf("AutoVersion is not supported by the store %T", d.storer)
+	}
+
+	next, err := v.NextVersion()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the next version: %v", err)
+	}
+
+	return next, nil
+}
+
+// commentUnchanged returns true if a comment updated at the given time is not
+// updated after CommentsUpdatedSince, and does not need to be saved
+func (d Downloader) commentUnchanged(updatedAt time.Time) bool {
+	return !d.CommentsUpdatedSince.IsZero() && !updatedAt.After(d.CommentsUpdatedSince)
+}
+
+// issueCommentUnchanged is commentUnchanged for issue and PR comments. The
+// comments with an invalid update time are always saved
+func (d Downloader) issueCommentUnchanged(comment *graphql.IssueComment) bool {
+	if d.CommentsUpdatedSince.IsZero() {
+		return false
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, comment.UpdatedAt)
+	return err == nil && d.commentUnchanged(updatedAt)
+}
+
+// issueCommentSkipped returns true if an issue or PR comment is not authored
+// by CommentAuthor, or is unchanged, and does not need to be saved
+func (d Downloader) issueCommentSkipped(comment *graphql.IssueComment) bool {
+	if d.CommentAuthor != "" && comment.Author.Login != d.CommentAuthor {
+		return true
+	}
+
+	return d.issueCommentUnchanged(comment)
+}
+
+// reviewsAuthor returns the value of the author argument of the reviews
+// connection, null unless CommentAuthor is set
+func (d Downloader) reviewsAuthor() *githubv4.String {
+	if d.CommentAuthor == "" {
+		return nil
+	}
+
+	return githubv4.NewString(githubv4.String(d.CommentAuthor))
+}
+
+// commentsComplete returns true if CompleteCommentsSkipped is set, and the
+// issue or PR with the given number is saved with the same update time and
+// all its comments, so they do not need to be downloaded again. It must be
+// called before saving the issue or PR
+func (d Downloader) commentsComplete(owner string, name string, number int, updatedAt time.Time, totalCount int) (bool, error) {
+	if !d.CompleteCommentsSkipped {
+		return false, nil
+	}
+
+	s := d.storer
+	if l, ok := s.(*syncStorer); ok {
+		// the query runs in the transaction of the saves
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		s = l.storer
+	}
+
+	if t, ok := s.(*transformStorer); ok {
+		s = t.storer
+	}
+
+	q, ok := s.(querier)
+	if !ok {
+		return false, fmt.Errorf("CompleteCommentsSkipped is not supported by the store %T", s)
+	}
+
+	savedUpdatedAt, saved, err := q.SavedComments(owner, name, number)
+	if err != nil {
+		return false, fmt.Errorf("failed to query the saved comments of #%v: %v", number, err)
+	}
+
+	return !savedUpdatedAt.IsZero() && savedUpdatedAt.Equal(updatedAt) && saved == totalCount, nil
+}
+
+// downloadVersion returns the version to save a download with, the next one
+// if the version is zero and AutoVersion is set
+func (d Downloader) downloadVersion(version int) (int, error) {
+	if !d.AutoVersion || version != 0 {
+		return version, nil
+	}
+
+	return d.nextVersion()
+}
+
+// latestVersion returns the version to make current or clean up, the latest
+// one if the version is zero and AutoVersion is set
+func (d Downloader) latestVersion(version int) (int, error) {
+	if !d.AutoVersion || version != 0 {
+		return version, nil
+	}
+
+	next, err := d.nextVersion()
+	return next - 1, err
+}
+
+// incremental is the state of an incremental download, see
+// DownloadRepositoryIncremental. A nil incremental is a full download
+type incremental struct {
+	since time.Time
+	// latest is the latest update time of the saved issues and PRs
+	latest time.Time
+	// base is the version the unchanged issues and PRs are copied forward
+	// from by copier, see DownloadRepositoryDelta. Unset otherwise
+	base   int
+	copier copier
+}
+
+// order returns the order of the issues and PRs: by update time, newest
+// first, in an incremental download, and by creation time otherwise
+func (inc *incremental) order() githubv4.IssueOrder {
+	if inc == nil {
+		return githubv4.IssueOrder{Field: githubv4.IssueOrderFieldCreatedAt, Direction: githubv4.OrderDirectionAsc}
+	}
+
+	return githubv4.IssueOrder{Field: githubv4.IssueOrderFieldUpdatedAt, Direction: githubv4.OrderDirectionDesc}
+}
+
+// stale returns true if an issue or PR updated at the given time was not
+// updated since the previous download. It is always false in a full download,
+// and for an unknown, zero, update time
+func (inc *incremental) stale(updatedAt time.Time) bool {
+	return inc != nil && !updatedAt.IsZero() && updatedAt.Before(inc.since)
+}
+
+// updated returns true if an issue or PR updated at the given time must be
+// downloaded, and keeps the latest update time of the downloaded ones
+func (inc *incremental) updated(updatedAt time.Time) bool {
+	if inc == nil {
+		return true
+	}
+
+	if inc.stale(updatedAt) {
+		return false
+	}
+
+	if updatedAt.After(inc.latest) {
+		inc.latest = updatedAt
+	}
+
+	return true
+}
+
+// copyForward copies the unchanged issues and PRs forward from the base
+// version, in a delta download. It must be called inside a transaction, after
+// saving the changed ones
+func (inc *incremental) copyForward(owner string, name string) error {
+	if inc == nil || inc.copier == nil {
+		return nil
+	}
+
+	err := inc.copier.CopyForward(owner, name, inc.base)
+	if err != nil {
+		return fmt.Errorf("failed to copy the unchanged issues and PRs forward: %v", err)
+	}
+
+	return nil
+}
+
+// parseUpdatedAt parses the update time of a PR, it is zero if it is invalid
+func parseUpdatedAt(updatedAt string) time.Time {
+	t, _ := time.Parse(time.RFC3339, updatedAt)
+	return t
+}
+
+// nodeCache is a set of node ids, safe for concurrent use
+type nodeCache struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func newNodeCache() *nodeCache {
+	return &nodeCache{ids: make(map[string]bool)}
+}
+
+// add adds id to the set, and returns false if it was already in it
+func (c *nodeCache) add(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ids[id] {
+		return false
+	}
+
+	c.ids[id] = true
+	return true
 }
 
 // NewDownloader creates a new Downloader that will store the GitHub metadata
 // in the given DB. The HTTP client is expected to have the proper
 // authentication setup
 func NewDownloader(httpClient *http.Client, db *sql.DB) (*Downloader, error) {
+	return NewDBDownloader(httpClient, &store.DB{DB: db})
+}
+
+// NewDBDownloader is like NewDownloader, but it takes the DB store, so its
+// options, like MaxBodyLength, can be set
+func NewDBDownloader(httpClient *http.Client, s *store.DB) (*Downloader, error) {
 	// TODO: is the ghsync rate limited client needed?
 
-	t := &retryTransport{httpClient.Transport}
+	t := &retryTransport{T: httpClient.Transport}
 	httpClient.Transport = t
 
 	return &Downloader{
-		storer: &store.DB{DB: db},
-		client: githubv4.NewClient(httpClient),
+		storer:     s,
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
+	}, nil
+}
+
+// NewPostgresDownloader is like NewDBDownloader, but it takes the Postgres
+// store, see store.Postgres
+func NewPostgresDownloader(httpClient *http.Client, s *store.Postgres) (*Downloader, error) {
+	t := &retryTransport{T: httpClient.Transport}
+	httpClient.Transport = t
+
+	return &Downloader{
+		storer:     s,
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
 	}, nil
 }
 
@@ -70,824 +446,3049 @@ func NewDownloader(httpClient *http.Client, db *sql.DB) (*Downloader, error) {
 func NewStdoutDownloader(httpClient *http.Client) (*Downloader, error) {
 	// TODO: is the ghsync rate limited client needed?
 
-	t := &retryTransport{httpClient.Transport}
+	t := &retryTransport{T: httpClient.Transport}
 	httpClient.Transport = t
 
 	return &Downloader{
-		storer: &store.Stdout{},
-		client: githubv4.NewClient(httpClient),
+		storer:     &store.Stdout{},
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
 	}, nil
 }
 
-// DownloadRepository downloads the metadata for the given repository and all
-// its resources (issues, PRs, comments, reviews)
-func (d Downloader) DownloadRepository(ctx context.Context, owner string, name string, version int) error {
-	d.storer.Version(version)
+// NewSortableStdoutDownloader creates a new Downloader that will print the
+// GitHub metadata to out, prefixing each line with a sortable key. Sorting
+// the output gives the same lines regardless of the order of the download,
+// see store.Stdout.SortKeys. The HTTP client is expected to have the proper
+// authentication setup
+func NewSortableStdoutDownloader(httpClient *http.Client, out io.Writer) (*Downloader, error) {
+	t := &retryTransport{T: httpClient.Transport}
+	httpClient.Transport = t
 
-	var err error
-	err = d.storer.Begin()
-	if err != nil {
-		return fmt.Errorf("could not call Begin(): %v", err)
-	}
+	return &Downloader{
+		storer:     &store.Stdout{Out: out, SortKeys: true},
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
+	}, nil
+}
 
-	defer func() {
-		if err != nil {
-			d.storer.Rollback()
-			return
-		}
+// NewJSONLDownloader creates a new Downloader that will write the GitHub
+// metadata to out as JSON lines, see store.JSONL. The HTTP client is expected
+// to have the proper authentication setup
+func NewJSONLDownloader(httpClient *http.Client, out io.Writer) (*Downloader, error) {
+	t := &retryTransport{T: httpClient.Transport}
+	httpClient.Transport = t
 
-		d.storer.Commit()
-	}()
+	return &Downloader{
+		storer:     store.NewJSONL(out),
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
+	}, nil
+}
 
-	var q struct {
-		graphql.Repository `graphql:"repository(owner: $owner, name: $name)"`
+// NewStdoutJSONDownloader creates a new Downloader that will print the
+// GitHub metadata to stdout as JSON lines, see NewJSONLDownloader
+func NewStdoutJSONDownloader(httpClient *http.Client) (*Downloader, error) {
+	return NewJSONLDownloader(httpClient, os.Stdout)
+}
+
+// NewCSVDownloader creates a new Downloader that will write the issues, PRs,
+// comments and reviews as CSV files in the directory dir, see store.CSV. The
+// HTTP client is expected to have the proper authentication setup
+func NewCSVDownloader(httpClient *http.Client, dir string) (*Downloader, error) {
+	t := &retryTransport{T: httpClient.Transport}
+	httpClient.Transport = t
+
+	return &Downloader{
+		storer:     store.NewCSV(dir),
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
+	}, nil
+}
+
+// NewMemDownloader creates a new Downloader that will keep the GitHub
+// metadata in the given Mem store. The HTTP client is expected to have the
+// proper authentication setup
+func NewMemDownloader(httpClient *http.Client, m *store.Mem) (*Downloader, error) {
+	t := &retryTransport{T: httpClient.Transport}
+	httpClient.Transport = t
+
+	return &Downloader{
+		storer:     m,
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
+	}, nil
+}
+
+// NewEventLogDownloader creates a new Downloader that will append the GitHub
+// metadata to the given EventLog. The HTTP client is expected to have the
+// proper authentication setup
+func NewEventLogDownloader(httpClient *http.Client, l *store.EventLog) (*Downloader, error) {
+	t := &retryTransport{T: httpClient.Transport}
+	httpClient.Transport = t
+
+	return &Downloader{
+		storer:     l,
+		client:     githubv4.NewClient(httpClient),
+		httpClient: httpClient,
+	}, nil
+}
+
+// SetEndpoint makes the Downloader send its queries to the given GraphQL
+// URL, instead of the public GitHub API. The URL is used as is, so it can point
+// to a GitHub Enterprise server (https://host/api/graphql) or to a proxy with
+// a rewritten path (https://proxy/github/api/graphql)
+func (d *Downloader) SetEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid GraphQL endpoint %q: %v", endpoint, err)
 	}
 
-	// Some variables are repeated in the query, like assigneesCursor for Issues
-	// and PullRequests. It's ok to reuse because in this top level Repository
-	// query the cursors are set to nil, and when the pagination occurs, the
-	// queries only request either Issues or PullRequests
-	variables := map[string]interface{}{
-		"owner": githubv4.String(owner),
-		"name":  githubv4.String(name),
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid GraphQL endpoint %q: an absolute http or https URL is required", endpoint)
+	}
 
-		"assigneesPage":                 githubv4.Int(assigneesPage),
-		"issueCommentsPage":             githubv4.Int(issueCommentsPage),
-		"issuesPage":                    githubv4.Int(issuesPage),
-		"labelsPage":                    githubv4.Int(labelsPage),
-		"pullRequestReviewCommentsPage": githubv4.Int(pullRequestReviewCommentsPage),
-		"pullRequestReviewsPage":        githubv4.Int(pullRequestReviewsPage),
-		"pullRequestsPage":              githubv4.Int(pullRequestsPage),
-		"repositoryTopicsPage":          githubv4.Int(repositoryTopicsPage),
+	d.client = githubv4.NewEnterpriseClient(u.String(), d.httpClient)
+	d.endpoint = u.String()
+	return nil
+}
 
-		"assigneesCursor":                 (*githubv4.String)(nil),
-		"issueCommentsCursor":             (*githubv4.String)(nil),
-		"issuesCursor":                    (*githubv4.String)(nil),
-		"labelsCursor":                    (*githubv4.String)(nil),
-		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
-		"pullRequestReviewsCursor":        (*githubv4.String)(nil),
-		"pullRequestsCursor":              (*githubv4.String)(nil),
-		"repositoryTopicsCursor":          (*githubv4.String)(nil),
+// WithBaseDelay makes the Downloader wait at least the given delay between
+// successive GraphQL queries, including those of concurrent downloads that use
+// it. It keeps the request rate low enough to avoid the GitHub secondary rate
+// limits, which can be triggered even within the primary rate limit. Zero, the
+// default, means no delay
+func (d *Downloader) WithBaseDelay(delay time.Duration) *Downloader {
+	if delay <= 0 {
+		d.throttle = nil
+		return d
 	}
 
-	err = d.client.Query(ctx, &q, variables)
-	if err != nil {
-		return fmt.Errorf("first query failed: %v", err)
+	d.throttle = &throttle{clock: realClock{}, delay: delay}
+	return d
+}
+
+// WithRetries sets the maximum number of retries of a failed request to
+// GitHub, and the delay before the first retry, which doubles on each of the
+// next ones. Zero keeps the default, 10 retries from 10ms
+func (d *Downloader) WithRetries(maxRetries int, baseDelay time.Duration) *Downloader {
+	if t, ok := d.httpClient.Transport.(*retryTransport); ok {
+		t.MaxRetries = maxRetries
+		t.BaseDelay = baseDelay
 	}
 
-	// repository topics
-	topics, err := d.downloadTopics(ctx, &q.Repository)
-	if err != nil {
+	return d
+}
+
+// query sends the GraphQL query, after waiting for the throttle and, if the
+// remaining rate limit is low, for its reset. Nothing is sent if the context
+// is already done
+func (d Downloader) query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	err = d.storer.SaveRepository(&q.Repository.RepositoryFields, topics)
-	if err != nil {
-		return fmt.Errorf("failed to save repository %v: %v", q.Repository.NameWithOwner, err)
+	if d.throttle != nil {
+		err := d.throttle.wait(ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	// issues and comments
-	err = d.downloadIssues(ctx, owner, name, &q.Repository)
+	err := d.rateGuard.wait(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to check the rate limit: %v", err)
 	}
 
-	// PRs and comments
-	err = d.downloadPullRequests(ctx, owner, name, &q.Repository)
-	if err != nil {
-		return err
+	if d.dryRun != nil {
+		return d.dryRun.query(ctx, d.client, q, variables)
 	}
 
-	return nil
+	return d.client.Query(ctx, q, variables)
 }
 
-// RateRemaining returns the remaining rate limit for the v4 GitHub API
-func (d Downloader) RateRemaining(ctx context.Context) (int, error) {
-	var q struct {
-		RateLimit struct {
-			Remaining int
+// errEmptyData is returned when a query is answered without data and without
+// errors, e.g. {"data": null}
+var errEmptyData = errors.New("the response has no data")
+
+// queryWithData is like query, but it retries with backoff while the response
+// has no data, according to empty. GitHub answers some valid queries with null
+// data during incidents. A repository or organization that does not exist is
+// answered with an error instead, so these responses are never a genuinely
+// empty result
+func (d Downloader) queryWithData(ctx context.Context, q interface{}, variables map[string]interface{}, empty func() bool) error {
+	return retry(func() error {
+		err := d.query(ctx, q, variables)
+		if err != nil {
+			return &errUnretriable{Err: err}
 		}
+
+		if empty() {
+			return errEmptyData
+		}
+
+		return nil
+	})
+}
+
+// CheckScopes returns the OAuth scopes granted to the token, read from the
+// X-OAuth-Scopes header of a rate limit query. Callers can use them to warn
+// before downloading data that requires a scope the token does not have.
+// Tokens that are not OAuth tokens, e.g. GitHub App tokens, have no scopes
+func (d Downloader) CheckScopes(ctx context.Context) ([]string, error) {
+	endpoint := d.endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
 	}
 
-	err := d.client.Query(ctx, &q, nil)
-	if err != nil {
-		return 0, fmt.Errorf("failed to query remaining rate limit: %v", err)
+	httpClient := d.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
 	}
 
-	return q.RateLimit.Remaining, nil
-}
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(`{"query": "query{rateLimit{remaining}}"}`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scopes request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
-func (d Downloader) downloadTopics(ctx context.Context, repository *graphql.Repository) ([]string, error) {
-	topics := []string{}
+	if d.throttle != nil {
+		err = d.throttle.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Topics included in the first page
-	for _, topicNode := range repository.RepositoryTopics.Nodes {
-		topics = append(topics, topicNode.Topic.Name)
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token scopes: %v", err)
 	}
+	defer resp.Body.Close()
 
-	variables := map[string]interface{}{
-		"id": githubv4.ID(repository.Id),
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to request token scopes: unexpected status %v", resp.Status)
+	}
 
-		"repositoryTopicsPage":   githubv4.Int(repositoryTopicsPage),
-		"repositoryTopicsCursor": (*githubv4.String)(nil),
+	scopes := []string{}
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" {
+			scopes = append(scopes, scope)
+		}
 	}
 
-	// if there are more topics, loop over all the pages
-	hasNextPage := repository.RepositoryTopics.PageInfo.HasNextPage
-	endCursor := repository.RepositoryTopics.PageInfo.EndCursor
+	return scopes, nil
+}
 
-	for hasNextPage {
-		// get only repository topics
-		var q struct {
-			Node struct {
-				Repository struct {
-					RepositoryTopics graphql.RepositoryTopicsConnection `graphql:"repositoryTopics(first: $repositoryTopicsPage, after: $repositoryTopicsCursor)"`
-				} `graphql:"... on Repository"`
-			} `graphql:"node(id:$id)"`
+// DownloadRepository downloads the metadata for the given repository and all
+// its resources (issues, PRs, comments, reviews). A failure because of the
+// rate limit, a missing repository or a transient error is returned as a
+// *RateLimitError, *NotFoundError or *TransientError, see errors.As
+func (d Downloader) DownloadRepository(ctx context.Context, owner string, name string, version int) error {
+	if d.UnchangedSkipped {
+		skipped, err := d.skipUnchanged(ctx, owner, name, version)
+		if err != nil || skipped {
+			return d.classifyError(ctx, err)
 		}
+	}
 
-		variables["repositoryTopicsCursor"] = githubv4.String(endCursor)
+	err := d.downloadRepositoryVersion(ctx, owner, name, version)
+	return d.classifyError(ctx, err)
+}
 
-		err := d.client.Query(ctx, &q, variables)
-		if err != nil {
-			return nil, fmt.Errorf("RepositoryTopics query failed: %v", err)
+// RepoRef identifies a repository by its owner and name
+type RepoRef struct {
+	Owner string
+	Name  string
+}
+
+func (r RepoRef) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// DownloadRepositories downloads each repository like DownloadRepository, in
+// its own transaction, all of them with the same version. A zero version with
+// AutoVersion is resolved once, before the first repository. The failure of a
+// repository does not stop the rest, the returned error is a *MultiError with
+// an EntityError of kind "repository" for each failed one, and the others
+// are committed. A done context stops the batch, its error is added too.
+// Callers mirroring several repositories can then set the version as active
+// once, with SetActiveVersion
+func (d Downloader) DownloadRepositories(ctx context.Context, version int, repos []RepoRef) error {
+	_, err := d.downloadRepositories(ctx, version, repos)
+	return err
+}
+
+// downloadRepositories is DownloadRepositories, it also returns the
+// repositories that were downloaded
+func (d Downloader) downloadRepositories(ctx context.Context, version int, repos []RepoRef) ([]RepoRef, error) {
+	version, err := d.downloadVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []RepoRef
+	var errs MultiError
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			errs.Add(err)
+			break
 		}
 
-		for _, topicNode := range q.Node.Repository.RepositoryTopics.Nodes {
-			topics = append(topics, topicNode.Topic.Name)
+		err := d.DownloadRepository(ctx, repo.Owner, repo.Name, version)
+		if err != nil {
+			errs.Add(&EntityError{Kind: "repository", ID: repo.String(), Err: err})
+			continue
 		}
 
-		hasNextPage = q.Node.Repository.RepositoryTopics.PageInfo.HasNextPage
-		endCursor = q.Node.Repository.RepositoryTopics.PageInfo.EndCursor
+		done = append(done, repo)
 	}
 
-	return topics, nil
+	return done, errs.ErrorOrNil()
 }
 
-func (d Downloader) downloadIssues(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
-	process := func(issue *graphql.Issue) error {
-		assignees, err := d.downloadIssueAssignees(ctx, issue)
-		if err != nil {
-			return err
+// DownloadRepositoryIncremental is like DownloadRepository, but it only
+// downloads the issues and PRs updated at or after since, with all their
+// resources. The repository and its topics are always saved. The issues and
+// PRs are requested by update time, newest first, and the pagination stops at
+// the first one updated before since. A reopened issue is downloaded, its
+// update time moves forward. A review does not always update its PR, so the
+// open PRs are downloaded too, no matter their update time.
+//
+// The update time of the issues and PRs, as reported by GitHub, is the
+// authoritative timestamp. The returned time is the latest one of the saved
+// issues and PRs, or since if there is none; callers can persist it and pass
+// it as since to the next download, it does not depend on the local clock.
+// A zero since downloads everything, like DownloadRepository
+func (d Downloader) DownloadRepositoryIncremental(ctx context.Context, owner string, name string, version int, since time.Time) (time.Time, error) {
+	if d.SampleSize > 0 && !since.IsZero() {
+		return time.Time{}, fmt.Errorf("SampleSize is not supported by incremental downloads")
+	}
+
+	d.incremental = &incremental{since: since, latest: since}
+	err := d.downloadRepositoryVersion(ctx, owner, name, version)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return d.incremental.latest, nil
+}
+
+// DownloadRepositoryDelta downloads the repository into a new version, the
+// next one after the ones in the store, with the issues and PRs updated since
+// the latest update of the ones saved in baseVersion, see
+// DownloadRepositoryIncremental. The unchanged issues and PRs, and all their
+// resources, are then copied forward from baseVersion, so the new version is
+// a complete snapshot that only costs the queries of the changes. It returns
+// the new version. The store must support it, like store.DB
+func (d Downloader) DownloadRepositoryDelta(ctx context.Context, owner string, name string, baseVersion int) (int, error) {
+	if d.SampleSize > 0 {
+		return 0, fmt.Errorf("SampleSize is not supported by delta downloads")
+	}
+
+	c, ok := d.storer.(copier)
+	if !ok {
+		return 0, fmt.Errorf("delta downloads are not supported by the store %T", d.storer)
+	}
+
+	since, err := c.LatestUpdate(owner, name, baseVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := d.nextVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	d.incremental = &incremental{since: since, latest: since, base: baseVersion, copier: c}
+	err = d.downloadRepositoryVersion(ctx, owner, name, version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// downloadRepositoryVersion downloads the repository in its own transaction,
+// with the given version
+func (d Downloader) downloadRepositoryVersion(ctx context.Context, owner string, name string, version int) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	skipped, err := d.newSkipped()
+	if err != nil {
+		return err
+	}
+
+	version, err = d.downloadVersion(version)
+	if err != nil {
+		return err
+	}
+
+	d.skipped = skipped
+	d.storer = d.transformedStorer()
+	d.storer = d.authorsStorer()
+	d.storer.Version(version)
+	d.users = newNodeCache()
+	d.rateGuard = d.newRateGuard()
+
+	err = d.storer.Begin()
+	if err != nil {
+		return fmt.Errorf("could not call Begin(): %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			d.storer.Rollback()
+			return
+		}
+
+		err = d.storer.Commit()
+		if err != nil {
+			err = fmt.Errorf("could not call Commit(): %v", err)
+			return
+		}
+
+		err = d.skipped.err()
+	}()
+
+	err = d.downloadRepository(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+
+	err = d.incremental.copyForward(owner, name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// downloadRepository downloads the repository and all its resources, it must
+// be called inside a transaction
+func (d Downloader) downloadRepository(ctx context.Context, owner string, name string) error {
+	var q struct {
+		graphql.Repository `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	// Some variables are repeated in the query, like assigneesCursor for Issues
+	// and PullRequests. It's ok to reuse because in this top level Repository
+	// query the cursors are set to nil, and when the pagination occurs, the
+	// queries only request either Issues or PullRequests
+	variables := map[string]interface{}{
+		"owner": githubv4.String(owner),
+		"name":  githubv4.String(name),
+
+		"assigneesPage":                 d.pageSize(d.PageSizes.Assignees, assigneesPage),
+		"assignmentEventsPage":          d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+		"closingReferencesPage":         d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+		"issueCommentsPage":             d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"issuesPage":                    d.pageSize(d.PageSizes.Issues, issuesPage),
+		"labelsPage":                    d.pageSize(d.PageSizes.Labels, labelsPage),
+		"pullRequestCommitsPage":        d.pageSize(d.PageSizes.PullRequestCommits, pullRequestCommitsPage),
+		"pullRequestReviewCommentsPage": d.pageSize(d.PageSizes.PullRequestReviewComments, pullRequestReviewCommentsPage),
+		"pullRequestReviewsPage":        d.pageSize(d.PageSizes.PullRequestReviews, pullRequestReviewsPage),
+		"pullRequestReviewsAuthor":      d.reviewsAuthor(),
+		"pullRequestsPage":              d.pageSize(d.PageSizes.PullRequests, pullRequestsPage),
+		"repositoryTopicsPage":          d.pageSize(d.PageSizes.RepositoryTopics, repositoryTopicsPage),
+		"timelineItemsPage":             d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+		"assigneesCursor":                 (*githubv4.String)(nil),
+		"assignmentEventsCursor":          (*githubv4.String)(nil),
+		"closingReferencesCursor":         (*githubv4.String)(nil),
+		"issueCommentsCursor":             (*githubv4.String)(nil),
+		"issuesCursor":                    (*githubv4.String)(nil),
+		"labelsCursor":                    (*githubv4.String)(nil),
+		"pullRequestCommitsCursor":        (*githubv4.String)(nil),
+		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
+		"pullRequestReviewsCursor":        (*githubv4.String)(nil),
+		"pullRequestsCursor":              (*githubv4.String)(nil),
+		"repositoryTopicsCursor":          (*githubv4.String)(nil),
+		"timelineItemsCursor":             (*githubv4.String)(nil),
+
+		"issuesOrder":       d.incremental.order(),
+		"pullRequestsOrder": d.incremental.order(),
+		"pullRequestStates": d.PullRequestStates,
+
+		"bodiesOmitted":              githubv4.Boolean(d.BodiesOmitted),
+		"projectStatusesIncluded":    githubv4.Boolean(d.ProjectStatusesIncluded),
+		"pullRequestCommitsIncluded": githubv4.Boolean(d.PullRequestCommitsIncluded || d.CommitAuthorsIncluded),
+		"commitAuthorsIncluded":      githubv4.Boolean(d.CommitAuthorsIncluded),
+		"timelineEventsIncluded":     githubv4.Boolean(d.TimelineEventsIncluded),
+	}
+
+	if d.SampleSize > 0 {
+		// only the total count of issues and PRs is needed to sample them
+		variables["issuesPage"] = githubv4.Int(0)
+		variables["pullRequestsPage"] = githubv4.Int(0)
+	}
+
+	err := d.queryWithData(ctx, &q, variables, func() bool { return q.Repository.Name == "" })
+	if err != nil {
+		return fmt.Errorf("first query failed: %v", err)
+	}
+
+	if !d.resume.skip(CheckpointRepository) {
+		err = d.saveRepository(ctx, owner, name, &q.Repository)
+		if err != nil {
+			return err
+		}
+
+		err = d.resume.commit(d.storer, CheckpointIssues, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	// issues and comments
+	if !d.resume.skip(CheckpointIssues) {
+		err = d.downloadIssues(ctx, owner, name, &q.Repository)
+		if err != nil {
+			return err
+		}
+
+		err = d.resume.commit(d.storer, CheckpointPullRequests, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	// PRs and comments
+	err = d.downloadPullRequests(ctx, owner, name, &q.Repository)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// saveRepository saves the repository, its topics, rulesets, templates and,
+// if ReadmeIncluded is set, its README
+func (d Downloader) saveRepository(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
+	// repository topics
+	topics, err := d.downloadTopics(ctx, repository)
+	if err != nil {
+		return err
+	}
+
+	err = d.storer.SaveRepository(&repository.RepositoryFields, topics)
+	if err != nil {
+		return fmt.Errorf("failed to save repository %v: %v", repository.NameWithOwner, err)
+	}
+
+	for _, topic := range topics {
+		err = d.storer.SaveTopic(topic)
+		if err != nil {
+			return fmt.Errorf("failed to save topic %v: %v", topic, err)
+		}
+
+		err = d.storer.SaveRepositoryTopic(owner, name, topic)
+		if err != nil {
+			return fmt.Errorf("failed to save topic %v for repository %v: %v", topic, repository.NameWithOwner, err)
+		}
+	}
+
+	// rulesets, they require admin access to the repository
+	err = d.downloadRulesets(ctx, owner, name, repository)
+	if err != nil {
+		return err
+	}
+
+	// issue and PR templates
+	err = d.saveTemplates(owner, name, repository)
+	if err != nil {
+		return err
+	}
+
+	if d.ReadmeIncluded {
+		err = d.downloadReadme(ctx, owner, name, repository)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RateLimit is the rate limit of the v4 GitHub API, see
+// https://docs.github.com/en/graphql/overview/resource-limitations
+type RateLimit struct {
+	// Cost is the number of points the rate limit query itself cost
+	Cost int
+	// Limit is the maximum number of points per hour
+	Limit int
+	// Remaining is the number of points left until ResetAt
+	Remaining int
+	// ResetAt is the time, in UTC, when Remaining is reset to Limit
+	ResetAt time.Time
+}
+
+// ErrRateLimitUnavailable is returned by RateLimit when the API does not
+// report the rate limit, e.g. a GitHub Enterprise Server without the
+// rateLimit field in its schema, or with rate limiting disabled
+var ErrRateLimitUnavailable = errors.New("the rate limit is not available")
+
+// RateUnavailable is returned by RateRemaining when the API does not report
+// the rate limit, see ErrRateLimitUnavailable
+const RateUnavailable = -1
+
+// RateLimit returns the current rate limit for the v4 GitHub API. A scheduler
+// can use it to sleep until ResetAt when there are no remaining points left.
+// It returns ErrRateLimitUnavailable if the API does not report it
+func (d Downloader) RateLimit(ctx context.Context) (RateLimit, error) {
+	var q struct {
+		RateLimit *struct {
+			Cost      int
+			Limit     int
+			Remaining int
+			ResetAt   time.Time
+		}
+	}
+
+	err := d.query(ctx, &q, nil)
+	if err != nil {
+		if rateLimitMissing(err) {
+			return RateLimit{}, ErrRateLimitUnavailable
+		}
+
+		return RateLimit{}, fmt.Errorf("failed to query rate limit: %v", err)
+	}
+
+	if q.RateLimit == nil {
+		return RateLimit{}, ErrRateLimitUnavailable
+	}
+
+	return RateLimit{
+		Cost:      q.RateLimit.Cost,
+		Limit:     q.RateLimit.Limit,
+		Remaining: q.RateLimit.Remaining,
+		ResetAt:   q.RateLimit.ResetAt.UTC(),
+	}, nil
+}
+
+// RateRemaining returns the remaining rate limit for the v4 GitHub API, or
+// RateUnavailable if the API does not report it
+func (d Downloader) RateRemaining(ctx context.Context) (int, error) {
+	var q struct {
+		RateLimit *struct {
+			Remaining int
+		}
+	}
+
+	err := d.query(ctx, &q, nil)
+	if err != nil {
+		if rateLimitMissing(err) {
+			return RateUnavailable, nil
+		}
+
+		return 0, fmt.Errorf("failed to query remaining rate limit: %v", err)
+	}
+
+	if q.RateLimit == nil {
+		return RateUnavailable, nil
+	}
+
+	return q.RateLimit.Remaining, nil
+}
+
+// rateLimitMissing returns true if the error of a rate limit query is caused
+// by a schema without the rateLimit field
+func rateLimitMissing(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "rateLimit") && strings.Contains(msg, "doesn't exist on type")
+}
+
+func (d Downloader) downloadTopics(ctx context.Context, repository *graphql.Repository) ([]string, error) {
+	topics := []string{}
+
+	// Topics included in the first page
+	for _, topicNode := range repository.RepositoryTopics.Nodes {
+		topics = append(topics, topicNode.Topic.Name)
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(repository.Id),
+
+		"repositoryTopicsPage":   d.pageSize(d.PageSizes.RepositoryTopics, repositoryTopicsPage),
+		"repositoryTopicsCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more topics, loop over all the pages
+	err := paginate(ctx, repository.RepositoryTopics.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only repository topics
+		var q struct {
+			Node struct {
+				Repository struct {
+					RepositoryTopics graphql.RepositoryTopicsConnection `graphql:"repositoryTopics(first: $repositoryTopicsPage, after: $repositoryTopicsCursor)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["repositoryTopicsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("RepositoryTopics query failed: %v", err)
+		}
+
+		for _, topicNode := range q.Node.Repository.RepositoryTopics.Nodes {
+			topics = append(topics, topicNode.Topic.Name)
+		}
+
+		return q.Node.Repository.RepositoryTopics.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+// downloadRulesets saves the rulesets of the repository. Reading them requires
+// admin access, if the token does not have it the rulesets are skipped
+func (d Downloader) downloadRulesets(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
+	variables := map[string]interface{}{
+		"id": githubv4.ID(repository.Id),
+
+		"rulesPage":      githubv4.Int(rulesPage),
+		"rulesetsPage":   d.pageSize(d.PageSizes.Rulesets, rulesetsPage),
+		"rulesetsCursor": (*githubv4.String)(nil),
+	}
+
+	// rulesets are not included in the repository query, so the permission
+	// error does not fail the whole download
+	return paginateAll(ctx, func(cursor string) (graphql.PageInfo, error) {
+		var q struct {
+			Node struct {
+				Repository struct {
+					Rulesets graphql.RepositoryRulesetConnection `graphql:"rulesets(first: $rulesetsPage, after: $rulesetsCursor)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["rulesetsCursor"] = afterCursor(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			if isPermissionError(err) {
+				log.Warningf("skipping rulesets of repository %v/%v: %v", owner, name, err)
+				return graphql.PageInfo{}, nil
+			}
+
+			return graphql.PageInfo{}, fmt.Errorf("failed to query rulesets for repository %v/%v: %v", owner, name, err)
+		}
+
+		for i := range q.Node.Repository.Rulesets.Nodes {
+			ruleset := &q.Node.Repository.Rulesets.Nodes[i]
+			err := d.storer.SaveRuleset(owner, name, ruleset)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to save ruleset %v for repository %v/%v: %v", ruleset.Name, owner, name, err)
+			}
+		}
+
+		return q.Node.Repository.Rulesets.PageInfo, nil
+	})
+}
+
+// saveTemplates saves the issue and PR templates of the repository, included
+// in the repository query. Most repositories have none
+func (d Downloader) saveTemplates(owner string, name string, repository *graphql.Repository) error {
+	var templates []store.Template
+	for _, t := range repository.IssueTemplates {
+		templates = append(templates, store.Template{
+			Kind:     store.IssueTemplate,
+			Filename: t.Filename,
+			Name:     t.Name,
+			Title:    t.Title,
+			About:    t.About,
+			Body:     t.Body,
+		})
+	}
+	for _, t := range repository.PullRequestTemplates {
+		templates = append(templates, store.Template{
+			Kind:     store.PullRequestTemplate,
+			Filename: t.Filename,
+			Body:     t.Body,
+		})
+	}
+
+	for i := range templates {
+		err := d.storer.SaveTemplate(owner, name, &templates[i])
+		if err != nil {
+			return fmt.Errorf("failed to save template %v for repository %v: %v", templates[i].Filename, repository.NameWithOwner, err)
+		}
+	}
+
+	return nil
+}
+
+// readmeExtensions are the extensions of the README files, from the most to
+// the least preferred when a repository has more than one
+var readmeExtensions = []string{".md", ".markdown", ".rst", ".txt", ""}
+
+// readmeFilename returns the README among the given file names, matched
+// case-insensitively, e.g. README.md, readme.rst or README. It returns an
+// empty string if there is none
+func readmeFilename(filenames []string) string {
+	best, bestRank := "", -1
+	for _, filename := range filenames {
+		lower := strings.ToLower(filename)
+		if lower != "readme" && !strings.HasPrefix(lower, "readme.") {
+			continue
+		}
+
+		// other extensions, e.g. README.adoc, come after the known ones
+		rank := len(readmeExtensions)
+		for i, ext := range readmeExtensions {
+			if lower == "readme"+ext {
+				rank = i
+				break
+			}
+		}
+
+		if bestRank == -1 || rank < bestRank || (rank == bestRank && filename < best) {
+			best, bestRank = filename, rank
+		}
+	}
+
+	return best
+}
+
+// downloadReadme saves the README of the repository, if it has one. The file
+// names of the root of the default branch are queried first, and then the
+// README found among them
+func (d Downloader) downloadReadme(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
+	var entries struct {
+		Node struct {
+			Repository struct {
+				Object *struct {
+					Tree struct {
+						Entries []struct {
+							Name string
+							Type string
+						}
+					} `graphql:"... on Tree"`
+				} `graphql:"object(expression: $expression)"`
+			} `graphql:"... on Repository"`
+		} `graphql:"node(id:$id)"`
+	}
+
+	variables := map[string]interface{}{
+		"id":         githubv4.ID(repository.Id),
+		"expression": githubv4.String("HEAD:"),
+	}
+
+	err := d.query(ctx, &entries, variables)
+	if err != nil {
+		return fmt.Errorf("failed to query the files of repository %v/%v: %v", owner, name, err)
+	}
+
+	// empty repositories have no HEAD
+	if entries.Node.Repository.Object == nil {
+		return nil
+	}
+
+	var filenames []string
+	for _, entry := range entries.Node.Repository.Object.Tree.Entries {
+		if entry.Type == "blob" {
+			filenames = append(filenames, entry.Name)
+		}
+	}
+
+	filename := readmeFilename(filenames)
+	if filename == "" {
+		return nil
+	}
+
+	var blob struct {
+		Node struct {
+			Repository struct {
+				Object *struct {
+					Blob struct {
+						ByteSize int
+						IsBinary bool
+						Text     string
+					} `graphql:"... on Blob"`
+				} `graphql:"object(expression: $expression)"`
+			} `graphql:"... on Repository"`
+		} `graphql:"node(id:$id)"`
+	}
+
+	variables["expression"] = githubv4.String("HEAD:" + filename)
+	err = d.query(ctx, &blob, variables)
+	if err != nil {
+		return fmt.Errorf("failed to query README %v of repository %v/%v: %v", filename, owner, name, err)
+	}
+
+	// the default branch may have changed between the queries
+	if blob.Node.Repository.Object == nil {
+		return nil
+	}
+
+	readme := &store.Readme{
+		Filename: filename,
+		ByteSize: blob.Node.Repository.Object.Blob.ByteSize,
+	}
+	if !blob.Node.Repository.Object.Blob.IsBinary {
+		readme.Body = blob.Node.Repository.Object.Blob.Text
+	}
+
+	err = d.storer.SaveReadme(owner, name, readme)
+	if err != nil {
+		return fmt.Errorf("failed to save README %v for repository %v/%v: %v", filename, owner, name, err)
+	}
+
+	return nil
+}
+
+// isPermissionError returns true if err is the GraphQL error returned when the
+// token lacks the scope or access level to read a field
+func isPermissionError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"admin rights", "not accessible", "forbidden", "permission", "organization owner"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d Downloader) downloadIssues(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
+	if d.Concurrency > 1 {
+		d.storer = &syncStorer{storer: d.storer}
+	}
+
+	progress := d.newProgress(ProgressIssues, owner, name)
+	process := func(issue *graphql.Issue) error {
+		return d.tolerate(ctx, "issue", owner, name, issue.Number, func() error {
+			return d.processIssue(ctx, owner, name, issue, progress)
+		})
+	}
+
+	if d.SampleSize > 0 {
+		return d.downloadIssueSample(ctx, owner, name, repository, process)
+	}
+
+	// Save issues included in the first page
+	hasNextPage := repository.Issues.PageInfo.HasNextPage
+	endCursor := repository.Issues.PageInfo.EndCursor
+	nodes := repository.Issues.Nodes
+
+	if cursor := d.resume.cursor(CheckpointIssues); cursor != "" {
+		// the first pages were saved by a previous run
+		hasNextPage = true
+		endCursor = cursor
+		nodes = nil
+	}
+
+	var issues []*graphql.Issue
+	for i := range nodes {
+		issue := &nodes[i]
+		if !d.incremental.updated(issue.UpdatedAt) {
+			hasNextPage = false
+			break
+		}
+
+		issues = append(issues, issue)
+	}
+
+	err := d.processNodes(len(issues), func(i int) error {
+		err := process(issues[i])
+		if err != nil {
+			return fmt.Errorf("failed to process issue %v/%v #%v: %v", owner, name, issues[i].Number, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(repository.Id),
+
+		"assigneesPage":         d.pageSize(d.PageSizes.Assignees, assigneesPage),
+		"assignmentEventsPage":  d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+		"closingReferencesPage": d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+		"issueCommentsPage":     d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"issuesPage":            d.pageSize(d.PageSizes.Issues, issuesPage),
+		"labelsPage":            d.pageSize(d.PageSizes.Labels, labelsPage),
+		"timelineItemsPage":     d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+		"assigneesCursor":         (*githubv4.String)(nil),
+		"assignmentEventsCursor":  (*githubv4.String)(nil),
+		"closingReferencesCursor": (*githubv4.String)(nil),
+		"issueCommentsCursor":     (*githubv4.String)(nil),
+		"issuesCursor":            (*githubv4.String)(nil),
+		"labelsCursor":            (*githubv4.String)(nil),
+		"timelineItemsCursor":     (*githubv4.String)(nil),
+
+		"issuesOrder": d.incremental.order(),
+
+		"bodiesOmitted":          githubv4.Boolean(d.BodiesOmitted),
+		"timelineEventsIncluded": githubv4.Boolean(d.TimelineEventsIncluded),
+	}
+
+	// if there are more issues, loop over all the pages
+	first := graphql.PageInfo{HasNextPage: hasNextPage, EndCursor: endCursor}
+	return paginate(ctx, first, func(cursor string) (graphql.PageInfo, error) {
+		// get only issues
+		var q struct {
+			Node struct {
+				Repository struct {
+					Issues graphql.IssueConnection `graphql:"issues(first: $issuesPage, after: $issuesCursor, orderBy: $issuesOrder)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		// in a resumable download the pages saved so far are committed
+		err := d.resume.commit(d.storer, CheckpointIssues, cursor)
+		if err != nil {
+			return graphql.PageInfo{}, err
+		}
+
+		variables["issuesCursor"] = githubv4.String(cursor)
+
+		err = d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query issues for repository %v: %v", repository.NameWithOwner, err)
+		}
+		progress.page(cursor)
+
+		page := q.Node.Repository.Issues.PageInfo
+		var issues []*graphql.Issue
+		for i := range q.Node.Repository.Issues.Nodes {
+			issue := &q.Node.Repository.Issues.Nodes[i]
+			if !d.incremental.updated(issue.UpdatedAt) {
+				page.HasNextPage = false
+				break
+			}
+
+			issues = append(issues, issue)
+		}
+
+		err = d.processNodes(len(issues), func(i int) error {
+			err := process(issues[i])
+			if err != nil {
+				return fmt.Errorf("failed to process issue %v #%v: %v", repository.NameWithOwner, issues[i].Number, err)
+			}
+
+			return nil
+		})
+
+		return page, err
+	})
+}
+
+// processIssue saves the issue and downloads all its resources
+func (d Downloader) processIssue(ctx context.Context, owner string, name string, issue *graphql.Issue, progress *progress) error {
+	complete, err := d.commentsComplete(owner, name, issue.Number, issue.UpdatedAt, issue.Comments.TotalCount)
+	if err != nil {
+		return err
+	}
+
+	assignees, err := d.downloadIssueAssignees(ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	labels, err := d.downloadIssueLabels(ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	err = d.storer.SaveIssue(owner, name, issue, assignees, labels)
+	if err != nil {
+		return err
+	}
+	progress.saved()
+	err = d.saveMentions(issue.Id, issue.Body)
+	if err != nil {
+		return err
+	}
+	err = d.saveReactions(issue.Id, issue.ReactionGroups)
+	if err != nil {
+		return err
+	}
+	err = d.saveLock(owner, name, issue.Number, issue.Locked, &issue.LockedBy)
+	if err != nil {
+		return err
+	}
+	err = d.downloadAssignmentEvents(ctx, owner, name, issue.Id, issue.Number, &issue.AssignmentEvents)
+	if err != nil {
+		return err
+	}
+	err = d.downloadIssueEvents(ctx, owner, name, issue)
+	if err != nil {
+		return err
+	}
+	err = d.downloadClosingReferences(ctx, owner, name, issue.Id, issue.Number, false, &issue.ClosedByPullRequests)
+	if err != nil {
+		return err
+	}
+	if complete {
+		return nil
+	}
+	return d.downloadIssueComments(ctx, owner, name, issue)
+}
+
+// downloadIssueSample processes a random sample of SampleSize issues of the
+// repository. Each sampled issue is queried by its node id
+func (d Downloader) downloadIssueSample(ctx context.Context, owner string, name string, repository *graphql.Repository, process func(*graphql.Issue) error) error {
+	ids, err := d.sampleNodeIDs(ctx, repository.Issues.TotalCount, func(variables map[string]interface{}) (*graphql.NodeIDConnection, error) {
+		var q struct {
+			Node struct {
+				Repository struct {
+					Issues graphql.NodeIDConnection `graphql:"issues(first: $nodeIDsPage, after: $nodeIDsCursor)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["id"] = githubv4.ID(repository.Id)
+		err := d.query(ctx, &q, variables)
+		return &q.Node.Repository.Issues, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query issue ids for repository %v/%v: %v", owner, name, err)
+	}
+
+	variables := map[string]interface{}{
+		"assigneesPage":         d.pageSize(d.PageSizes.Assignees, assigneesPage),
+		"assignmentEventsPage":  d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+		"closingReferencesPage": d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+		"issueCommentsPage":     d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"labelsPage":            d.pageSize(d.PageSizes.Labels, labelsPage),
+		"timelineItemsPage":     d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+		"assigneesCursor":         (*githubv4.String)(nil),
+		"assignmentEventsCursor":  (*githubv4.String)(nil),
+		"closingReferencesCursor": (*githubv4.String)(nil),
+		"issueCommentsCursor":     (*githubv4.String)(nil),
+		"labelsCursor":            (*githubv4.String)(nil),
+		"timelineItemsCursor":     (*githubv4.String)(nil),
+
+		"bodiesOmitted":          githubv4.Boolean(d.BodiesOmitted),
+		"timelineEventsIncluded": githubv4.Boolean(d.TimelineEventsIncluded),
+	}
+
+	for _, id := range ids {
+		var q struct {
+			Node struct {
+				Issue graphql.Issue `graphql:"... on Issue"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["id"] = githubv4.ID(id)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return fmt.Errorf("failed to query issue %v for repository %v/%v: %v", id, owner, name, err)
+		}
+
+		err = process(&q.Node.Issue)
+		if err != nil {
+			return fmt.Errorf("failed to process issue %v/%v #%v: %v", owner, name, q.Node.Issue.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// sampleNodeIDs returns the ids of a random sample of SampleSize nodes of a
+// connection with total nodes. The query function must request the page of
+// ids for the given variables
+func (d Downloader) sampleNodeIDs(ctx context.Context, total int, query func(variables map[string]interface{}) (*graphql.NodeIDConnection, error)) ([]string, error) {
+	offsets := sampleOffsets(total, d.SampleSize)
+
+	variables := map[string]interface{}{
+		"nodeIDsPage":   d.pageSize(d.PageSizes.NodeIDs, nodeIDsPage),
+		"nodeIDsCursor": (*githubv4.String)(nil),
+	}
+
+	var ids []string
+	offset := 0
+	for len(offsets) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page, err := query(variables)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, node := range page.Nodes {
+			if len(offsets) > 0 && offsets[0] == offset {
+				ids = append(ids, node.Id)
+				offsets = offsets[1:]
+			}
+			offset++
+		}
+
+		// the connection can shrink while it is paginated
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["nodeIDsCursor"] = githubv4.String(page.PageInfo.EndCursor)
+	}
+
+	return ids, nil
+}
+
+// sampleOffsets returns n random offsets, sorted, of a connection with total
+// nodes, or all of them if n >= total.
+//
+// GraphQL cursors are opaque, so a random offset can not be turned into a
+// cursor to start paginating from, and GitHub connections do not take an
+// offset argument. As an approximation, the connection is still paginated from
+// the start until the last sampled offset, but requesting only the node ids,
+// nodeIDsPage at a time, which costs a small part of the rate limit of the full
+// query. Only the nodes at the sampled offsets are then downloaded. The sample
+// is uniform over the nodes that existed when the ids were paginated
+func sampleOffsets(total, n int) []int {
+	if n >= total {
+		n = total
+	}
+
+	offsets := rand.Perm(total)[:n]
+	sort.Ints(offsets)
+	return offsets
+}
+
+func (d Downloader) downloadIssueAssignees(ctx context.Context, issue *graphql.Issue) ([]string, error) {
+	assignees := []string{}
+
+	// Assignees included in the first page
+	for _, node := range issue.Assignees.Nodes {
+		assignees = append(assignees, node.Login)
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(issue.Id),
+
+		"assigneesPage":   d.pageSize(d.PageSizes.Assignees, assigneesPage),
+		"assigneesCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more assignees, loop over all the pages
+	err := paginate(ctx, issue.Assignees.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only issue assignees
+		var q struct {
+			Node struct {
+				Issue struct {
+					Assignees graphql.UserConnection `graphql:"assignees(first: $assigneesPage, after: $assigneesCursor)"`
+				} `graphql:"... on Issue"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["assigneesCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query issue assignees for issue #%v: %v", issue.Number, err)
+		}
+
+		for _, node := range q.Node.Issue.Assignees.Nodes {
+			assignees = append(assignees, node.Login)
+		}
+
+		return q.Node.Issue.Assignees.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assignees, nil
+}
+
+func (d Downloader) downloadIssueLabels(ctx context.Context, issue *graphql.Issue) ([]string, error) {
+	labels := []string{}
+
+	// Labels included in the first page
+	for _, node := range issue.Labels.Nodes {
+		labels = append(labels, node.Name)
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(issue.Id),
+
+		"labelsPage":   d.pageSize(d.PageSizes.Labels, labelsPage),
+		"labelsCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more labels, loop over all the pages
+	err := paginate(ctx, issue.Labels.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only issue labels
+		var q struct {
+			Node struct {
+				Issue struct {
+					Labels graphql.LabelConnection `graphql:"labels(first: $labelsPage, after: $labelsCursor)"`
+				} `graphql:"... on Issue"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["labelsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query issue labels for issue #%v: %v", issue.Number, err)
+		}
+
+		for _, node := range q.Node.Issue.Labels.Nodes {
+			labels = append(labels, node.Name)
+		}
+
+		return q.Node.Issue.Labels.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// downloadAssignmentEvents saves the assigned and unassigned events of the
+// issue or PR with the given node id, in the order they happened. The first
+// page of events is the one already included in the issue or PR query
+func (d Downloader) downloadAssignmentEvents(ctx context.Context, owner string, name string, id string, number int, events *graphql.AssignmentEventConnection) error {
+	// save first page of events
+	for i := range events.Nodes {
+		event := &events.Nodes[i]
+		err := d.storer.SaveAssignmentEvent(owner, name, number, event)
+		if err != nil {
+			return fmt.Errorf("failed to save assignment events for #%v: %v", number, err)
+		}
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(id),
+
+		"assignmentEventsPage":   d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+		"assignmentEventsCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more events, loop over all the pages
+	return paginate(ctx, events.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only assignment events, the node can be an issue or a PR
+		var q struct {
+			Node struct {
+				Issue struct {
+					AssignmentEvents graphql.AssignmentEventConnection `graphql:"assignmentEvents: timelineItems(first: $assignmentEventsPage, after: $assignmentEventsCursor, itemTypes: [ASSIGNED_EVENT, UNASSIGNED_EVENT])"`
+				} `graphql:"... on Issue"`
+				PullRequest struct {
+					AssignmentEvents graphql.AssignmentEventConnection `graphql:"assignmentEvents: timelineItems(first: $assignmentEventsPage, after: $assignmentEventsCursor, itemTypes: [ASSIGNED_EVENT, UNASSIGNED_EVENT])"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["assignmentEventsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query assignment events for #%v: %v", number, err)
+		}
+
+		// both fragments are decoded from the same object, so they hold the
+		// same events
+		page := q.Node.Issue.AssignmentEvents
+		for i := range page.Nodes {
+			event := &page.Nodes[i]
+			err := d.storer.SaveAssignmentEvent(owner, name, number, event)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to save assignment events for #%v: %v", number, err)
+			}
+		}
+
+		return page.PageInfo, nil
+	})
+}
+
+// downloadIssueEvents saves the timeline events of the issue, see
+// graphql.IssueEvent, in the order they happened. The first page of events is
+// the one already included in the issue query
+func (d Downloader) downloadIssueEvents(ctx context.Context, owner string, name string, issue *graphql.Issue) error {
+	// save first page of events
+	for i := range issue.TimelineItems.Nodes {
+		event := &issue.TimelineItems.Nodes[i]
+		err := d.storer.SaveIssueEvent(owner, name, issue.Number, event)
+		if err != nil {
+			return fmt.Errorf("failed to save timeline events for issue #%v: %v", issue.Number, err)
+		}
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(issue.Id),
+
+		"timelineItemsPage":   d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+		"timelineItemsCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more events, loop over all the pages
+	return paginate(ctx, issue.TimelineItems.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only timeline events
+		var q struct {
+			Node struct {
+				Issue struct {
+					TimelineItems graphql.IssueEventConnection `graphql:"timelineEvents: timelineItems(first: $timelineItemsPage, after: $timelineItemsCursor, itemTypes: [LABELED_EVENT, UNLABELED_EVENT, CLOSED_EVENT, REOPENED_EVENT, CROSS_REFERENCED_EVENT])"`
+				} `graphql:"... on Issue"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["timelineItemsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query timeline events for issue #%v: %v", issue.Number, err)
+		}
+
+		page := q.Node.Issue.TimelineItems
+		for i := range page.Nodes {
+			event := &page.Nodes[i]
+			err := d.storer.SaveIssueEvent(owner, name, issue.Number, event)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to save timeline events for issue #%v: %v", issue.Number, err)
+			}
+		}
+
+		return page.PageInfo, nil
+	})
+}
+
+// downloadPullRequestEvents saves the timeline events of the PR, see
+// graphql.PullRequestEvent, in the order they happened. The first page of
+// events is the one already included in the PR query
+func (d Downloader) downloadPullRequestEvents(ctx context.Context, owner string, name string, pr *graphql.PullRequest) error {
+	// save first page of events
+	for i := range pr.TimelineItems.Nodes {
+		event := &pr.TimelineItems.Nodes[i]
+		err := d.storer.SavePullRequestEvent(owner, name, pr.Number, event)
+		if err != nil {
+			return fmt.Errorf("failed to save timeline events for PR #%v: %v", pr.Number, err)
+		}
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(pr.Id),
+
+		"timelineItemsPage":   d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+		"timelineItemsCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more events, loop over all the pages
+	return paginate(ctx, pr.TimelineItems.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only timeline events
+		var q struct {
+			Node struct {
+				PullRequest struct {
+					TimelineItems graphql.PullRequestEventConnection `graphql:"timelineEvents: timelineItems(first: $timelineItemsPage, after: $timelineItemsCursor, itemTypes: [LABELED_EVENT, UNLABELED_EVENT, CLOSED_EVENT, REOPENED_EVENT, MERGED_EVENT, CROSS_REFERENCED_EVENT])"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["timelineItemsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query timeline events for PR #%v: %v", pr.Number, err)
+		}
+
+		page := q.Node.PullRequest.TimelineItems
+		for i := range page.Nodes {
+			event := &page.Nodes[i]
+			err := d.storer.SavePullRequestEvent(owner, name, pr.Number, event)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to save timeline events for PR #%v: %v", pr.Number, err)
+			}
+		}
+
+		return page.PageInfo, nil
+	})
+}
+
+// downloadClosingReferences saves the closing references of the issue or PR
+// with the given node id: the PRs that close the issue, or the issues closed by
+// the PR. The first page of references is the one already included in the
+// issue or PR query
+func (d Downloader) downloadClosingReferences(ctx context.Context, owner string, name string, id string, number int, isPullRequest bool, references *graphql.ClosingReferenceConnection) error {
+	save := func(page *graphql.ClosingReferenceConnection) error {
+		for _, node := range page.Nodes {
+			reference := &store.ClosingReference{
+				IssueRepositoryOwner:       owner,
+				IssueRepositoryName:        name,
+				IssueNumber:                number,
+				PullRequestRepositoryOwner: node.Repository.Owner.Login,
+				PullRequestRepositoryName:  node.Repository.Name,
+				PullRequestNumber:          node.Number,
+			}
+			if isPullRequest {
+				reference = &store.ClosingReference{
+					IssueRepositoryOwner:       node.Repository.Owner.Login,
+					IssueRepositoryName:        node.Repository.Name,
+					IssueNumber:                node.Number,
+					PullRequestRepositoryOwner: owner,
+					PullRequestRepositoryName:  name,
+					PullRequestNumber:          number,
+				}
+			}
+
+			err := d.storer.SaveClosingReference(reference)
+			if err != nil {
+				return fmt.Errorf("failed to save closing references for #%v: %v", number, err)
+			}
+		}
+
+		return nil
+	}
+
+	// save first page of references
+	err := save(references)
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(id),
+
+		"closingReferencesPage":   d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+		"closingReferencesCursor": (*githubv4.String)(nil),
+	}
+
+	// if there are more references, loop over all the pages
+	return paginate(ctx, references.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only closing references, the node can be an issue or a PR
+		var q struct {
+			Node struct {
+				Issue struct {
+					ClosingReferences graphql.ClosingReferenceConnection `graphql:"closingReferences: closedByPullRequestsReferences(first: $closingReferencesPage, after: $closingReferencesCursor, includeClosedPrs: true)"`
+				} `graphql:"... on Issue"`
+				PullRequest struct {
+					ClosingReferences graphql.ClosingReferenceConnection `graphql:"closingReferences: closingIssuesReferences(first: $closingReferencesPage, after: $closingReferencesCursor)"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["closingReferencesCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query closing references for #%v: %v", number, err)
+		}
+
+		// both fragments are decoded from the same object, so they hold the
+		// same references
+		page := q.Node.Issue.ClosingReferences
+		err = save(&page)
+		if err != nil {
+			return graphql.PageInfo{}, err
+		}
+
+		return page.PageInfo, nil
+	})
+}
+
+// saveLock saves the lock of a locked issue or PR, from its last locked event.
+// Nothing is saved if it is not locked, or it has no locked event
+func (d Downloader) saveLock(owner string, name string, number int, locked bool, events *graphql.LockedByConnection) error {
+	if !locked || len(events.Nodes) == 0 {
+		return nil
+	}
+
+	event := events.Nodes[0].LockedEvent
+	err := d.storer.SaveLock(owner, name, number, &store.Lock{
+		NodeID:        event.Id,
+		LockedAt:      event.CreatedAt,
+		LockedByID:    event.Actor.DatabaseId,
+		LockedByLogin: event.Actor.Login,
+		Reason:        event.LockReason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save the lock of #%v: %v", number, err)
+	}
+
+	return nil
+}
+
+// saveProjectStatuses saves the status of the PR in each of its projects.
+// Nothing is saved if it is not in any project
+func (d Downloader) saveProjectStatuses(owner string, name string, pr *graphql.PullRequest) error {
+	for _, item := range pr.ProjectItems.Nodes {
+		err := d.storer.SaveProjectStatus(owner, name, pr.Number, &store.ProjectStatus{
+			NodeID:               item.Id,
+			ProjectNodeID:        item.Project.Id,
+			ProjectNumber:        item.Project.Number,
+			ProjectTitle:         item.Project.Title,
+			Status:               item.Status.SingleSelect.Name,
+			StatusUpdatedAt:      item.Status.SingleSelect.UpdatedAt,
+			PullRequestUpdatedAt: pr.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save the status of #%v in project %v: %v", pr.Number, item.Project.Title, err)
+		}
+	}
+
+	return nil
+}
+
+// saveMentions saves the users and teams mentioned in the body of the issue,
+// PR, comment or review with the given node id
+func (d Downloader) saveMentions(subjectID string, body string) error {
+	for _, login := range ParseMentions(body) {
+		err := d.storer.SaveMention(subjectID, login)
+		if err != nil {
+			return fmt.Errorf("failed to save mention of %v in %v: %v", login, subjectID, err)
+		}
+	}
+
+	return nil
+}
+
+// saveReactions saves the reaction groups of the issue, PR, comment or review
+// with the given node id, skipping the empty ones
+func (d Downloader) saveReactions(subjectID string, groups []graphql.ReactionGroup) error {
+	for i := range groups {
+		if groups[i].Reactors.TotalCount == 0 {
+			continue
+		}
+
+		err := d.storer.SaveReactionGroup(subjectID, &groups[i])
+		if err != nil {
+			return fmt.Errorf("failed to save %v reactions to %v: %v", groups[i].Content, subjectID, err)
+		}
+	}
+
+	return nil
+}
+
+func (d Downloader) downloadIssueComments(ctx context.Context, owner string, name string, issue *graphql.Issue) error {
+	if watermark, ok := d.CommentWatermarks[issue.Id]; ok {
+		return d.downloadCommentsSince(ctx, issue.Id, watermark, &issue.Comments, func(comment *graphql.IssueComment) error {
+			return d.saveIssueComment(owner, name, issue.Number, comment)
+		})
+	}
+
+	// save first page of comments
+	for i := range issue.Comments.Nodes {
+		err := d.saveIssueComment(owner, name, issue.Number, &issue.Comments.Nodes[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(issue.Id),
+
+		"issueCommentsPage":   d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"issueCommentsCursor": (*githubv4.String)(nil),
+
+		"bodiesOmitted": githubv4.Boolean(d.BodiesOmitted),
+	}
+
+	// if there are more issue comments, loop over all the pages
+	return paginate(ctx, issue.Comments.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only issue comments
+		var q struct {
+			Node struct {
+				Issue struct {
+					Comments graphql.IssueCommentsConnection `graphql:"comments(first: $issueCommentsPage, after: $issueCommentsCursor)"`
+				} `graphql:"... on Issue"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["issueCommentsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query issue comments for issue #%v: %v", issue.Number, err)
+		}
+
+		for i := range q.Node.Issue.Comments.Nodes {
+			err := d.saveIssueComment(owner, name, issue.Number, &q.Node.Issue.Comments.Nodes[i])
+			if err != nil {
+				return graphql.PageInfo{}, err
+			}
+		}
+
+		return q.Node.Issue.Comments.PageInfo, nil
+	})
+}
+
+// saveIssueComment saves the comment of the issue, with its mentions and
+// reactions, unless it is skipped, see issueCommentSkipped
+func (d Downloader) saveIssueComment(owner string, name string, number int, comment *graphql.IssueComment) error {
+	if d.issueCommentSkipped(comment) {
+		return nil
+	}
+
+	err := d.storer.SaveIssueComment(owner, name, number, comment)
+	if err != nil {
+		return fmt.Errorf("failed to save issue comments for issue #%v: %v", number, err)
+	}
+
+	err = d.saveMentions(comment.Id, comment.Body)
+	if err != nil {
+		return err
+	}
+
+	return d.saveReactions(comment.Id, comment.ReactionGroups)
+}
+
+// downloadCommentsSince saves, newest first, the comments of the issue or PR
+// with the given node id that are newer than the watermark comment. If the
+// first page of comments, already included in the issue or PR query, has all
+// of them no other query is made. Otherwise the comments are queried backwards
+// from the newest one, until the watermark. If the watermark is not found,
+// e.g. the comment was deleted, all the comments are saved
+func (d Downloader) downloadCommentsSince(ctx context.Context, id string, watermark string, comments *graphql.IssueCommentsConnection, save func(comment *graphql.IssueComment) error) error {
+	// saveNewer saves the nodes from the last one, and returns true once it
+	// reaches the watermark
+	saveNewer := func(nodes []graphql.IssueComment) (bool, error) {
+		for i := len(nodes) - 1; i >= 0; i-- {
+			if nodes[i].Id == watermark {
+				return true, nil
+			}
+
+			err := save(&nodes[i])
+			if err != nil {
+				return false, err
+			}
+		}
+
+		return false, nil
+	}
+
+	if !comments.PageInfo.HasNextPage {
+		_, err := saveNewer(comments.Nodes)
+		return err
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(id),
+
+		"issueCommentsPage":   d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"issueCommentsCursor": (*githubv4.String)(nil),
+
+		"bodiesOmitted": githubv4.Boolean(d.BodiesOmitted),
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// get only comments, the node can be an issue or a PR
+		var q struct {
+			Node struct {
+				Issue struct {
+					Comments graphql.IssueCommentsBackwardConnection `graphql:"comments(last: $issueCommentsPage, before: $issueCommentsCursor)"`
+				} `graphql:"... on Issue"`
+				PullRequest struct {
+					Comments graphql.IssueCommentsBackwardConnection `graphql:"comments(last: $issueCommentsPage, before: $issueCommentsCursor)"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return fmt.Errorf("failed to query comments of node %v: %v", id, err)
+		}
+
+		// both fragments are decoded from the same object, so they hold the
+		// same comments
+		page := q.Node.Issue.Comments
+		found, err := saveNewer(page.Nodes)
+		if err != nil || found || !page.PageInfo.HasPreviousPage {
+			return err
+		}
+
+		variables["issueCommentsCursor"] = githubv4.String(page.PageInfo.StartCursor)
+	}
+}
+
+func (d Downloader) downloadPullRequests(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
+	if d.Concurrency > 1 {
+		d.storer = &syncStorer{storer: d.storer}
+	}
+
+	progress := d.newProgress(ProgressPullRequests, owner, name)
+	process := func(pr *graphql.PullRequest) error {
+		return d.tolerate(ctx, "pull request", owner, name, pr.Number, func() error {
+			return d.processPullRequest(ctx, owner, name, pr, progress)
+		})
+	}
+
+	if d.SampleSize > 0 {
+		return d.downloadPullRequestSample(ctx, owner, name, repository, process)
+	}
+
+	// Save PRs included in the first page
+	hasNextPage := repository.PullRequests.PageInfo.HasNextPage
+	endCursor := repository.PullRequests.PageInfo.EndCursor
+	nodes := repository.PullRequests.Nodes
+
+	if cursor := d.resume.cursor(CheckpointPullRequests); cursor != "" {
+		// the first pages were saved by a previous run
+		hasNextPage = true
+		endCursor = cursor
+		nodes = nil
+	}
+
+	var prs []*graphql.PullRequest
+	for i := range nodes {
+		pr := &nodes[i]
+		if !d.incremental.updated(parseUpdatedAt(pr.UpdatedAt)) {
+			hasNextPage = false
+			break
+		}
+
+		prs = append(prs, pr)
+	}
+
+	err := d.processNodes(len(prs), func(i int) error {
+		err := process(prs[i])
+		if err != nil {
+			return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, prs[i].Number, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(repository.Id),
+
+		"assigneesPage":                 d.pageSize(d.PageSizes.Assignees, assigneesPage),
+		"assignmentEventsPage":          d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+		"closingReferencesPage":         d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+		"issueCommentsPage":             d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"labelsPage":                    d.pageSize(d.PageSizes.Labels, labelsPage),
+		"pullRequestCommitsPage":        d.pageSize(d.PageSizes.PullRequestCommits, pullRequestCommitsPage),
+		"pullRequestReviewCommentsPage": d.pageSize(d.PageSizes.PullRequestReviewComments, pullRequestReviewCommentsPage),
+		"pullRequestReviewsPage":        d.pageSize(d.PageSizes.PullRequestReviews, pullRequestReviewsPage),
+		"pullRequestReviewsAuthor":      d.reviewsAuthor(),
+		"pullRequestsPage":              d.pageSize(d.PageSizes.PullRequests, pullRequestsPage),
+		"timelineItemsPage":             d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+		"assigneesCursor":                 (*githubv4.String)(nil),
+		"assignmentEventsCursor":          (*githubv4.String)(nil),
+		"closingReferencesCursor":         (*githubv4.String)(nil),
+		"issueCommentsCursor":             (*githubv4.String)(nil),
+		"labelsCursor":                    (*githubv4.String)(nil),
+		"pullRequestCommitsCursor":        (*githubv4.String)(nil),
+		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
+		"pullRequestReviewsCursor":        (*githubv4.String)(nil),
+		"pullRequestsCursor":              (*githubv4.String)(nil),
+		"timelineItemsCursor":             (*githubv4.String)(nil),
+
+		"pullRequestsOrder": d.incremental.order(),
+		"pullRequestStates": d.PullRequestStates,
+
+		"bodiesOmitted":              githubv4.Boolean(d.BodiesOmitted),
+		"projectStatusesIncluded":    githubv4.Boolean(d.ProjectStatusesIncluded),
+		"pullRequestCommitsIncluded": githubv4.Boolean(d.PullRequestCommitsIncluded || d.CommitAuthorsIncluded),
+		"commitAuthorsIncluded":      githubv4.Boolean(d.CommitAuthorsIncluded),
+		"timelineEventsIncluded":     githubv4.Boolean(d.TimelineEventsIncluded),
+	}
+
+	// if there are more PRs, loop over all the pages
+	first := graphql.PageInfo{HasNextPage: hasNextPage, EndCursor: endCursor}
+	err = paginate(ctx, first, func(cursor string) (graphql.PageInfo, error) {
+		// get only PRs
+		var q struct {
+			Node struct {
+				Repository struct {
+					PullRequests graphql.PullRequestConnection `graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor, orderBy: $pullRequestsOrder, states: $pullRequestStates)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		// in a resumable download the pages saved so far are committed
+		err := d.resume.commit(d.storer, CheckpointPullRequests, cursor)
+		if err != nil {
+			return graphql.PageInfo{}, err
+		}
+
+		variables["pullRequestsCursor"] = githubv4.String(cursor)
+
+		err = d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query PRs for repository %v/%v: %v", owner, name, err)
+		}
+		progress.page(cursor)
+
+		page := q.Node.Repository.PullRequests.PageInfo
+		var prs []*graphql.PullRequest
+		for i := range q.Node.Repository.PullRequests.Nodes {
+			pr := &q.Node.Repository.PullRequests.Nodes[i]
+			if !d.incremental.updated(parseUpdatedAt(pr.UpdatedAt)) {
+				page.HasNextPage = false
+				break
+			}
+
+			prs = append(prs, pr)
+		}
+
+		err = d.processNodes(len(prs), func(i int) error {
+			err := process(prs[i])
+			if err != nil {
+				return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, prs[i].Number, err)
+			}
+
+			return nil
+		})
+
+		return page, err
+	})
+	if err != nil {
+		return err
+	}
+
+	if d.incremental == nil || d.incremental.since.IsZero() || !d.pullRequestStateIncluded(githubv4.PullRequestStateOpen) {
+		return nil
+	}
+
+	// a review does not always update its PR, the open PRs not updated since
+	// the previous download are downloaded too
+	delete(variables, "pullRequestsOrder")
+	delete(variables, "pullRequestStates")
+
+	return paginateAll(ctx, func(cursor string) (graphql.PageInfo, error) {
+		var q struct {
+			Node struct {
+				Repository struct {
+					PullRequests graphql.PullRequestConnection `graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor, states: OPEN)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["pullRequestsCursor"] = afterCursor(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, fmt.Errorf("failed to query open PRs for repository %v/%v: %v", owner, name, err)
+		}
+		progress.page(cursor)
+
+		var prs []*graphql.PullRequest
+		for i := range q.Node.Repository.PullRequests.Nodes {
+			pr := &q.Node.Repository.PullRequests.Nodes[i]
+			if !d.incremental.stale(parseUpdatedAt(pr.UpdatedAt)) {
+				// already downloaded
+				continue
+			}
+
+			prs = append(prs, pr)
+		}
+
+		err = d.processNodes(len(prs), func(i int) error {
+			err := process(prs[i])
+			if err != nil {
+				return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, prs[i].Number, err)
+			}
+
+			return nil
+		})
+
+		return q.Node.Repository.PullRequests.PageInfo, err
+	})
+}
+
+// processPullRequest saves the PR and downloads all its resources
+func (d Downloader) processPullRequest(ctx context.Context, owner string, name string, pr *graphql.PullRequest, progress *progress) error {
+	// an invalid update time is never equal to the saved one
+	complete, err := d.commentsComplete(owner, name, pr.Number, parseUpdatedAt(pr.UpdatedAt), pr.Comments.TotalCount)
+	if err != nil {
+		return err
+	}
+
+	assignees, err := d.downloadPullRequestAssignees(ctx, pr)
+	if err != nil {
+		return err
+	}
+
+	labels, err := d.downloadPullRequestLabels(ctx, pr)
+	if err != nil {
+		return err
+	}
+
+	if d.MergeableRetryDelay > 0 && pr.State == "OPEN" && pr.Mergeable == "UNKNOWN" {
+		err = d.retryPullRequestMergeable(ctx, pr)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = d.storer.SavePullRequest(owner, name, pr, assignees, labels)
+	if err != nil {
+		return err
+	}
+	progress.saved()
+	err = d.saveMentions(pr.Id, pr.Body)
+	if err != nil {
+		return err
+	}
+	err = d.saveReactions(pr.Id, pr.ReactionGroups)
+	if err != nil {
+		return err
+	}
+	err = d.saveLock(owner, name, pr.Number, pr.Locked, &pr.LockedBy)
+	if err != nil {
+		return err
+	}
+	err = d.saveProjectStatuses(owner, name, pr)
+	if err != nil {
+		return err
+	}
+	err = d.downloadAssignmentEvents(ctx, owner, name, pr.Id, pr.Number, &pr.AssignmentEvents)
+	if err != nil {
+		return err
+	}
+	err = d.downloadPullRequestEvents(ctx, owner, name, pr)
+	if err != nil {
+		return err
+	}
+	err = d.downloadClosingReferences(ctx, owner, name, pr.Id, pr.Number, true, &pr.ClosingIssues)
+	if err != nil {
+		return err
+	}
+	if !complete {
+		err = d.downloadPullRequestComments(ctx, owner, name, pr)
+		if err != nil {
+			return err
+		}
+	}
+	err = d.downloadPullRequestReviews(ctx, owner, name, pr)
+	if err != nil {
+		return err
+	}
+	if d.PullRequestCommitsIncluded || d.CommitAuthorsIncluded {
+		err = d.downloadPullRequestCommits(ctx, owner, name, pr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pullRequestStateIncluded returns true if the PRs in the given state are
+// downloaded, see PullRequestStates
+func (d Downloader) pullRequestStateIncluded(state githubv4.PullRequestState) bool {
+	if len(d.PullRequestStates) == 0 {
+		return true
+	}
+
+	for _, s := range d.PullRequestStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// downloadPullRequestSample processes a random sample of SampleSize PRs of the
+// repository. Each sampled PR is queried by its node id
+func (d Downloader) downloadPullRequestSample(ctx context.Context, owner string, name string, repository *graphql.Repository, process func(*graphql.PullRequest) error) error {
+	ids, err := d.sampleNodeIDs(ctx, repository.PullRequests.TotalCount, func(variables map[string]interface{}) (*graphql.NodeIDConnection, error) {
+		var q struct {
+			Node struct {
+				Repository struct {
+					PullRequests graphql.NodeIDConnection `graphql:"pullRequests(first: $nodeIDsPage, after: $nodeIDsCursor, states: $pullRequestStates)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id:$id)"`
+		}
+
+		variables["id"] = githubv4.ID(repository.Id)
+		variables["pullRequestStates"] = d.PullRequestStates
+		err := d.query(ctx, &q, variables)
+		return &q.Node.Repository.PullRequests, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query PR ids for repository %v/%v: %v", owner, name, err)
+	}
+
+	variables := map[string]interface{}{
+		"assigneesPage":                 d.pageSize(d.PageSizes.Assignees, assigneesPage),
+		"assignmentEventsPage":          d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+		"closingReferencesPage":         d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+		"issueCommentsPage":             d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+		"labelsPage":                    d.pageSize(d.PageSizes.Labels, labelsPage),
+		"pullRequestCommitsPage":        d.pageSize(d.PageSizes.PullRequestCommits, pullRequestCommitsPage),
+		"pullRequestReviewCommentsPage": d.pageSize(d.PageSizes.PullRequestReviewComments, pullRequestReviewCommentsPage),
+		"pullRequestReviewsPage":        d.pageSize(d.PageSizes.PullRequestReviews, pullRequestReviewsPage),
+		"pullRequestReviewsAuthor":      d.reviewsAuthor(),
+		"timelineItemsPage":             d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+		"assigneesCursor":                 (*githubv4.String)(nil),
+		"assignmentEventsCursor":          (*githubv4.String)(nil),
+		"closingReferencesCursor":         (*githubv4.String)(nil),
+		"issueCommentsCursor":             (*githubv4.String)(nil),
+		"labelsCursor":                    (*githubv4.String)(nil),
+		"pullRequestCommitsCursor":        (*githubv4.String)(nil),
+		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
+		"pullRequestReviewsCursor":        (*githubv4.String)(nil),
+		"timelineItemsCursor":             (*githubv4.String)(nil),
+
+		"bodiesOmitted":              githubv4.Boolean(d.BodiesOmitted),
+		"projectStatusesIncluded":    githubv4.Boolean(d.ProjectStatusesIncluded),
+		"pullRequestCommitsIncluded": githubv4.Boolean(d.PullRequestCommitsIncluded || d.CommitAuthorsIncluded),
+		"commitAuthorsIncluded":      githubv4.Boolean(d.CommitAuthorsIncluded),
+		"timelineEventsIncluded":     githubv4.Boolean(d.TimelineEventsIncluded),
+	}
+
+	for _, id := range ids {
+		var q struct {
+			Node struct {
+				PullRequest graphql.PullRequest `graphql:"... on PullRequest"`
+			} `graphql:"node(id:$id)"`
 		}
 
-		labels, err := d.downloadIssueLabels(ctx, issue)
+		variables["id"] = githubv4.ID(id)
+
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to query PR %v for repository %v/%v: %v", id, owner, name, err)
 		}
 
-		err = d.storer.SaveIssue(owner, name, issue, assignees, labels)
+		err = process(&q.Node.PullRequest)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, q.Node.PullRequest.Number, err)
 		}
-		return d.downloadIssueComments(ctx, owner, name, issue)
 	}
 
-	// Save issues included in the first page
-	for _, issue := range repository.Issues.Nodes {
-		err := process(&issue)
+	return nil
+}
+
+// downloadPullRequestCommits saves the commits of the PR, and the users that
+// authored them, see savePullRequestCommit. The first page is requested with
+// the PR
+func (d Downloader) downloadPullRequestCommits(ctx context.Context, owner string, name string, pr *graphql.PullRequest) error {
+	for i := range pr.PullRequestCommits.Nodes {
+		err := d.savePullRequestCommit(owner, name, pr, &pr.PullRequestCommits.Nodes[i])
 		if err != nil {
-			return fmt.Errorf("failed to process issue %v/%v #%v: %v", owner, name, issue.Number, err)
+			return err
 		}
 	}
 
 	variables := map[string]interface{}{
-		"id": githubv4.ID(repository.Id),
-
-		"assigneesPage":     githubv4.Int(assigneesPage),
-		"issueCommentsPage": githubv4.Int(issueCommentsPage),
-		"issuesPage":        githubv4.Int(issuesPage),
-		"labelsPage":        githubv4.Int(labelsPage),
+		"id": githubv4.ID(pr.Id),
 
-		"assigneesCursor":     (*githubv4.String)(nil),
-		"issueCommentsCursor": (*githubv4.String)(nil),
-		"issuesCursor":        (*githubv4.String)(nil),
-		"labelsCursor":        (*githubv4.String)(nil),
+		"pullRequestCommitsPage":     d.pageSize(d.PageSizes.PullRequestCommits, pullRequestCommitsPage),
+		"pullRequestCommitsCursor":   (*githubv4.String)(nil),
+		"pullRequestCommitsIncluded": githubv4.Boolean(true),
+		"commitAuthorsIncluded":      githubv4.Boolean(d.CommitAuthorsIncluded),
 	}
 
-	// if there are more issues, loop over all the pages
-	hasNextPage := repository.Issues.PageInfo.HasNextPage
-	endCursor := repository.Issues.PageInfo.EndCursor
-
-	for hasNextPage {
-		// get only issues
+	// if there are more commits, loop over all the pages
+	return paginate(ctx, pr.PullRequestCommits.PageInfo, func(cursor string) (graphql.PageInfo, error) {
 		var q struct {
 			Node struct {
-				Repository struct {
-					Issues graphql.IssueConnection `graphql:"issues(first: $issuesPage, after: $issuesCursor)"`
-				} `graphql:"... on Repository"`
+				PullRequest struct {
+					PullRequestCommits graphql.PullRequestCommitConnection `graphql:"pullRequestCommits: commits(first: $pullRequestCommitsPage, after: $pullRequestCommitsCursor) @include(if: $pullRequestCommitsIncluded)"`
+				} `graphql:"... on PullRequest"`
 			} `graphql:"node(id:$id)"`
 		}
 
-		variables["issuesCursor"] = githubv4.String(endCursor)
+		variables["pullRequestCommitsCursor"] = githubv4.String(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return fmt.Errorf("failed to query issues for repository %v: %v", repository.NameWithOwner, err)
+			return graphql.PageInfo{}, fmt.Errorf("failed to query PR commits for PR #%v: %v", pr.Number, err)
 		}
 
-		for _, issue := range q.Node.Repository.Issues.Nodes {
-			err := process(&issue)
+		for i := range q.Node.PullRequest.PullRequestCommits.Nodes {
+			err := d.savePullRequestCommit(owner, name, pr, &q.Node.PullRequest.PullRequestCommits.Nodes[i])
 			if err != nil {
-				return fmt.Errorf("failed to process issue %v #%v: %v", repository.NameWithOwner, issue.Number, err)
+				return graphql.PageInfo{}, err
 			}
 		}
 
-		hasNextPage = q.Node.Repository.Issues.PageInfo.HasNextPage
-		endCursor = q.Node.Repository.Issues.PageInfo.EndCursor
+		return q.Node.PullRequest.PullRequestCommits.PageInfo, nil
+	})
+}
+
+// savePullRequestCommit saves the commit with PullRequestCommitsIncluded, and
+// its GitHub author with CommitAuthorsIncluded. The authors already saved in
+// this download are skipped
+func (d Downloader) savePullRequestCommit(owner string, name string, pr *graphql.PullRequest, commit *graphql.PullRequestCommit) error {
+	if d.PullRequestCommitsIncluded {
+		err := d.storer.SavePullRequestCommit(owner, name, pr.Number, commit)
+		if err != nil {
+			return fmt.Errorf("failed to save PR commit %v for PR #%v: %v", commit.Commit.Oid, pr.Number, err)
+		}
+	}
+
+	user := commit.CommitAuthor.Author.User
+	if !d.CommitAuthorsIncluded || user == nil {
+		return nil
+	}
+
+	err := d.saveUser(user)
+	if err != nil {
+		return fmt.Errorf("failed to save commit author %v for PR #%v: %v", user.Login, pr.Number, err)
 	}
 
 	return nil
 }
 
-func (d Downloader) downloadIssueAssignees(ctx context.Context, issue *graphql.Issue) ([]string, error) {
+// retryPullRequestMergeable waits MergeableRetryDelay and queries again the
+// mergeable state and potential merge commit of the PR
+func (d Downloader) retryPullRequestMergeable(ctx context.Context, pr *graphql.PullRequest) error {
+	c := d.clock
+	if c == nil {
+		c = realClock{}
+	}
+
+	err := c.Sleep(ctx, d.MergeableRetryDelay)
+	if err != nil {
+		return err
+	}
+
+	var q struct {
+		Node struct {
+			PullRequest struct {
+				Mergeable            string
+				PotentialMergeCommit struct {
+					Oid string
+				}
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id:$id)"`
+	}
+
+	variables := map[string]interface{}{
+		"id": githubv4.ID(pr.Id),
+	}
+
+	err = d.query(ctx, &q, variables)
+	if err != nil {
+		return fmt.Errorf("failed to query mergeable state for PR #%v: %v", pr.Number, err)
+	}
+
+	pr.Mergeable = q.Node.PullRequest.Mergeable
+	pr.PotentialMergeCommit.Oid = q.Node.PullRequest.PotentialMergeCommit.Oid
+	return nil
+}
+
+func (d Downloader) downloadPullRequestAssignees(ctx context.Context, pr *graphql.PullRequest) ([]string, error) {
 	assignees := []string{}
 
 	// Assignees included in the first page
-	for _, node := range issue.Assignees.Nodes {
+	for _, node := range pr.Assignees.Nodes {
 		assignees = append(assignees, node.Login)
 	}
 
 	variables := map[string]interface{}{
-		"id": githubv4.ID(issue.Id),
+		"id": githubv4.ID(pr.Id),
 
-		"assigneesPage":   githubv4.Int(assigneesPage),
+		"assigneesPage":   d.pageSize(d.PageSizes.Assignees, assigneesPage),
 		"assigneesCursor": (*githubv4.String)(nil),
 	}
 
-	// if there are more assignees, loop over all the pages
-	hasNextPage := issue.Assignees.PageInfo.HasNextPage
-	endCursor := issue.Assignees.PageInfo.EndCursor
-
-	for hasNextPage {
-		// get only issue assignees
+	// if there are more assigness, loop over all the pages
+	err := paginate(ctx, pr.Assignees.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only PR assignees
 		var q struct {
 			Node struct {
-				Issue struct {
+				PullRequest struct {
 					Assignees graphql.UserConnection `graphql:"assignees(first: $assigneesPage, after: $assigneesCursor)"`
-				} `graphql:"... on Issue"`
+				} `graphql:"... on PullRequest"`
 			} `graphql:"node(id:$id)"`
 		}
 
-		variables["assigneesCursor"] = githubv4.String(endCursor)
+		variables["assigneesCursor"] = githubv4.String(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query issue assignees for issue #%v: %v", issue.Number, err)
+			return graphql.PageInfo{}, fmt.Errorf("failed to query PR assignees for PR #%v: %v", pr.Number, err)
 		}
 
-		for _, node := range q.Node.Issue.Assignees.Nodes {
+		for _, node := range q.Node.PullRequest.Assignees.Nodes {
 			assignees = append(assignees, node.Login)
 		}
 
-		hasNextPage = q.Node.Issue.Assignees.PageInfo.HasNextPage
-		endCursor = q.Node.Issue.Assignees.PageInfo.EndCursor
+		return q.Node.PullRequest.Assignees.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return assignees, nil
 }
 
-func (d Downloader) downloadIssueLabels(ctx context.Context, issue *graphql.Issue) ([]string, error) {
+func (d Downloader) downloadPullRequestLabels(ctx context.Context, pr *graphql.PullRequest) ([]string, error) {
 	labels := []string{}
 
 	// Labels included in the first page
-	for _, node := range issue.Labels.Nodes {
+	for _, node := range pr.Labels.Nodes {
 		labels = append(labels, node.Name)
 	}
 
 	variables := map[string]interface{}{
-		"id": githubv4.ID(issue.Id),
+		"id": githubv4.ID(pr.Id),
 
-		"labelsPage":   githubv4.Int(labelsPage),
+		"labelsPage":   d.pageSize(d.PageSizes.Labels, labelsPage),
 		"labelsCursor": (*githubv4.String)(nil),
 	}
 
 	// if there are more labels, loop over all the pages
-	hasNextPage := issue.Labels.PageInfo.HasNextPage
-	endCursor := issue.Labels.PageInfo.EndCursor
-
-	for hasNextPage {
-		// get only issue labels
+	err := paginate(ctx, pr.Labels.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only PR labels
 		var q struct {
 			Node struct {
-				Issue struct {
+				PullRequest struct {
 					Labels graphql.LabelConnection `graphql:"labels(first: $labelsPage, after: $labelsCursor)"`
-				} `graphql:"... on Issue"`
+				} `graphql:"... on PullRequest"`
 			} `graphql:"node(id:$id)"`
 		}
 
-		variables["labelsCursor"] = githubv4.String(endCursor)
+		variables["labelsCursor"] = githubv4.String(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query issue labels for issue #%v: %v", issue.Number, err)
+			return graphql.PageInfo{}, fmt.Errorf("failed to query PR labels for PR #%v: %v", pr.Number, err)
 		}
 
-		for _, node := range q.Node.Issue.Labels.Nodes {
+		for _, node := range q.Node.PullRequest.Labels.Nodes {
 			labels = append(labels, node.Name)
 		}
 
-		hasNextPage = q.Node.Issue.Labels.PageInfo.HasNextPage
-		endCursor = q.Node.Issue.Labels.PageInfo.EndCursor
+		return q.Node.PullRequest.Labels.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return labels, nil
 }
 
-func (d Downloader) downloadIssueComments(ctx context.Context, owner string, name string, issue *graphql.Issue) error {
+func (d Downloader) downloadPullRequestComments(ctx context.Context, owner string, name string, pr *graphql.PullRequest) error {
+	if watermark, ok := d.CommentWatermarks[pr.Id]; ok {
+		return d.downloadCommentsSince(ctx, pr.Id, watermark, &pr.Comments, func(comment *graphql.IssueComment) error {
+			return d.savePullRequestComment(owner, name, pr.Number, comment)
+		})
+	}
+
 	// save first page of comments
-	for _, comment := range issue.Comments.Nodes {
-		err := d.storer.SaveIssueComment(owner, name, issue.Number, &comment)
+	for i := range pr.Comments.Nodes {
+		err := d.savePullRequestComment(owner, name, pr.Number, &pr.Comments.Nodes[i])
 		if err != nil {
 			return err
 		}
 	}
 
 	variables := map[string]interface{}{
-		"id": githubv4.ID(issue.Id),
+		"id": githubv4.ID(pr.Id),
 
-		"issueCommentsPage":   githubv4.Int(issueCommentsPage),
+		"issueCommentsPage":   d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
 		"issueCommentsCursor": (*githubv4.String)(nil),
+
+		"bodiesOmitted": githubv4.Boolean(d.BodiesOmitted),
 	}
 
 	// if there are more issue comments, loop over all the pages
-	hasNextPage := issue.Comments.PageInfo.HasNextPage
-	endCursor := issue.Comments.PageInfo.EndCursor
-
-	for hasNextPage {
-		// get only issue comments
+	return paginate(ctx, pr.Comments.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only PR comments
 		var q struct {
 			Node struct {
-				Issue struct {
+				PullRequest struct {
 					Comments graphql.IssueCommentsConnection `graphql:"comments(first: $issueCommentsPage, after: $issueCommentsCursor)"`
-				} `graphql:"... on Issue"`
+				} `graphql:"... on PullRequest"`
 			} `graphql:"node(id:$id)"`
 		}
 
-		variables["issueCommentsCursor"] = githubv4.String(endCursor)
+		variables["issueCommentsCursor"] = githubv4.String(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return fmt.Errorf("failed to query issue comments for issue #%v: %v", issue.Number, err)
+			return graphql.PageInfo{}, fmt.Errorf("failed to query PR comments for PR #%v: %v", pr.Number, err)
 		}
 
-		for _, comment := range q.Node.Issue.Comments.Nodes {
-			err := d.storer.SaveIssueComment(owner, name, issue.Number, &comment)
+		for i := range q.Node.PullRequest.Comments.Nodes {
+			err := d.savePullRequestComment(owner, name, pr.Number, &q.Node.PullRequest.Comments.Nodes[i])
 			if err != nil {
-				return fmt.Errorf("failed to save issue comments for issue #%v: %v", issue.Number, err)
+				return graphql.PageInfo{}, err
 			}
 		}
 
-		hasNextPage = q.Node.Issue.Comments.PageInfo.HasNextPage
-		endCursor = q.Node.Issue.Comments.PageInfo.EndCursor
+		return q.Node.PullRequest.Comments.PageInfo, nil
+	})
+}
+
+// savePullRequestComment saves the comment of the PR, with its mentions and
+// reactions, unless it is skipped, see issueCommentSkipped
+func (d Downloader) savePullRequestComment(owner string, name string, number int, comment *graphql.IssueComment) error {
+	if d.issueCommentSkipped(comment) {
+		return nil
 	}
 
-	return nil
+	err := d.storer.SavePullRequestComment(owner, name, number, comment)
+	if err != nil {
+		return fmt.Errorf("failed to save PR comments for PR #%v: %v", number, err)
+	}
+
+	err = d.saveMentions(comment.Id, comment.Body)
+	if err != nil {
+		return err
+	}
+
+	return d.saveReactions(comment.Id, comment.ReactionGroups)
 }
 
-func (d Downloader) downloadPullRequests(ctx context.Context, owner string, name string, repository *graphql.Repository) error {
-	process := func(pr *graphql.PullRequest) error {
-		assignees, err := d.downloadPullRequestAssignees(ctx, pr)
-		if err != nil {
-			return err
-		}
+func (d Downloader) downloadPullRequestReviews(ctx context.Context, owner string, name string, pr *graphql.PullRequest) error {
+	// The reviews are returned in the order they were created, so the
+	// transitions of each reviewer can be tracked while they are processed
+	transitions := make(map[string]*store.ReviewStateTransition)
 
-		labels, err := d.downloadPullRequestLabels(ctx, pr)
+	process := func(review *graphql.PullRequestReview) error {
+		err := d.storer.SavePullRequestReview(owner, name, pr.Number, review)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to save PR review for PR #%v: %v", pr.Number, err)
 		}
-
-		err = d.storer.SavePullRequest(owner, name, pr, assignees, labels)
+		err = d.saveMentions(review.Id, review.Body)
 		if err != nil {
 			return err
 		}
-		err = d.downloadPullRequestComments(ctx, owner, name, pr)
+		err = d.saveReactions(review.Id, review.ReactionGroups)
 		if err != nil {
 			return err
 		}
-		err = d.downloadPullRequestReviews(ctx, owner, name, pr)
+
+		transition := &store.ReviewStateTransition{
+			UserLogin:   review.Author.LoginOrGhost(),
+			ToState:     review.State,
+			ReviewId:    review.DatabaseId,
+			SubmittedAt: review.SubmittedAt,
+		}
+		if prev, ok := transitions[transition.UserLogin]; ok {
+			transition.Sequence = prev.Sequence + 1
+			transition.FromState = prev.ToState
+		}
+		transitions[transition.UserLogin] = transition
+
+		err = d.storer.SaveReviewStateTransition(owner, name, pr.Number, transition)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to save PR review state transition for PR #%v: %v", pr.Number, err)
 		}
 
-		return nil
+		return d.downloadReviewComments(ctx, owner, name, pr.Number, review)
 	}
 
-	// Save PRs included in the first page
-	for _, pr := range repository.PullRequests.Nodes {
-		err := process(&pr)
+	// save first page of reviews
+	for i := range pr.Reviews.Nodes {
+		review := &pr.Reviews.Nodes[i]
+		err := process(review)
 		if err != nil {
-			return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, pr.Number, err)
+			return err
 		}
 	}
 
 	variables := map[string]interface{}{
-		"id": githubv4.ID(repository.Id),
+		"id": githubv4.ID(pr.Id),
 
-		"assigneesPage":                 githubv4.Int(assigneesPage),
-		"issueCommentsPage":             githubv4.Int(issueCommentsPage),
-		"labelsPage":                    githubv4.Int(labelsPage),
-		"pullRequestReviewCommentsPage": githubv4.Int(pullRequestReviewCommentsPage),
-		"pullRequestReviewsPage":        githubv4.Int(pullRequestReviewsPage),
-		"pullRequestsPage":              githubv4.Int(pullRequestsPage),
+		"pullRequestReviewCommentsPage": d.pageSize(d.PageSizes.PullRequestReviewComments, pullRequestReviewCommentsPage),
+		"pullRequestReviewsPage":        d.pageSize(d.PageSizes.PullRequestReviews, pullRequestReviewsPage),
+		"pullRequestReviewsAuthor":      d.reviewsAuthor(),
 
-		"assigneesCursor":                 (*githubv4.String)(nil),
-		"issueCommentsCursor":             (*githubv4.String)(nil),
-		"labelsCursor":                    (*githubv4.String)(nil),
 		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
 		"pullRequestReviewsCursor":        (*githubv4.String)(nil),
-		"pullRequestsCursor":              (*githubv4.String)(nil),
-	}
 
-	// if there are more PRs, loop over all the pages
-	hasNextPage := repository.PullRequests.PageInfo.HasNextPage
-	endCursor := repository.PullRequests.PageInfo.EndCursor
+		"bodiesOmitted": githubv4.Boolean(d.BodiesOmitted),
+	}
 
-	for hasNextPage {
-		// get only PRs
+	// if there are more reviews, loop over all the pages
+	return paginate(ctx, pr.Reviews.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		// get only PR reviews
 		var q struct {
 			Node struct {
-				Repository struct {
-					PullRequests graphql.PullRequestConnection `graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor)"`
-				} `graphql:"... on Repository"`
+				PullRequest struct {
+					Reviews graphql.PullRequestReviewConnection `graphql:"reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor, author: $pullRequestReviewsAuthor)"`
+				} `graphql:"... on PullRequest"`
 			} `graphql:"node(id:$id)"`
 		}
 
-		variables["pullRequestsCursor"] = githubv4.String(endCursor)
+		variables["pullRequestReviewsCursor"] = githubv4.String(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return fmt.Errorf("failed to query PRs for repository %v/%v: %v", owner, name, err)
+			return graphql.PageInfo{}, fmt.Errorf("failed to query PR reviews for PR #%v: %v", pr.Number, err)
 		}
 
-		for _, pr := range q.Node.Repository.PullRequests.Nodes {
-			err := process(&pr)
+		for i := range q.Node.PullRequest.Reviews.Nodes {
+			review := &q.Node.PullRequest.Reviews.Nodes[i]
+			err := process(review)
 			if err != nil {
-				return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, pr.Number, err)
+				return graphql.PageInfo{}, err
 			}
 		}
 
-		hasNextPage = q.Node.Repository.PullRequests.PageInfo.HasNextPage
-		endCursor = q.Node.Repository.PullRequests.PageInfo.EndCursor
-	}
-
-	return nil
+		return q.Node.PullRequest.Reviews.PageInfo, nil
+	})
 }
 
-func (d Downloader) downloadPullRequestAssignees(ctx context.Context, pr *graphql.PullRequest) ([]string, error) {
-	assignees := []string{}
-
-	// Assignees included in the first page
-	for _, node := range pr.Assignees.Nodes {
-		assignees = append(assignees, node.Login)
-	}
-
-	variables := map[string]interface{}{
-		"id": githubv4.ID(pr.Id),
-
-		"assigneesPage":   githubv4.Int(assigneesPage),
-		"assigneesCursor": (*githubv4.String)(nil),
-	}
-
-	// if there are more assigness, loop over all the pages
-	hasNextPage := pr.Assignees.PageInfo.HasNextPage
-	endCursor := pr.Assignees.PageInfo.EndCursor
-
-	for hasNextPage {
-		// get only PR assignees
-		var q struct {
-			Node struct {
-				PullRequest struct {
-					Assignees graphql.UserConnection `graphql:"assignees(first: $assigneesPage, after: $assigneesCursor)"`
-				} `graphql:"... on PullRequest"`
-			} `graphql:"node(id:$id)"`
+func (d Downloader) downloadReviewComments(ctx context.Context, repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	process := func(comment *graphql.PullRequestReviewComment) error {
+		if d.commentUnchanged(comment.UpdatedAt) {
+			return nil
 		}
 
-		variables["assigneesCursor"] = githubv4.String(endCursor)
-
-		err := d.client.Query(ctx, &q, variables)
+		err := d.storer.SavePullRequestReviewComment(repositoryOwner, repositoryName, pullRequestNumber, review.DatabaseId, comment)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query PR assignees for PR #%v: %v", pr.Number, err)
-		}
-
-		for _, node := range q.Node.PullRequest.Assignees.Nodes {
-			assignees = append(assignees, node.Login)
+			return fmt.Errorf(
+				"failed to save PullRequestReviewComment for PR #%v, review ID %v: %v",
+				pullRequestNumber, review.Id, err)
 		}
 
-		hasNextPage = q.Node.PullRequest.Assignees.PageInfo.HasNextPage
-		endCursor = q.Node.PullRequest.Assignees.PageInfo.EndCursor
+		return d.saveMentions(comment.Id, comment.Body)
 	}
 
-	return assignees, nil
-}
-
-func (d Downloader) downloadPullRequestLabels(ctx context.Context, pr *graphql.PullRequest) ([]string, error) {
-	labels := []string{}
-
-	// Labels included in the first page
-	for _, node := range pr.Labels.Nodes {
-		labels = append(labels, node.Name)
+	// save first page of comments
+	for i := range review.Comments.Nodes {
+		comment := &review.Comments.Nodes[i]
+		err := process(comment)
+		if err != nil {
+			return err
+		}
 	}
 
 	variables := map[string]interface{}{
-		"id": githubv4.ID(pr.Id),
+		"id": githubv4.ID(review.Id),
 
-		"labelsPage":   githubv4.Int(assigneesPage),
-		"labelsCursor": (*githubv4.String)(nil),
-	}
+		"pullRequestReviewCommentsPage":   d.pageSize(d.PageSizes.PullRequestReviewComments, pullRequestReviewCommentsPage),
+		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
 
-	// if there are more labels, loop over all the pages
-	hasNextPage := pr.Labels.PageInfo.HasNextPage
-	endCursor := pr.Labels.PageInfo.EndCursor
+		"bodiesOmitted": githubv4.Boolean(d.BodiesOmitted),
+	}
 
-	for hasNextPage {
-		// get only PR labels
+	// if there are more review comments, loop over all the pages
+	return paginate(ctx, review.Comments.PageInfo, func(cursor string) (graphql.PageInfo, error) {
 		var q struct {
 			Node struct {
-				PullRequest struct {
-					Labels graphql.LabelConnection `graphql:"labels(first: $labelsPage, after: $labelsCursor)"`
-				} `graphql:"... on PullRequest"`
+				PullRequestReview struct {
+					Comments graphql.PullRequestReviewCommentConnection `graphql:"comments(first: $pullRequestReviewCommentsPage, after: $pullRequestReviewCommentsCursor)"`
+				} `graphql:"... on PullRequestReview"`
 			} `graphql:"node(id:$id)"`
 		}
 
-		variables["labelsCursor"] = githubv4.String(endCursor)
+		variables["pullRequestReviewCommentsCursor"] = githubv4.String(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query PR labels for PR #%v: %v", pr.Number, err)
+			return graphql.PageInfo{}, fmt.Errorf(
+				"failed to query PR review comments for PR #%v, review ID %v: %v",
+				pullRequestNumber, review.Id, err)
 		}
 
-		for _, node := range q.Node.PullRequest.Labels.Nodes {
-			labels = append(labels, node.Name)
+		for i := range q.Node.PullRequestReview.Comments.Nodes {
+			comment := &q.Node.PullRequestReview.Comments.Nodes[i]
+			err := process(comment)
+			if err != nil {
+				return graphql.PageInfo{}, err
+			}
 		}
 
-		hasNextPage = q.Node.PullRequest.Labels.PageInfo.HasNextPage
-		endCursor = q.Node.PullRequest.Labels.PageInfo.EndCursor
-	}
+		return q.Node.PullRequestReview.Comments.PageInfo, nil
+	})
+}
 
-	return labels, nil
+// DownloadOrganization downloads the metadata for the given organization and
+// its member users
+func (d Downloader) DownloadOrganization(ctx context.Context, name string, version int) error {
+	return d.DownloadOrganizationWithRepositories(ctx, name, nil, version)
 }
 
-func (d Downloader) downloadPullRequestComments(ctx context.Context, owner string, name string, pr *graphql.PullRequest) error {
-	// save first page of comments
-	for _, comment := range pr.Comments.Nodes {
-		err := d.storer.SavePullRequestComment(owner, name, pr.Number, &comment)
-		if err != nil {
-			return fmt.Errorf("failed to save PR comments for PR #%v: %v", pr.Number, err)
-		}
-	}
+// DownloadOrganizationWithRepositories downloads the organization, its
+// members, and the given subset of the organization repositories. Everything
+// is saved in a single transaction, with the same version, so the snapshot
+// either contains all of them or none. The errors are classified like the
+// ones of DownloadRepository
+func (d Downloader) DownloadOrganizationWithRepositories(ctx context.Context, name string, repositories []string, version int) error {
+	err := d.downloadOrganizationWithRepositories(ctx, name, repositories, version)
+	return d.classifyError(ctx, err)
+}
 
-	variables := map[string]interface{}{
-		"id": githubv4.ID(pr.Id),
+func (d Downloader) downloadOrganizationWithRepositories(ctx context.Context, name string, repositories []string, version int) (err error) {
+	skipped, err := d.newSkipped()
+	if err != nil {
+		return err
+	}
 
-		"issueCommentsPage":   githubv4.Int(issueCommentsPage),
-		"issueCommentsCursor": (*githubv4.String)(nil),
+	version, err = d.downloadVersion(version)
+	if err != nil {
+		return err
 	}
 
-	// if there are more issue comments, loop over all the pages
-	hasNextPage := pr.Comments.PageInfo.HasNextPage
-	endCursor := pr.Comments.PageInfo.EndCursor
+	d.skipped = skipped
+	d.storer = d.transformedStorer()
+	d.storer = d.authorsStorer()
+	d.storer.Version(version)
+	d.users = newNodeCache()
+	d.rateGuard = d.newRateGuard()
 
-	for hasNextPage {
-		// get only PR comments
-		var q struct {
-			Node struct {
-				PullRequest struct {
-					Comments graphql.IssueCommentsConnection `graphql:"comments(first: $issueCommentsPage, after: $issueCommentsCursor)"`
-				} `graphql:"... on PullRequest"`
-			} `graphql:"node(id:$id)"`
+	err = d.storer.Begin()
+	if err != nil {
+		return fmt.Errorf("could not call Begin(): %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			d.storer.Rollback()
+			return
 		}
 
-		variables["issueCommentsCursor"] = githubv4.String(endCursor)
-
-		err := d.client.Query(ctx, &q, variables)
+		err = d.storer.Commit()
 		if err != nil {
-			return fmt.Errorf("failed to query PR comments for PR #%v: %v", pr.Number, err)
+			err = fmt.Errorf("could not call Commit(): %v", err)
+			return
 		}
 
-		for _, comment := range q.Node.PullRequest.Comments.Nodes {
-			err := d.storer.SavePullRequestComment(owner, name, pr.Number, &comment)
-			if err != nil {
-				return fmt.Errorf("failed to save PR comments for PR #%v: %v", pr.Number, err)
-			}
-		}
+		err = d.skipped.err()
+	}()
 
-		hasNextPage = q.Node.PullRequest.Comments.PageInfo.HasNextPage
-		endCursor = q.Node.PullRequest.Comments.PageInfo.EndCursor
+	err = d.downloadOrganization(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		err = d.downloadRepository(ctx, name, repository)
+		if err != nil {
+			return fmt.Errorf("failed to download repository %v/%v: %v", name, repository, err)
+		}
 	}
 
 	return nil
 }
 
-func (d Downloader) downloadPullRequestReviews(ctx context.Context, owner string, name string, pr *graphql.PullRequest) error {
-	process := func(review *graphql.PullRequestReview) error {
-		err := d.storer.SavePullRequestReview(owner, name, pr.Number, review)
-		if err != nil {
-			return fmt.Errorf("failed to save PR review for PR #%v: %v", pr.Number, err)
-		}
-		return d.downloadReviewComments(ctx, owner, name, pr.Number, review)
+// DownloadOrganizationRepositories lists the repositories of the
+// organization and downloads each one like DownloadRepositories, all of them
+// with the same version, without knowing their names up front. The archived
+// repositories and the forks are skipped, unless includeArchived or
+// includeForks are set. The organization and its members are not saved, see
+// DownloadOrganization.
+//
+// It returns the names of the downloaded repositories, in the order of the
+// listing, also when some of them failed; the error is then a *MultiError
+// with the failed ones
+func (d Downloader) DownloadOrganizationRepositories(ctx context.Context, name string, version int, includeArchived, includeForks bool) ([]string, error) {
+	d.rateGuard = d.newRateGuard()
+	repos, err := d.listRepositories(ctx, name, includeArchived, includeForks)
+	if err != nil {
+		return nil, err
 	}
 
-	// save first page of reviews
-	for _, review := range pr.Reviews.Nodes {
-		err := process(&review)
-		if err != nil {
-			return err
-		}
+	done, err := d.downloadRepositories(ctx, version, repos)
+
+	var names []string
+	for _, repo := range done {
+		names = append(names, repo.Name)
 	}
 
-	variables := map[string]interface{}{
-		"id": githubv4.ID(pr.Id),
+	return names, err
+}
 
-		"pullRequestReviewCommentsPage": githubv4.Int(pullRequestReviewCommentsPage),
-		"pullRequestReviewsPage":        githubv4.Int(pullRequestReviewsPage),
+// listRepositories returns the repositories of the organization, without the
+// archived ones and the forks unless they are included
+func (d Downloader) listRepositories(ctx context.Context, name string, includeArchived, includeForks bool) ([]RepoRef, error) {
+	variables := map[string]interface{}{
+		"organizationLogin": githubv4.String(name),
 
-		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
-		"pullRequestReviewsCursor":        (*githubv4.String)(nil),
+		"repositoriesPage": d.pageSize(d.PageSizes.Repositories, repositoriesPage),
+		"isFork":           (*githubv4.Boolean)(nil),
 	}
 
-	// if there are more reviews, loop over all the pages
-	hasNextPage := pr.Reviews.PageInfo.HasNextPage
-	endCursor := pr.Reviews.PageInfo.EndCursor
+	if !includeForks {
+		variables["isFork"] = githubv4.Boolean(false)
+	}
 
-	for hasNextPage {
-		// get only PR reviews
+	var repos []RepoRef
+	err := paginateAll(ctx, func(cursor string) (graphql.PageInfo, error) {
 		var q struct {
-			Node struct {
-				PullRequest struct {
-					Reviews graphql.PullRequestReviewConnection `graphql:"reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor)"`
-				} `graphql:"... on PullRequest"`
-			} `graphql:"node(id:$id)"`
+			Organization struct {
+				Repositories struct {
+					PageInfo graphql.PageInfo
+					Nodes    []struct {
+						Name       string
+						IsArchived bool
+					}
+				} `graphql:"repositories(first: $repositoriesPage, after: $repositoriesCursor, isFork: $isFork)"`
+			} `graphql:"organization(login: $organizationLogin)"`
 		}
 
-		variables["pullRequestReviewsCursor"] = githubv4.String(endCursor)
+		variables["repositoriesCursor"] = afterCursor(cursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return fmt.Errorf("failed to query PR reviews for PR #%v: %v", pr.Number, err)
+			return graphql.PageInfo{}, fmt.Errorf("failed to query repositories for organization %v: %v", name, err)
 		}
 
-		for _, review := range q.Node.PullRequest.Reviews.Nodes {
-			err := process(&review)
-			if err != nil {
-				return err
+		for _, node := range q.Organization.Repositories.Nodes {
+			if node.IsArchived && !includeArchived {
+				continue
 			}
+
+			repos = append(repos, RepoRef{Owner: name, Name: node.Name})
 		}
 
-		hasNextPage = q.Node.PullRequest.Reviews.PageInfo.HasNextPage
-		endCursor = q.Node.PullRequest.Reviews.PageInfo.EndCursor
+		return q.Organization.Repositories.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return repos, nil
 }
 
-func (d Downloader) downloadReviewComments(ctx context.Context, repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
-	process := func(comment *graphql.PullRequestReviewComment) error {
-		err := d.storer.SavePullRequestReviewComment(repositoryOwner, repositoryName, pullRequestNumber, review.DatabaseId, comment)
-		if err != nil {
-			return fmt.Errorf(
-				"failed to save PullRequestReviewComment for PR #%v, review ID %v: %v",
-				pullRequestNumber, review.Id, err)
-		}
+// OrganizationCheckpoint is the progress of an organization download made in
+// chunks by DownloadOrganizationChunk, possibly across several runs. It can be
+// saved between runs, e.g. as JSON
+type OrganizationCheckpoint struct {
+	Organization string `json:"organization"`
+	// Version is the version of every chunk. A zero version is resolved by
+	// the first chunk, see Downloader.AutoVersion
+	Version int `json:"version"`
+	// MembersCursor is the cursor of the last saved page of members, empty
+	// before the first chunk
+	MembersCursor string `json:"membersCursor"`
+	// Done is true once all the members are saved
+	Done bool `json:"done"`
+}
 
+// DownloadOrganizationChunk continues the download of the organization from
+// the checkpoint, saving at most pages pages of members, or all of them if
+// pages is 0. The first chunk saves the organization too, and its audit log
+// when AuditLogIncluded is set. Each chunk is saved in its own transaction,
+// with the checkpoint version, and the checkpoint is only updated once it is
+// committed, so a failed chunk can be retried from the same checkpoint.
+// Call Finalize when the checkpoint is done
+func (d Downloader) DownloadOrganizationChunk(ctx context.Context, checkpoint *OrganizationCheckpoint, pages int) error {
+	err := d.downloadOrganizationChunk(ctx, checkpoint, pages)
+	return d.classifyError(ctx, err)
+}
+
+func (d Downloader) downloadOrganizationChunk(ctx context.Context, checkpoint *OrganizationCheckpoint, pages int) error {
+	if checkpoint.Done {
 		return nil
 	}
 
-	// save first page of comments
-	for _, comment := range review.Comments.Nodes {
-		err := process(&comment)
+	skipped, err := d.newSkipped()
+	if err != nil {
+		return err
+	}
+
+	first := checkpoint.MembersCursor == ""
+	version := checkpoint.Version
+	if first {
+		version, err = d.downloadVersion(version)
 		if err != nil {
 			return err
 		}
 	}
 
-	variables := map[string]interface{}{
-		"id": githubv4.ID(review.Id),
+	d.skipped = skipped
+	d.storer = d.transformedStorer()
+	d.storer = d.authorsStorer()
+	d.storer.Version(version)
+	d.users = newNodeCache()
+	d.rateGuard = d.newRateGuard()
 
-		"pullRequestReviewCommentsPage":   githubv4.Int(pullRequestReviewCommentsPage),
-		"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
+	err = d.storer.Begin()
+	if err != nil {
+		return fmt.Errorf("could not call Begin(): %v", err)
 	}
 
-	// if there are more review comments, loop over all the pages
-	hasNextPage := review.Comments.PageInfo.HasNextPage
-	endCursor := review.Comments.PageInfo.EndCursor
+	var (
+		cursor      string
+		hasNextPage bool
+	)
+	if first {
+		cursor, hasNextPage, err = d.downloadOrganizationFirstChunk(ctx, checkpoint.Organization, pages)
+	} else {
+		cursor, hasNextPage, err = d.downloadMembers(ctx, checkpoint.Organization, checkpoint.MembersCursor, pages)
+	}
 
-	for hasNextPage {
-		var q struct {
-			Node struct {
-				PullRequestReview struct {
-					Comments graphql.PullRequestReviewCommentConnection `graphql:"comments(first: $pullRequestReviewCommentsPage, after: $pullRequestReviewCommentsCursor)"`
-				} `graphql:"... on PullRequestReview"`
-			} `graphql:"node(id:$id)"`
-		}
+	if err != nil {
+		d.storer.Rollback()
+		return err
+	}
+
+	err = d.storer.Commit()
+	if err != nil {
+		return fmt.Errorf("could not call Commit(): %v", err)
+	}
+
+	checkpoint.Version = version
+	checkpoint.MembersCursor = cursor
+	checkpoint.Done = !hasNextPage
+	return d.skipped.err()
+}
 
-		variables["pullRequestReviewCommentsCursor"] = githubv4.String(endCursor)
+// downloadOrganizationFirstChunk saves the organization and at most pages
+// pages of its members, see DownloadOrganizationChunk. It must be called
+// inside a transaction
+func (d Downloader) downloadOrganizationFirstChunk(ctx context.Context, name string, pages int) (string, bool, error) {
+	organization, err := d.saveOrganization(ctx, name)
+	if err != nil {
+		return "", false, err
+	}
 
-		err := d.client.Query(ctx, &q, variables)
+	if d.AuditLogIncluded {
+		err = d.downloadAuditLog(ctx, name)
 		if err != nil {
-			return fmt.Errorf(
-				"failed to query PR review comments for PR #%v, review ID %v: %v",
-				pullRequestNumber, review.Id, err)
+			return "", false, err
 		}
+	}
 
-		for _, comment := range q.Node.PullRequestReview.Comments.Nodes {
-			err := process(&comment)
-			if err != nil {
-				return err
-			}
+	if d.OrgProjectsIncluded {
+		err = d.downloadOrgProjects(ctx, name)
+		if err != nil {
+			return "", false, err
 		}
+	}
+
+	return d.downloadUsers(ctx, name, organization, pages)
+}
 
-		hasNextPage = q.Node.PullRequestReview.Comments.PageInfo.HasNextPage
-		endCursor = q.Node.PullRequestReview.Comments.PageInfo.EndCursor
+// Finalize makes the version of a done checkpoint the current one, see
+// SetCurrent. It fails if the checkpoint has members left to download
+func (d Downloader) Finalize(checkpoint *OrganizationCheckpoint) error {
+	if !checkpoint.Done {
+		return fmt.Errorf("organization %v download is not done, it continues after cursor %q",
+			checkpoint.Organization, checkpoint.MembersCursor)
 	}
 
-	return nil
+	return d.SetCurrent(checkpoint.Version)
 }
 
-// DownloadOrganization downloads the metadata for the given organization and
-// its member users
-func (d Downloader) DownloadOrganization(ctx context.Context, name string, version int) error {
-	d.storer.Version(version)
+// downloadOrganization downloads the organization and its members, it must be
+// called inside a transaction
+func (d Downloader) downloadOrganization(ctx context.Context, name string) error {
+	organization, err := d.saveOrganization(ctx, name)
+	if err != nil {
+		return err
+	}
 
-	var err error
-	err = d.storer.Begin()
+	_, _, err = d.downloadUsers(ctx, name, organization, 0)
 	if err != nil {
-		return fmt.Errorf("could not call Begin(): %v", err)
+		return err
 	}
 
-	defer func() {
+	if d.AuditLogIncluded {
+		err = d.downloadAuditLog(ctx, name)
 		if err != nil {
-			d.storer.Rollback()
-			return
+			return err
 		}
+	}
 
-		d.storer.Commit()
-	}()
+	if d.OrgProjectsIncluded {
+		err = d.downloadOrgProjects(ctx, name)
+		if err != nil {
+			return err
+		}
+	}
 
+	return nil
+}
+
+// saveOrganization queries and saves the organization, and returns it with
+// the first page of its members
+func (d Downloader) saveOrganization(ctx context.Context, name string) (*graphql.Organization, error) {
 	var q struct {
 		graphql.Organization `graphql:"organization(login: $organizationLogin)"`
 	}
 
-	// Some variables are repeated in the query, like assigneesCursor for Issues
-	// and PullRequests. It's ok to reuse because in this top level Repository
-	// query the cursors are set to nil, and when the pagination occurs, the
-	// queries only request either Issues or PullRequests
 	variables := map[string]interface{}{
 		"organizationLogin": githubv4.String(name),
 
-		"membersWithRolePage":   githubv4.Int(membersWithRolePage),
+		"membersWithRolePage":   d.pageSize(d.PageSizes.MembersWithRole, membersWithRolePage),
 		"membersWithRoleCursor": (*githubv4.String)(nil),
 	}
 
-	err = d.client.Query(ctx, &q, variables)
+	err := d.queryWithData(ctx, &q, variables, func() bool { return q.Organization.Login == "" })
 	if err != nil {
-		return fmt.Errorf("organization query failed: %v", err)
+		return nil, fmt.Errorf("organization query failed: %v", err)
 	}
 
 	err = d.storer.SaveOrganization(&q.Organization)
 	if err != nil {
-		return fmt.Errorf("failed to save organization %v: %v", name, err)
+		return nil, fmt.Errorf("failed to save organization %v: %v", name, err)
 	}
 
-	// issues and comments
-	err = d.downloadUsers(ctx, name, &q.Organization)
-	if err != nil {
-		return err
+	return &q.Organization, nil
+}
+
+// downloadAuditLog saves the audit log entries of the organization. If the
+// token is not an owner's, or the audit log is not available in the
+// organization plan, it is skipped
+func (d Downloader) downloadAuditLog(ctx context.Context, name string) error {
+	variables := map[string]interface{}{
+		"organizationLogin": githubv4.String(name),
+
+		"auditLogPage":   d.pageSize(d.PageSizes.AuditLog, auditLogPage),
+		"auditLogCursor": (*githubv4.String)(nil),
 	}
 
-	return nil
+	return paginateAll(ctx, func(cursor string) (graphql.PageInfo, error) {
+		var q struct {
+			Organization struct {
+				AuditLog graphql.AuditLogEntryConnection `graphql:"auditLog(first: $auditLogPage, after: $auditLogCursor)"`
+			} `graphql:"organization(login: $organizationLogin)"`
+		}
+
+		variables["auditLogCursor"] = afterCursor(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			if isPermissionError(err) || strings.Contains(err.Error(), "doesn't exist on type") {
+				log.Warningf("skipping audit log of organization %v: %v", name, err)
+				return graphql.PageInfo{}, nil
+			}
+
+			return graphql.PageInfo{}, fmt.Errorf("failed to query audit log for organization %v: %v", name, err)
+		}
+
+		for i := range q.Organization.AuditLog.Nodes {
+			entry := &q.Organization.AuditLog.Nodes[i]
+			err := d.storer.SaveAuditLogEntry(name, entry)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to save audit log entry for organization %v: %v", name, err)
+			}
+		}
+
+		return q.Organization.AuditLog.PageInfo, nil
+	})
 }
 
-func (d Downloader) downloadUsers(ctx context.Context, name string, organization *graphql.Organization) error {
-	process := func(user *graphql.UserExtended) error {
-		err := d.storer.SaveUser(user)
+// downloadOrgProjects saves the projects of the organization, when
+// OrgProjectsIncluded is set. The items of each project are appended to it
+// before saving, so each project is saved once with all its items
+func (d Downloader) downloadOrgProjects(ctx context.Context, name string) error {
+	variables := map[string]interface{}{
+		"organizationLogin": githubv4.String(name),
+
+		"projectsV2Page":     d.pageSize(d.PageSizes.OrgProjects, orgProjectsPage),
+		"projectsV2Cursor":   (*githubv4.String)(nil),
+		"projectV2ItemsPage": d.pageSize(d.PageSizes.OrgProjectItems, orgProjectItemsPage),
+	}
+
+	return paginateAll(ctx, func(cursor string) (graphql.PageInfo, error) {
+		var q struct {
+			Organization struct {
+				ProjectsV2 graphql.OrgProjectConnection `graphql:"projectsV2(first: $projectsV2Page, after: $projectsV2Cursor)"`
+			} `graphql:"organization(login: $organizationLogin)"`
+		}
+
+		variables["projectsV2Cursor"] = afterCursor(cursor)
+
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return fmt.Errorf("failed to save UserExtended: %v", err)
+			if isPermissionError(err) || strings.Contains(err.Error(), "read:project") {
+				log.Warningf("skipping projects of organization %v: %v", name, err)
+				return graphql.PageInfo{}, nil
+			}
+
+			return graphql.PageInfo{}, fmt.Errorf("failed to query projects for organization %v: %v", name, err)
 		}
 
-		return nil
+		for i := range q.Organization.ProjectsV2.Nodes {
+			project := &q.Organization.ProjectsV2.Nodes[i]
+			err := d.downloadOrgProjectItems(ctx, project)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to query items of project %v for organization %v: %v", project.Number, name, err)
+			}
+
+			err = d.storer.SaveOrgProject(name, project)
+			if err != nil {
+				return graphql.PageInfo{}, fmt.Errorf("failed to save project %v for organization %v: %v", project.Number, name, err)
+			}
+		}
+
+		return q.Organization.ProjectsV2.PageInfo, nil
+	})
+}
+
+// downloadOrgProjectItems appends to the project the items after its first
+// page
+func (d Downloader) downloadOrgProjectItems(ctx context.Context, project *graphql.OrgProject) error {
+	variables := map[string]interface{}{
+		"id": githubv4.ID(project.Id),
+
+		"projectV2ItemsPage":   d.pageSize(d.PageSizes.OrgProjectItems, orgProjectItemsPage),
+		"projectV2ItemsCursor": (*githubv4.String)(nil),
 	}
 
+	return paginate(ctx, project.Items.PageInfo, func(cursor string) (graphql.PageInfo, error) {
+		var q struct {
+			Node struct {
+				ProjectV2 struct {
+					Items graphql.OrgProjectItemConnection `graphql:"items(first: $projectV2ItemsPage, after: $projectV2ItemsCursor)"`
+				} `graphql:"... on ProjectV2"`
+			} `graphql:"node(id: $id)"`
+		}
+
+		variables["projectV2ItemsCursor"] = githubv4.String(cursor)
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return graphql.PageInfo{}, err
+		}
+
+		items := q.Node.ProjectV2.Items
+		project.Items.Nodes = append(project.Items.Nodes, items.Nodes...)
+		project.Items.PageInfo = items.PageInfo
+
+		return items.PageInfo, nil
+	})
+}
+
+// downloadUsers saves the members in the first page of the organization, and
+// the following ones up to pages pages in total, or all of them if pages is 0.
+// It returns the cursor of the last saved page, and whether there are more
+func (d Downloader) downloadUsers(ctx context.Context, name string, organization *graphql.Organization, pages int) (string, bool, error) {
 	// Save users included in the first page
-	for _, user := range organization.MembersWithRole.Nodes {
-		err := process(&user)
+	for i := range organization.MembersWithRole.Nodes {
+		err := d.saveMember(&organization.MembersWithRole.Nodes[i])
 		if err != nil {
-			return fmt.Errorf("failed to process user %v: %v", user.Login, err)
+			return "", false, err
 		}
 	}
 
+	hasNextPage := organization.MembersWithRole.PageInfo.HasNextPage
+	endCursor := organization.MembersWithRole.PageInfo.EndCursor
+	if !hasNextPage || pages == 1 {
+		return endCursor, hasNextPage, nil
+	}
+
+	if pages > 0 {
+		pages--
+	}
+
+	return d.downloadMembers(ctx, name, endCursor, pages)
+}
+
+// downloadMembers saves the members after the given cursor, at most pages
+// pages of them, or all of them if pages is 0. It returns the cursor of the
+// last saved page, and whether there are more
+func (d Downloader) downloadMembers(ctx context.Context, name string, endCursor string, pages int) (string, bool, error) {
 	variables := map[string]interface{}{
 		"organizationLogin": githubv4.String(name),
 
-		"membersWithRolePage":   githubv4.Int(membersWithRolePage),
+		"membersWithRolePage":   d.pageSize(d.PageSizes.MembersWithRole, membersWithRolePage),
 		"membersWithRoleCursor": (*githubv4.String)(nil),
 	}
 
-	// if there are more users, loop over all the pages
-	hasNextPage := organization.MembersWithRole.PageInfo.HasNextPage
-	endCursor := organization.MembersWithRole.PageInfo.EndCursor
+	hasNextPage := true
+	for page := 0; hasNextPage && (pages == 0 || page < pages); page++ {
+		if err := ctx.Err(); err != nil {
+			return "", false, err
+		}
 
-	for hasNextPage {
 		// get only users
 		var q struct {
 			Organization struct {
@@ -897,15 +3498,15 @@ func (d Downloader) downloadUsers(ctx context.Context, name string, organization
 
 		variables["membersWithRoleCursor"] = githubv4.String(endCursor)
 
-		err := d.client.Query(ctx, &q, variables)
+		err := d.query(ctx, &q, variables)
 		if err != nil {
-			return fmt.Errorf("failed to organization members for organization %v: %v", name, err)
+			return "", false, fmt.Errorf("failed to organization members for organization %v: %v", name, err)
 		}
 
-		for _, user := range q.Organization.MembersWithRole.Nodes {
-			err := process(&user)
+		for i := range q.Organization.MembersWithRole.Nodes {
+			err := d.saveMember(&q.Organization.MembersWithRole.Nodes[i])
 			if err != nil {
-				return fmt.Errorf("failed to process user %v: %v", user.Login, err)
+				return "", false, err
 			}
 		}
 
@@ -913,12 +3514,96 @@ func (d Downloader) downloadUsers(ctx context.Context, name string, organization
 		endCursor = q.Organization.MembersWithRole.PageInfo.EndCursor
 	}
 
+	return endCursor, hasNextPage, nil
+}
+
+// saveMember saves a member of the organization
+func (d Downloader) saveMember(user *graphql.UserExtended) error {
+	err := d.saveUser(user)
+	if err != nil {
+		return fmt.Errorf("failed to process user %v: failed to save UserExtended: %v", user.Login, err)
+	}
+
+	return nil
+}
+
+// saveUser saves the user, unless it was already saved in the current
+// download
+func (d Downloader) saveUser(user *graphql.UserExtended) error {
+	if d.users != nil && !d.users.add(user.Id) {
+		return nil
+	}
+
+	return d.storer.SaveUser(user)
+}
+
+// DownloadUser downloads the profile of the user with the given login, e.g.
+// an author of issues or comments who is not a member of the downloaded
+// organizations. It is saved in its own transaction, with the given version.
+// The errors are classified like the ones of DownloadRepository
+func (d Downloader) DownloadUser(ctx context.Context, login string, version int) error {
+	err := d.downloadUser(ctx, login, version)
+	return d.classifyError(ctx, err)
+}
+
+func (d Downloader) downloadUser(ctx context.Context, login string, version int) (err error) {
+	version, err = d.downloadVersion(version)
+	if err != nil {
+		return err
+	}
+
+	d.storer = d.transformedStorer()
+	d.storer.Version(version)
+	d.rateGuard = d.newRateGuard()
+
+	err = d.storer.Begin()
+	if err != nil {
+		return fmt.Errorf("could not call Begin(): %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			d.storer.Rollback()
+			return
+		}
+
+		err = d.storer.Commit()
+		if err != nil {
+			err = fmt.Errorf("could not call Commit(): %v", err)
+		}
+	}()
+
+	var q struct {
+		User graphql.UserExtended `graphql:"user(login: $login)"`
+	}
+
+	variables := map[string]interface{}{
+		"login": githubv4.String(login),
+	}
+
+	err = d.queryWithData(ctx, &q, variables, func() bool { return q.User.Login == "" })
+	if err != nil {
+		err = fmt.Errorf("user query failed: %v", err)
+		return err
+	}
+
+	err = d.storer.SaveUser(&q.User)
+	if err != nil {
+		err = fmt.Errorf("failed to save user %v: %v", login, err)
+		return err
+	}
+
 	return nil
 }
 
 // SetCurrent enables the given version as the current one accessible in the DB
 func (d Downloader) SetCurrent(version int) error {
-	err := d.storer.SetActiveVersion(version)
+	version, err := d.latestVersion(version)
+	if err != nil {
+		return err
+	}
+
+	err = d.storer.SetActiveVersion(version)
 	if err != nil {
 		return fmt.Errorf("failed to set current DB version to %v: %v", version, err)
 	}
@@ -927,7 +3612,12 @@ func (d Downloader) SetCurrent(version int) error {
 
 // Cleanup deletes from the DB all records that do not belong to the currentVersion
 func (d Downloader) Cleanup(currentVersion int) error {
-	err := d.storer.Cleanup(currentVersion)
+	currentVersion, err := d.latestVersion(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	err = d.storer.Cleanup(currentVersion)
 	if err != nil {
 		return fmt.Errorf("failed to do cleanup for DB version %v: %v", currentVersion, err)
 	}
@@ -1,15 +1,29 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/src-d/metadata-retrieval/database"
+	"github.com/src-d/metadata-retrieval/github/graphql"
+	"github.com/src-d/metadata-retrieval/github/store"
 	"github.com/src-d/metadata-retrieval/testutils"
 
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/lib/pq"
+	"github.com/shurcooL/githubv4"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 )
@@ -98,6 +112,21 @@ func testOnlineRepo(t *testing.T, oracle RepositoryTest, d *Downloader, storer *
 	require.Equal(oracle.HasWiki, storer.Repository.HasWikiEnabled)
 	require.Len(storer.PRs, oracle.NumOfPRs)
 	require.Len(storer.PRComments, oracle.NumOfPRComments)
+	// Every saved entity must have its node id, it is the only id shared
+	// with the GraphQL API
+	require.NotEmpty(storer.Repository.Id, "repository node id")
+	for _, pr := range storer.PRs {
+		require.NotEmpty(pr.Id, "PR #%v node id", pr.Number)
+		for _, review := range pr.Reviews.Nodes {
+			require.NotEmpty(review.Id, "PR #%v review node id", pr.Number)
+			for _, comment := range review.Comments.Nodes {
+				require.NotEmpty(comment.Id, "PR #%v review comment node id", pr.Number)
+			}
+		}
+	}
+	for _, comment := range storer.PRComments {
+		require.NotEmpty(comment.Id, "PR comment node id")
+	}
 }
 
 // TestOnlineRepositoryDownload Tests the download of known and fixed GitHub repositories
@@ -130,6 +159,10 @@ func testOnlineOrg(t *testing.T, oracle OrganizationTest, d *Downloader, storer
 	require.Equal(oracle.PublicRepos, storer.Organization.PublicRepos.TotalCount)
 	require.Equal(oracle.TotalPrivateRepos, storer.Organization.TotalPrivateRepos.TotalCount)
 	require.Len(storer.Users, oracle.NumOfUsers)
+	require.NotEmpty(storer.Organization.Id, "organization node id")
+	for _, user := range storer.Users {
+		require.NotEmpty(user.Id, "user %v node id", user.Login)
+	}
 }
 
 // TestOnlineOrganizationDownload Tests the download of known and fixed GitHub organization
@@ -151,3 +184,2902 @@ func TestOnlineOrganizationDownload(t *testing.T) {
 	}
 
 }
+
+// TestDefaultBranchHead checks that the latest commit of the default branch
+// is downloaded with the repository
+func TestDefaultBranchHead(t *testing.T) {
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"defaultBranchRef": {
+				"name": "master",
+				"target": {
+					"oid": "6ecf0ef2c2dffb796033e5a02219af86ec6584e5",
+					"committedDate": "2015-04-05T21:30:47Z"
+				}
+			},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	})
+
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require := require.New(t)
+	require.NoError(err)
+
+	head := storer.Repository.DefaultBranchRef
+	require.Equal("master", head.Name)
+	require.Equal("6ecf0ef2c2dffb796033e5a02219af86ec6584e5", head.Target.Commit.Oid)
+	require.Equal("2015-04-05 21:30:47 +0000 UTC", head.Target.Commit.CommittedDate.String())
+}
+
+func TestSocialPreview(t *testing.T) {
+	repository := map[string]string{
+		"custom":  `"openGraphImageUrl": "https://repository-images.githubusercontent.com/1/preview", "usesCustomOpenGraphImage": true`,
+		"default": `"openGraphImageUrl": "https://opengraph.githubassets.com/abc/git-fixtures/default", "usesCustomOpenGraphImage": false`,
+	}
+
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		name := variables["name"].(string)
+		return `{"data": {"repository": {
+			"name": "` + name + `",
+			"nameWithOwner": "git-fixtures/` + name + `",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"description": "A fixture",
+			"homepageUrl": "https://example.com",
+			` + repository[name] + `,
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	})
+
+	require := require.New(t)
+
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "custom", 0))
+	require.Equal("A fixture", storer.Repository.Description)
+	require.Equal("https://example.com", storer.Repository.HomepageUrl)
+	require.Equal("https://repository-images.githubusercontent.com/1/preview", storer.Repository.OpenGraphImageUrl)
+	require.True(storer.Repository.UsesCustomOpenGraphImage)
+
+	// the default preview is generated by GitHub, its URL is saved too
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "default", 0))
+	require.Equal("https://opengraph.githubassets.com/abc/git-fixtures/default", storer.Repository.OpenGraphImageUrl)
+	require.False(storer.Repository.UsesCustomOpenGraphImage)
+}
+
+// TestReviewStateTransitions checks that the reviews of each reviewer are
+// saved as an ordered sequence of state transitions
+func TestReviewStateTransitions(t *testing.T) {
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 1,
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 10, "state": "COMMENTED", "submittedAt": "2019-10-01T10:00:00Z", "author": {"login": "alice", "__typename": "User"}},
+					{"databaseId": 11, "state": "CHANGES_REQUESTED", "submittedAt": "2019-10-01T11:00:00Z", "author": {"login": "alice", "__typename": "User"}},
+					{"databaseId": 12, "state": "COMMENTED", "submittedAt": "2019-10-01T12:00:00Z", "author": {"login": "bob", "__typename": "User"}},
+					{"databaseId": 13, "state": "APPROVED", "submittedAt": "2019-10-02T09:00:00Z", "author": {"login": "alice", "__typename": "User"}}
+				]}
+			}]}
+		}}}`
+	})
+
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require := require.New(t)
+	require.NoError(err)
+
+	var alice []string
+	for _, transition := range storer.ReviewTransitions[1] {
+		if transition.UserLogin != "alice" {
+			continue
+		}
+
+		require.Len(alice, transition.Sequence)
+		alice = append(alice, transition.FromState+"->"+transition.ToState)
+	}
+
+	require.Equal([]string{
+		"->COMMENTED",
+		"COMMENTED->CHANGES_REQUESTED",
+		"CHANGES_REQUESTED->APPROVED",
+	}, alice)
+	require.Len(storer.ReviewTransitions[1], 4)
+}
+
+// TestGhostAuthors checks that the comments and reviews of deleted accounts,
+// returned with a null author, are saved as authored by the ghost user
+func TestGhostAuthors(t *testing.T) {
+	var buf bytes.Buffer
+	d := &Downloader{
+		storer: &store.Stdout{Out: &buf},
+		client: newTestClient(t, func(query string, variables map[string]interface{}) string {
+			return `{"data": {"repository": {
+				"name": "basic",
+				"nameWithOwner": "git-fixtures/basic",
+				"owner": {"login": "git-fixtures", "__typename": "Organization"},
+				"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+				"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+					"number": 1,
+					"author": null,
+					"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"databaseId": 1, "author": null, "body": "issue comment"}
+					]}
+				}]},
+				"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+					"number": 2,
+					"author": null,
+					"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"databaseId": 2, "author": null, "body": "pr comment"}
+					]},
+					"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"databaseId": 10, "state": "APPROVED", "author": null, "body": "review", "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+							{"databaseId": 3, "author": null, "body": "review comment"}
+						]}}
+					]}
+				}]}
+			}}}`
+		}),
+	}
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	out := buf.String()
+	require.Contains(out, `issue comment data fetched by ghost at 0001-01-01 00:00:00 +0000 UTC: "issue comment"`)
+	require.Contains(out, `pr comment data fetched by ghost at 0001-01-01 00:00:00 +0000 UTC: "pr comment"`)
+	require.Contains(out, `PR Review data fetched by ghost at pending: "review"`)
+	require.Contains(out, `PR review comment data fetched by ghost at 0001-01-01 00:00:00 +0000 UTC: "review comment"`)
+	require.Contains(out, `PR Review state of ghost changed from "" to "APPROVED"`)
+}
+
+// TestBodiesOmitted checks that when the bodies are omitted they are not
+// requested, but the rest of the comments metadata is still downloaded
+func TestAssignmentEvents(t *testing.T) {
+	var pages []interface{}
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "repository(") {
+			// second page of events of issue #1
+			pages = append(pages, variables["assignmentEventsCursor"])
+			return `{"data": {"node": {"assignmentEvents": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"__typename": "AssignedEvent", "createdAt": "2019-10-01T12:00:00Z", "actor": {"login": "alice"}, "assignee": {"__typename": "Bot", "login": "dependabot"}}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "issue1",
+				"number": 1,
+				"assignmentEvents": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+					{"__typename": "AssignedEvent", "createdAt": "2019-10-01T10:00:00Z", "actor": {"login": "alice"}, "assignee": {"__typename": "User", "login": "bob"}},
+					{"__typename": "UnassignedEvent", "createdAt": "2019-10-01T11:00:00Z", "actor": {"login": "bob"}, "assignee": {"__typename": "User", "login": "bob"}}
+				]}
+			}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 2,
+				"assignmentEvents": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"__typename": "AssignedEvent", "createdAt": "2019-10-02T10:00:00Z", "actor": {"login": "bob"}, "assignee": {"__typename": "User", "login": "alice"}}
+				]}
+			}]}
+		}}}`
+	})
+
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require := require.New(t)
+	require.NoError(err)
+	require.Equal([]interface{}{"c1"}, pages)
+
+	events := func(number int) []string {
+		var res []string
+		for _, event := range storer.AssignmentEvents[number] {
+			fields := event.Fields()
+			res = append(res, event.Typename+" "+fields.AssigneeLogin()+" by "+fields.Actor.Login)
+		}
+		return res
+	}
+
+	require.Equal([]string{
+		"AssignedEvent bob by alice",
+		"UnassignedEvent bob by bob",
+		"AssignedEvent dependabot by alice",
+	}, events(1))
+	require.Equal([]string{"AssignedEvent alice by bob"}, events(2))
+}
+
+func TestTimelineEvents(t *testing.T) {
+	var pages []interface{}
+	var repositoryQuery string
+	var included interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "repository(") {
+			// second page of events of PR #2
+			pages = append(pages, variables["timelineItemsCursor"])
+			return `{"data": {"node": {"timelineEvents": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"__typename": "MergedEvent", "id": "ME_1", "createdAt": "2019-10-02T12:00:00Z", "actor": {"login": "alice"}}
+			]}}}}`
+		}
+
+		repositoryQuery = query
+		included = variables["timelineEventsIncluded"]
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "issue1",
+				"number": 1,
+				"timelineEvents": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"__typename": "LabeledEvent", "id": "LE_1", "createdAt": "2019-10-01T10:00:00Z", "actor": {"login": "alice"}},
+					{"__typename": "CrossReferencedEvent", "id": "CRE_1", "createdAt": "2019-10-01T11:00:00Z", "actor": {"login": "bob"}},
+					{"__typename": "ClosedEvent", "id": "CE_1", "createdAt": "2019-10-01T12:00:00Z", "actor": {"login": "bob"}}
+				]}
+			}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "pr2",
+				"number": 2,
+				"timelineEvents": {"pageInfo": {"hasNextPage": true, "endCursor": "t1"}, "nodes": [
+					{"__typename": "UnlabeledEvent", "id": "UE_1", "createdAt": "2019-10-02T10:00:00Z", "actor": {"login": "bob"}}
+				]}
+			}]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	// the events are not requested by default
+	d, _ := newTestDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal(false, included)
+
+	// the assigned and unassigned events are only requested by assignmentEvents
+	pages = nil
+	d, storer := newTestDownloader(t, handler)
+	d.TimelineEventsIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal(true, included)
+	require.Contains(repositoryQuery, "itemTypes: [LABELED_EVENT, UNLABELED_EVENT, CLOSED_EVENT, REOPENED_EVENT, CROSS_REFERENCED_EVENT]) @include(if: $timelineEventsIncluded)")
+	require.Equal(2, strings.Count(repositoryQuery, "UNASSIGNED_EVENT"), "once for the issues and once for the PRs")
+	require.Equal([]interface{}{"t1"}, pages)
+
+	var issueEvents []string
+	for _, event := range storer.IssueEvents[1] {
+		fields := event.Fields()
+		issueEvents = append(issueEvents, event.Typename+" "+fields.Id+" by "+fields.Actor.Login+" at "+fields.CreatedAt.Format(time.RFC3339))
+	}
+	require.Equal([]string{
+		"LabeledEvent LE_1 by alice at 2019-10-01T10:00:00Z",
+		"CrossReferencedEvent CRE_1 by bob at 2019-10-01T11:00:00Z",
+		"ClosedEvent CE_1 by bob at 2019-10-01T12:00:00Z",
+	}, issueEvents)
+
+	var prEvents []string
+	for _, event := range storer.PullRequestEvents[2] {
+		fields := event.Fields()
+		prEvents = append(prEvents, event.Typename+" "+fields.Id+" by "+fields.Actor.Login+" at "+fields.CreatedAt.Format(time.RFC3339))
+	}
+	require.Equal([]string{
+		"UnlabeledEvent UE_1 by bob at 2019-10-02T10:00:00Z",
+		"MergedEvent ME_1 by alice at 2019-10-02T12:00:00Z",
+	}, prEvents)
+}
+
+func TestReactions(t *testing.T) {
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "issue1",
+				"number": 1,
+				"reactionGroups": [
+					{"content": "THUMBS_UP", "reactors": {"totalCount": 3}},
+					{"content": "THUMBS_DOWN", "reactors": {"totalCount": 0}}
+				],
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+					"id": "ic1",
+					"reactionGroups": [{"content": "HEART", "reactors": {"totalCount": 1}}]
+				}]}
+			}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "pr2",
+				"number": 2,
+				"reactionGroups": [{"content": "ROCKET", "reactors": {"totalCount": 2}}],
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+					"id": "pc1",
+					"reactionGroups": [{"content": "LAUGH", "reactors": {"totalCount": 0}}]
+				}]},
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+					"id": "rv1",
+					"databaseId": 10,
+					"reactionGroups": [{"content": "THUMBS_DOWN", "reactors": {"totalCount": 4}}],
+					"comments": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+				}]}
+			}]}
+		}}}`
+	})
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	reactions := map[string][]string{}
+	for id, groups := range storer.ReactionGroups {
+		for _, g := range groups {
+			reactions[id] = append(reactions[id], fmt.Sprintf("%v %v", g.Content, g.Reactors.TotalCount))
+		}
+	}
+
+	// the empty groups are not saved
+	require.Equal(map[string][]string{
+		"issue1": {"THUMBS_UP 3"},
+		"ic1":    {"HEART 1"},
+		"pr2":    {"ROCKET 2"},
+		"rv1":    {"THUMBS_DOWN 4"},
+	}, reactions)
+	// but they are kept in the saved comment
+	require.Len(storer.PRComments[0].ReactionGroups, 1)
+}
+
+// txStorer records the transaction calls and the version of the saved
+// organizations and repositories
+type txStorer struct {
+	*testutils.Memory
+	v   int
+	log []string
+}
+
+func (s *txStorer) Version(v int) { s.v = v }
+func (s *txStorer) Begin() error  { s.log = append(s.log, "begin"); return nil }
+func (s *txStorer) Commit() error { s.log = append(s.log, "commit"); return nil }
+func (s *txStorer) Rollback() error {
+	s.log = append(s.log, "rollback")
+	return nil
+}
+
+func (s *txStorer) SaveOrganization(organization *graphql.Organization) error {
+	s.log = append(s.log, fmt.Sprintf("organization %v v%v", organization.Login, s.v))
+	return s.Memory.SaveOrganization(organization)
+}
+
+func (s *txStorer) SetActiveVersion(v int) error {
+	s.log = append(s.log, fmt.Sprintf("active v%v", v))
+	return nil
+}
+
+func (s *txStorer) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	s.log = append(s.log, fmt.Sprintf("repository %v v%v", repository.NameWithOwner, s.v))
+	return s.Memory.SaveRepository(repository, topics)
+}
+
+func TestOrganizationWithRepositories(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "organization(") {
+			return `{"data": {"organization": {
+				"login": "src-d",
+				"membersWithRole": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+			}}}`
+		}
+
+		name := variables["name"].(string)
+		if name == "missing" {
+			return `{"errors": [{"message": "Could not resolve to a Repository with the name 'missing'."}]}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "` + name + `",
+			"nameWithOwner": "src-d/` + name + `",
+			"owner": {"login": "src-d", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	storer := &txStorer{Memory: new(testutils.Memory)}
+	d := &Downloader{storer: storer, client: newTestClient(t, handler)}
+
+	err := d.DownloadOrganizationWithRepositories(context.TODO(), "src-d", []string{"gitbase", "go-git"}, 3)
+	require.NoError(err)
+	require.Equal([]string{
+		"begin",
+		"organization src-d v3",
+		"repository src-d/gitbase v3",
+		"repository src-d/go-git v3",
+		"commit",
+	}, storer.log)
+
+	// a failed repository rolls back the organization too
+	storer = &txStorer{Memory: new(testutils.Memory)}
+	d = &Downloader{storer: storer, client: newTestClient(t, handler)}
+
+	err = d.DownloadOrganizationWithRepositories(context.TODO(), "src-d", []string{"gitbase", "missing"}, 4)
+	require.Error(err)
+	require.Equal([]string{
+		"begin",
+		"organization src-d v4",
+		"repository src-d/gitbase v4",
+		"rollback",
+	}, storer.log)
+}
+
+// commitErrStorer fails to commit, e.g. a file store that can not write the
+// transaction
+type commitErrStorer struct {
+	*txStorer
+}
+
+func (s *commitErrStorer) Commit() error {
+	s.log = append(s.log, "commit")
+	return errors.New("disk full")
+}
+
+func TestCommitError(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		switch {
+		case strings.Contains(query, "user("):
+			return `{"data": {"user": {"id": "u1", "login": "alice"}}}`
+		case strings.Contains(query, "organization("):
+			return `{"data": {"organization": {
+				"login": "src-d",
+				"membersWithRole": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+			}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "gitbase",
+			"nameWithOwner": "src-d/gitbase",
+			"owner": {"login": "src-d", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	downloads := map[string]func(d *Downloader) error{
+		"repository": func(d *Downloader) error {
+			return d.DownloadRepository(context.TODO(), "src-d", "gitbase", 1)
+		},
+		"organization": func(d *Downloader) error {
+			return d.DownloadOrganizationWithRepositories(context.TODO(), "src-d", []string{"gitbase"}, 1)
+		},
+		"user": func(d *Downloader) error {
+			return d.DownloadUser(context.TODO(), "alice", 1)
+		},
+	}
+
+	for name, download := range downloads {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			storer := &commitErrStorer{&txStorer{Memory: new(testutils.Memory)}}
+			d := &Downloader{storer: storer, client: newTestClient(t, handler)}
+
+			err := download(d)
+			require.Error(err)
+			require.Contains(err.Error(), "could not call Commit(): disk full")
+			require.Equal("commit", storer.log[len(storer.log)-1])
+		})
+	}
+}
+
+func TestDownloadRepositories(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		name := variables["name"].(string)
+		if name == "missing" {
+			return `{"errors": [{"message": "Could not resolve to a Repository with the name 'missing'."}]}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "` + name + `",
+			"nameWithOwner": "src-d/` + name + `",
+			"owner": {"login": "src-d", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	storer := &txStorer{Memory: new(testutils.Memory)}
+	d := &Downloader{storer: storer, client: newTestClient(t, handler)}
+
+	// a failed repository does not stop the rest, each one is committed
+	err := d.DownloadRepositories(context.TODO(), 5, []RepoRef{
+		{Owner: "src-d", Name: "gitbase"},
+		{Owner: "src-d", Name: "missing"},
+		{Owner: "src-d", Name: "go-git"},
+	})
+	require.Error(err)
+	require.Equal([]string{
+		"begin",
+		"repository src-d/gitbase v5",
+		"commit",
+		"begin",
+		"rollback",
+		"begin",
+		"repository src-d/go-git v5",
+		"commit",
+	}, storer.log)
+
+	multi, ok := err.(*MultiError)
+	require.True(ok)
+	failed := multi.Filter("repository")
+	require.Len(failed, 1)
+	require.Equal("src-d/missing", failed[0].ID)
+
+	storer.log = nil
+	require.NoError(d.DownloadRepositories(context.TODO(), 6, []RepoRef{{Owner: "src-d", Name: "gitbase"}}))
+	require.Equal([]string{"begin", "repository src-d/gitbase v6", "commit"}, storer.log)
+}
+
+func TestDownloadOrganizationRepositories(t *testing.T) {
+	var forks []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "repositories(first: $repositoriesPage") {
+			if variables["repositoriesCursor"] == nil {
+				forks = append(forks, variables["isFork"])
+				return `{"data": {"organization": {"repositories": {"pageInfo": {"hasNextPage": true, "endCursor": "e1"}, "nodes": [
+					{"name": "gitbase"},
+					{"name": "old", "isArchived": true}
+				]}}}}`
+			}
+
+			return `{"data": {"organization": {"repositories": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"name": "missing"},
+				{"name": "go-git"}
+			]}}}}`
+		}
+
+		name := variables["name"].(string)
+		if name == "missing" {
+			return `{"errors": [{"message": "Could not resolve to a Repository with the name 'missing'."}]}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "` + name + `",
+			"nameWithOwner": "src-d/` + name + `",
+			"owner": {"login": "src-d", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	storer := &txStorer{Memory: new(testutils.Memory)}
+	d := &Downloader{storer: storer, client: newTestClient(t, handler)}
+
+	// the archived repository is skipped, the failed one is reported
+	names, err := d.DownloadOrganizationRepositories(context.TODO(), "src-d", 5, false, false)
+	require.Error(err)
+	require.Equal([]string{"gitbase", "go-git"}, names)
+	require.Equal([]string{
+		"begin",
+		"repository src-d/gitbase v5",
+		"commit",
+		"begin",
+		"rollback",
+		"begin",
+		"repository src-d/go-git v5",
+		"commit",
+	}, storer.log)
+
+	multi, ok := err.(*MultiError)
+	require.True(ok)
+	failed := multi.Filter("repository")
+	require.Len(failed, 1)
+	require.Equal("src-d/missing", failed[0].ID)
+
+	storer.log = nil
+	names, err = d.DownloadOrganizationRepositories(context.TODO(), "src-d", 6, true, true)
+	require.Error(err)
+	require.Equal([]string{"gitbase", "old", "go-git"}, names)
+
+	// the forks are filtered by the query unless they are included
+	require.Equal([]interface{}{false, nil}, forks)
+}
+
+func TestDownloadUser(t *testing.T) {
+	var logins []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		logins = append(logins, variables["login"])
+		if variables["login"] == "ghost" {
+			return `{"data": {"user": null}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a User with the login of 'ghost'."}]}`
+		}
+
+		return `{"data": {"user": {
+			"id": "u1",
+			"login": "alice",
+			"name": "Alice",
+			"company": "source{d}",
+			"followers": {"totalCount": 42}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestDownloader(t, handler)
+	s := &txStorer{Memory: m}
+	d.storer = s
+
+	require.NoError(d.DownloadUser(context.TODO(), "alice", 2))
+	require.Equal([]interface{}{"alice"}, logins)
+	require.Equal([]string{"begin", "commit"}, s.log)
+	require.Len(m.Users, 1)
+	require.Equal("Alice", m.Users[0].Name)
+	require.Equal("source{d}", m.Users[0].Company)
+	require.Equal(42, m.Users[0].Followers.TotalCount)
+
+	// an unknown user is rolled back
+	s.log = nil
+	err := d.DownloadUser(context.TODO(), "ghost", 2)
+	require.Error(err)
+	require.Contains(err.Error(), "Could not resolve to a User")
+	require.Equal([]string{"begin", "rollback"}, s.log)
+	require.Len(m.Users, 1)
+}
+
+func TestOrganizationChunks(t *testing.T) {
+	failures := 1
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "rateLimit") {
+			return `{"data": {"rateLimit": {"cost": 1, "limit": 5000, "remaining": 0, "resetAt": "2019-10-01T11:00:00Z"}}}`
+		}
+
+		switch variables["membersWithRoleCursor"] {
+		case nil:
+			return `{"data": {"organization": {
+				"login": "src-d",
+				"membersWithRole": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+					{"id": "u1", "login": "alice"}, {"id": "u2", "login": "bob"}
+				]}
+			}}}`
+		case "c1":
+			return `{"data": {"organization": {"membersWithRole": {"pageInfo": {"hasNextPage": true, "endCursor": "c2"}, "nodes": [
+				{"id": "u3", "login": "carol"}
+			]}}}}`
+		default:
+			if failures > 0 {
+				failures--
+				return `{"errors": [{"message": "API rate limit exceeded"}]}`
+			}
+
+			return `{"data": {"organization": {"membersWithRole": {"pageInfo": {"hasNextPage": false, "endCursor": "c3"}, "nodes": [
+				{"id": "u4", "login": "dave"}
+			]}}}}`
+		}
+	}
+
+	logins := func(users []*graphql.UserExtended) []string {
+		var res []string
+		for _, user := range users {
+			res = append(res, user.Login)
+		}
+		return res
+	}
+
+	require := require.New(t)
+	storer := &txStorer{Memory: new(testutils.Memory)}
+
+	// first session: the organization and two pages of members
+	d := &Downloader{storer: storer, client: newTestClient(t, handler)}
+	checkpoint := &OrganizationCheckpoint{Organization: "src-d", Version: 5}
+	require.NoError(d.DownloadOrganizationChunk(context.TODO(), checkpoint, 2))
+	require.Equal(&OrganizationCheckpoint{Organization: "src-d", Version: 5, MembersCursor: "c2"}, checkpoint)
+	require.Equal([]string{"alice", "bob", "carol"}, logins(storer.Users))
+	require.Error(d.Finalize(checkpoint))
+
+	saved, err := json.Marshal(checkpoint)
+	require.NoError(err)
+
+	// second session, resumed from the saved checkpoint. A failed chunk is
+	// rolled back, and leaves the checkpoint as it was
+	d = &Downloader{storer: storer, client: newTestClient(t, handler)}
+	checkpoint = new(OrganizationCheckpoint)
+	require.NoError(json.Unmarshal(saved, checkpoint))
+
+	err = d.DownloadOrganizationChunk(context.TODO(), checkpoint, 2)
+	var rateErr *RateLimitError
+	require.True(errors.As(err, &rateErr), "%T is not a *RateLimitError", err)
+	require.Equal(time.Date(2019, 10, 1, 11, 0, 0, 0, time.UTC), rateErr.ResetAt)
+	require.Equal("c2", checkpoint.MembersCursor)
+
+	require.NoError(d.DownloadOrganizationChunk(context.TODO(), checkpoint, 2))
+	require.Equal(&OrganizationCheckpoint{Organization: "src-d", Version: 5, MembersCursor: "c3", Done: true}, checkpoint)
+	require.Equal([]string{"alice", "bob", "carol", "dave"}, logins(storer.Users))
+
+	require.NoError(d.Finalize(checkpoint))
+	require.Equal([]string{
+		"begin",
+		"organization src-d v5",
+		"commit",
+		"begin",
+		"rollback",
+		"begin",
+		"commit",
+		"active v5",
+	}, storer.log)
+
+	// a done checkpoint has nothing left to download
+	require.NoError(d.DownloadOrganizationChunk(context.TODO(), checkpoint, 2))
+	require.Len(storer.log, 8)
+}
+
+func TestPullRequestMergeable(t *testing.T) {
+	var retried []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "repository(") {
+			retried = append(retried, variables["id"])
+			return `{"data": {"node": {"mergeable": "MERGEABLE", "potentialMergeCommit": {"oid": "ccc"}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr1", "number": 1, "state": "OPEN", "mergeable": "MERGEABLE", "potentialMergeCommit": {"oid": "aaa"}},
+				{"id": "pr2", "number": 2, "state": "OPEN", "mergeable": "CONFLICTING", "potentialMergeCommit": null},
+				{"id": "pr3", "number": 3, "state": "OPEN", "mergeable": "UNKNOWN", "potentialMergeCommit": null}
+			]}
+		}}}`
+	}
+
+	type state struct {
+		Mergeable string
+		Oid       string
+	}
+	states := func(m *store.Mem) map[int]state {
+		res := make(map[int]state)
+		for number, pr := range m.Repos["git-fixtures"]["basic"].PRs {
+			res[number] = state{pr.Mergeable, pr.PotentialMergeCommit.Oid}
+		}
+		return res
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Empty(retried)
+	require.Equal(map[int]state{
+		1: {"MERGEABLE", "aaa"},
+		2: {"CONFLICTING", ""},
+		3: {"UNKNOWN", ""},
+	}, states(m))
+
+	clock := &fakeClock{}
+	d, m = newTestMemDownloader(t, handler)
+	d.clock = clock
+	d.MergeableRetryDelay = time.Minute
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{"pr3"}, retried)
+	require.Equal([]time.Duration{time.Minute}, clock.sleeps)
+	require.Equal(map[int]state{
+		1: {"MERGEABLE", "aaa"},
+		2: {"CONFLICTING", ""},
+		3: {"MERGEABLE", "ccc"},
+	}, states(m))
+}
+
+func TestMilestones(t *testing.T) {
+	var query string
+	d, m := newTestMemDownloader(t, func(q string, variables map[string]interface{}) string {
+		query = q
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 1, "milestone": {"id": "m1", "title": "v1.0", "state": "OPEN", "dueOn": "2019-12-01T00:00:00Z"}},
+				{"number": 2, "milestone": null}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 3, "milestone": {"id": "m0", "title": "v0.9", "state": "CLOSED", "dueOn": null}}
+			]}
+		}}}`
+	})
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Contains(query, "milestone{id,title,state,dueOn}")
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Equal(graphql.Milestone{
+		Id:    "m1",
+		Title: "v1.0",
+		State: "OPEN",
+		DueOn: time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC),
+	}, repo.Issues[1].Milestone)
+	require.Equal(graphql.Milestone{}, repo.Issues[2].Milestone)
+	require.Equal(graphql.Milestone{Id: "m0", Title: "v0.9", State: "CLOSED"}, repo.PRs[3].Milestone)
+}
+
+func TestEdits(t *testing.T) {
+	var query string
+	d, m := newTestMemDownloader(t, func(q string, variables map[string]interface{}) string {
+		query = q
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 1,
+				"lastEditedAt": "2019-10-01T10:00:00Z",
+				"editor": {"login": "alice", "__typename": "User", "databaseId": 10},
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 1, "lastEditedAt": "2019-10-02T10:00:00Z", "editor": null},
+					{"databaseId": 2, "lastEditedAt": null, "editor": null}
+				]}
+			}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 2,
+				"lastEditedAt": null,
+				"editor": null,
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 3, "lastEditedAt": "2019-10-03T10:00:00Z", "editor": {"login": "bob", "__typename": "User"}, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"databaseId": 4, "lastEditedAt": "2019-10-04T10:00:00Z", "editor": {"login": "bob", "__typename": "User"}}
+					]}}
+				]}
+			}]}
+		}}}`
+	})
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Contains(query, "lastEditedAt,editor{login,__typename,... on User{databaseId,id,login}}")
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	issue := repo.Issues[1]
+	require.Equal(time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC), issue.EditedAt)
+	require.Equal("alice", issue.EditorLogin())
+	require.Equal(10, issue.LastEditedBy.DatabaseId)
+
+	// an edit by a deleted account, and no edit
+	require.Equal("ghost", issue.Comments[0].EditorLogin())
+	require.True(issue.Comments[1].EditedAt.IsZero())
+	require.Equal("", issue.Comments[1].EditorLogin())
+
+	pr := repo.PRs[2]
+	require.True(pr.EditedAt.IsZero())
+	require.Equal("bob", pr.Reviews[0].EditorLogin())
+	require.Equal("bob", pr.Reviews[0].Comments[0].EditorLogin())
+}
+
+func TestRulesets(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	rulesets := `{"data": {"node": {"rulesets": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+		"databaseId": 42,
+		"name": "protect main",
+		"enforcement": "ACTIVE",
+		"target": "BRANCH",
+		"createdAt": "2023-05-01T10:00:00Z",
+		"updatedAt": "2023-05-02T10:00:00Z",
+		"conditions": {"refName": {"include": ["~DEFAULT_BRANCH", "refs/heads/release/*"], "exclude": []}},
+		"rules": {"nodes": [{"type": "DELETION"}, {"type": "NON_FAST_FORWARD"}, {"type": "PULL_REQUEST"}]}
+	}]}}}}`
+
+	require := require.New(t)
+
+	storer := new(testutils.Memory)
+	d := &Downloader{storer: storer, client: newTestClientWithRulesets(t, handler, rulesets)}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	require.Len(storer.Rulesets, 1)
+	ruleset := storer.Rulesets[0]
+	require.Equal(42, ruleset.DatabaseId)
+	require.Equal("protect main", ruleset.Name)
+	require.Equal("ACTIVE", ruleset.Enforcement)
+	require.Equal("BRANCH", ruleset.Target)
+	require.Equal([]string{"~DEFAULT_BRANCH", "refs/heads/release/*"}, ruleset.Conditions.RefName.Include)
+	require.Equal([]string{"DELETION", "NON_FAST_FORWARD", "PULL_REQUEST"}, ruleset.RuleTypes())
+
+	// without admin access the rulesets are skipped, the rest is downloaded
+	forbidden := `{"data": {"node": {"rulesets": null}}, "errors": [{"type": "FORBIDDEN", "message": "Resource not accessible by integration"}]}`
+
+	storer = new(testutils.Memory)
+	d = &Downloader{storer: storer, client: newTestClientWithRulesets(t, handler, forbidden)}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Empty(storer.Rulesets)
+	require.Equal("basic", storer.Repository.Name)
+}
+
+func TestAuditLog(t *testing.T) {
+	var auditLog string
+	var cursors []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "auditLog(") {
+			return `{"data": {"organization": {
+				"login": "src-d",
+				"membersWithRole": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+			}}}`
+		}
+
+		cursors = append(cursors, variables["auditLogCursor"])
+		if variables["auditLogCursor"] == nil {
+			return auditLog
+		}
+
+		return `{"data": {"organization": {"auditLog": {"pageInfo": {"hasNextPage": false}, "nodes": [
+			{"__typename": "RepoCreateAuditEntry", "id": "e3", "action": "repo.create", "actorLogin": "bob", "createdAt": "2019-10-02T10:00:00.000Z", "operationType": "CREATE"}
+		]}}}}`
+	}
+
+	require := require.New(t)
+
+	auditLog = `{"data": {"organization": {"auditLog": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+		{"__typename": "OrgAddMemberAuditEntry", "id": "e1", "action": "org.add_member", "actorLogin": "alice", "userLogin": "bob", "actorIp": "10.0.0.1", "createdAt": "2019-10-01T10:00:00.000Z", "operationType": "CREATE"},
+		{"__typename": "OrgUpdateMemberAuditEntry", "id": "e2", "action": "org.update_member", "actorLogin": "alice", "userLogin": "bob", "createdAt": "2019-10-01T11:00:00.000Z", "operationType": "MODIFY"}
+	]}}}}`
+
+	// the audit log is opt-in
+	d, storer := newTestDownloader(t, handler)
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Empty(cursors)
+	require.Empty(storer.AuditLog)
+
+	d, storer = newTestDownloader(t, handler)
+	d.AuditLogIncluded = true
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Equal([]interface{}{nil, "c1"}, cursors)
+
+	var entries []string
+	for _, entry := range storer.AuditLog {
+		entries = append(entries, fmt.Sprintf("%v %v %v %v %v",
+			entry.Typename, entry.Node.Id, entry.AuditEntry.Action, entry.AuditEntry.ActorLogin, entry.AuditEntry.CreatedAt.Format(time.RFC3339)))
+	}
+	require.Equal([]string{
+		"OrgAddMemberAuditEntry e1 org.add_member alice 2019-10-01T10:00:00Z",
+		"OrgUpdateMemberAuditEntry e2 org.update_member alice 2019-10-01T11:00:00Z",
+		"RepoCreateAuditEntry e3 repo.create bob 2019-10-02T10:00:00Z",
+	}, entries)
+	require.Equal("10.0.0.1", storer.AuditLog[0].AuditEntry.ActorIp)
+
+	// without an owner token the audit log is skipped
+	auditLog = `{"data": {"organization": {"auditLog": null}}, "errors": [{"type": "FORBIDDEN", "message": "src-d does not have permission to read the audit log"}]}`
+	cursors = nil
+
+	d, storer = newTestDownloader(t, handler)
+	d.AuditLogIncluded = true
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Len(cursors, 1)
+	require.Empty(storer.AuditLog)
+	require.Equal("src-d", storer.Organization.Login)
+}
+
+func TestOrgProjects(t *testing.T) {
+	var projects string
+	var itemCursors []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "node(id: $id)") {
+			itemCursors = append(itemCursors, variables["projectV2ItemsCursor"])
+			return `{"data": {"node": {"items": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i3", "type": "DRAFT_ISSUE", "content": {"id": "d1", "title": "Plan v2"}, "status": null}
+			]}}}}`
+		}
+
+		if !strings.Contains(query, "projectsV2(") {
+			return `{"data": {"organization": {
+				"login": "src-d",
+				"membersWithRole": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+			}}}`
+		}
+
+		return projects
+	}
+
+	require := require.New(t)
+
+	projects = `{"data": {"organization": {"projectsV2": {"pageInfo": {"hasNextPage": false}, "nodes": [
+		{"id": "p1", "number": 1, "title": "Roadmap", "closed": false, "public": true, "url": "https://github.com/orgs/src-d/projects/1",
+		 "fields": {"nodes": [{"name": "Title", "dataType": "TITLE"}, {"name": "Status", "dataType": "SINGLE_SELECT"}]},
+		 "items": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+			{"id": "i1", "type": "ISSUE", "content": {"id": "n1", "number": 10, "title": "Bug", "repository": {"nameWithOwner": "src-d/gitbase"}},
+			 "status": {"name": "Todo", "updatedAt": "2019-10-01T10:00:00.000Z"}},
+			{"id": "i2", "type": "PULL_REQUEST", "content": {"id": "n2", "number": 11, "title": "Fix bug", "repository": {"nameWithOwner": "src-d/gitbase"}},
+			 "status": {"name": "Done", "updatedAt": "2019-10-02T10:00:00.000Z"}}
+		 ]}},
+		{"id": "p2", "number": 2, "title": "Empty", "closed": true,
+		 "fields": {"nodes": []},
+		 "items": {"pageInfo": {"hasNextPage": false}, "nodes": []}}
+	]}}}}`
+
+	// the projects are opt-in
+	d, storer := newTestDownloader(t, handler)
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Empty(storer.OrgProjects)
+
+	d, storer = newTestDownloader(t, handler)
+	d.OrgProjectsIncluded = true
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Equal([]interface{}{"c1"}, itemCursors)
+	require.Len(storer.OrgProjects, 2)
+
+	roadmap := storer.OrgProjects[0]
+	require.Equal("Roadmap", roadmap.Title)
+	require.Equal([]string{"Title", "Status"}, roadmap.FieldNames())
+	require.Equal([]string{"TITLE", "SINGLE_SELECT"}, roadmap.FieldDataTypes())
+
+	var items []string
+	for i := range roadmap.Items.Nodes {
+		item := &roadmap.Items.Nodes[i]
+		items = append(items, fmt.Sprintf("%v %v %v %v %v %q",
+			item.Id, item.Type, item.ContentRepository(), item.ContentNumber(), item.ContentTitle(), item.Status.SingleSelect.Name))
+	}
+	require.Equal([]string{
+		`i1 ISSUE src-d/gitbase 10 Bug "Todo"`,
+		`i2 PULL_REQUEST src-d/gitbase 11 Fix bug "Done"`,
+		`i3 DRAFT_ISSUE  0 Plan v2 ""`,
+	}, items)
+	require.Equal("d1", roadmap.Items.Nodes[2].ContentNodeID())
+
+	empty := storer.OrgProjects[1]
+	require.True(empty.Closed)
+	require.Empty(empty.FieldNames())
+	require.Empty(empty.Items.Nodes)
+
+	// without the read:project scope the projects are skipped
+	projects = `{"data": {"organization": {"projectsV2": null}}, "errors": [{"type": "INSUFFICIENT_SCOPES", "message": "Your token has not been granted the required scopes to execute this query. The 'projectsV2' field requires one of the following scopes: ['read:project'], but your token has only been granted the: ['repo'] scopes."}]}`
+
+	d, storer = newTestDownloader(t, handler)
+	d.OrgProjectsIncluded = true
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Empty(storer.OrgProjects)
+	require.Equal("src-d", storer.Organization.Login)
+}
+
+func TestBodiesOmitted(t *testing.T) {
+	for _, omitted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("omitted=%v", omitted), func(t *testing.T) {
+			require := require.New(t)
+
+			var queries []string
+			var bodiesOmitted []interface{}
+			d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+				queries = append(queries, query)
+				bodiesOmitted = append(bodiesOmitted, variables["bodiesOmitted"])
+
+				body := `"body": "LGTM",`
+				if variables["bodiesOmitted"] == true {
+					body = ""
+				}
+
+				return `{"data": {"repository": {
+					"name": "basic",
+					"nameWithOwner": "git-fixtures/basic",
+					"owner": {"login": "git-fixtures", "__typename": "Organization"},
+					"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+					"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+					"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+						"number": 1,
+						"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+							` + body + `
+							"databaseId": 20,
+							"createdAt": "2019-10-01T10:00:00Z",
+							"author": {"login": "alice", "__typename": "User"}
+						}]}
+					}]}
+				}}}`
+			})
+			d.BodiesOmitted = omitted
+
+			err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+			require.NoError(err)
+
+			require.Len(queries, 1)
+			require.Contains(queries[0], "body: body @skip(if: $bodiesOmitted)")
+			require.Equal([]interface{}{omitted}, bodiesOmitted)
+
+			require.Len(storer.PRComments, 1)
+			comment := storer.PRComments[0]
+			require.Equal("alice", comment.Author.Login)
+			require.Equal("2019-10-01 10:00:00 +0000 UTC", comment.CreatedAt.String())
+			if omitted {
+				require.Empty(comment.Body)
+			} else {
+				require.Equal("LGTM", comment.Body)
+			}
+		})
+	}
+}
+
+// TestTotalCounts checks that the total number of comments and reviews is
+// downloaded before paginating them, and used to size the Mem store
+func TestTotalCounts(t *testing.T) {
+	d, storer := newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 1,
+				"comments": {"totalCount": 3, "pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 1}, {"databaseId": 2}, {"databaseId": 3}
+				]},
+				"reviews": {"totalCount": 2, "pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 4, "comments": {"totalCount": 1, "nodes": [{"databaseId": 6}]}},
+					{"databaseId": 5, "comments": {"totalCount": 0, "nodes": []}}
+				]}
+			}]}
+		}}}`
+	})
+
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require := require.New(t)
+	require.NoError(err)
+
+	pr := storer.Repos["git-fixtures"]["basic"].PRs[1]
+	require.Len(pr.Comments, 3)
+	require.Equal(3, cap(pr.Comments))
+	require.Len(pr.Reviews, 2)
+	require.Equal(2, cap(pr.Reviews))
+	require.Equal(1, pr.Reviews[0].PullRequestReviewFields.Comments.TotalCount)
+	require.Len(pr.Reviews[0].Comments, 1)
+	require.Equal(1, cap(pr.Reviews[0].Comments))
+}
+
+// TestSetEndpoint checks that the queries are sent to the overridden
+// GraphQL URL
+func TestSetEndpoint(t *testing.T) {
+	require := require.New(t)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/github/api/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"rateLimit": {"remaining": 42}}}`)
+	}))
+	defer server.Close()
+
+	d, err := NewStdoutDownloader(server.Client())
+	require.NoError(err)
+
+	for _, endpoint := range []string{"", "/github/api/graphql", "ftp://proxy/graphql", "https://"} {
+		require.Error(d.SetEndpoint(endpoint), endpoint)
+	}
+
+	require.NoError(d.SetEndpoint(server.URL + "/github/api/graphql"))
+
+	remaining, err := d.RateRemaining(context.TODO())
+	require.NoError(err)
+	require.Equal(42, remaining)
+	require.Equal([]string{"/github/api/graphql"}, paths)
+}
+
+func TestRateLimit(t *testing.T) {
+	require := require.New(t)
+
+	var queries []string
+	d, _ := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		queries = append(queries, query)
+		return `{"data": {"rateLimit": {"cost": 1, "limit": 5000, "remaining": 4321, "resetAt": "2019-10-01T12:00:00+02:00"}}}`
+	})
+
+	rate, err := d.RateLimit(context.TODO())
+	require.NoError(err)
+	require.Equal(RateLimit{
+		Cost:      1,
+		Limit:     5000,
+		Remaining: 4321,
+		ResetAt:   time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC),
+	}, rate)
+	require.Equal(time.UTC, rate.ResetAt.Location())
+	require.Len(queries, 1)
+	require.Contains(queries[0], "resetAt")
+}
+
+func TestCheckScopes(t *testing.T) {
+	require := require.New(t)
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("X-OAuth-Scopes", header)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"rateLimit": {"remaining": 42}}}`)
+	}))
+	defer server.Close()
+
+	client := oauth2.NewClient(context.TODO(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	d, err := NewStdoutDownloader(client)
+	require.NoError(err)
+	require.NoError(d.SetEndpoint(server.URL))
+
+	header = "repo, read:org,admin:org_hook"
+	scopes, err := d.CheckScopes(context.TODO())
+	require.NoError(err)
+	require.Equal([]string{"repo", "read:org", "admin:org_hook"}, scopes)
+
+	header = ""
+	scopes, err = d.CheckScopes(context.TODO())
+	require.NoError(err)
+	require.Empty(scopes)
+
+	d, err = NewStdoutDownloader(server.Client())
+	require.NoError(err)
+	require.NoError(d.SetEndpoint(server.URL))
+
+	_, err = d.CheckScopes(context.TODO())
+	require.Error(err)
+}
+
+func TestEventLogResume(t *testing.T) {
+	require := require.New(t)
+
+	handler := func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": [{"topic": {"name": "git"}}]},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{"number": 1}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	var log bytes.Buffer
+	for version := 0; version < 2; version++ {
+		last, err := store.LastSequence(bytes.NewReader(log.Bytes()))
+		require.NoError(err)
+
+		d := &Downloader{
+			storer: store.NewEventLog(&log, last),
+			client: newTestClient(t, handler),
+		}
+		require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", version))
+	}
+
+	var types []string
+	var last uint64
+	dec := json.NewDecoder(&log)
+	for dec.More() {
+		var e store.Event
+		require.NoError(dec.Decode(&e))
+		require.Equal(last+1, e.Sequence)
+		last = e.Sequence
+
+		types = append(types, fmt.Sprintf("v%v %v", e.Version, e.Type))
+	}
+
+	run := []string{"begin", "repository", "topic", "repository_topic", "issue", "commit"}
+	var expected []string
+	for version := 0; version < 2; version++ {
+		for _, typ := range run {
+			expected = append(expected, fmt.Sprintf("v%v %v", version, typ))
+		}
+	}
+	require.Equal(expected, types)
+}
+
+func TestSampleSize(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		switch {
+		case strings.Contains(query, "issues(first: $nodeIDsPage"):
+			from, to, next := 1, 20, `"hasNextPage": true, "endCursor": "c1"`
+			if variables["nodeIDsCursor"] == "c1" {
+				from, to, next = 21, 30, `"hasNextPage": false`
+			}
+
+			var nodes []string
+			for i := from; i <= to; i++ {
+				nodes = append(nodes, fmt.Sprintf(`{"id": "issue%v"}`, i))
+			}
+
+			return `{"data": {"node": {"issues": {"totalCount": 30, "pageInfo": {` + next + `}, "nodes": [` + strings.Join(nodes, ",") + `]}}}}`
+		case strings.Contains(query, "pullRequests(first: $nodeIDsPage"):
+			return `{"data": {"node": {"pullRequests": {"totalCount": 3, "pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr31"}, {"id": "pr32"}, {"id": "pr33"}
+			]}}}}`
+		case strings.Contains(query, "... on PullRequest"):
+			id := variables["id"].(string)
+			return fmt.Sprintf(`{"data": {"node": {"id": "%v", "number": %v}}}`, id, strings.TrimPrefix(id, "pr"))
+		case strings.Contains(query, "... on Issue"):
+			id := variables["id"].(string)
+			return fmt.Sprintf(`{"data": {"node": {"id": "%v", "number": %v}}}`, id, strings.TrimPrefix(id, "issue"))
+		}
+
+		// only the total counts are requested in the first query
+		if variables["issuesPage"] != 0.0 || variables["pullRequestsPage"] != 0.0 {
+			return `{"errors": [{"message": "unexpected page size"}]}`
+		}
+
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"totalCount": 30, "pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": []},
+			"pullRequests": {"totalCount": 3, "pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	for _, size := range []int{1, 5, 25} {
+		d, m := newTestMemDownloader(t, handler)
+		d.SampleSize = size
+		require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+		repo := m.Repos["git-fixtures"]["basic"]
+		require.Len(repo.Issues, size)
+		for number := range repo.Issues {
+			require.True(number >= 1 && number <= 30, "issue #%v", number)
+		}
+
+		require.True(len(repo.PRs) <= size, "%v PRs sampled", len(repo.PRs))
+		if size >= 3 {
+			require.Len(repo.PRs, 3)
+		}
+	}
+}
+
+func TestCommitAuthors(t *testing.T) {
+	var included []interface{}
+	var queried []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "node(id:$id)") {
+			queried = append(queried, variables["pullRequestCommitsCursor"])
+			return `{"data": {"node": {"pullRequestCommits": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"commit": {"oid": "c3"}, "commitAuthor": {"author": {"user": {"id": "u1", "login": "alice"}}}}
+			]}}}}`
+		}
+
+		included = append(included, variables["commitAuthorsIncluded"])
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr1", "number": 1, "pullRequestCommits": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+					{"commit": {"oid": "c1"}, "commitAuthor": {"author": {"user": {"id": "u1", "login": "alice"}}}},
+					{"commit": {"oid": "c2"}, "commitAuthor": {"author": {"user": null}}}
+				]}}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	// the commit authors are opt-in
+	d, storer := newTestDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{false}, included)
+	require.Empty(queried)
+	require.Empty(storer.Users)
+
+	included = nil
+	d, storer = newTestDownloader(t, handler)
+	d.CommitAuthorsIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{true}, included)
+	require.Equal([]interface{}{"c1"}, queried)
+	require.Len(storer.Users, 1)
+	require.Equal("alice", storer.Users[0].Login)
+	require.Empty(storer.PRCommits)
+
+	// each download saves the users again
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 1))
+	require.Len(storer.Users, 2)
+
+	// with the PR commits, the authors are saved in the same pass
+	queried = nil
+	d, storer = newTestDownloader(t, handler)
+	d.CommitAuthorsIncluded = true
+	d.PullRequestCommitsIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{"c1"}, queried)
+	require.Len(storer.Users, 1)
+	require.Len(storer.PRCommits[1], 3)
+}
+
+func TestPullRequestCommits(t *testing.T) {
+	var included []interface{}
+	var queried []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "node(id:$id)") {
+			queried = append(queried, variables["pullRequestCommitsCursor"])
+			return `{"data": {"node": {"pullRequestCommits": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"commit": {"oid": "c2", "authoredDate": "2019-01-03T00:00:00Z", "committedDate": "2019-01-04T00:00:00Z",
+					"author": {"name": "Bob", "email": "bob@example.com", "user": null},
+					"committer": {"name": "Bob", "email": "bob@example.com", "user": null}}}
+			]}}}}`
+		}
+
+		included = append(included, variables["pullRequestCommitsIncluded"])
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr1", "number": 1, "pullRequestCommits": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+					{"commit": {"oid": "c1", "message": "fix", "authoredDate": "2019-01-01T00:00:00Z", "committedDate": "2019-01-02T00:00:00Z",
+						"author": {"name": "Alice", "email": "alice@example.com", "user": {"login": "alice"}},
+						"committer": {"name": "GitHub", "email": "noreply@github.com", "user": null}}}
+				]}}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	// the PR commits are opt-in
+	d, storer := newTestDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{false}, included)
+	require.Empty(queried)
+	require.Empty(storer.PRCommits)
+
+	included = nil
+	d, storer = newTestDownloader(t, handler)
+	d.PullRequestCommitsIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{true}, included)
+	require.Equal([]interface{}{"c1"}, queried)
+
+	commits := storer.PRCommits[1]
+	require.Len(commits, 2)
+
+	c := commits[0].Commit
+	require.Equal("c1", c.Oid)
+	require.Equal("fix", c.Message)
+	require.Equal(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), c.AuthoredDate)
+	require.Equal(time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC), c.CommittedDate)
+	require.Equal("alice@example.com", c.Author.Email)
+	require.Equal("alice", c.Author.Login())
+	require.Equal("GitHub", c.Committer.Name)
+	require.Equal("", c.Committer.Login())
+
+	c = commits[1].Commit
+	require.Equal("c2", c.Oid)
+	require.Equal(time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC), c.AuthoredDate)
+	require.Equal(time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC), c.CommittedDate)
+	require.Equal("", c.Author.Login())
+}
+
+func TestClosingReferences(t *testing.T) {
+	var queried []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "node(id:$id)") && strings.Contains(query, "closingIssuesReferences") {
+			queried = append(queried, variables["id"])
+			return `{"data": {"node": {"closingReferences": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 4, "repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i3", "number": 3, "closingReferences": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"number": 1, "repository": {"name": "basic", "owner": {"login": "git-fixtures"}}},
+					{"number": 2, "repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}
+				]}},
+				{"id": "i4", "number": 4, "closingReferences": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"number": 1, "repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr1", "number": 1, "closingReferences": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+					{"number": 3, "repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}
+				]}},
+				{"id": "pr2", "number": 2, "closingReferences": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"number": 3, "repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}
+				]}}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// only the PR with a second page is queried again
+	require.Equal([]interface{}{"pr1"}, queried)
+
+	// each reference is saved from both sides, but stored once
+	require.Len(m.ClosingReferences, 3)
+	issues := func(refs []store.ClosingReference) []int {
+		var numbers []int
+		for _, r := range refs {
+			numbers = append(numbers, r.IssueNumber)
+		}
+		return numbers
+	}
+	prs := func(refs []store.ClosingReference) []int {
+		var numbers []int
+		for _, r := range refs {
+			numbers = append(numbers, r.PullRequestNumber)
+		}
+		return numbers
+	}
+
+	require.ElementsMatch([]int{3, 4}, issues(m.ClosingIssues("git-fixtures", "basic", 1)))
+	require.ElementsMatch([]int{3}, issues(m.ClosingIssues("git-fixtures", "basic", 2)))
+	require.ElementsMatch([]int{1, 2}, prs(m.ClosingPullRequests("git-fixtures", "basic", 3)))
+	require.ElementsMatch([]int{1}, prs(m.ClosingPullRequests("git-fixtures", "basic", 4)))
+}
+
+func TestCommentWatermarks(t *testing.T) {
+	var cursors []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "comments(last: $issueCommentsPage") {
+			require.Equal(t, "i1", variables["id"])
+			cursors = append(cursors, variables["issueCommentsCursor"])
+			switch variables["issueCommentsCursor"] {
+			case nil:
+				return `{"data": {"node": {"comments": {"pageInfo": {"hasPreviousPage": true, "startCursor": "s5"}, "nodes": [
+					{"id": "c5"}, {"id": "c6"}
+				]}}}}`
+			case "s5":
+				return `{"data": {"node": {"comments": {"pageInfo": {"hasPreviousPage": true, "startCursor": "s3"}, "nodes": [
+					{"id": "c3"}, {"id": "c4"}
+				]}}}}`
+			}
+
+			return `{"data": {"node": {"comments": {"pageInfo": {"hasPreviousPage": false}, "nodes": [
+				{"id": "c1"}, {"id": "c2"}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i1", "number": 1, "comments": {"pageInfo": {"hasNextPage": true, "endCursor": "e2"}, "nodes": [
+					{"id": "c1"}, {"id": "c2"}
+				]}},
+				{"id": "i2", "number": 2, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "d1"}, {"id": "d2"}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr3", "number": 3, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "p1"}, {"id": "p2"}, {"id": "p3"}
+				]}}
+			]}
+		}}}`
+	}
+
+	ids := func(comments []graphql.IssueComment) []string {
+		var res []string
+		for _, c := range comments {
+			res = append(res, c.Id)
+		}
+		return res
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	d.CommentWatermarks = map[string]string{"i1": "c3", "pr3": "p2"}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// the pagination goes backwards, and stops at the page with the watermark
+	require.Equal([]interface{}{nil, "s5"}, cursors)
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Equal([]string{"c6", "c5", "c4"}, ids(repo.Issues[1].Comments))
+	// without a watermark, all the comments are downloaded as usual
+	require.Equal([]string{"d1", "d2"}, ids(repo.Issues[2].Comments))
+	// the first page has all the comments, it is not queried again
+	require.Equal([]string{"p3"}, ids(repo.PRs[3].Comments))
+}
+
+const autoVersionRepository = `{"data": {"repository": {
+	"id": "r1",
+	"name": "basic",
+	"nameWithOwner": "git-fixtures/basic",
+	"owner": {"login": "git-fixtures", "__typename": "Organization"},
+	"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+	"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+	"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+}}}`
+
+func TestAutoVersionUnsupported(t *testing.T) {
+	require := require.New(t)
+
+	d, _ := newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		return autoVersionRepository
+	})
+	d.AutoVersion = true
+
+	// store.Mem does not know its versions
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Error(err)
+	require.Contains(err.Error(), "AutoVersion is not supported")
+
+	// an explicit version does not need it
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 3))
+}
+
+// TestAutoVersionDB downloads a repository several times in the PostgreSQL
+// database set in DATABASE_URL, and checks that each download gets the next
+// version
+func TestAutoVersionDB(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	require := require.New(t)
+
+	db, err := sql.Open("postgres", url)
+	require.NoError(err)
+	defer db.Close()
+
+	err = database.Migrate(url)
+	if err != migrate.ErrNoChange {
+		require.NoError(err)
+	}
+
+	s := &store.DB{DB: db}
+	d := &Downloader{
+		storer: s,
+		client: newTestClient(t, func(query string, variables map[string]interface{}) string {
+			return autoVersionRepository
+		}),
+		AutoVersion: true,
+	}
+
+	first, err := s.NextVersion()
+	require.NoError(err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+		next, err := s.NextVersion()
+		require.NoError(err)
+		require.Equal(first+i+1, next)
+
+		// the latest version is the one just downloaded
+		require.NoError(d.SetCurrent(0))
+
+		var versions []int64
+		err = db.QueryRow(`SELECT versions FROM repositories_versioned WHERE node_id = 'r1'`).Scan(pq.Array(&versions))
+		require.NoError(err)
+		require.Contains(versions, int64(first+i))
+	}
+}
+
+func TestCommentsUpdatedSince(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "comments(first: $issueCommentsPage") && variables["issueCommentsCursor"] != nil {
+			return `{"data": {"node": {"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "c3", "updatedAt": "2019-10-03T10:00:00Z"}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i1", "number": 1, "comments": {"pageInfo": {"hasNextPage": true, "endCursor": "e2"}, "nodes": [
+					{"id": "c1", "updatedAt": "2019-09-01T10:00:00Z"},
+					{"id": "c2", "updatedAt": "2019-10-01T10:00:00Z"}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr2", "number": 2,
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "p1", "updatedAt": "2019-10-02T10:00:00Z"},
+					{"id": "p2", "updatedAt": ""}
+				]},
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "r1", "databaseId": 10, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"id": "rc1", "updatedAt": "2019-09-30T10:00:00Z"},
+						{"id": "rc2", "updatedAt": "2019-10-02T10:00:00Z"}
+					]}}
+				]}}
+			]}
+		}}}`
+	}
+
+	issueIDs := func(comments []graphql.IssueComment) []string {
+		var res []string
+		for _, c := range comments {
+			res = append(res, c.Id)
+		}
+		return res
+	}
+	reviewIDs := func(comments []graphql.PullRequestReviewComment) []string {
+		var res []string
+		for _, c := range comments {
+			res = append(res, c.Id)
+		}
+		return res
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Equal([]string{"c1", "c2", "c3"}, issueIDs(repo.Issues[1].Comments))
+	require.Equal([]string{"p1", "p2"}, issueIDs(repo.PRs[2].Comments))
+	require.Equal([]string{"rc1", "rc2"}, reviewIDs(repo.PRs[2].Reviews[0].Comments))
+
+	d, m = newTestMemDownloader(t, handler)
+	d.CommentsUpdatedSince = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// only the comments edited after the watermark are saved, and the ones
+	// without a valid update time
+	repo = m.Repos["git-fixtures"]["basic"]
+	require.Equal([]string{"c3"}, issueIDs(repo.Issues[1].Comments))
+	require.Equal([]string{"p1", "p2"}, issueIDs(repo.PRs[2].Comments))
+	require.Equal([]string{"rc2"}, reviewIDs(repo.PRs[2].Reviews[0].Comments))
+}
+
+func TestCommentAuthor(t *testing.T) {
+	require := require.New(t)
+
+	var reviewsAuthors []interface{}
+	d, m := newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		require.Contains(query, "reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor, author: $pullRequestReviewsAuthor)")
+		reviewsAuthors = append(reviewsAuthors, variables["pullRequestReviewsAuthor"])
+
+		if variables["pullRequestReviewsCursor"] != nil {
+			return `{"data": {"node": {"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "r2", "databaseId": 11, "author": {"login": "alice", "__typename": "User"}, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": []}}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i1", "number": 1, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "c1", "author": {"login": "alice", "__typename": "User"}},
+					{"id": "c2", "author": {"login": "bob", "__typename": "User"}}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr2", "number": 2,
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "p1", "author": {"login": "bob", "__typename": "User"}},
+					{"id": "p2", "author": {"login": "alice", "__typename": "User"}}
+				]},
+				"reviews": {"pageInfo": {"hasNextPage": true, "endCursor": "e1"}, "nodes": [
+					{"id": "r1", "databaseId": 10, "author": {"login": "alice", "__typename": "User"}, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"id": "rc1", "author": {"login": "alice", "__typename": "User"}}
+					]}}
+				]}}
+			]}
+		}}}`
+	})
+	d.CommentAuthor = "alice"
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// the reviews are filtered by the API, in every query
+	require.Len(reviewsAuthors, 2)
+	for _, author := range reviewsAuthors {
+		require.Equal("alice", author)
+	}
+
+	// the comments of other authors are skipped by the downloader
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Len(repo.Issues[1].Comments, 1)
+	require.Equal("c1", repo.Issues[1].Comments[0].Id)
+	require.Len(repo.PRs[2].Comments, 1)
+	require.Equal("p2", repo.PRs[2].Comments[0].Id)
+	require.Len(repo.PRs[2].Reviews, 2)
+	require.Len(repo.PRs[2].Reviews[0].Comments, 1)
+
+	// without CommentAuthor the reviews are not filtered
+	reviewsAuthors = nil
+	d, m = newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		reviewsAuthors = append(reviewsAuthors, variables["pullRequestReviewsAuthor"])
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i1", "number": 1, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "c1", "author": {"login": "alice", "__typename": "User"}},
+					{"id": "c2", "author": {"login": "bob", "__typename": "User"}}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	})
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{nil}, reviewsAuthors)
+	require.Len(m.Repos["git-fixtures"]["basic"].Issues[1].Comments, 2)
+}
+
+func TestLocks(t *testing.T) {
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 1, "locked": true, "lockedBy": {"nodes": [
+					{"id": "LOE_1", "actor": {"login": "alice", "__typename": "User", "databaseId": 7}, "createdAt": "2019-10-01T10:00:00Z", "lockReason": "TOO_HEATED"}
+				]}},
+				{"number": 2, "locked": false, "lockedBy": {"nodes": [
+					{"actor": {"login": "alice", "__typename": "User", "databaseId": 7}, "createdAt": "2019-10-01T10:00:00Z", "lockReason": "SPAM"}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 3, "locked": true, "lockedBy": {"nodes": [
+					{"id": "LOE_3", "actor": {"login": "bob", "__typename": "User", "databaseId": 8}, "createdAt": "2019-10-02T10:00:00Z", "lockReason": null}
+				]}}
+			]}
+		}}}`
+	})
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// issue 2 was unlocked after its last locked event
+	require.Len(storer.Locks, 2)
+	require.Equal(&store.Lock{
+		NodeID:        "LOE_1",
+		LockedAt:      time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC),
+		LockedByID:    7,
+		LockedByLogin: "alice",
+		Reason:        "TOO_HEATED",
+	}, storer.Locks[1])
+	require.Equal(&store.Lock{
+		NodeID:        "LOE_3",
+		LockedAt:      time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC),
+		LockedByID:    8,
+		LockedByLogin: "bob",
+	}, storer.Locks[3])
+}
+
+func TestProjectStatuses(t *testing.T) {
+	var included []interface{}
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		included = append(included, variables["projectStatusesIncluded"])
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 1, "updatedAt": "2019-10-03T10:00:00Z", "projectItems": {"nodes": [
+					{"id": "PVTI_1", "project": {"id": "PVT_1", "number": 4, "title": "Roadmap"},
+						"status": {"name": "In review", "updatedAt": "2019-10-02T10:00:00Z"}},
+					{"id": "PVTI_2", "project": {"id": "PVT_2", "number": 5, "title": "Triage"}, "status": null}
+				]}},
+				{"number": 2, "updatedAt": "2019-10-03T11:00:00Z", "projectItems": {"nodes": []}}
+			]}
+		}}}`
+	})
+	d.ProjectStatusesIncluded = true
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{true}, included)
+
+	// PR 2 is not in any project
+	require.Len(storer.ProjectStatuses, 1)
+	require.Equal([]store.ProjectStatus{{
+		NodeID:               "PVTI_1",
+		ProjectNodeID:        "PVT_1",
+		ProjectNumber:        4,
+		ProjectTitle:         "Roadmap",
+		Status:               "In review",
+		StatusUpdatedAt:      time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC),
+		PullRequestUpdatedAt: "2019-10-03T10:00:00Z",
+	}, {
+		NodeID:               "PVTI_2",
+		ProjectNodeID:        "PVT_2",
+		ProjectNumber:        5,
+		ProjectTitle:         "Triage",
+		PullRequestUpdatedAt: "2019-10-03T10:00:00Z",
+	}}, storer.ProjectStatuses[1])
+}
+
+func TestCompleteCommentsSkipped(t *testing.T) {
+	pr2UpdatedAt := "2019-10-01T10:00:00Z"
+	var commentQueries []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "node(id:$id)") {
+			commentQueries = append(commentQueries, variables["id"])
+			return `{"data": {"node": {"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "` + variables["id"].(string) + `c2"}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "pr1", "number": 1, "updatedAt": "2019-10-01T10:00:00Z", "comments": {
+					"totalCount": 2, "pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [{"id": "pr1c1"}]
+				}},
+				{"id": "pr2", "number": 2, "updatedAt": "` + pr2UpdatedAt + `", "comments": {
+					"totalCount": 2, "pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [{"id": "pr2c1"}]
+				}}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	d.CompleteCommentsSkipped = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{"pr1", "pr2"}, commentQueries)
+
+	// PR 1 is unchanged, and all its comments are saved
+	pr2UpdatedAt = "2019-10-02T10:00:00Z"
+	commentQueries = nil
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{"pr2"}, commentQueries)
+
+	prs := m.Repos["git-fixtures"]["basic"].PRs
+	require.Len(prs[1].Comments, 2)
+	require.Equal("2019-10-02T10:00:00Z", prs[2].UpdatedAt)
+
+	// the store must know the saved comments
+	d, _ = newTestDownloader(t, handler)
+	d.CompleteCommentsSkipped = true
+	require.Error(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+}
+
+func TestDownloadRepositoryIncremental(t *testing.T) {
+	var queries []string
+	handler := func(query string, variables map[string]interface{}) string {
+		switch {
+		case strings.Contains(query, "states: OPEN"):
+			queries = append(queries, "open PRs")
+			return `{"data": {"node": {"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 10, "state": "OPEN", "updatedAt": "2019-10-04T10:00:00Z"},
+				{"number": 12, "state": "OPEN", "updatedAt": "2019-09-30T10:00:00Z"}
+			]}}}}`
+		case strings.Contains(query, "node(id:$id)"):
+			queries = append(queries, "next page")
+			return `{"errors": [{"message": "unexpected pagination"}]}`
+		}
+
+		queries = append(queries, fmt.Sprintf("repository %v", variables["issuesOrder"]))
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": true, "endCursor": "i1"}, "nodes": [
+				{"number": 3, "updatedAt": "2019-10-03T10:00:00Z"},
+				{"number": 2, "updatedAt": "2019-10-02T10:00:00Z"},
+				{"number": 1, "updatedAt": "2019-10-01T10:00:00Z"}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": true, "endCursor": "p1"}, "nodes": [
+				{"number": 10, "state": "OPEN", "updatedAt": "2019-10-04T10:00:00Z"},
+				{"number": 11, "state": "CLOSED", "updatedAt": "2019-10-01T10:00:00Z"}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	latest, err := d.DownloadRepositoryIncremental(context.TODO(), "git-fixtures", "basic", 0,
+		time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC))
+	require.NoError(err)
+	require.Equal(time.Date(2019, 10, 4, 10, 0, 0, 0, time.UTC), latest)
+
+	// the pagination stops at issue 1 and PR 11, and the open PR 12 is
+	// downloaded even though it was not updated
+	require.Equal([]string{
+		"repository map[direction:DESC field:UPDATED_AT]",
+		"open PRs",
+	}, queries)
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Equal("basic", repo.Name)
+	var issues, prs []int
+	for number := range repo.Issues {
+		issues = append(issues, number)
+	}
+	for number := range repo.PRs {
+		prs = append(prs, number)
+	}
+	require.ElementsMatch([]int{2, 3}, issues)
+	require.ElementsMatch([]int{10, 12}, prs)
+
+	// nothing was updated since the latest download, only the open PRs are
+	// downloaded
+	queries = nil
+	d, m = newTestMemDownloader(t, handler)
+	latest, err = d.DownloadRepositoryIncremental(context.TODO(), "git-fixtures", "basic", 0,
+		time.Date(2019, 10, 5, 10, 0, 0, 0, time.UTC))
+	require.NoError(err)
+	require.Equal(time.Date(2019, 10, 5, 10, 0, 0, 0, time.UTC), latest)
+	require.Empty(m.Repos["git-fixtures"]["basic"].Issues)
+	require.Len(m.Repos["git-fixtures"]["basic"].PRs, 2)
+
+	// a full download keeps the creation order
+	queries = nil
+	d, _ = newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		queries = append(queries, fmt.Sprintf("repository %v", variables["issuesOrder"]))
+		return `{"data": {"repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}}`
+	})
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]string{"repository map[direction:ASC field:CREATED_AT]"}, queries)
+}
+
+func TestDownloadRepositoryDeltaUnsupported(t *testing.T) {
+	require := require.New(t)
+
+	d, _ := newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		return autoVersionRepository
+	})
+
+	// store.Mem does not know its versions
+	_, err := d.DownloadRepositoryDelta(context.TODO(), "git-fixtures", "basic", 1)
+	require.Error(err)
+	require.Contains(err.Error(), "delta downloads are not supported")
+}
+
+// TestDownloadRepositoryDeltaDB downloads a repository in the PostgreSQL
+// database set in DATABASE_URL, and then a delta of it, and checks that the
+// delta version has both the changed issues and the unchanged ones copied
+// forward
+func TestDownloadRepositoryDeltaDB(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	require := require.New(t)
+
+	db, err := sql.Open("postgres", url)
+	require.NoError(err)
+	defer db.Close()
+
+	err = database.Migrate(url)
+	if err != migrate.ErrNoChange {
+		require.NoError(err)
+	}
+
+	const comment = `"comments": {"totalCount": 1, "pageInfo": {"hasNextPage": false}, "nodes": [
+		{"id": "delta-c1", "databaseId": 1, "body": "first", "createdAt": "2019-10-01T10:00:00Z", "updatedAt": "2019-10-01T10:00:00Z",
+		 "author": {"login": "alice", "__typename": "User"}}
+	]}`
+
+	var orders []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "states: OPEN") {
+			return `{"data": {"node": {"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}}}}`
+		}
+
+		orders = append(orders, variables["issuesOrder"])
+		issues := `{"id": "delta-i1", "number": 1, "title": "first", "updatedAt": "2019-10-01T10:00:00Z", ` + comment + `},
+			{"id": "delta-i2", "number": 2, "title": "old", "updatedAt": "2019-10-02T10:00:00Z"}`
+		if len(orders) > 1 {
+			// issue 2 is updated, the pagination stops at issue 1
+			issues = `{"id": "delta-i2", "number": 2, "title": "new", "updatedAt": "2019-10-05T10:00:00Z"},
+				{"id": "delta-i1", "number": 1, "title": "first", "updatedAt": "2019-10-01T10:00:00Z", ` + comment + `}`
+		}
+
+		return `{"data": {"repository": {
+			"id": "delta-r1",
+			"name": "delta",
+			"nameWithOwner": "git-fixtures/delta",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [` + issues + `]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "delta-p10", "number": 10, "state": "CLOSED", "title": "fix", "updatedAt": "2019-10-01T10:00:00Z"}
+			]}
+		}}}`
+	}
+
+	s := &store.DB{DB: db}
+	d := &Downloader{
+		storer:      s,
+		client:      newTestClient(t, handler),
+		AutoVersion: true,
+	}
+
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "delta", 0))
+	base, err := s.NextVersion()
+	require.NoError(err)
+	base--
+
+	version, err := d.DownloadRepositoryDelta(context.TODO(), "git-fixtures", "delta", base)
+	require.NoError(err)
+	require.Equal(base+1, version)
+	require.Len(orders, 2)
+
+	rows, err := db.Query(`SELECT number, title FROM issues_versioned
+		WHERE repository_owner = 'git-fixtures' AND repository_name = 'delta' AND $1 = ANY(versions)
+		ORDER BY number`, version)
+	require.NoError(err)
+	defer rows.Close()
+
+	var issues []string
+	for rows.Next() {
+		var number int
+		var title string
+		require.NoError(rows.Scan(&number, &title))
+		issues = append(issues, fmt.Sprintf("%v %v", number, title))
+	}
+	require.NoError(rows.Err())
+	require.Equal([]string{"1 first", "2 new"}, issues)
+
+	// the comment of the unchanged issue, and the unchanged PR, are copied
+	// forward
+	var count int
+	require.NoError(db.QueryRow(`SELECT count(*) FROM issue_comments_versioned
+		WHERE node_id = 'delta-c1' AND $1 = ANY(versions)`, version).Scan(&count))
+	require.Equal(1, count)
+	require.NoError(db.QueryRow(`SELECT count(*) FROM pull_requests_versioned
+		WHERE node_id = 'delta-p10' AND $1 = ANY(versions)`, version).Scan(&count))
+	require.Equal(1, count)
+}
+
+func TestPullRequestStates(t *testing.T) {
+	var states []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "$pullRequestStates:[PullRequestState!]") ||
+			!strings.Contains(query, "states: $pullRequestStates") {
+			return `{"errors": [{"message": "PR states filter missing"}]}`
+		}
+
+		states = append(states, variables["pullRequestStates"])
+		if strings.Contains(query, "node(id:$id)") {
+			return `{"data": {"node": {"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 2, "state": "OPEN", "updatedAt": "2019-10-02T10:00:00Z"}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"owner": {"login": "git-fixtures"},
+			"pullRequests": {"pageInfo": {"hasNextPage": true, "endCursor": "p1"}, "nodes": [
+				{"number": 1, "state": "OPEN", "updatedAt": "2019-10-03T10:00:00Z"}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	// all the states by default
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{nil, nil}, states)
+	require.Len(m.Repos["git-fixtures"]["basic"].PRs, 2)
+
+	// the filter is set in the first query and in the pagination
+	states = nil
+	d, m = newTestMemDownloader(t, handler)
+	d.PullRequestStates = []githubv4.PullRequestState{githubv4.PullRequestStateOpen}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{[]interface{}{"OPEN"}, []interface{}{"OPEN"}}, states)
+	require.Len(m.Repos["git-fixtures"]["basic"].PRs, 2)
+
+	// the open PRs are not downloaded again in an incremental download
+	// without them
+	states = nil
+	d, _ = newTestMemDownloader(t, handler)
+	d.PullRequestStates = []githubv4.PullRequestState{githubv4.PullRequestStateMerged}
+	_, err := d.DownloadRepositoryIncremental(context.TODO(), "git-fixtures", "basic", 0,
+		time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(err)
+	require.Len(states, 2)
+}
+
+// pointerStorer keeps the pointers it is given, to check that each one
+// still points to its own node after the download
+type pointerStorer struct {
+	*testutils.Memory
+	issues         []*graphql.Issue
+	issueComments  []*graphql.IssueComment
+	prs            []*graphql.PullRequest
+	prComments     []*graphql.IssueComment
+	reviews        []*graphql.PullRequestReview
+	reviewComments []*graphql.PullRequestReviewComment
+}
+
+func (s *pointerStorer) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	s.issues = append(s.issues, issue)
+	return nil
+}
+
+func (s *pointerStorer) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	s.issueComments = append(s.issueComments, comment)
+	return nil
+}
+
+func (s *pointerStorer) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	s.prs = append(s.prs, pr)
+	return nil
+}
+
+func (s *pointerStorer) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	s.prComments = append(s.prComments, comment)
+	return nil
+}
+
+func (s *pointerStorer) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	s.reviews = append(s.reviews, review)
+	return nil
+}
+
+func (s *pointerStorer) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	s.reviewComments = append(s.reviewComments, comment)
+	return nil
+}
+
+func TestLoopPointers(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"owner": {"login": "git-fixtures"},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i1", "number": 1, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "i1c1"}, {"id": "i1c2"}]}},
+				{"id": "i2", "number": 2, "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "i2c1"}]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "p1", "number": 3,
+					"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "p1c1"}, {"id": "p1c2"}]},
+					"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"id": "p1r1", "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "p1r1c1"}, {"id": "p1r1c2"}]}},
+						{"id": "p1r2", "comments": {"pageInfo": {"hasNextPage": false}, "nodes": []}}
+					]}
+				},
+				{"id": "p2", "number": 4}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	storer := &pointerStorer{Memory: new(testutils.Memory)}
+	d := &Downloader{storer: storer, client: newTestClient(t, handler)}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	var ids []string
+	for _, issue := range storer.issues {
+		ids = append(ids, issue.Id)
+	}
+	for _, comment := range storer.issueComments {
+		ids = append(ids, comment.Id)
+	}
+	for _, pr := range storer.prs {
+		ids = append(ids, pr.Id)
+	}
+	for _, comment := range storer.prComments {
+		ids = append(ids, comment.Id)
+	}
+	for _, review := range storer.reviews {
+		ids = append(ids, review.Id)
+	}
+	for _, comment := range storer.reviewComments {
+		ids = append(ids, comment.Id)
+	}
+
+	require.Equal([]string{
+		"i1", "i2", "i1c1", "i1c2", "i2c1",
+		"p1", "p2", "p1c1", "p1c2",
+		"p1r1", "p1r2", "p1r1c1", "p1r1c2",
+	}, ids)
+}
+
+func TestReadme(t *testing.T) {
+	entries := `[
+		{"name": "LICENSE", "type": "blob"},
+		{"name": "README.md", "type": "tree"},
+		{"name": "README.rst", "type": "blob"},
+		{"name": "setup.py", "type": "blob"}
+	]`
+	var expressions []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "object(expression: $expression)") {
+			return `{"data": {"repository": {"id": "repo1", "name": "basic", "owner": {"login": "git-fixtures"}}}}`
+		}
+
+		expressions = append(expressions, variables["expression"])
+		switch variables["expression"] {
+		case "HEAD:":
+			if entries == "" {
+				return `{"data": {"node": {"object": null}}}`
+			}
+
+			return `{"data": {"node": {"object": {"entries": ` + entries + `}}}}`
+		case "HEAD:README.rst":
+			return `{"data": {"node": {"object": {"byteSize": 13, "isBinary": false, "text": "Basic\n=====\n"}}}}`
+		}
+
+		return `{"errors": [{"message": "unexpected expression"}]}`
+	}
+
+	require := require.New(t)
+
+	d, storer := newTestDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Nil(storer.Readme)
+	require.Empty(expressions)
+
+	d.ReadmeIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{"HEAD:", "HEAD:README.rst"}, expressions)
+	require.Equal(&store.Readme{Filename: "README.rst", Body: "Basic\n=====\n", ByteSize: 13}, storer.Readme)
+
+	// no README
+	entries = `[{"name": "LICENSE", "type": "blob"}]`
+	expressions = nil
+	d, storer = newTestDownloader(t, handler)
+	d.ReadmeIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{"HEAD:"}, expressions)
+	require.Nil(storer.Readme)
+
+	// empty repository
+	entries = ""
+	d, storer = newTestDownloader(t, handler)
+	d.ReadmeIncluded = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Nil(storer.Readme)
+}
+
+func TestReadmeFilename(t *testing.T) {
+	tests := []struct {
+		filenames []string
+		expected  string
+	}{
+		{nil, ""},
+		{[]string{"LICENSE", "readme-old.md", "READ.ME"}, ""},
+		{[]string{"LICENSE", "README"}, "README"},
+		{[]string{"README", "README.txt", "readme.rst"}, "readme.rst"},
+		{[]string{"README.rst", "README.md"}, "README.md"},
+		{[]string{"README.adoc", "README"}, "README"},
+		{[]string{"README.org", "README.adoc"}, "README.adoc"},
+		{[]string{"Readme.Markdown", "README.rst"}, "Readme.Markdown"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, readmeFilename(test.filenames), "%v", test.filenames)
+	}
+}
+
+func TestPageSizes(t *testing.T) {
+	var pages []interface{}
+	handler := func(query string, variables map[string]interface{}) string {
+		pages = append(pages, variables["issuesPage"], variables["issueCommentsPage"], variables["pullRequestReviewsPage"])
+		return `{"data": {"repository": {"name": "basic", "owner": {"login": "git-fixtures"}}}}`
+	}
+
+	require := require.New(t)
+
+	// the defaults
+	d, _ := newTestDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{float64(issuesPage), float64(issueCommentsPage), float64(pullRequestReviewsPage)}, pages)
+
+	pages = nil
+	d.PageSizes = PageSizes{Issues: 100, PullRequestReviews: 1}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{float64(100), float64(issueCommentsPage), float64(1)}, pages)
+}
+
+// TestPullRequestLabelsPageSize checks that the further pages of the labels
+// of a PR are requested with the labels page size, not the assignees one
+func TestPullRequestLabelsPageSize(t *testing.T) {
+	var labelsPages []interface{}
+	d, _ := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		if variables["labelsCursor"] != nil {
+			labelsPages = append(labelsPages, variables["labelsPage"])
+			return `{"data": {"node": {"labels": {"pageInfo": {"hasNextPage": false}, "nodes": [{"name": "bug"}]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"owner": {"login": "git-fixtures"},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "pr1",
+				"number": 1,
+				"labels": {"pageInfo": {"hasNextPage": true, "endCursor": "l1"}, "nodes": [{"name": "enhancement"}]}
+			}]}
+		}}}`
+	})
+
+	require := require.New(t)
+
+	d.PageSizes = PageSizes{Assignees: 3, Labels: 7}
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]interface{}{float64(7)}, labelsPages)
+}
+
+// cancelStorer cancels the download once the repository is saved
+type cancelStorer struct {
+	*testutils.Memory
+	cancel context.CancelFunc
+}
+
+func (s *cancelStorer) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	s.cancel()
+	return s.Memory.SaveRepository(repository, topics)
+}
+
+func TestContextCanceled(t *testing.T) {
+	var queries int
+	handler := func(query string, variables map[string]interface{}) string {
+		queries++
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"owner": {"login": "git-fixtures"},
+			"issues": {"pageInfo": {"hasNextPage": true, "endCursor": "i1"}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	// nothing is sent with a context already canceled
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	cancelCtx()
+
+	d, _ := newTestDownloader(t, handler)
+	err := d.DownloadRepository(ctx, "git-fixtures", "basic", 0)
+	require.Equal(context.Canceled, err)
+	require.Zero(queries)
+
+	// the pagination stops when the context is canceled between pages
+	ctx, cancel := context.WithCancel(context.Background())
+	d.storer = &cancelStorer{Memory: new(testutils.Memory), cancel: cancel}
+	err = d.DownloadRepository(ctx, "git-fixtures", "basic", 0)
+	require.Equal(context.Canceled, err)
+	require.Equal(1, queries)
+}
+
+// TestPendingReview checks that the submission time of a pending review is
+// saved as null, not as the zero time
+func TestPendingReview(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"owner": {"login": "git-fixtures"},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"number": 1,
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"databaseId": 10, "state": "COMMENTED", "submittedAt": "2019-10-01T10:00:00Z", "author": {"login": "alice"}},
+					{"databaseId": 11, "state": "PENDING", "submittedAt": null, "author": {"login": "alice"}}
+				]}
+			}]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	pr := m.Repos["git-fixtures"]["basic"].PRs[1]
+	require.Len(pr.Reviews, 2)
+	require.Equal(time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC), *pr.Reviews[0].SubmittedAt)
+	require.Nil(pr.Reviews[1].SubmittedAt)
+	require.Len(pr.ReviewTransitions, 2)
+	require.NotNil(pr.ReviewTransitions[0].SubmittedAt)
+	require.Nil(pr.ReviewTransitions[1].SubmittedAt)
+
+	var out bytes.Buffer
+	d.storer = store.NewJSONL(&out)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Contains(out.String(), `"SubmittedAt":"2019-10-01T10:00:00Z"`)
+	require.Contains(out.String(), `"SubmittedAt":null`)
+}
+
+func TestProgress(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "node(id:$id)") {
+			return `{"data": {"node": {"issues": {"pageInfo": {"hasNextPage": false, "endCursor": "i2"}, "nodes": [
+				{"number": 3}
+			]}}}}`
+		}
+
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"owner": {"login": "git-fixtures"},
+			"issues": {"pageInfo": {"hasNextPage": true, "endCursor": "i1"}, "nodes": [
+				{"number": 1},
+				{"number": 2}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 4}
+			]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	// no progress by default
+	d, _ := newTestDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	var events []ProgressEvent
+	d.ProgressFunc = func(ev ProgressEvent) { events = append(events, ev) }
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	issue := func(saved int, newPage bool, cursor string) ProgressEvent {
+		return ProgressEvent{Resource: ProgressIssues, RepositoryOwner: "git-fixtures", RepositoryName: "basic",
+			Saved: saved, NewPage: newPage, Cursor: cursor}
+	}
+
+	require.Equal([]ProgressEvent{
+		issue(1, false, ""),
+		issue(2, false, ""),
+		issue(2, true, "i1"),
+		issue(3, false, "i1"),
+		{Resource: ProgressPullRequests, RepositoryOwner: "git-fixtures", RepositoryName: "basic", Saved: 1},
+	}, events)
+}
+
+func TestResumeRepository(t *testing.T) {
+	var queries []string
+	failed := false
+	handler := func(query string, variables map[string]interface{}) string {
+		switch {
+		case variables["issuesCursor"] != nil:
+			queries = append(queries, fmt.Sprintf("issues %v", variables["issuesCursor"]))
+			return `{"data": {"node": {"issues": {"pageInfo": {"hasNextPage": false, "endCursor": "i2"}, "nodes": [
+				{"number": 2}
+			]}}}}`
+		case variables["pullRequestsCursor"] != nil:
+			queries = append(queries, fmt.Sprintf("PRs %v", variables["pullRequestsCursor"]))
+			if !failed {
+				failed = true
+				return `{"errors": [{"message": "502 Bad Gateway"}]}`
+			}
+
+			return `{"data": {"node": {"pullRequests": {"pageInfo": {"hasNextPage": false, "endCursor": "p2"}, "nodes": [
+				{"number": 11}
+			]}}}}`
+		}
+
+		queries = append(queries, "repository")
+		return `{"data": {"repository": {
+			"id": "repo1",
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": true, "endCursor": "i1"}, "nodes": [{"number": 1}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": true, "endCursor": "p1"}, "nodes": [{"number": 10}]}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestDownloader(t, handler)
+	s := &txStorer{Memory: m}
+	d.storer = s
+
+	checkpoint := &RepositoryCheckpoint{Version: 3}
+	err := d.ResumeRepository(context.TODO(), "git-fixtures", "basic", checkpoint)
+	require.Error(err)
+	require.Contains(err.Error(), "502 Bad Gateway")
+	require.Equal(&RepositoryCheckpoint{Version: 3, Phase: CheckpointPullRequests, Cursor: "p1"}, checkpoint)
+	require.Equal([]string{"repository", "issues i1", "PRs p1"}, queries)
+
+	// the repository, each page of issues, and the end of the issues are
+	// committed, the first page of PRs too before querying the next one, and
+	// only the page in flight is rolled back
+	require.Equal([]string{
+		"begin",
+		"repository git-fixtures/basic v3",
+		"commit", "begin",
+		"commit", "begin",
+		"commit", "begin",
+		"commit", "begin",
+		"rollback",
+	}, s.log)
+
+	// the download continues after the last saved page of PRs
+	queries = nil
+	require.NoError(d.ResumeRepository(context.TODO(), "git-fixtures", "basic", checkpoint))
+	require.Equal(&RepositoryCheckpoint{Version: 3, Done: true}, checkpoint)
+	require.Equal([]string{"repository", "PRs p1"}, queries)
+
+	// the repository is only saved by the first run
+	require.Equal(1, strings.Count(strings.Join(s.log, "\n"), "repository"))
+	require.Len(m.PRs, 2)
+
+	// a done checkpoint is not downloaded again
+	queries = nil
+	require.NoError(d.ResumeRepository(context.TODO(), "git-fixtures", "basic", checkpoint))
+	require.Empty(queries)
+}
+
+func TestEmptyDataRetry(t *testing.T) {
+	var delays []time.Duration
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	var repositoryQueries, organizationQueries int
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "organization(") {
+			organizationQueries++
+			if organizationQueries == 1 {
+				return `{"data": {"organization": null}}`
+			}
+
+			return `{"data": {"organization": {
+				"login": "src-d",
+				"membersWithRole": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+			}}}`
+		}
+
+		repositoryQueries++
+		if repositoryQueries <= 2 {
+			return `{"data": null}`
+		}
+
+		// a genuinely empty repository has no issues nor PRs, but it has a name
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal(3, repositoryQueries)
+	require.Len(delays, 2)
+	require.True(delays[1] > delays[0], "the delays must back off")
+	require.Contains(m.Repos["git-fixtures"], "basic")
+
+	delays = nil
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Equal(2, organizationQueries)
+	require.Len(delays, 1)
+	require.Contains(m.Organizations, "src-d")
+
+	// the empty responses are not retried forever
+	repositoryQueries = -100
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Error(err)
+	require.Contains(err.Error(), errEmptyData.Error())
+}
+
+func TestTemplates(t *testing.T) {
+	var templates string
+	handler := func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			` + templates + `
+		}}}`
+	}
+
+	require := require.New(t)
+
+	templates = `"issueTemplates": [
+		{"name": "Bug report", "filename": "bug_report.md", "title": "[BUG]", "about": "Create a report", "body": "**Describe the bug**"},
+		{"name": "Feature request", "filename": "feature_request.md", "title": "", "about": "Suggest an idea", "body": "**Describe the feature**"}
+	],
+	"pullRequestTemplates": [
+		{"filename": "PULL_REQUEST_TEMPLATE.md", "body": "Fixes #"}
+	]`
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Equal([]store.Template{{
+		Kind:     store.IssueTemplate,
+		Filename: "bug_report.md",
+		Name:     "Bug report",
+		Title:    "[BUG]",
+		About:    "Create a report",
+		Body:     "**Describe the bug**",
+	}, {
+		Kind:     store.IssueTemplate,
+		Filename: "feature_request.md",
+		Name:     "Feature request",
+		About:    "Suggest an idea",
+		Body:     "**Describe the feature**",
+	}, {
+		Kind:     store.PullRequestTemplate,
+		Filename: "PULL_REQUEST_TEMPLATE.md",
+		Body:     "Fixes #",
+	}}, m.Repos["git-fixtures"]["basic"].Templates)
+
+	// GitHub answers null for the repositories without templates
+	templates = `"issueTemplates": null, "pullRequestTemplates": null`
+
+	d, m = newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	require.Empty(m.Repos["git-fixtures"]["basic"].Templates)
+}
+
+// unchangedStorer is a txStorer with a saved version of git-fixtures/basic,
+// see Downloader.UnchangedSkipped
+type unchangedStorer struct {
+	*txStorer
+	saved     int
+	pushedAt  time.Time
+	updatedAt time.Time
+}
+
+func (s *unchangedStorer) LatestRepository(repositoryOwner, repositoryName string) (int, time.Time, time.Time, error) {
+	return s.saved, s.pushedAt, s.updatedAt, nil
+}
+
+func (s *unchangedStorer) CopyRepositoryForward(repositoryOwner, repositoryName string, from int) error {
+	s.log = append(s.log, fmt.Sprintf("copy %v/%v v%v to v%v", repositoryOwner, repositoryName, from, s.v))
+	return nil
+}
+
+func TestUnchangedSkipped(t *testing.T) {
+	require := require.New(t)
+
+	var queries []string
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "issues") {
+			queries = append(queries, "times")
+			return `{"data": {"repository": {"pushedAt": "2019-10-01T10:00:00Z", "updatedAt": "2019-10-02T10:00:00Z"}}}`
+		}
+
+		queries = append(queries, "repository")
+		return autoVersionRepository
+	}
+
+	storer := &unchangedStorer{
+		txStorer:  &txStorer{Memory: new(testutils.Memory)},
+		saved:     1,
+		pushedAt:  time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC),
+		updatedAt: time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC),
+	}
+	d := &Downloader{storer: storer, client: newTestClient(t, handler), UnchangedSkipped: true}
+
+	// an unchanged repository is not downloaded
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 2))
+	require.Equal([]string{"times"}, queries)
+	require.Nil(storer.Repository)
+	require.Empty(storer.log)
+
+	// and with UnchangedCopied its saved version is copied forward
+	queries = nil
+	d.UnchangedCopied = true
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 2))
+	require.Equal([]string{"times"}, queries)
+	require.Equal([]string{"begin", "copy git-fixtures/basic v1 to v2", "commit"}, storer.log)
+
+	// a pushed repository is fully downloaded
+	queries = nil
+	storer.log = nil
+	storer.pushedAt = storer.pushedAt.Add(-time.Hour)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 2))
+	require.Equal([]string{"times", "repository"}, queries)
+	require.Equal([]string{"begin", "repository git-fixtures/basic v2", "commit"}, storer.log)
+
+	// a repository never saved is fully downloaded without comparing times
+	queries = nil
+	storer.saved = 0
+	storer.Repository = nil
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 2))
+	require.Equal([]string{"repository"}, queries)
+	require.Equal("basic", storer.Repository.Name)
+
+	// store.Mem does not know its versions
+	m, _ := newTestMemDownloader(t, handler)
+	m.UnchangedSkipped = true
+	err := m.DownloadRepository(context.TODO(), "git-fixtures", "basic", 2)
+	require.Error(err)
+	require.Contains(err.Error(), "UnchangedSkipped is not supported")
+}
+
+// TestNodeIDs checks that Mem keeps the node ids of the issues, PRs, comments,
+// reviews and review comments, to correlate them with the GitHub nodes
+func TestNodeIDs(t *testing.T) {
+	d, m := newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "i1", "number": 1,
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "i1-c1"}]}
+			}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "pr2", "number": 2,
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "pr2-c1"}]},
+				"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+					"id": "pr2-r1", "databaseId": 10, "state": "COMMENTED",
+					"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "pr2-r1-c1"}]}
+				}]}
+			}]}
+		}}}`
+	})
+
+	require := require.New(t)
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	issue := repo.Issues[1]
+	require.Equal("i1", issue.Id)
+	require.Len(issue.Comments, 1)
+	require.Equal("i1-c1", issue.Comments[0].Id)
+
+	pr := repo.PRs[2]
+	require.Equal("pr2", pr.Id)
+	require.Len(pr.Comments, 1)
+	require.Equal("pr2-c1", pr.Comments[0].Id)
+	require.Len(pr.Reviews, 1)
+	require.Equal("pr2-r1", pr.Reviews[0].Id)
+	require.Len(pr.Reviews[0].Comments, 1)
+	require.Equal("pr2-r1-c1", pr.Reviews[0].Comments[0].Id)
+}
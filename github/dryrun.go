@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/src-d/metadata-retrieval/github/store"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// dryRun counts the queries of a dry run and their cost, see WithDryRun. It
+// is safe for concurrent use
+type dryRun struct {
+	mu      sync.Mutex
+	cost    int
+	queries int
+}
+
+// queryCost is the rateLimit field added to the queries of a dry run
+type queryCost struct {
+	Cost int
+}
+
+// query sends q with the rateLimit cost as an extra top level field, and
+// counts it. q is embedded in a new struct type with the extra field, the
+// embedded fields are inlined in the query and in its response
+func (r *dryRun) query(ctx context.Context, client *githubv4.Client, q interface{}, variables map[string]interface{}) error {
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "Query", Type: reflect.TypeOf(q).Elem(), Anonymous: true},
+		{Name: "DryRunCost", Type: reflect.TypeOf((*queryCost)(nil)), Tag: `graphql:"dryRunCost: rateLimit"`},
+	})
+
+	wrapped := reflect.New(typ)
+	err := client.Query(ctx, wrapped.Interface(), variables)
+	// the data of a response with errors is kept, like with q
+	reflect.ValueOf(q).Elem().Set(wrapped.Elem().Field(0))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queries++
+	if cost := wrapped.Elem().Field(1).Interface().(*queryCost); cost != nil {
+		r.cost += cost.Cost
+	}
+
+	return err
+}
+
+// WithDryRun makes the Downloader send the same queries, with the same
+// pagination, but save nothing: the store is replaced by store.Discard. Each
+// query also requests its rate limit cost, and DryRunCost returns the total,
+// to estimate the cost of a long download before running it, e.g. to split it
+// across several tokens. The API must report the rate limit, see
+// ErrRateLimitUnavailable.
+//
+// The features that read the store, like AutoVersion, UnchangedSkipped or
+// DownloadRepositoryDelta, are not supported by store.Discard
+func (d *Downloader) WithDryRun() *Downloader {
+	d.storer = store.Discard{}
+	d.dryRun = &dryRun{}
+	return d
+}
+
+// DryRunCost returns the total rate limit cost of the queries sent since
+// WithDryRun, and their number. Both are zero without WithDryRun
+func (d Downloader) DryRunCost() (cost int, queries int) {
+	if d.dryRun == nil {
+		return 0, 0
+	}
+
+	d.dryRun.mu.Lock()
+	defer d.dryRun.mu.Unlock()
+
+	return d.dryRun.cost, d.dryRun.queries
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/testutils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		if !strings.Contains(query, "dryRunCost: rateLimit{cost}") {
+			return `{"errors": [{"message": "the cost is not requested"}]}`
+		}
+
+		if variables["issuesCursor"] != nil {
+			return `{"data": {"dryRunCost": {"cost": 2}, "node": {
+				"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "i2", "number": 2}]}
+			}}}`
+		}
+
+		return `{"data": {"dryRunCost": {"cost": 5}, "repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": true, "endCursor": "e1"}, "nodes": [{"id": "i1", "number": 1}]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+
+	require := require.New(t)
+
+	storer := new(testutils.Memory)
+	d := &Downloader{storer: storer, client: newTestClientWithRulesets(t, handler,
+		`{"data": {"dryRunCost": {"cost": 1}, "node": {"rulesets": {"pageInfo": {"hasNextPage": false}, "nodes": []}}}}`)}
+
+	cost, queries := d.DryRunCost()
+	require.Zero(cost)
+	require.Zero(queries)
+
+	d.WithDryRun()
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// the pagination is the same, nothing is saved
+	cost, queries = d.DryRunCost()
+	require.Equal(8, cost)
+	require.Equal(3, queries)
+	require.Nil(storer.Repository)
+}
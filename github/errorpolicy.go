@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// ErrorPolicy decides what DownloadRepository does when an issue or PR can
+// not be downloaded, see Downloader.ErrorPolicy
+type ErrorPolicy int
+
+const (
+	// Abort returns the error of the first issue or PR that fails, and rolls
+	// back the whole download. It is the default
+	Abort ErrorPolicy = iota
+	// Continue logs the issue or PR that fails, skips it and goes on with
+	// the rest. The download is committed without the skipped ones, and then
+	// a *MultiError is returned with an EntityError for each of them. The
+	// store must support savepoints, like store.DB, to undo the saves of the
+	// skipped ones, and it can not be used with Concurrency
+	Continue
+)
+
+// savepointer is implemented by the stores that can undo part of a
+// transaction, see Downloader.ErrorPolicy
+type savepointer interface {
+	Savepoint() error
+	RollbackToSavepoint() error
+	ReleaseSavepoint() error
+}
+
+// skipped are the issues and PRs skipped by the current download with the
+// Continue ErrorPolicy
+type skipped struct {
+	// savepoints undo the saves of a skipped issue or PR
+	savepoints savepointer
+
+	mu   sync.Mutex
+	errs MultiError
+}
+
+// errContinueConcurrency is returned by the downloads with the Continue
+// ErrorPolicy and Concurrency: a savepoint would undo the saves of the other
+// issues and PRs in flight too
+var errContinueConcurrency = errors.New("the Continue ErrorPolicy can not be used with Concurrency")
+
+// errContinueSavepoints is returned by the downloads with the Continue
+// ErrorPolicy and a store without savepoints: the partial saves of the
+// skipped issues and PRs would be committed
+var errContinueSavepoints = errors.New("the Continue ErrorPolicy needs a store with savepoints")
+
+// newSkipped returns the skipped items of a new download with the Continue
+// ErrorPolicy, or nil with Abort. It must be called before the storer of the
+// Downloader is wrapped
+func (d Downloader) newSkipped() (*skipped, error) {
+	if d.ErrorPolicy != Continue {
+		return nil, nil
+	}
+
+	if d.Concurrency > 1 {
+		return nil, errContinueConcurrency
+	}
+
+	sp, ok := d.storer.(savepointer)
+	if !ok {
+		return nil, errContinueSavepoints
+	}
+
+	return &skipped{savepoints: sp}, nil
+}
+
+// err returns the skipped items as a *MultiError, or nil if there are none
+func (s *skipped) err() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.errs.ErrorOrNil()
+}
+
+// tolerate calls fn, which downloads the issue or PR of the given kind and
+// number. With the Continue ErrorPolicy, its error is logged and added to the
+// skipped items instead of returned, and its partial saves are undone. A
+// canceled context and an exceeded rate limit still abort the download, the
+// next items would fail too
+func (d Downloader) tolerate(ctx context.Context, kind string, owner string, name string, number int, fn func() error) error {
+	s := d.skipped
+	if s == nil {
+		return fn()
+	}
+
+	if err := s.savepoints.Savepoint(); err != nil {
+		return err
+	}
+
+	err := fn()
+	if err == nil {
+		return s.savepoints.ReleaseSavepoint()
+	}
+
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if isRateLimitError(err) {
+		return err
+	}
+
+	if rbErr := s.savepoints.RollbackToSavepoint(); rbErr != nil {
+		return fmt.Errorf("%v, and could not undo its saves: %v", err, rbErr)
+	}
+
+	id := fmt.Sprintf("%v/%v#%v", owner, name, number)
+	log.Warningf("skipping %v %v: %v", kind, id, err)
+
+	s.mu.Lock()
+	s.errs.Add(&EntityError{Kind: kind, ID: id, Err: err})
+	s.mu.Unlock()
+
+	return nil
+}
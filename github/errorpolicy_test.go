@@ -0,0 +1,139 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/store"
+
+	"github.com/stretchr/testify/require"
+)
+
+// savepointMemory records the savepoint calls, see ErrorPolicy
+type savepointMemory struct {
+	*store.Mem
+	calls []string
+}
+
+func (s *savepointMemory) Savepoint() error {
+	s.calls = append(s.calls, "savepoint")
+	return nil
+}
+
+func (s *savepointMemory) RollbackToSavepoint() error {
+	s.calls = append(s.calls, "rollback")
+	return nil
+}
+
+func (s *savepointMemory) ReleaseSavepoint() error {
+	s.calls = append(s.calls, "release")
+	return nil
+}
+
+// errorPolicyHandler returns 3 issues with a second page of comments, and
+// fails the page of the given issues
+func errorPolicyHandler(failed ...string) graphqlHandler {
+	return func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "comments(first: $issueCommentsPage") && variables["issueCommentsCursor"] != nil {
+			id := variables["id"].(string)
+			for _, f := range failed {
+				if f == id {
+					return `{"errors": [{"message": "Something went wrong while executing your query."}]}`
+				}
+			}
+
+			return `{"data": {"node": {"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "` + id + `-c2", "databaseId": 2}
+			]}}}}`
+		}
+
+		var nodes []string
+		for i := 1; i <= 3; i++ {
+			nodes = append(nodes, fmt.Sprintf(`{"id": "i%v", "number": %v, "comments": {"pageInfo": {"hasNextPage": true, "endCursor": "e1"}, "nodes": [
+				{"id": "i%v-c1", "databaseId": 1}
+			]}}`, i, i, i))
+		}
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [` + strings.Join(nodes, ",") + `]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	require := require.New(t)
+
+	// Abort is the default
+	d, _ := newTestDownloader(t, errorPolicyHandler("i2"))
+	err := d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Error(err)
+	require.Contains(err.Error(), "failed to process issue git-fixtures/basic #2")
+
+	// Continue skips the failed issues, undoes their saves and reports them
+	storer := &savepointMemory{Mem: store.NewMem()}
+	d = &Downloader{storer: storer, client: newTestClient(t, errorPolicyHandler("i2", "i3"))}
+	d.ErrorPolicy = Continue
+	err = d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Error(err)
+
+	multi, ok := err.(*MultiError)
+	require.True(ok, "%T is not a *MultiError", err)
+	skipped := multi.Filter("issue")
+	require.Len(skipped, 2)
+	require.Equal("git-fixtures/basic#2", skipped[0].ID)
+	require.Equal("git-fixtures/basic#3", skipped[1].ID)
+
+	require.Len(storer.Repos["git-fixtures"]["basic"].Issues[1].Comments, 2)
+	require.Equal([]string{
+		"savepoint", "release",
+		"savepoint", "rollback",
+		"savepoint", "rollback",
+	}, storer.calls)
+
+	// without failures there is no error
+	d = &Downloader{storer: &savepointMemory{Mem: store.NewMem()}, client: newTestClient(t, errorPolicyHandler())}
+	d.ErrorPolicy = Continue
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	// the savepoints would undo the saves of the other workers
+	d.Concurrency = 4
+	err = d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Equal(errContinueConcurrency, err)
+
+	// without savepoints the saves of the failed issues would be kept
+	mem := store.NewMem()
+	d = &Downloader{storer: mem, client: newTestClient(t, errorPolicyHandler("i2"))}
+	d.ErrorPolicy = Continue
+	err = d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0)
+	require.Equal(errContinueSavepoints, err)
+	require.Empty(mem.Repos)
+}
+
+func TestTolerateRateLimit(t *testing.T) {
+	require := require.New(t)
+
+	// without a client: the rate limit must not be queried
+	d := Downloader{skipped: &skipped{savepoints: &savepointMemory{Mem: store.NewMem()}}}
+
+	rateErr := errors.New("failed to query issue comments: API rate limit exceeded for user ID 1.")
+	err := d.tolerate(context.TODO(), "issue", "git-fixtures", "basic", 1, func() error {
+		return rateErr
+	})
+	require.Equal(rateErr, err)
+
+	wrapped := fmt.Errorf("failed to process issue: %w", &RateLimitError{Err: rateErr})
+	err = d.tolerate(context.TODO(), "issue", "git-fixtures", "basic", 2, func() error {
+		return wrapped
+	})
+	require.Equal(wrapped, err)
+
+	require.NoError(d.skipped.err())
+}
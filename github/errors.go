@@ -0,0 +1,244 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EntityError is the failure to download or save a single entity
+type EntityError struct {
+	// Kind of the entity, e.g. "repository" or "issue"
+	Kind string
+	// ID identifies the entity, e.g. "src-d/gitbase" or "src-d/gitbase#12"
+	ID  string
+	Err error
+}
+
+func (e *EntityError) Error() string {
+	return fmt.Sprintf("%v %v: %v", e.Kind, e.ID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *EntityError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError aggregates the failures of several entities, so a process can
+// go on after one of them fails and report all of them at the end. The
+// zero value is an empty MultiError ready to use
+type MultiError struct {
+	errs []error
+}
+
+// Add appends err to the failures, nil errors are ignored
+func (m *MultiError) Add(err error) {
+	if err != nil {
+		m.errs = append(m.errs, err)
+	}
+}
+
+// Len returns the number of failures
+func (m *MultiError) Len() int {
+	return len(m.errs)
+}
+
+// Errors returns the failures in the order they were added
+func (m *MultiError) Errors() []error {
+	return append([]error(nil), m.errs...)
+}
+
+// Filter returns the failures of the entities of the given kind. Failures
+// that are not an EntityError have no kind, and are never returned
+func (m *MultiError) Filter(kind string) []*EntityError {
+	var res []*EntityError
+	for _, err := range m.errs {
+		if e, ok := err.(*EntityError); ok && e.Kind == kind {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
+
+// Is returns true if any of the failures matches target, see errors.Is
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first failure that matches target, see errors.As
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ErrorOrNil returns m if it has any failure, or nil. Use it to return a
+// MultiError as an error, a nil *MultiError is not a nil error
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.errs) == 0 {
+		return nil
+	}
+
+	return m
+}
+
+func (m *MultiError) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%v errors: %v", len(m.errs), strings.Join(msgs, "; "))
+}
+
+// RateLimitError is returned by the downloads that fail because the rate limit
+// is exhausted. Callers can wait until ResetAt and download again
+type RateLimitError struct {
+	// ResetAt is the time, in UTC, when the rate limit is reset, zero if the
+	// API does not report it
+	ResetAt time.Time
+	Err     error
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// NotFoundError is returned by the downloads of a repository, organization,
+// user, issue or PR that does not exist, or is not visible to the token.
+// Downloading it again will fail too
+type NotFoundError struct {
+	// Kind of the missing entity: "repository", "organization", "user",
+	// "issue" or "pull request"
+	Kind string
+	Err  error
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// TransientError is returned by the downloads that fail with an error that
+// may not happen again, e.g. a network error or a 5xx status still failing
+// after the retries, or a secondary rate limit. Callers can download again
+// later
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// notFoundRegexp matches the GraphQL NOT_FOUND errors of repositories,
+// organizations, users, issues and PRs, e.g. "Could not resolve to a
+// Repository with the name 'src-d/missing'."
+var notFoundRegexp = regexp.MustCompile(`Could not resolve to an? (Repository|Organization|User|Issue|PullRequest)\b`)
+
+// notFoundKinds are the NotFoundError kinds of the GraphQL types
+var notFoundKinds = map[string]string{
+	"Repository":   "repository",
+	"Organization": "organization",
+	"User":         "user",
+	"Issue":        "issue",
+	"PullRequest":  "pull request",
+}
+
+// transientMessages are the messages of the errors that may not happen again.
+// The errors of the queries are wrapped as text, so their type is lost
+var transientMessages = []string{
+	"non-200 OK status code: 5",
+	"secondary rate limit",
+	"abuse detection",
+	"Something went wrong while executing your query",
+	errEmptyData.Error(),
+	"connection reset",
+	"connection refused",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"unexpected EOF",
+}
+
+// isRateLimitError returns whether err is a *RateLimitError, or the error of
+// a query that exceeded the rate limit, without querying the API
+func isRateLimitError(err error) bool {
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(err.Error()), "api rate limit exceeded")
+}
+
+// classifyError returns err as a *RateLimitError, *NotFoundError or
+// *TransientError according to its message, or err itself if it is none of
+// them, it is a context error or it is already classified. The reset time of
+// a rate limit is queried with RateLimit. A *MultiError, e.g. of the items
+// skipped with the Continue ErrorPolicy, is returned as is
+func (d Downloader) classifyError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	switch err.(type) {
+	case *RateLimitError, *NotFoundError, *TransientError, *MultiError:
+		return err
+	}
+
+	msg := err.Error()
+	switch {
+	case isRateLimitError(err):
+		// the rate limit query would wait for the reset
+		d.rateGuard = nil
+		rateErr := &RateLimitError{Err: err}
+		if rate, err := d.RateLimit(ctx); err == nil {
+			rateErr.ResetAt = rate.ResetAt
+		}
+
+		return rateErr
+	case notFoundRegexp.MatchString(msg):
+		kind := notFoundRegexp.FindStringSubmatch(msg)[1]
+		return &NotFoundError{Kind: notFoundKinds[kind], Err: err}
+	}
+
+	for _, s := range transientMessages {
+		if strings.Contains(msg, s) {
+			return &TransientError{Err: err}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/testutils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiError(t *testing.T) {
+	require := require.New(t)
+
+	var m MultiError
+	require.NoError(m.ErrorOrNil())
+
+	m.Add(nil)
+	m.Add(&EntityError{Kind: "repository", ID: "src-d/gitbase", Err: context.DeadlineExceeded})
+	m.Add(&EntityError{Kind: "issue", ID: "src-d/go-git#12", Err: fmt.Errorf("not found")})
+	m.Add(&EntityError{Kind: "repository", ID: "src-d/lookout", Err: fmt.Errorf("forbidden")})
+	m.Add(fmt.Errorf("rate limit exceeded"))
+
+	require.Equal(4, m.Len())
+	require.Len(m.Errors(), 4)
+
+	var ids []string
+	for _, e := range m.Filter("repository") {
+		ids = append(ids, e.ID)
+	}
+	require.Equal([]string{"src-d/gitbase", "src-d/lookout"}, ids)
+	require.Empty(m.Filter("user"))
+
+	err := m.ErrorOrNil()
+	require.Error(err)
+	require.Equal("4 errors: "+
+		"repository src-d/gitbase: context deadline exceeded; "+
+		"issue src-d/go-git#12: not found; "+
+		"repository src-d/lookout: forbidden; "+
+		"rate limit exceeded", err.Error())
+
+	// errors.As finds the first EntityError, and errors.Is the wrapped causes
+	var entityErr *EntityError
+	require.True(errors.As(err, &entityErr))
+	require.Equal("src-d/gitbase", entityErr.ID)
+	require.True(errors.Is(err, context.DeadlineExceeded))
+
+	var multi *MultiError
+	require.True(errors.As(fmt.Errorf("download failed: %w", err), &multi))
+	require.Equal(4, multi.Len())
+}
+
+func TestClassifyError(t *testing.T) {
+	require := require.New(t)
+
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "rateLimit") {
+			return `{"data": {"rateLimit": {"cost": 1, "limit": 5000, "remaining": 0, "resetAt": "2019-10-01T11:00:00Z"}}}`
+		}
+
+		switch variables["name"] {
+		case "missing":
+			return `{"data": {"repository": null}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a Repository with the name 'src-d/missing'."}]}`
+		case "limited":
+			return `{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded for user ID 1."}]}`
+		default:
+			return `{"errors": [{"message": "Something went wrong while executing your query."}]}`
+		}
+	}
+
+	d := &Downloader{storer: new(testutils.Memory), client: newTestClient(t, handler)}
+
+	err := d.DownloadRepository(context.TODO(), "src-d", "missing", 0)
+	var notFound *NotFoundError
+	require.True(errors.As(err, &notFound))
+	require.Equal("repository", notFound.Kind)
+	require.Contains(err.Error(), "Could not resolve to a Repository")
+
+	err = d.DownloadRepository(context.TODO(), "src-d", "limited", 0)
+	var rateErr *RateLimitError
+	require.True(errors.As(err, &rateErr))
+	require.Equal(time.Date(2019, 10, 1, 11, 0, 0, 0, time.UTC), rateErr.ResetAt)
+
+	// the classified errors are found in the failures of a batch too
+	err = d.DownloadRepositories(context.TODO(), 0, []RepoRef{{Owner: "src-d", Name: "gitbase"}})
+	var transient *TransientError
+	require.True(errors.As(err, &transient))
+
+	for msg, transient := range map[string]bool{
+		"failed to query issues: non-200 OK status code: 502 Bad Gateway body: \"\"":  true,
+		"read tcp 10.0.0.1:443: connection reset by peer":                             true,
+		"You have exceeded a secondary rate limit":                                    true,
+		"failed to query issues: non-200 OK status code: 401 Unauthorized body: \"\"": false,
+		"Could not resolve to a node with the global id of 'x'":                       false,
+	} {
+		err := d.classifyError(context.TODO(), errors.New(msg))
+		_, ok := err.(*TransientError)
+		require.Equal(transient, ok, msg)
+	}
+
+	require.Equal(context.Canceled, d.classifyError(context.TODO(), context.Canceled))
+	require.NoError(d.classifyError(context.TODO(), nil))
+}
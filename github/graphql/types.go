@@ -8,6 +8,13 @@ type PageInfo struct {
 	EndCursor   string
 }
 
+// BackwardPageInfo is the PageInfo of a connection paginated with last and
+// before, from the newest nodes to the oldest ones
+type BackwardPageInfo struct {
+	HasPreviousPage bool
+	StartCursor     string
+}
+
 // Organization represents https://developer.github.com/v4/object/organization/
 type Organization struct {
 	OrganizationFields
@@ -43,6 +50,181 @@ type OrganizationFields struct {
 	UpdatedAt string // updated_at timestamptz,
 }
 
+// AuditLogEntryConnection represents https://developer.github.com/v4/object/organizationauditentryconnection/
+type AuditLogEntryConnection struct {
+	PageInfo PageInfo
+	Nodes    []AuditLogEntry
+} // `graphql:"auditLog(first: $auditLogPage, after: $auditLogCursor)"`
+
+// AuditLogEntry represents https://developer.github.com/v4/union/organizationauditentry/
+// Only the fields of the AuditEntry interface, shared by all the entry types,
+// are downloaded
+type AuditLogEntry struct {
+	Typename string `graphql:"__typename"` // entry_type text NOT NULL,
+	Node     struct {
+		Id string // node_id text,
+	} `graphql:"... on Node"`
+	AuditEntry struct {
+		Action        string    // action text NOT NULL,
+		ActorIp       string    // actor_ip text,
+		ActorLogin    string    // actor_login text,
+		CreatedAt     time.Time // created_at timestamptz,
+		OperationType string    // operation_type text,
+		UserLogin     string    // user_login text,
+	} `graphql:"... on AuditEntry"`
+}
+
+// OrgProjectConnection represents https://docs.github.com/en/graphql/reference/objects#projectv2connection
+type OrgProjectConnection struct {
+	PageInfo PageInfo
+	Nodes    []OrgProject
+} // `graphql:"projectsV2(first: $projectsV2Page, after: $projectsV2Cursor)"`
+
+// OrgProject represents https://docs.github.com/en/graphql/reference/objects#projectv2
+// owned by an organization. Only the first page of items is requested with
+// the project, the rest are appended by the downloader before saving it
+type OrgProject struct {
+	Closed    bool      // closed boolean,
+	CreatedAt time.Time // created_at timestamptz,
+	Fields    struct {
+		Nodes []struct {
+			Common struct {
+				DataType string
+				Name     string
+			} `graphql:"... on ProjectV2FieldCommon"`
+		}
+	} `graphql:"fields(first: 50)"` // field_names text[] NOT NULL, field_data_types text[] NOT NULL,
+	Id               string                   // node_id text NOT NULL,
+	Items            OrgProjectItemConnection `graphql:"items(first: $projectV2ItemsPage)"`
+	Number           int                      // number bigint NOT NULL,
+	Public           bool                     // public boolean,
+	ShortDescription string                   // short_description text,
+	Title            string                   // title text NOT NULL,
+	UpdatedAt        time.Time                // updated_at timestamptz,
+	Url              string                   // url text,
+}
+
+// FieldNames returns the name of each field of the project
+func (p *OrgProject) FieldNames() []string {
+	names := make([]string, len(p.Fields.Nodes))
+	for i, field := range p.Fields.Nodes {
+		names[i] = field.Common.Name
+	}
+
+	return names
+}
+
+// FieldDataTypes returns the data type of each field of the project, in the
+// same order as FieldNames
+func (p *OrgProject) FieldDataTypes() []string {
+	types := make([]string, len(p.Fields.Nodes))
+	for i, field := range p.Fields.Nodes {
+		types[i] = field.Common.DataType
+	}
+
+	return types
+}
+
+// OrgProjectItemConnection represents https://docs.github.com/en/graphql/reference/objects#projectv2itemconnection
+type OrgProjectItemConnection struct {
+	PageInfo PageInfo
+	Nodes    []OrgProjectItem
+} // `graphql:"items(first: $projectV2ItemsPage, after: $projectV2ItemsCursor)"`
+
+// OrgProjectItem represents https://docs.github.com/en/graphql/reference/objects#projectv2item
+// of an organization project. Its content is an issue, a pull request or a
+// draft issue, and it is empty if the item is redacted
+type OrgProjectItem struct {
+	Content struct {
+		DraftIssue struct {
+			Id    string
+			Title string
+		} `graphql:"... on DraftIssue"`
+		Issue struct {
+			Id         string
+			Number     int
+			Repository struct {
+				NameWithOwner string
+			}
+			Title string
+		} `graphql:"... on Issue"`
+		PullRequest struct {
+			Id         string
+			Number     int
+			Repository struct {
+				NameWithOwner string
+			}
+			Title string
+		} `graphql:"... on PullRequest"`
+	} // content_node_id text, content_number bigint, content_repository text, content_title text,
+	CreatedAt  time.Time // created_at timestamptz,
+	Id         string    // node_id text NOT NULL,
+	IsArchived bool      // archived boolean,
+	// Status is the value of the Status field of the project, see ProjectItem
+	Status struct {
+		SingleSelect struct {
+			Name      string    // status text,
+			UpdatedAt time.Time // status_updated_at timestamptz,
+		} `graphql:"... on ProjectV2ItemFieldSingleSelectValue"`
+	} `graphql:"status: fieldValueByName(name: \"Status\")"`
+	Type      string    // item_type text NOT NULL,
+	UpdatedAt time.Time // updated_at timestamptz,
+}
+
+// ContentNodeID returns the node id of the content of the item
+func (i *OrgProjectItem) ContentNodeID() string {
+	switch i.Type {
+	case "ISSUE":
+		return i.Content.Issue.Id
+	case "PULL_REQUEST":
+		return i.Content.PullRequest.Id
+	case "DRAFT_ISSUE":
+		return i.Content.DraftIssue.Id
+	}
+
+	return ""
+}
+
+// ContentNumber returns the number of the issue or pull request of the item,
+// or 0 for other contents
+func (i *OrgProjectItem) ContentNumber() int {
+	switch i.Type {
+	case "ISSUE":
+		return i.Content.Issue.Number
+	case "PULL_REQUEST":
+		return i.Content.PullRequest.Number
+	}
+
+	return 0
+}
+
+// ContentRepository returns the owner/name of the repository of the issue or
+// pull request of the item, or an empty string for other contents
+func (i *OrgProjectItem) ContentRepository() string {
+	switch i.Type {
+	case "ISSUE":
+		return i.Content.Issue.Repository.NameWithOwner
+	case "PULL_REQUEST":
+		return i.Content.PullRequest.Repository.NameWithOwner
+	}
+
+	return ""
+}
+
+// ContentTitle returns the title of the content of the item
+func (i *OrgProjectItem) ContentTitle() string {
+	switch i.Type {
+	case "ISSUE":
+		return i.Content.Issue.Title
+	case "PULL_REQUEST":
+		return i.Content.PullRequest.Title
+	case "DRAFT_ISSUE":
+		return i.Content.DraftIssue.Title
+	}
+
+	return ""
+}
+
 // OrganizationMemberConnection represents https://developer.github.com/v4/object/organizationmemberconnection/
 type OrganizationMemberConnection struct {
 	TotalCount int
@@ -98,10 +280,29 @@ type UserExtended struct {
 type Repository struct {
 	RepositoryFields
 	RepositoryTopics RepositoryTopicsConnection `graphql:"repositoryTopics(first: $repositoryTopicsPage, after: $repositoryTopicsCursor)"`
-	Issues           IssueConnection            `graphql:"issues(first: $issuesPage, after: $issuesCursor)"`
-	PullRequests     PullRequestConnection      `graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor)"`
+	Issues           IssueConnection            `graphql:"issues(first: $issuesPage, after: $issuesCursor, orderBy: $issuesOrder)"`
+	PullRequests     PullRequestConnection      `graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor, orderBy: $pullRequestsOrder, states: $pullRequestStates)"`
+	// IssueTemplates and PullRequestTemplates are lists, not connections,
+	// repositories only have a few of them
+	IssueTemplates       []IssueTemplate
+	PullRequestTemplates []PullRequestTemplate
 } // `graphql:"repository(owner: $owner, name: $name)"`
 
+// IssueTemplate represents https://docs.github.com/en/graphql/reference/objects#issuetemplate
+type IssueTemplate struct {
+	Name     string // name text NOT NULL,
+	Filename string // filename text NOT NULL,
+	Title    string // title text NOT NULL,
+	About    string // about text NOT NULL,
+	Body     string // body text NOT NULL,
+}
+
+// PullRequestTemplate represents https://docs.github.com/en/graphql/reference/objects#pullrequesttemplate
+type PullRequestTemplate struct {
+	Filename string // filename text NOT NULL,
+	Body     string // body text NOT NULL,
+}
+
 // RepositoryFields defines the fields for Repository
 // https://developer.github.com/v4/object/repository/
 type RepositoryFields struct {
@@ -112,7 +313,13 @@ type RepositoryFields struct {
 	Url                string    // clone_url text
 	CreatedAt          time.Time // created_at timestamptz
 	DefaultBranchRef   struct {
-		Name string // default_branch text
+		Name   string // default_branch text
+		Target struct {
+			Commit struct {
+				Oid           string    // default_branch_sha text
+				CommittedDate time.Time // default_branch_committed_at timestamptz
+			} `graphql:"... on Commit"`
+		}
 	}
 	Description      string // description text
 	IsDisabled       bool   // disabled boolean
@@ -154,6 +361,49 @@ type RepositoryFields struct {
 	Watchers  struct {
 		TotalCount int // watchers_count bigint
 	}
+
+	// OpenGraphImageUrl is the social preview image, the one generated by
+	// GitHub unless UsesCustomOpenGraphImage
+	OpenGraphImageUrl        string // open_graph_image_url text
+	UsesCustomOpenGraphImage bool   // uses_custom_open_graph_image boolean
+}
+
+// RepositoryRulesetConnection represents https://developer.github.com/v4/object/repositoryrulesetconnection/
+type RepositoryRulesetConnection struct {
+	PageInfo PageInfo
+	Nodes    []RepositoryRuleset
+} // `graphql:"rulesets(first: $rulesetsPage, after: $rulesetsCursor)"`
+
+// RepositoryRuleset represents https://developer.github.com/v4/object/repositoryruleset/
+type RepositoryRuleset struct {
+	Conditions struct {
+		RefName struct {
+			Exclude []string // conditions_exclude text[] NOT NULL,
+			Include []string // conditions_include text[] NOT NULL,
+		}
+	}
+	CreatedAt   time.Time // created_at timestamptz,
+	Enforcement string    // enforcement text,
+	DatabaseId  int       // id bigint,
+	Name        string    // name text,
+	Id          string    // node_id text,
+	Rules       struct {
+		Nodes []struct {
+			Type string // rule_types text[] NOT NULL,
+		}
+	} `graphql:"rules(first: $rulesPage)"`
+	Target    string    // target text,
+	UpdatedAt time.Time // updated_at timestamptz,
+}
+
+// RuleTypes returns the type of each rule of the ruleset
+func (r *RepositoryRuleset) RuleTypes() []string {
+	types := make([]string, len(r.Rules.Nodes))
+	for i, rule := range r.Rules.Nodes {
+		types[i] = rule.Type
+	}
+
+	return types
 }
 
 // RepositoryTopicsConnection represents https://developer.github.com/v4/object/repositorytopicconnection/
@@ -168,9 +418,19 @@ type RepositoryTopicsConnection struct {
 
 // IssueConnection represents https://developer.github.com/v4/object/issueconnection/
 type IssueConnection struct {
-	PageInfo PageInfo
-	Nodes    []Issue
-} //`graphql:"issues(first: $issuesPage, after: $issuesCursor)"`
+	TotalCount int
+	PageInfo   PageInfo
+	Nodes      []Issue
+} //`graphql:"issues(first: $issuesPage, after: $issuesCursor, orderBy: $issuesOrder)"`
+
+// NodeIDConnection is any connection of nodes, requesting only their ids
+type NodeIDConnection struct {
+	TotalCount int
+	PageInfo   PageInfo
+	Nodes      []struct {
+		Id string
+	}
+}
 
 type IssueCommentsConnection struct {
 	TotalCount int
@@ -178,6 +438,13 @@ type IssueCommentsConnection struct {
 	Nodes      []IssueComment
 } // `graphql:"comments(first: $issueCommentsPage, after: $issueCommentsCursor)"`
 
+// IssueCommentsBackwardConnection is an IssueCommentsConnection paginated
+// newest-first
+type IssueCommentsBackwardConnection struct {
+	PageInfo BackwardPageInfo
+	Nodes    []IssueComment
+} // `graphql:"comments(last: $issueCommentsPage, before: $issueCommentsCursor)"`
+
 // Issue represents https://developer.github.com/v4/object/issue/
 type Issue struct {
 	IssueFields
@@ -185,8 +452,31 @@ type Issue struct {
 	Labels    LabelConnection         `graphql:"labels(first: $labelsPage, after: $labelsCursor)"`
 	Comments  IssueCommentsConnection `graphql:"comments(first: $issueCommentsPage, after: $issueCommentsCursor)"`
 	ClosedBy  ClosedByConnection      `graphql:"timelineItems(last:1, itemTypes:CLOSED_EVENT)"`
+	LockedBy  LockedByConnection      `graphql:"lockedBy: timelineItems(last:1, itemTypes:LOCKED_EVENT)"`
+
+	AssignmentEvents AssignmentEventConnection `graphql:"assignmentEvents: timelineItems(first: $assignmentEventsPage, after: $assignmentEventsCursor, itemTypes: [ASSIGNED_EVENT, UNASSIGNED_EVENT])"`
+	TimelineItems    IssueEventConnection      `graphql:"timelineEvents: timelineItems(first: $timelineItemsPage, after: $timelineItemsCursor, itemTypes: [LABELED_EVENT, UNLABELED_EVENT, CLOSED_EVENT, REOPENED_EVENT, CROSS_REFERENCED_EVENT]) @include(if: $timelineEventsIncluded)"`
+	// ClosedByPullRequests are the PRs that close the issue when merged
+	ClosedByPullRequests ClosingReferenceConnection `graphql:"closingReferences: closedByPullRequestsReferences(first: $closingReferencesPage, after: $closingReferencesCursor, includeClosedPrs: true)"`
 } // `graphql:"issue(number: $issueNumber)"`
 
+// ClosingReferenceConnection is the connection of the PRs that close an issue,
+// https://developer.github.com/v4/object/pullrequestconnection/, or of the
+// issues closed by a PR, https://developer.github.com/v4/object/issueconnection/
+// Only the number and the repository of each node are requested
+type ClosingReferenceConnection struct {
+	PageInfo PageInfo
+	Nodes    []struct {
+		Number     int
+		Repository struct {
+			Name  string
+			Owner struct {
+				Login string
+			}
+		}
+	}
+} // `graphql:"closingReferences: closedByPullRequestsReferences(first: $closingReferencesPage, after: $closingReferencesCursor, includeClosedPrs: true)"`
+
 // User represents https://developer.github.com/v4/object/user/
 type User struct {
 	DatabaseId int
@@ -200,6 +490,49 @@ type Actor struct {
 	User     `graphql:"... on User"`
 }
 
+// GhostLogin is the login GitHub shows in place of the deleted accounts, the
+// API returns them as a null actor
+const GhostLogin = "ghost"
+
+// LoginOrGhost returns the login of the actor, or GhostLogin if the actor was
+// null because its account was deleted
+func (a *Actor) LoginOrGhost() string {
+	if a.Login == "" {
+		return GhostLogin
+	}
+
+	return a.Login
+}
+
+// Milestone represents https://developer.github.com/v4/object/milestone/
+// A null milestone, most issues and PRs have none, is decoded as the zero
+// Milestone, with an empty Id
+type Milestone struct {
+	Id    string    // milestone_id text NOT NULL,
+	Title string    // milestone_title text NOT NULL,
+	State string    // milestone_state text NOT NULL,
+	DueOn time.Time // milestone_due_on timestamptz, zero if it has no due date
+}
+
+// Edit is the last edit of the body of an issue, PR, comment, review or
+// review comment, from https://developer.github.com/v4/interface/comment/
+// EditedAt is zero if the body was never edited. LastEditedBy is null, the
+// zero Actor, if it was never edited or the editor account was deleted
+type Edit struct {
+	EditedAt     time.Time `graphql:"lastEditedAt"` // last_edited_at timestamptz,
+	LastEditedBy Actor     `graphql:"editor"`       // last_edited_by_id bigint NOT NULL, last_edited_by_login text NOT NULL,
+}
+
+// EditorLogin returns the login of the last editor, GhostLogin if the editor
+// account was deleted, or an empty string if the body was never edited
+func (e *Edit) EditorLogin() string {
+	if e.EditedAt.IsZero() {
+		return ""
+	}
+
+	return e.LastEditedBy.LoginOrGhost()
+}
+
 type IssueFields struct {
 	Body       string    // body text,
 	ClosedAt   time.Time // closed_at timestamptz,
@@ -207,16 +540,16 @@ type IssueFields struct {
 	Url        string    // htmlurl text,
 	DatabaseId int       // id bigint,
 	Locked     bool      // locked boolean,
-	Milestone  struct {
-		Id    string // milestone_id text NOT NULL,
-		Title string // milestone_title text NOT NULL,
-	}
-	Id        string    // node_id text,
-	Number    int       // number bigint,
-	State     string    // state text,
-	Title     string    // title text,
-	UpdatedAt time.Time // updated_at timestamptz,
-	Author    Actor     // user_id bigint NOT NULL, user_login text NOT NULL,
+	Milestone  Milestone // milestone_*, the zero Milestone if it has none
+	Id         string    // node_id text,
+	Number     int       // number bigint,
+	State      string    // state text,
+	Title      string    // title text,
+	UpdatedAt  time.Time // updated_at timestamptz,
+	Author     Actor     // user_id bigint NOT NULL, user_login text NOT NULL,
+	Edit                 // last_edited_*, zero if never edited
+
+	ReactionGroups []ReactionGroup // saved in the reactions table
 }
 
 type ClosedByConnection struct {
@@ -227,6 +560,144 @@ type ClosedByConnection struct {
 	}
 } // `graphql:"timelineItems(last:1, itemTypes:CLOSED_EVENT)"`
 
+// LockedByConnection represents the last locked event of
+// https://developer.github.com/v4/object/issuetimelineitemsconnection/
+type LockedByConnection struct {
+	Nodes []struct {
+		LockedEvent struct {
+			Id         string    // node_id text NOT NULL,
+			Actor      Actor     // locked_by_id bigint NOT NULL, locked_by_login text NOT NULL,
+			CreatedAt  time.Time // locked_at timestamptz,
+			LockReason string    // lock_reason text NOT NULL,
+		} `graphql:"... on LockedEvent"`
+	}
+} // `graphql:"lockedBy: timelineItems(last:1, itemTypes:LOCKED_EVENT)"`
+
+// AssignmentEventConnection represents the assignment events of
+// https://developer.github.com/v4/object/issuetimelineitemsconnection/
+type AssignmentEventConnection struct {
+	PageInfo PageInfo
+	Nodes    []AssignmentEvent
+} // `graphql:"assignmentEvents: timelineItems(first: $assignmentEventsPage, after: $assignmentEventsCursor, itemTypes: [ASSIGNED_EVENT, UNASSIGNED_EVENT])"`
+
+// AssignmentEvent represents either
+// https://developer.github.com/v4/object/assignedevent/ or
+// https://developer.github.com/v4/object/unassignedevent/
+type AssignmentEvent struct {
+	Typename        string                `graphql:"__typename"` // event text NOT NULL,
+	AssignedEvent   AssignmentEventFields `graphql:"... on AssignedEvent"`
+	UnassignedEvent AssignmentEventFields `graphql:"... on UnassignedEvent"`
+}
+
+// Fields returns the fields of the AssignedEvent or UnassignedEvent, depending
+// on the event type
+func (e *AssignmentEvent) Fields() *AssignmentEventFields {
+	if e.Typename == "UnassignedEvent" {
+		return &e.UnassignedEvent
+	}
+
+	return &e.AssignedEvent
+}
+
+// AssignmentEventFields defines the fields shared by AssignedEvent and
+// UnassignedEvent
+type AssignmentEventFields struct {
+	Id        string    // node_id text,
+	CreatedAt time.Time // created_at timestamptz,
+	Actor     Actor     // actor_login text NOT NULL,
+	Assignee  struct {
+		Typename string `graphql:"__typename"`
+		User     struct {
+			Login string // assignee_login text NOT NULL,
+		} `graphql:"... on User"`
+		Bot struct {
+			Login string // assignee_login text NOT NULL,
+		} `graphql:"... on Bot"`
+		Mannequin struct {
+			Login string // assignee_login text NOT NULL,
+		} `graphql:"... on Mannequin"`
+	}
+}
+
+// AssigneeLogin returns the login of the user, bot or mannequin that was
+// assigned or unassigned
+func (f *AssignmentEventFields) AssigneeLogin() string {
+	switch f.Assignee.Typename {
+	case "Bot":
+		return f.Assignee.Bot.Login
+	case "Mannequin":
+		return f.Assignee.Mannequin.Login
+	default:
+		return f.Assignee.User.Login
+	}
+}
+
+// IssueEventConnection represents the timeline events of
+// https://developer.github.com/v4/object/issuetimelineitemsconnection/
+type IssueEventConnection struct {
+	PageInfo PageInfo
+	Nodes    []IssueEvent
+} // `graphql:"timelineEvents: timelineItems(first: $timelineItemsPage, after: $timelineItemsCursor, itemTypes: [...])"`
+
+// IssueEvent represents one of the timeline events of an issue that are
+// downloaded: labeled, unlabeled, closed, reopened or cross-referenced, e.g.
+// https://developer.github.com/v4/object/labeledevent/ The assigned and
+// unassigned events are AssignmentEvents
+type IssueEvent struct {
+	Typename             string              `graphql:"__typename"` // event text NOT NULL,
+	LabeledEvent         TimelineEventFields `graphql:"... on LabeledEvent"`
+	UnlabeledEvent       TimelineEventFields `graphql:"... on UnlabeledEvent"`
+	ClosedEvent          TimelineEventFields `graphql:"... on ClosedEvent"`
+	ReopenedEvent        TimelineEventFields `graphql:"... on ReopenedEvent"`
+	CrossReferencedEvent TimelineEventFields `graphql:"... on CrossReferencedEvent"`
+}
+
+// Fields returns the fields of the event, depending on the event type
+func (e *IssueEvent) Fields() *TimelineEventFields {
+	switch e.Typename {
+	case "UnlabeledEvent":
+		return &e.UnlabeledEvent
+	case "ClosedEvent":
+		return &e.ClosedEvent
+	case "ReopenedEvent":
+		return &e.ReopenedEvent
+	case "CrossReferencedEvent":
+		return &e.CrossReferencedEvent
+	default:
+		return &e.LabeledEvent
+	}
+}
+
+// PullRequestEventConnection represents the timeline events of
+// https://developer.github.com/v4/object/pullrequesttimelineitemsconnection/
+type PullRequestEventConnection struct {
+	PageInfo PageInfo
+	Nodes    []PullRequestEvent
+} // `graphql:"timelineEvents: timelineItems(first: $timelineItemsPage, after: $timelineItemsCursor, itemTypes: [...])"`
+
+// PullRequestEvent represents one of the timeline events of a PR that are
+// downloaded: the ones of IssueEvent, and merged, which only PRs have
+type PullRequestEvent struct {
+	IssueEvent
+	MergedEvent TimelineEventFields `graphql:"... on MergedEvent"`
+}
+
+// Fields returns the fields of the event, depending on the event type
+func (e *PullRequestEvent) Fields() *TimelineEventFields {
+	if e.Typename == "MergedEvent" {
+		return &e.MergedEvent
+	}
+
+	return e.IssueEvent.Fields()
+}
+
+// TimelineEventFields defines the fields shared by the timeline events
+type TimelineEventFields struct {
+	Id        string    // node_id text,
+	CreatedAt time.Time // created_at timestamptz,
+	Actor     Actor     // actor_login text NOT NULL,
+}
+
 // UserConnection represents https://developer.github.com/v4/object/userconnection/
 type UserConnection struct {
 	PageInfo PageInfo
@@ -244,30 +715,124 @@ type LabelConnection struct {
 	Nodes    []Label
 } //`graphql:"labels(first: $labelsPage, after: $labelsCursor)"`
 
+// ReactionGroup represents https://docs.github.com/en/graphql/reference/objects#reactiongroup
+// the number of reactions of one kind, e.g. THUMBS_UP, to an issue, PR,
+// comment or review. GitHub returns a group for every kind, even if empty
+type ReactionGroup struct {
+	Content  string // content text NOT NULL,
+	Reactors struct {
+		TotalCount int // total_count bigint NOT NULL,
+	}
+}
+
 type IssueComment struct {
 	AuthorAssociation string    // author_association text,
-	Body              string    // body text,
+	Body              string    `graphql:"body: body @skip(if: $bodiesOmitted)"` // body text,
 	CreatedAt         time.Time // created_at timestamptz,
 	Url               string    // htmlurl text,
 	DatabaseId        int       // id bigint,
 	Id                string    // node_id text,
 	UpdatedAt         string    // updated_at timestamptz,
 	Author            Actor     // user_id bigint NOT NULL, user_login text NOT NULL,
+	Edit                        // last_edited_*, zero if never edited
+
+	ReactionGroups []ReactionGroup // saved in the reactions table
 }
 
-type PullRequestConnection struct {
+// PullRequestCommitConnection represents https://developer.github.com/v4/object/pullrequestcommitconnection/
+type PullRequestCommitConnection struct {
 	PageInfo PageInfo
-	Nodes    []PullRequest
-} //`graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor)"`
+	Nodes    []PullRequestCommit
+} // `graphql:"pullRequestCommits: commits(first: $pullRequestCommitsPage, after: $pullRequestCommitsCursor) @include(if: $pullRequestCommitsIncluded)"`
+
+// PullRequestCommit represents https://developer.github.com/v4/object/pullrequestcommit/
+// The GitHub users of the author and the committer are null when their emails
+// are not linked to a user
+type PullRequestCommit struct {
+	Commit struct {
+		Oid           string    // oid text,
+		Message       string    // message text,
+		AuthoredDate  time.Time // authored_at timestamptz,
+		CommittedDate time.Time // committed_at timestamptz,
+		Author        GitActor  // author_*
+		Committer     GitActor  // committer_*
+	}
+	// CommitAuthor is the GitHub user of the commit author with its profile,
+	// only requested to save the commit authors
+	CommitAuthor struct {
+		Author struct {
+			User *UserExtended
+		}
+	} `graphql:"commitAuthor: commit @include(if: $commitAuthorsIncluded)"`
+}
+
+// GitActor represents https://developer.github.com/v4/object/gitactor/
+type GitActor struct {
+	Name  string // _name text NOT NULL,
+	Email string // _email text NOT NULL,
+	User  *struct {
+		Login string // _login text NOT NULL,
+	}
+}
+
+// Login returns the login of the GitHub user of the actor, or an empty string
+// if the email of the actor is not linked to a user
+func (a *GitActor) Login() string {
+	if a.User == nil {
+		return ""
+	}
+
+	return a.User.Login
+}
+
+type PullRequestConnection struct {
+	TotalCount int
+	PageInfo   PageInfo
+	Nodes      []PullRequest
+} //`graphql:"pullRequests(first: $pullRequestsPage, after: $pullRequestsCursor, orderBy: $pullRequestsOrder, states: $pullRequestStates)"`
 
 type PullRequest struct {
 	PullRequestFields
 	Assignees UserConnection              `graphql:"assignees(first: $assigneesPage, after: $assigneesCursor)"`
 	Labels    LabelConnection             `graphql:"labels(first: $labelsPage, after: $labelsCursor)"`
 	Comments  IssueCommentsConnection     `graphql:"comments(first: $issueCommentsPage, after: $issueCommentsCursor)"`
-	Reviews   PullRequestReviewConnection `graphql:"reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor)"`
+	Reviews   PullRequestReviewConnection `graphql:"reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor, author: $pullRequestReviewsAuthor)"`
+	LockedBy  LockedByConnection          `graphql:"lockedBy: timelineItems(last:1, itemTypes:LOCKED_EVENT)"`
+
+	AssignmentEvents AssignmentEventConnection  `graphql:"assignmentEvents: timelineItems(first: $assignmentEventsPage, after: $assignmentEventsCursor, itemTypes: [ASSIGNED_EVENT, UNASSIGNED_EVENT])"`
+	TimelineItems    PullRequestEventConnection `graphql:"timelineEvents: timelineItems(first: $timelineItemsPage, after: $timelineItemsCursor, itemTypes: [LABELED_EVENT, UNLABELED_EVENT, CLOSED_EVENT, REOPENED_EVENT, MERGED_EVENT, CROSS_REFERENCED_EVENT]) @include(if: $timelineEventsIncluded)"`
+	// ClosingIssues are the issues that the PR closes when merged
+	ClosingIssues ClosingReferenceConnection `graphql:"closingReferences: closingIssuesReferences(first: $closingReferencesPage, after: $closingReferencesCursor)"`
+	ProjectItems  ProjectItemConnection      `graphql:"projectItems(first: 10, includeArchived: false) @include(if: $projectStatusesIncluded)"`
+	// PullRequestCommits is aliased, PullRequestFields.Commits is its total
+	PullRequestCommits PullRequestCommitConnection `graphql:"pullRequestCommits: commits(first: $pullRequestCommitsPage, after: $pullRequestCommitsCursor) @include(if: $pullRequestCommitsIncluded)"`
 } // `graphql:"pullRequest(number: $prNumber)"`
 
+// ProjectItemConnection represents the items of a PR in projects,
+// https://docs.github.com/en/graphql/reference/objects#projectv2itemconnection
+// Only the first 10 are requested, a PR is rarely in more projects
+type ProjectItemConnection struct {
+	Nodes []ProjectItem
+} // `graphql:"projectItems(first: 10, includeArchived: false) @include(if: $projectStatusesIncluded)"`
+
+// ProjectItem represents https://docs.github.com/en/graphql/reference/objects#projectv2item
+type ProjectItem struct {
+	Id      string // node_id text NOT NULL,
+	Project struct {
+		Id     string // project_node_id text NOT NULL,
+		Number int    // project_number bigint NOT NULL,
+		Title  string // project_title text NOT NULL,
+	}
+	// Status is the value of the Status field of the project, it is empty if
+	// the field is not set or the project has no such single select field
+	Status struct {
+		SingleSelect struct {
+			Name      string    // status text NOT NULL,
+			UpdatedAt time.Time // status_updated_at timestamptz,
+		} `graphql:"... on ProjectV2ItemFieldSingleSelectValue"`
+	} `graphql:"status: fieldValueByName(name: \"Status\")"`
+}
+
 type Ref struct {
 	Name       string // _ref text
 	Repository struct {
@@ -292,6 +857,7 @@ type PullRequestFields struct {
 	Additions         int       // additions bigint,
 	AuthorAssociation string    // author_association text,
 	BaseRef           Ref       // base_*
+	BaseRefOid        string    // base_sha text, set even if the branch is deleted
 	Body              string    // body text,
 	ChangedFiles      int       // changed_files bigint,
 	ClosedAt          time.Time // closed_at timestamptz,
@@ -301,22 +867,29 @@ type PullRequestFields struct {
 	CreatedAt           time.Time // created_at timestamptz,
 	Deletions           int       // deletions bigint,
 	HeadRef             Ref       // head_*
+	HeadRefOid          string    // head_sha text, set even if the branch is deleted, and used to guess the merge_method
+	Locked              bool      // used to save the lock
 	Url                 string    // htmlurl text,
 	DatabaseId          int       // id bigint,
 	MaintainerCanModify bool      // maintainer_can_modify boolean,
 	MergeCommit         struct {
 		Oid string // merge_commit_sha text,
+		// MessageHeadline and Parents are used to guess the merge_method
+		MessageHeadline string
+		Parents         struct {
+			TotalCount int
+		}
 	}
-	Mergeable string    // mergeable boolean,
-	Merged    bool      // merged boolean,
-	MergedAt  time.Time // merged_at timestamptz,
-	MergedBy  Actor     // merged_by_id bigint NOT NULL, merged_by_login text NOT NULL,
-	Milestone struct {
-		Id    string // milestone_id text NOT NULL,
-		Title string // milestone_title text NOT NULL,
+	Mergeable            string    // mergeable boolean, mergeable_state text,
+	Merged               bool      // merged boolean,
+	MergedAt             time.Time // merged_at timestamptz,
+	MergedBy             Actor     // merged_by_id bigint NOT NULL, merged_by_login text NOT NULL,
+	Milestone            Milestone // milestone_*, the zero Milestone if it has none
+	Id                   string    // node_id text,
+	Number               int       // number bigint,
+	PotentialMergeCommit struct {
+		Oid string // potential_merge_commit_sha text,
 	}
-	Id            string // node_id text,
-	Number        int    // number bigint,
 	ReviewThreads struct {
 		TotalCount int // review_comments bigint,
 	}
@@ -324,13 +897,16 @@ type PullRequestFields struct {
 	Title     string // title text,
 	UpdatedAt string // updated_at timestamptz,
 	Author    Actor  // user_id bigint NOT NULL, user_login text NOT NULL,
+	Edit             // last_edited_*, zero if never edited
+
+	ReactionGroups []ReactionGroup // saved in the reactions table
 }
 
 type PullRequestReviewConnection struct {
-	//TotalCount int
-	PageInfo PageInfo
-	Nodes    []PullRequestReview
-} // `graphql:"reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor)"`
+	TotalCount int
+	PageInfo   PageInfo
+	Nodes      []PullRequestReview
+} // `graphql:"reviews(first: $pullRequestReviewsPage, after: $pullRequestReviewsCursor, author: $pullRequestReviewsAuthor)"`
 
 type PullRequestReview struct {
 	PullRequestReviewFields
@@ -338,29 +914,32 @@ type PullRequestReview struct {
 }
 
 type PullRequestReviewFields struct {
-	Body   string // body text,
+	Body   string `graphql:"body: body @skip(if: $bodiesOmitted)"` // body text,
 	Commit struct {
 		Oid string // commit_id text,
 	}
-	Url         string    // htmlurl text,
-	DatabaseId  int       // id bigint,
-	Id          string    // node_id text,
-	State       string    // state text,
-	SubmittedAt time.Time // submitted_at timestamptz,
-	Author      Actor     // user_id bigint NOT NULL, user_login text NOT NULL,
+	Url         string     // htmlurl text,
+	DatabaseId  int        // id bigint,
+	Id          string     // node_id text,
+	State       string     // state text,
+	SubmittedAt *time.Time // submitted_at timestamptz, nil for pending reviews
+	Author      Actor      // user_id bigint NOT NULL, user_login text NOT NULL,
+	Edit                   // last_edited_*, zero if never edited
+
+	ReactionGroups []ReactionGroup // saved in the reactions table
 
 	Comments PullRequestReviewCommentConnection `graphql:"comments(first: $pullRequestReviewCommentsPage, after: $pullRequestReviewCommentsCursor)"`
 }
 
 type PullRequestReviewCommentConnection struct {
-	//TotalCount int
-	PageInfo PageInfo
-	Nodes    []PullRequestReviewComment
+	TotalCount int
+	PageInfo   PageInfo
+	Nodes      []PullRequestReviewComment
 }
 
 type PullRequestReviewComment struct {
 	AuthorAssociation string // author_association text,
-	Body              string // body text,
+	Body              string `graphql:"body: body @skip(if: $bodiesOmitted)"` // body text,
 	Commit            struct {
 		Oid string // commit_id text,
 	}
@@ -378,4 +957,5 @@ type PullRequestReviewComment struct {
 	Position         int       // position bigint,
 	UpdatedAt        time.Time // updated_at timestamptz,
 	Author           Actor     // user_id bigint NOT NULL, user_login text NOT NULL,
+	Edit                       // last_edited_*, zero if never edited
 }
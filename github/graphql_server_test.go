@@ -0,0 +1,98 @@
+package github
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/store"
+	"github.com/src-d/metadata-retrieval/testutils"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// graphqlHandler returns the JSON response for the given GraphQL query
+type graphqlHandler func(query string, variables map[string]interface{}) string
+
+// emptyRulesets is the response to the rulesets query of a repository without
+// rulesets
+const emptyRulesets = `{"data": {"node": {"rulesets": {"pageInfo": {"hasNextPage": false}, "nodes": []}}}}`
+
+// testServers are the servers started by newTestClient, closed by TestMain
+// once all the tests are done
+var (
+	testServersMu sync.Mutex
+	testServers   []*httptest.Server
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+
+	for _, server := range testServers {
+		server.Close()
+	}
+
+	os.Exit(code)
+}
+
+// newTestClient returns a GraphQL client that sends its queries to a local
+// test server answering with the given handler. The rulesets queries are
+// answered with an empty list
+func newTestClient(t testing.TB, handler graphqlHandler) *githubv4.Client {
+	return newTestClientWithRulesets(t, handler, emptyRulesets)
+}
+
+// newTestClientWithRulesets is like newTestClient, but the rulesets queries
+// are answered with the given response instead of being sent to the handler
+func newTestClientWithRulesets(t testing.TB, handler graphqlHandler, rulesets string) *githubv4.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body.Query, "rulesets(") {
+			io.WriteString(w, rulesets)
+			return
+		}
+
+		io.WriteString(w, handler(body.Query, body.Variables))
+	}))
+	testServersMu.Lock()
+	testServers = append(testServers, server)
+	testServersMu.Unlock()
+
+	return githubv4.NewEnterpriseClient(server.URL, server.Client())
+}
+
+// newTestDownloader returns a Downloader that sends its queries to a local
+// test server answering with the given handler, and saves the data in memory
+func newTestDownloader(t *testing.T, handler graphqlHandler) (*Downloader, *testutils.Memory) {
+	storer := new(testutils.Memory)
+	return &Downloader{
+		storer: storer,
+		client: newTestClient(t, handler),
+	}, storer
+}
+
+// newTestMemDownloader is like newTestDownloader, but it saves the data in
+// a store.Mem
+func newTestMemDownloader(t *testing.T, handler graphqlHandler) (*Downloader, *store.Mem) {
+	storer := store.NewMem()
+	return &Downloader{
+		storer: storer,
+		client: newTestClient(t, handler),
+	}, storer
+}
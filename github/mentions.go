@@ -0,0 +1,121 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionRegexp matches a user mention, @login, or a team mention,
+// @org/team. The first group is the character before the @, it must not be
+// part of a word, an email, a path or an escape
+var mentionRegexp = regexp.MustCompile(
+	`(^|[^A-Za-z0-9_\\/@.-])@([A-Za-z0-9](?:-?[A-Za-z0-9]){0,38}(?:/[A-Za-z0-9_-]+)?)`)
+
+// ParseMentions returns the users and teams mentioned in a Markdown body,
+// without the @ and in the order they first appear. Mentions inside fenced
+// code blocks and inline code spans, and escaped ones (\@login), are not
+// mentions
+func ParseMentions(body string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+
+	var fence string
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) < 4 {
+			if fence != "" {
+				// the closing fence is at least as long as the opening one
+				if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]+" \t") == "" {
+					fence = ""
+				}
+				continue
+			}
+
+			if f := codeFence(trimmed); f != "" {
+				fence = f
+				continue
+			}
+		}
+
+		if fence != "" {
+			continue
+		}
+
+		for _, m := range mentionRegexp.FindAllStringSubmatch(withoutCodeSpans(line), -1) {
+			login := m[2]
+			if !seen[login] {
+				seen[login] = true
+				mentions = append(mentions, login)
+			}
+		}
+	}
+
+	return mentions
+}
+
+// codeFence returns the fence (``` or ~~~, or longer) that opens a code block
+// at the start of line, or an empty string
+func codeFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+
+	return ""
+}
+
+// withoutCodeSpans replaces the inline code spans of line with spaces. A span
+// starts with a run of backticks and ends with a run of the same length;
+// unmatched runs are left as they are
+func withoutCodeSpans(line string) string {
+	b := []byte(line)
+	for i := 0; i < len(b); {
+		if b[i] != '`' {
+			i++
+			continue
+		}
+
+		n := 1
+		for i+n < len(b) && b[i+n] == '`' {
+			n++
+		}
+
+		end := closingRun(b, i+n, n)
+		if end < 0 {
+			i += n
+			continue
+		}
+
+		for j := i; j < end+n; j++ {
+			b[j] = ' '
+		}
+		i = end + n
+	}
+
+	return string(b)
+}
+
+// closingRun returns the position of the first run of exactly n backticks
+// starting at or after from, or -1
+func closingRun(b []byte, from, n int) int {
+	for i := from; i < len(b); {
+		if b[i] != '`' {
+			i++
+			continue
+		}
+
+		m := 1
+		for i+m < len(b) && b[i+m] == '`' {
+			m++
+		}
+
+		if m == n {
+			return i
+		}
+		i += m
+	}
+
+	return -1
+}
@@ -0,0 +1,33 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	body := "@alice can you take a look? cc @src-d/maintainers, @bob.\n" +
+		"\n" +
+		"```go\n" +
+		"// @carol in a fenced block\n" +
+		"```\n" +
+		"~~~~\n" +
+		"@dave in a tilde fence\n" +
+		"~~~\n" +
+		"still in the block, @erin\n" +
+		"~~~~\n" +
+		"Inline `@frank` and ``a ` @grace`` spans, \\@heidi escaped, mail ivan@example.com\n" +
+		"(@judy) and @alice again, unclosed `@mallory\n"
+
+	require.Equal(t, []string{
+		"alice",
+		"src-d/maintainers",
+		"bob",
+		"judy",
+		"mallory",
+	}, ParseMentions(body))
+
+	require.Empty(t, ParseMentions(""))
+	require.Empty(t, ParseMentions("```\n@alice\n"))
+}
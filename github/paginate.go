@@ -0,0 +1,47 @@
+package github
+
+import (
+	"context"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// paginate loops over the pages of a connection that follow its first page,
+// which is already processed by the caller. first is the page info of the
+// first page, and next queries the page after the given cursor, processes its
+// nodes and returns its page info. The context is checked before each query
+func paginate(ctx context.Context, first graphql.PageInfo, next func(cursor string) (graphql.PageInfo, error)) error {
+	page := first
+	for page.HasNextPage {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		page, err = next(page.EndCursor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// paginateAll is like paginate, for the connections whose first page is not
+// included in a parent query: next is called first with an empty cursor to
+// query the first page. See afterCursor
+func paginateAll(ctx context.Context, next func(cursor string) (graphql.PageInfo, error)) error {
+	return paginate(ctx, graphql.PageInfo{HasNextPage: true}, next)
+}
+
+// afterCursor returns the value of the after variable of a query for the
+// given cursor, null for the empty cursor of the first page
+func afterCursor(cursor string) interface{} {
+	if cursor == "" {
+		return (*githubv4.String)(nil)
+	}
+
+	return githubv4.String(cursor)
+}
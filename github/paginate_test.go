@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	require := require.New(t)
+
+	pages := map[string]graphql.PageInfo{
+		"c1": {HasNextPage: true, EndCursor: "c2"},
+		"c2": {HasNextPage: false, EndCursor: "c3"},
+	}
+
+	var cursors []string
+	next := func(cursor string) (graphql.PageInfo, error) {
+		cursors = append(cursors, cursor)
+		return pages[cursor], nil
+	}
+
+	// the first page is not queried, the pages that follow it are queried
+	// until the last one
+	require.NoError(paginate(context.TODO(), graphql.PageInfo{HasNextPage: true, EndCursor: "c1"}, next))
+	require.Equal([]string{"c1", "c2"}, cursors)
+
+	// a single page
+	cursors = nil
+	require.NoError(paginate(context.TODO(), graphql.PageInfo{}, next))
+	require.Empty(cursors)
+
+	// the error of a page stops the pagination
+	cursors = nil
+	failing := func(cursor string) (graphql.PageInfo, error) {
+		cursors = append(cursors, cursor)
+		return graphql.PageInfo{HasNextPage: true, EndCursor: "c2"}, fmt.Errorf("page %v failed", cursor)
+	}
+	err := paginate(context.TODO(), graphql.PageInfo{HasNextPage: true, EndCursor: "c1"}, failing)
+	require.EqualError(err, "page c1 failed")
+	require.Equal([]string{"c1"}, cursors)
+
+	// a canceled context stops the pagination before the next query
+	cursors = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	canceling := func(cursor string) (graphql.PageInfo, error) {
+		cursors = append(cursors, cursor)
+		cancel()
+		return pages[cursor], nil
+	}
+	err = paginate(ctx, graphql.PageInfo{HasNextPage: true, EndCursor: "c1"}, canceling)
+	require.Equal(context.Canceled, err)
+	require.Equal([]string{"c1"}, cursors)
+}
+
+func TestPaginateAll(t *testing.T) {
+	require := require.New(t)
+
+	pages := map[string]graphql.PageInfo{
+		"":   {HasNextPage: true, EndCursor: "c1"},
+		"c1": {HasNextPage: false, EndCursor: "c2"},
+	}
+
+	// the first page is queried with an empty cursor
+	var cursors []string
+	require.NoError(paginateAll(context.TODO(), func(cursor string) (graphql.PageInfo, error) {
+		cursors = append(cursors, cursor)
+		return pages[cursor], nil
+	}))
+	require.Equal([]string{"", "c1"}, cursors)
+
+	require.Equal((*githubv4.String)(nil), afterCursor(""))
+	require.Equal(githubv4.String("c1"), afterCursor("c1"))
+}
@@ -0,0 +1,82 @@
+package github
+
+import "sync"
+
+// The resources reported by a ProgressEvent
+const (
+	ProgressIssues       = "issues"
+	ProgressPullRequests = "pull_requests"
+)
+
+// ProgressEvent reports the progress of a repository download to
+// Downloader.ProgressFunc. An event is reported after each issue or PR is
+// saved, and when a new page of them is requested
+type ProgressEvent struct {
+	// Resource is ProgressIssues or ProgressPullRequests
+	Resource        string
+	RepositoryOwner string
+	RepositoryName  string
+	// Saved is the number of issues or PRs of the repository saved so far
+	Saved int
+	// NewPage is set when the event reports a new page instead of a saved
+	// issue or PR
+	NewPage bool
+	// Cursor is the cursor after which the current page starts, it is empty
+	// for the first page and for samples
+	Cursor string
+}
+
+// progress keeps the count of the issues or PRs saved by a download. Its
+// methods are noops on a nil progress, see newProgress
+type progress struct {
+	// mu serializes the reports of the concurrent workers, see
+	// Downloader.Concurrency
+	mu     sync.Mutex
+	report func(ProgressEvent)
+	event  ProgressEvent
+}
+
+// newProgress returns the progress of the given resource, or nil if
+// ProgressFunc is not set
+func (d Downloader) newProgress(resource string, owner string, name string) *progress {
+	if d.ProgressFunc == nil {
+		return nil
+	}
+
+	return &progress{
+		report: d.ProgressFunc,
+		event: ProgressEvent{
+			Resource:        resource,
+			RepositoryOwner: owner,
+			RepositoryName:  name,
+		},
+	}
+}
+
+// saved reports a saved issue or PR
+func (p *progress) saved() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.event.Saved++
+	p.event.NewPage = false
+	p.report(p.event)
+}
+
+// page reports a new page, requested after the given cursor
+func (p *progress) page(cursor string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.event.Cursor = cursor
+	p.event.NewPage = true
+	p.report(p.event)
+}
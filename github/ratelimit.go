@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"sync"
+
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// rateRefreshQueries is the number of queries sent between two refreshes of
+// the rate limit snapshot of a rateGuard
+const rateRefreshQueries = 50
+
+// rateGuard waits for the reset of the rate limit when the remaining points
+// drop below a minimum, see Downloader.MinRemaining. The rate limit is not
+// queried before every query: each query costs at least one point, so the
+// remaining points are estimated from the latest snapshot, which is refreshed
+// every rateRefreshQueries queries. If the API does not report the rate limit,
+// see ErrRateLimitUnavailable, the guard is disabled. It is safe for
+// concurrent use. Its methods are noops on a nil rateGuard, see newRateGuard
+type rateGuard struct {
+	mu      sync.Mutex
+	clock   clock
+	min     int
+	refresh func(ctx context.Context) (RateLimit, error)
+
+	// rate is the latest snapshot, zero before the first query, and queries
+	// the number of queries sent since it was taken
+	rate    RateLimit
+	queries int
+	fresh   bool
+	// disabled is set once the rate limit is found to be unavailable
+	disabled bool
+}
+
+// newRateGuard returns the rate guard of a download, or nil if MinRemaining
+// is not set
+func (d Downloader) newRateGuard() *rateGuard {
+	if d.MinRemaining <= 0 {
+		return nil
+	}
+
+	c := d.clock
+	if c == nil {
+		c = realClock{}
+	}
+
+	// the rate limit query itself is not guarded
+	unguarded := d
+	unguarded.rateGuard = nil
+	return &rateGuard{clock: c, min: d.MinRemaining, refresh: unguarded.RateLimit}
+}
+
+// wait blocks until the reset time of the rate limit if the remaining points
+// are below the minimum, and counts the query that follows it
+func (g *rateGuard) wait(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.disabled {
+		return nil
+	}
+
+	// the estimate is a lower bound, refresh it before waiting
+	if !g.fresh || g.queries >= rateRefreshQueries || (g.queries > 0 && g.remaining() < g.min) {
+		err := g.update(ctx)
+		if err != nil || g.disabled {
+			return err
+		}
+	}
+
+	if g.remaining() < g.min {
+		if d := g.rate.ResetAt.Sub(g.clock.Now()); d > 0 {
+			log.Infof("%v rate limit points remaining, below the minimum %v, waiting until %v", g.remaining(), g.min, g.rate.ResetAt)
+			err := g.clock.Sleep(ctx, d)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := g.update(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	g.queries++
+	return nil
+}
+
+// remaining returns the estimated remaining points
+func (g *rateGuard) remaining() int {
+	return g.rate.Remaining - g.queries
+}
+
+// update takes a new snapshot of the rate limit. If the rate limit is not
+// available the guard is disabled instead
+func (g *rateGuard) update(ctx context.Context) error {
+	rate, err := g.refresh(ctx)
+	if err == ErrRateLimitUnavailable {
+		log.Warningf("the rate limit is not available, the downloads will not wait for its reset")
+		g.disabled = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	g.rate = rate
+	g.queries = 0
+	g.fresh = true
+	return nil
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateGuard(t *testing.T) {
+	require := require.New(t)
+
+	clock := &fakeClock{now: time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)}
+	resetAt := clock.now.Add(30 * time.Minute)
+
+	var snapshots []RateLimit
+	refreshes := 0
+	g := &rateGuard{clock: clock, min: 100, refresh: func(ctx context.Context) (RateLimit, error) {
+		rate := snapshots[refreshes]
+		refreshes++
+		return rate, nil
+	}}
+
+	snapshots = []RateLimit{
+		{Remaining: 1000, ResetAt: resetAt},
+		{Remaining: 900, ResetAt: resetAt},
+	}
+
+	// the snapshot is taken before the first query, and refreshed every
+	// rateRefreshQueries queries
+	for i := 0; i < rateRefreshQueries+1; i++ {
+		require.NoError(g.wait(context.TODO()))
+	}
+	require.Equal(2, refreshes)
+	require.Empty(clock.sleeps)
+
+	// below the minimum the guard refreshes the estimate, and then waits for
+	// the reset
+	refreshes = 0
+	snapshots = []RateLimit{
+		{Remaining: 101, ResetAt: resetAt},
+		{Remaining: 80, ResetAt: resetAt},
+		{Remaining: 5000, ResetAt: resetAt.Add(time.Hour)},
+	}
+	g.fresh = false
+
+	require.NoError(g.wait(context.TODO()))
+	require.NoError(g.wait(context.TODO()))
+	require.Equal(1, refreshes)
+	require.Empty(clock.sleeps)
+
+	require.NoError(g.wait(context.TODO()))
+	require.Equal(3, refreshes)
+	require.Equal([]time.Duration{30 * time.Minute}, clock.sleeps)
+	require.Equal(4999, g.remaining())
+
+	// a nil guard never waits
+	var nilGuard *rateGuard
+	require.NoError(nilGuard.wait(context.TODO()))
+}
+
+func TestMinRemaining(t *testing.T) {
+	require := require.New(t)
+
+	clock := &fakeClock{now: time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)}
+
+	var queries []string
+	d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "rateLimit") {
+			queries = append(queries, "rate limit")
+			if len(queries) == 1 {
+				return `{"data": {"rateLimit": {"cost": 0, "limit": 5000, "remaining": 10, "resetAt": "2019-10-01T10:20:00Z"}}}`
+			}
+
+			return `{"data": {"rateLimit": {"cost": 0, "limit": 5000, "remaining": 5000, "resetAt": "2019-10-01T11:20:00Z"}}}`
+		}
+
+		queries = append(queries, "organization")
+		return `{"data": {"organization": {
+			"login": "src-d",
+			"membersWithRole": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	})
+	d.clock = clock
+
+	// without MinRemaining the rate limit is not queried
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Equal([]string{"organization"}, queries)
+
+	queries = nil
+	d.MinRemaining = 100
+	require.NoError(d.DownloadOrganization(context.TODO(), "src-d", 0))
+	require.Equal([]string{"rate limit", "rate limit", "organization"}, queries)
+	require.Equal([]time.Duration{20 * time.Minute}, clock.sleeps)
+	require.Equal("src-d", storer.Organization.Login)
+}
+
+func TestRateLimitUnavailable(t *testing.T) {
+	for _, response := range []string{
+		// GitHub Enterprise Server without the field in its schema
+		`{"errors": [{"message": "Field 'rateLimit' doesn't exist on type 'Query'", "locations": [{"line": 1, "column": 7}]}]}`,
+		// GitHub Enterprise Server with rate limiting disabled
+		`{"data": {"rateLimit": null}}`,
+	} {
+		t.Run(response, func(t *testing.T) {
+			require := require.New(t)
+
+			clock := &fakeClock{now: time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)}
+
+			var queries []string
+			d, storer := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+				if strings.Contains(query, "rateLimit") {
+					queries = append(queries, "rate limit")
+					return response
+				}
+
+				queries = append(queries, "organization")
+				if variables["membersWithRoleCursor"] != nil {
+					return `{"data": {"organization": {
+						"membersWithRole": {"pageInfo": {"hasNextPage": true, "endCursor": "m2"}, "nodes": []}
+					}}}`
+				}
+
+				return `{"data": {"organization": {
+					"login": "src-d",
+					"membersWithRole": {"pageInfo": {"hasNextPage": true, "endCursor": "m1"}, "nodes": []}
+				}}}`
+			})
+			d.clock = clock
+
+			remaining, err := d.RateRemaining(context.TODO())
+			require.NoError(err)
+			require.Equal(RateUnavailable, remaining)
+
+			_, err = d.RateLimit(context.TODO())
+			require.Equal(ErrRateLimitUnavailable, err)
+
+			// the rate limit is checked once, and then the guard is disabled
+			queries = nil
+			d.MinRemaining = 100
+			checkpoint := &OrganizationCheckpoint{Organization: "src-d"}
+			require.NoError(d.DownloadOrganizationChunk(context.TODO(), checkpoint, 2))
+			require.Equal([]string{"rate limit", "organization", "organization"}, queries)
+			require.Empty(clock.sleeps)
+			require.Equal("src-d", storer.Organization.Login)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the matches of the patterns of a Redactor
+const Redacted = "[REDACTED]"
+
+// Redactor scrubs user content, like secrets or email addresses, replacing
+// the matches of a list of regular expressions with Redacted. Its Redact
+// method can be used as Downloader.BodyTransformer
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor compiles the patterns, using the RE2 syntax of the regexp
+// package. It fails if any of them is invalid, listing all the invalid ones
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	var invalid []string
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			invalid = append(invalid, err.Error())
+			continue
+		}
+
+		r.patterns = append(r.patterns, re)
+	}
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid redaction patterns: %v", strings.Join(invalid, "; "))
+	}
+
+	return r, nil
+}
+
+// ReadRedactor reads the patterns of a Redactor, one per line. Empty lines,
+// and lines starting with #, are skipped
+func ReadRedactor(rd io.Reader) (*Redactor, error) {
+	var patterns []string
+
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read redaction patterns: %v", err)
+	}
+
+	return NewRedactor(patterns)
+}
+
+// LoadRedactor reads the patterns of a Redactor from a file, see ReadRedactor
+func LoadRedactor(path string) (*Redactor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open redaction patterns: %v", err)
+	}
+	defer f.Close()
+
+	return ReadRedactor(f)
+}
+
+// Redact returns the body with the matches of every pattern replaced with
+// Redacted, in the order of the patterns
+func (r *Redactor) Redact(body string) string {
+	for _, re := range r.patterns {
+		body = re.ReplaceAllLiteralString(body, Redacted)
+	}
+
+	return body
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const redactPatterns = `# email addresses
+[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}
+
+# fake API keys
+sk_live_[A-Za-z0-9]{16}
+`
+
+// writeRedactPatterns writes the patterns to a temporary file, and returns
+// its path and a func that removes it
+func writeRedactPatterns(t *testing.T, patterns string) (string, func()) {
+	dir, err := ioutil.TempDir("", "redact")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "patterns")
+	require.NoError(t, ioutil.WriteFile(path, []byte(patterns), 0644))
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestRedactor(t *testing.T) {
+	require := require.New(t)
+
+	path, cleanup := writeRedactPatterns(t, redactPatterns)
+	defer cleanup()
+
+	r, err := LoadRedactor(path)
+	require.NoError(err)
+	require.Equal(
+		"mail [REDACTED] with the key [REDACTED], @alice",
+		r.Redact("mail alice@example.com with the key sk_live_0123456789abcdef, @alice"))
+	require.Equal("nothing to redact", r.Redact("nothing to redact"))
+
+	_, err = ReadRedactor(strings.NewReader("valid\n(unclosed\n[a-\n"))
+	require.Error(err)
+	require.Contains(err.Error(), "(unclosed")
+	require.Contains(err.Error(), "[a-")
+
+	_, err = LoadRedactor(filepath.Join(os.TempDir(), "missing-redact-patterns"))
+	require.Error(err)
+}
+
+func TestBodyTransformer(t *testing.T) {
+	d, m := newTestMemDownloader(t, func(query string, variables map[string]interface{}) string {
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 1, "body": "reach me at alice@example.com", "comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+					{"id": "c1", "body": "the key is sk_live_0123456789abcdef"}
+				]}}
+			]},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"number": 2, "body": "cc bob@example.com"}
+			]}
+		}}}`
+	})
+
+	require := require.New(t)
+
+	path, cleanup := writeRedactPatterns(t, redactPatterns)
+	defer cleanup()
+
+	r, err := LoadRedactor(path)
+	require.NoError(err)
+	d.BodyTransformer = r.Redact
+
+	require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Equal("reach me at [REDACTED]", repo.Issues[1].Body)
+	require.Equal("the key is [REDACTED]", repo.Issues[1].Comments[0].Body)
+	require.Equal("cc [REDACTED]", repo.PRs[2].Body)
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// The phases of a RepositoryCheckpoint, in the order they are downloaded. The
+// reviews and comments of the issues and PRs are downloaded with them, in
+// the same page
+const (
+	CheckpointRepository   = "repository"
+	CheckpointIssues       = "issues"
+	CheckpointPullRequests = "pull_requests"
+)
+
+var checkpointPhases = []string{CheckpointRepository, CheckpointIssues, CheckpointPullRequests}
+
+// RepositoryCheckpoint is the progress of a repository download made by
+// ResumeRepository, possibly across several runs. It can be saved between
+// runs, e.g. as JSON
+type RepositoryCheckpoint struct {
+	// Version is the version of the download. A zero version is resolved by
+	// the first run, see Downloader.AutoVersion
+	Version int `json:"version"`
+	// Phase is the phase in flight, one of the Checkpoint constants. Empty
+	// means CheckpointRepository, before the first run
+	Phase string `json:"phase"`
+	// Cursor is the cursor of the last saved page of the phase, empty if none
+	// of its pages is saved yet
+	Cursor string `json:"cursor"`
+	// Done is true once the repository is saved
+	Done bool `json:"done"`
+}
+
+// ResumeRepository is like DownloadRepository, but it continues the download
+// from the checkpoint, and commits the transaction after the repository and
+// after each page of issues or PRs, recording the phase and the cursor of the
+// page in the checkpoint. When the download fails, e.g. after a transient
+// error on page 600, only the page in flight is rolled back, and calling
+// ResumeRepository again with the same checkpoint continues from it.
+// The repository is queried again by each run, but it is only saved by the
+// first one
+func (d Downloader) ResumeRepository(ctx context.Context, owner string, name string, checkpoint *RepositoryCheckpoint) error {
+	if checkpoint.Done {
+		return nil
+	}
+
+	if d.SampleSize > 0 {
+		return fmt.Errorf("SampleSize is not supported by resumable downloads")
+	}
+
+	version, err := d.downloadVersion(checkpoint.Version)
+	if err != nil {
+		return err
+	}
+
+	checkpoint.Version = version
+	d.resume = &resume{checkpoint: checkpoint}
+	err = d.downloadRepositoryVersion(ctx, owner, name, version)
+	if err != nil {
+		return err
+	}
+
+	checkpoint.Phase = ""
+	checkpoint.Cursor = ""
+	checkpoint.Done = true
+	return nil
+}
+
+// resume is the state of a download made by ResumeRepository. Its methods are
+// noops on a nil resume, a download that is not resumable
+type resume struct {
+	checkpoint *RepositoryCheckpoint
+}
+
+// skip returns true if the phase was done by a previous run
+func (r *resume) skip(phase string) bool {
+	if r == nil {
+		return false
+	}
+
+	return phaseIndex(r.checkpoint.Phase) > phaseIndex(phase)
+}
+
+// cursor returns the cursor of the last page of the phase saved by a previous
+// run, or an empty string if the phase starts from its first page
+func (r *resume) cursor(phase string) string {
+	if r == nil || r.checkpoint.Phase != phase {
+		return ""
+	}
+
+	return r.checkpoint.Cursor
+}
+
+// commit commits the transaction, records the phase in flight and the cursor
+// of its last saved page, and begins a new transaction
+func (r *resume) commit(s storer, phase string, cursor string) error {
+	if r == nil {
+		return nil
+	}
+
+	err := s.Commit()
+	if err != nil {
+		return fmt.Errorf("could not call Commit(): %v", err)
+	}
+
+	r.checkpoint.Phase = phase
+	r.checkpoint.Cursor = cursor
+
+	err = s.Begin()
+	if err != nil {
+		return fmt.Errorf("could not call Begin(): %v", err)
+	}
+
+	return nil
+}
+
+// phaseIndex returns the position of the phase in checkpointPhases
+func phaseIndex(phase string) int {
+	for i, p := range checkpointPhases {
+		if p == phase {
+			return i
+		}
+	}
+
+	return 0
+}
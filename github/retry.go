@@ -1,10 +1,12 @@
 package github
 
 import (
-	"bytes"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/src-d/go-log.v1"
@@ -19,36 +21,151 @@ func (e *errUnretriable) Error() string {
 	return e.Err.Error()
 }
 
+// retryTransport sends again the requests that fail with a transient error: a
+// network error, a 502, 503 or 504 status, or a secondary rate limit (the
+// abuse detection), a 403 or 429 status with a Retry-After header or with a
+// secondary rate limit message. The delay before each retry doubles from
+// BaseDelay up to truncate, with jitter, except for the secondary rate limits,
+// which wait as long as Retry-After says, or secondaryRateLimitDelay.
+// The requests with a body that cannot be rewound, see http.Request.GetBody,
+// are not retried, and the waits end when the context of the request is done
 type retryTransport struct {
 	T http.RoundTripper
+	// MaxRetries is the maximum number of retries of a request. Zero means
+	// the default, 10
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. Zero means the default,
+	// 10ms
+	BaseDelay time.Duration
+
+	clock clock
 }
 
 func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	var r *http.Response
-	var err error
-	retry(func() error {
-		r, err = t.T.RoundTrip(req)
-		if err != nil {
-			return err
+	maxRetries := t.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = retries
+	}
+
+	backoff := t.BaseDelay
+	if backoff <= 0 {
+		backoff = delay
+	}
+
+	c := t.clock
+	if c == nil {
+		c = realClock{}
+	}
+
+	for i := 0; ; i++ {
+		r, err := t.T.RoundTrip(req)
+		if err == nil && r.StatusCode == http.StatusOK {
+			return r, nil
 		}
 
-		if r.StatusCode == http.StatusOK {
-			return nil
+		wait, retriable := jitter(backoff), req.Context().Err() == nil
+		if err == nil {
+			body, _ := ioutil.ReadAll(r.Body)
+			r.Body.Close()
+
+			err = fmt.Errorf("non-200 OK status code: %v body: %q", r.Status, body)
+			switch r.StatusCode {
+			case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			case http.StatusForbidden, http.StatusTooManyRequests:
+				wait, retriable = retryAfter(r.Header.Get("Retry-After"))
+				if !retriable && secondaryRateLimited(body) {
+					wait, retriable = secondaryRateLimitDelay, true
+				}
+			default:
+				retriable = false
+			}
 		}
 
-		body, _ := ioutil.ReadAll(r.Body)
+		if !retriable || i == maxRetries {
+			return nil, err
+		}
 
-		// Restore the io.ReadCloser
-		r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		rewound, rewindErr := rewind(req)
+		if rewindErr != nil {
+			log.Warningf("not retrying, %v: %v", rewindErr, err)
+			return nil, err
+		}
 
-		err = fmt.Errorf("non-200 OK status code: %v body: %q", r.Status, body)
-		if r.StatusCode > 500 {
-			return err
+		log.Errorf(err, "retrying in %v", wait)
+		if err := c.Sleep(req.Context(), wait); err != nil {
+			return nil, err
 		}
-		return &errUnretriable{Err: err}
-	})
 
-	return r, err
+		req = rewound
+		backoff *= 2
+		if backoff > truncate {
+			backoff = truncate
+		}
+	}
+}
+
+// jitter returns a random delay between half the given delay and the delay,
+// so the retries of concurrent requests do not happen at the same time
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter returns the delay of a Retry-After header, in seconds or as an
+// HTTP date, and false if there is no such header. Without it, a 403 or 429
+// status is not a secondary rate limit, and it is not retried
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+
+		return d, true
+	}
+
+	return 0, false
+}
+
+// secondaryRateLimitDelay is the wait after a secondary rate limit without a
+// Retry-After header, at least a minute according to the GitHub documentation
+const secondaryRateLimitDelay = time.Minute
+
+// secondaryRateLimited returns true if the body of a 403 or 429 response is
+// the message of a secondary rate limit, formerly called abuse detection,
+// instead of the exhausted primary rate limit or a lack of permissions
+func secondaryRateLimited(body []byte) bool {
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") ||
+		strings.Contains(message, "abuse detection")
+}
+
+// rewind returns a copy of the request with a new body, to send it again. The
+// requests without a body are returned as is
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("the request body cannot be rewound")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("could not rewind the request body: %v", err)
+	}
+
+	r := *req
+	r.Body = body
+	return &r, nil
 }
 
 const (
@@ -57,6 +174,10 @@ const (
 	truncate = 10 * time.Second
 )
 
+// sleep waits between the retries of retry, the tests replace it to record the
+// delays
+var sleep = time.Sleep
+
 func retry(f func() error) error {
 	d := delay
 	var i uint
@@ -75,7 +196,7 @@ func retry(f func() error) error {
 		}
 
 		log.Errorf(err, "retrying in %v", d)
-		time.Sleep(d)
+		sleep(d)
 
 		d = d * (1<<i + 1)
 		if d > truncate {
@@ -0,0 +1,172 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// statusTransport answers each request with the next of its responses, and
+// records the bodies of the requests
+type statusTransport struct {
+	responses []*http.Response
+	bodies    []string
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		t.bodies = append(t.bodies, string(body))
+	}
+
+	r := t.responses[0]
+	if len(t.responses) > 1 {
+		t.responses = t.responses[1:]
+	}
+
+	return r, nil
+}
+
+func statusResponse(status int, header http.Header) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"data": {}}`)),
+	}
+}
+
+func newQueryRequest(t *testing.T, ctx context.Context) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/graphql", strings.NewReader(`{"query": "{viewer{login}}"}`))
+	require.NoError(t, err)
+	return req.WithContext(ctx)
+}
+
+func TestRetryTransport(t *testing.T) {
+	require := require.New(t)
+
+	st := &statusTransport{responses: []*http.Response{
+		statusResponse(http.StatusBadGateway, nil),
+		statusResponse(http.StatusServiceUnavailable, nil),
+		statusResponse(http.StatusGatewayTimeout, nil),
+		statusResponse(http.StatusOK, nil),
+	}}
+	clock := &fakeClock{}
+	rt := &retryTransport{T: st, BaseDelay: 100 * time.Millisecond, clock: clock}
+
+	r, err := rt.RoundTrip(newQueryRequest(t, context.TODO()))
+	require.NoError(err)
+	require.Equal(http.StatusOK, r.StatusCode)
+
+	// the body is sent again on each retry
+	require.Len(st.bodies, 4)
+	for _, body := range st.bodies {
+		require.Equal(`{"query": "{viewer{login}}"}`, body)
+	}
+
+	// the delays double, with jitter
+	require.Len(clock.sleeps, 3)
+	for i, d := range clock.sleeps {
+		backoff := 100 * time.Millisecond << uint(i)
+		require.True(d >= backoff/2 && d <= backoff, "delay %v of retry %v", d, i)
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	require := require.New(t)
+
+	st := &statusTransport{responses: []*http.Response{
+		statusResponse(http.StatusForbidden, http.Header{"Retry-After": []string{"60"}}),
+		statusResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"2"}}),
+		statusResponse(http.StatusOK, nil),
+	}}
+	clock := &fakeClock{}
+	rt := &retryTransport{T: st, clock: clock}
+
+	_, err := rt.RoundTrip(newQueryRequest(t, context.TODO()))
+	require.NoError(err)
+	require.Equal([]time.Duration{time.Minute, 2 * time.Second}, clock.sleeps)
+
+	// without Retry-After a 403 is not a secondary rate limit
+	st = &statusTransport{responses: []*http.Response{statusResponse(http.StatusForbidden, nil)}}
+	clock = &fakeClock{}
+	rt = &retryTransport{T: st, clock: clock}
+
+	_, err = rt.RoundTrip(newQueryRequest(t, context.TODO()))
+	require.EqualError(err, `non-200 OK status code: 403 Forbidden body: "{\"data\": {}}"`)
+	require.Len(st.bodies, 1)
+	require.Empty(clock.sleeps)
+}
+
+func TestRetryTransportMaxRetries(t *testing.T) {
+	require := require.New(t)
+
+	st := &statusTransport{responses: []*http.Response{statusResponse(http.StatusBadGateway, nil)}}
+	clock := &fakeClock{}
+	rt := &retryTransport{T: st, MaxRetries: 2, clock: clock}
+
+	_, err := rt.RoundTrip(newQueryRequest(t, context.TODO()))
+	require.Error(err)
+	require.Len(st.bodies, 3)
+	require.Len(clock.sleeps, 2)
+}
+
+func TestRetryTransportUnrewindable(t *testing.T) {
+	require := require.New(t)
+
+	st := &statusTransport{responses: []*http.Response{statusResponse(http.StatusBadGateway, nil)}}
+	clock := &fakeClock{}
+	rt := &retryTransport{T: st, clock: clock}
+
+	req := newQueryRequest(t, context.TODO())
+	req.GetBody = nil
+
+	_, err := rt.RoundTrip(req)
+	require.Error(err)
+	require.Len(st.bodies, 1)
+	require.Empty(clock.sleeps)
+}
+
+func TestRetryTransportContext(t *testing.T) {
+	require := require.New(t)
+
+	st := &statusTransport{responses: []*http.Response{statusResponse(http.StatusBadGateway, nil)}}
+	rt := &retryTransport{T: st, BaseDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := rt.RoundTrip(newQueryRequest(t, ctx))
+	require.Equal(context.Canceled, err)
+	require.Len(st.bodies, 1)
+}
+
+func TestRetryTransportSecondaryRateLimit(t *testing.T) {
+	require := require.New(t)
+
+	abuse := statusResponse(http.StatusForbidden, nil)
+	abuse.Body = ioutil.NopCloser(strings.NewReader(`{"documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits",
+		"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`))
+
+	st := &statusTransport{responses: []*http.Response{abuse, statusResponse(http.StatusOK, nil)}}
+	clock := &fakeClock{}
+	rt := &retryTransport{T: st, clock: clock}
+
+	r, err := rt.RoundTrip(newQueryRequest(t, context.TODO()))
+	require.NoError(err)
+	require.Equal(http.StatusOK, r.StatusCode)
+	require.Len(st.bodies, 2)
+	require.Equal([]time.Duration{secondaryRateLimitDelay}, clock.sleeps)
+}
@@ -0,0 +1,164 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// DownloadIssue downloads a single issue of the repository by its number, with
+// all its resources, like DownloadRepository does for each issue, e.g. to
+// refresh it without downloading the whole repository. The repository is not
+// saved. It is saved in its own transaction, with the given version. A
+// missing issue is returned as a *NotFoundError, and the other errors are
+// classified like the ones of DownloadRepository
+func (d Downloader) DownloadIssue(ctx context.Context, owner string, name string, number int, version int) error {
+	err := d.downloadSingle(ctx, version, func(d Downloader) error {
+		var q struct {
+			Repository struct {
+				Issue *graphql.Issue `graphql:"issue(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+
+		variables := map[string]interface{}{
+			"owner":  githubv4.String(owner),
+			"name":   githubv4.String(name),
+			"number": githubv4.Int(number),
+
+			"assigneesPage":         d.pageSize(d.PageSizes.Assignees, assigneesPage),
+			"assignmentEventsPage":  d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+			"closingReferencesPage": d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+			"issueCommentsPage":     d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+			"labelsPage":            d.pageSize(d.PageSizes.Labels, labelsPage),
+			"timelineItemsPage":     d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+			"assigneesCursor":         (*githubv4.String)(nil),
+			"assignmentEventsCursor":  (*githubv4.String)(nil),
+			"closingReferencesCursor": (*githubv4.String)(nil),
+			"issueCommentsCursor":     (*githubv4.String)(nil),
+			"labelsCursor":            (*githubv4.String)(nil),
+			"timelineItemsCursor":     (*githubv4.String)(nil),
+
+			"bodiesOmitted":          githubv4.Boolean(d.BodiesOmitted),
+			"timelineEventsIncluded": githubv4.Boolean(d.TimelineEventsIncluded),
+		}
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return fmt.Errorf("failed to query issue %v/%v #%v: %v", owner, name, number, err)
+		}
+
+		if q.Repository.Issue == nil {
+			return &NotFoundError{Kind: "issue", Err: fmt.Errorf("issue %v/%v #%v not found", owner, name, number)}
+		}
+
+		progress := d.newProgress(ProgressIssues, owner, name)
+		err = d.processIssue(ctx, owner, name, q.Repository.Issue, progress)
+		if err != nil {
+			return fmt.Errorf("failed to process issue %v/%v #%v: %v", owner, name, number, err)
+		}
+
+		return nil
+	})
+
+	return d.classifyError(ctx, err)
+}
+
+// DownloadPullRequest is like DownloadIssue, for a single PR. The PR is
+// downloaded even if PullRequestStates does not include its state
+func (d Downloader) DownloadPullRequest(ctx context.Context, owner string, name string, number int, version int) error {
+	err := d.downloadSingle(ctx, version, func(d Downloader) error {
+		var q struct {
+			Repository struct {
+				PullRequest *graphql.PullRequest `graphql:"pullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+
+		variables := map[string]interface{}{
+			"owner":  githubv4.String(owner),
+			"name":   githubv4.String(name),
+			"number": githubv4.Int(number),
+
+			"assigneesPage":                 d.pageSize(d.PageSizes.Assignees, assigneesPage),
+			"assignmentEventsPage":          d.pageSize(d.PageSizes.AssignmentEvents, assignmentEventsPage),
+			"closingReferencesPage":         d.pageSize(d.PageSizes.ClosingReferences, closingReferencesPage),
+			"issueCommentsPage":             d.pageSize(d.PageSizes.IssueComments, issueCommentsPage),
+			"labelsPage":                    d.pageSize(d.PageSizes.Labels, labelsPage),
+			"pullRequestCommitsPage":        d.pageSize(d.PageSizes.PullRequestCommits, pullRequestCommitsPage),
+			"pullRequestReviewCommentsPage": d.pageSize(d.PageSizes.PullRequestReviewComments, pullRequestReviewCommentsPage),
+			"pullRequestReviewsPage":        d.pageSize(d.PageSizes.PullRequestReviews, pullRequestReviewsPage),
+			"pullRequestReviewsAuthor":      d.reviewsAuthor(),
+			"timelineItemsPage":             d.pageSize(d.PageSizes.TimelineItems, timelineItemsPage),
+
+			"assigneesCursor":                 (*githubv4.String)(nil),
+			"assignmentEventsCursor":          (*githubv4.String)(nil),
+			"closingReferencesCursor":         (*githubv4.String)(nil),
+			"issueCommentsCursor":             (*githubv4.String)(nil),
+			"labelsCursor":                    (*githubv4.String)(nil),
+			"pullRequestCommitsCursor":        (*githubv4.String)(nil),
+			"pullRequestReviewCommentsCursor": (*githubv4.String)(nil),
+			"pullRequestReviewsCursor":        (*githubv4.String)(nil),
+			"timelineItemsCursor":             (*githubv4.String)(nil),
+
+			"bodiesOmitted":              githubv4.Boolean(d.BodiesOmitted),
+			"projectStatusesIncluded":    githubv4.Boolean(d.ProjectStatusesIncluded),
+			"pullRequestCommitsIncluded": githubv4.Boolean(d.PullRequestCommitsIncluded || d.CommitAuthorsIncluded),
+			"commitAuthorsIncluded":      githubv4.Boolean(d.CommitAuthorsIncluded),
+			"timelineEventsIncluded":     githubv4.Boolean(d.TimelineEventsIncluded),
+		}
+
+		err := d.query(ctx, &q, variables)
+		if err != nil {
+			return fmt.Errorf("failed to query PR %v/%v #%v: %v", owner, name, number, err)
+		}
+
+		if q.Repository.PullRequest == nil {
+			return &NotFoundError{Kind: "pull request", Err: fmt.Errorf("PR %v/%v #%v not found", owner, name, number)}
+		}
+
+		progress := d.newProgress(ProgressPullRequests, owner, name)
+		err = d.processPullRequest(ctx, owner, name, q.Repository.PullRequest, progress)
+		if err != nil {
+			return fmt.Errorf("failed to process PR %v/%v #%v: %v", owner, name, number, err)
+		}
+
+		return nil
+	})
+
+	return d.classifyError(ctx, err)
+}
+
+// downloadSingle calls download in a new transaction with the given version,
+// with the Downloader prepared for it
+func (d Downloader) downloadSingle(ctx context.Context, version int, download func(d Downloader) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	version, err := d.downloadVersion(version)
+	if err != nil {
+		return err
+	}
+
+	d.storer = d.transformedStorer()
+	d.storer = d.authorsStorer()
+	d.storer.Version(version)
+	d.users = newNodeCache()
+	d.rateGuard = d.newRateGuard()
+
+	err = d.storer.Begin()
+	if err != nil {
+		return fmt.Errorf("could not call Begin(): %v", err)
+	}
+
+	err = download(d)
+	if err != nil {
+		d.storer.Rollback()
+		return err
+	}
+
+	return d.storer.Commit()
+}
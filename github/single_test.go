@@ -0,0 +1,84 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadIssue(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		if strings.Contains(query, "comments(first: $issueCommentsPage") && variables["issueCommentsCursor"] != nil {
+			return `{"data": {"node": {"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [
+				{"id": "i2-c2", "updatedAt": "2019-10-02T10:00:00Z"}
+			]}}}}`
+		}
+
+		if variables["number"] != float64(2) {
+			return `{"data": {"repository": {"issue": null}}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to an Issue with the number of 99."}]}`
+		}
+
+		return `{"data": {"repository": {"issue": {
+			"id": "i2", "number": 2, "title": "Noisy",
+			"comments": {"pageInfo": {"hasNextPage": true, "endCursor": "e1"}, "nodes": [
+				{"id": "i2-c1", "updatedAt": "2019-10-01T10:00:00Z"}
+			]}
+		}}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadIssue(context.TODO(), "git-fixtures", "basic", 2, 0))
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Len(repo.Issues, 1)
+	issue := repo.Issues[2]
+	require.Equal("Noisy", issue.Title)
+	require.Len(issue.Comments, 2)
+	require.Equal("i2-c2", issue.Comments[1].Id)
+
+	err := d.DownloadIssue(context.TODO(), "git-fixtures", "basic", 99, 0)
+	var notFound *NotFoundError
+	require.True(errors.As(err, &notFound))
+	require.Equal("issue", notFound.Kind)
+}
+
+func TestDownloadPullRequest(t *testing.T) {
+	handler := func(query string, variables map[string]interface{}) string {
+		if variables["number"] != float64(3) {
+			return `{"data": {"repository": {"pullRequest": null}}}`
+		}
+
+		return `{"data": {"repository": {"pullRequest": {
+			"id": "pr3", "number": 3, "state": "MERGED", "headRefOid": "h1",
+			"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "pr3-c1"}]},
+			"reviews": {"pageInfo": {"hasNextPage": false}, "nodes": [{
+				"id": "pr3-r1", "databaseId": 10, "state": "APPROVED",
+				"comments": {"pageInfo": {"hasNextPage": false}, "nodes": [{"id": "pr3-r1-c1"}]}
+			}]}
+		}}}}`
+	}
+
+	require := require.New(t)
+
+	d, m := newTestMemDownloader(t, handler)
+	require.NoError(d.DownloadPullRequest(context.TODO(), "git-fixtures", "basic", 3, 0))
+
+	repo := m.Repos["git-fixtures"]["basic"]
+	require.Len(repo.PRs, 1)
+	pr := repo.PRs[3]
+	require.Equal("h1", pr.HeadRefOid)
+	require.Len(pr.Comments, 1)
+	require.Len(pr.Reviews, 1)
+	require.Len(pr.Reviews[0].Comments, 1)
+
+	// a PR answered with null data is not found too
+	err := d.DownloadPullRequest(context.TODO(), "git-fixtures", "basic", 4, 0)
+	var notFound *NotFoundError
+	require.True(errors.As(err, &notFound))
+	require.Equal("pull request", notFound.Kind)
+}
@@ -0,0 +1,440 @@
+package store
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// The kinds of rows exported by Mem.ExportCSV
+const (
+	CSVIssues       = "issues"
+	CSVPullRequests = "pull_requests"
+)
+
+// csvHeader are the columns written by Mem.ExportCSV
+var csvHeader = []string{
+	"repository", "number", "title", "author", "state", "created_at",
+	"closed_at", "labels", "assignees", "comments",
+}
+
+// ExportCSV writes a CSV summary of the issues, or the PRs, of all the
+// repositories, depending on kind, CSVIssues or CSVPullRequests. There is one
+// row per issue or PR, sorted by repository and number, after a header row.
+// The labels and assignees are joined with commas, the dates are in RFC 3339
+// format, and empty if not set. The comments column is the number of comments
+// in the store
+func (s *Mem) ExportCSV(w io.Writer, kind string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if kind != CSVIssues && kind != CSVPullRequests {
+		return fmt.Errorf("unknown CSV kind %q, it must be %q or %q", kind, CSVIssues, CSVPullRequests)
+	}
+
+	var rows [][]string
+	for owner, byName := range s.Repos {
+		for name, r := range byName {
+			repository := owner + "/" + name
+
+			if kind == CSVIssues {
+				for _, i := range r.Issues {
+					rows = append(rows, csvRow(repository, i.Number, i.Title, i.Author.Login, i.State,
+						i.CreatedAt, i.ClosedAt, i.Labels, i.Assignees, len(i.Comments)))
+				}
+				continue
+			}
+
+			for _, p := range r.PRs {
+				rows = append(rows, csvRow(repository, p.Number, p.Title, p.Author.Login, p.State,
+					p.CreatedAt, p.ClosedAt, p.Labels, p.Assignees, len(p.Comments)))
+			}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i][0] != rows[j][0] {
+			return rows[i][0] < rows[j][0]
+		}
+
+		ni, _ := strconv.Atoi(rows[i][1])
+		nj, _ := strconv.Atoi(rows[j][1])
+		return ni < nj
+	})
+
+	cw := csv.NewWriter(w)
+	// RFC 4180 lines end with CRLF
+	cw.UseCRLF = true
+
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	err = cw.WriteAll(rows)
+	if err != nil {
+		return fmt.Errorf("failed to write the CSV rows: %v", err)
+	}
+
+	return nil
+}
+
+func csvRow(repository string, number int, title, author, state string, createdAt, closedAt time.Time, labels, assignees []string, comments int) []string {
+	return []string{
+		repository,
+		strconv.Itoa(number),
+		title,
+		author,
+		state,
+		csvTime(createdAt),
+		csvTime(closedAt),
+		strings.Join(labels, ","),
+		strings.Join(assignees, ","),
+		strconv.Itoa(comments),
+	}
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// The files written by CSV in its directory
+const (
+	CSVIssuesFile       = "issues.csv"
+	CSVPullRequestsFile = "pull_requests.csv"
+	CSVCommentsFile     = "comments.csv"
+	CSVReviewsFile      = "reviews.csv"
+)
+
+// csvFiles are the files written by CSV, with their header rows
+var csvFiles = []struct {
+	name   string
+	header []string
+}{
+	{CSVIssuesFile, []string{
+		"repository", "number", "title", "author", "state", "created_at",
+		"updated_at", "closed_at", "labels", "assignees", "comments", "body",
+	}},
+	{CSVPullRequestsFile, []string{
+		"repository", "number", "title", "author", "state", "created_at",
+		"updated_at", "closed_at", "merged_at", "labels", "assignees",
+		"base_ref", "head_ref", "base_sha", "head_sha", "body",
+	}},
+	{CSVCommentsFile, []string{
+		"repository", "number", "node_id", "author", "author_association",
+		"created_at", "updated_at", "body",
+	}},
+	{CSVReviewsFile, []string{
+		"repository", "pull_request_number", "node_id", "author", "state",
+		"submitted_at", "body",
+	}},
+}
+
+// CSV writes the issues, PRs, comments and reviews as flat CSV files in a
+// directory, see the CSV file constants, for analysis tools that cannot read
+// the other stores. The issue and PR comments are both in comments.csv. The
+// other entities are not written.
+//
+// Begin creates or truncates the files and writes their header rows, so they
+// only hold the rows of the latest transaction, and Commit flushes and closes
+// them. Rollback closes them too, with the rows written so far. The labels
+// and assignees are joined with semicolons, the dates are in RFC 3339 format,
+// and empty if not set. It is safe for concurrent use
+type CSV struct {
+	dir string
+
+	mu sync.Mutex
+	// files are the open files by name, nil outside a transaction
+	files map[string]*csvFile
+}
+
+type csvFile struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// NewCSV returns a CSV that writes its files to the directory dir, which
+// must exist
+func NewCSV(dir string) *CSV {
+	return &CSV{dir: dir}
+}
+
+func (s *CSV) Begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files != nil {
+		return fmt.Errorf("transaction already started")
+	}
+
+	s.files = make(map[string]*csvFile, len(csvFiles))
+	for _, file := range csvFiles {
+		f, err := os.Create(filepath.Join(s.dir, file.name))
+		if err != nil {
+			s.close()
+			return fmt.Errorf("failed to create %v: %v", file.name, err)
+		}
+
+		w := csv.NewWriter(f)
+		// RFC 4180 lines end with CRLF
+		w.UseCRLF = true
+		s.files[file.name] = &csvFile{f: f, w: w}
+
+		err = w.Write(file.header)
+		if err != nil {
+			s.close()
+			return fmt.Errorf("failed to write the header of %v: %v", file.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *CSV) Commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files == nil {
+		return fmt.Errorf("no transaction started")
+	}
+
+	return s.close()
+}
+
+func (s *CSV) Rollback() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.close()
+	return nil
+}
+
+// close flushes and closes the open files, it returns the first error
+func (s *CSV) close() error {
+	var res error
+	for _, file := range csvFiles {
+		cf, ok := s.files[file.name]
+		if !ok {
+			continue
+		}
+
+		cf.w.Flush()
+		err := cf.w.Error()
+		if cerr := cf.f.Close(); err == nil {
+			err = cerr
+		}
+
+		if err != nil && res == nil {
+			res = fmt.Errorf("failed to write %v: %v", file.name, err)
+		}
+	}
+
+	s.files = nil
+	return res
+}
+
+// write writes a row to the file with the given name
+func (s *CSV) write(name string, row []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files == nil {
+		return fmt.Errorf("no transaction started")
+	}
+
+	err := s.files[name].w.Write(row)
+	if err != nil {
+		return fmt.Errorf("failed to write a row of %v: %v", name, err)
+	}
+
+	return nil
+}
+
+// Version is a noop, the files hold a single download
+func (s *CSV) Version(v int) {
+}
+
+// SetActiveVersion is a noop, the files hold a single download
+func (s *CSV) SetActiveVersion(v int) error {
+	return nil
+}
+
+// Cleanup is a noop, the files hold a single download
+func (s *CSV) Cleanup(currentVersion int) error {
+	return nil
+}
+
+func (s *CSV) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	return s.write(CSVIssuesFile, []string{
+		repositoryOwner + "/" + repositoryName,
+		strconv.Itoa(issue.Number),
+		issue.Title,
+		issue.Author.Login,
+		issue.State,
+		csvTime(issue.CreatedAt),
+		csvTime(issue.UpdatedAt),
+		csvTime(issue.ClosedAt),
+		strings.Join(labels, ";"),
+		strings.Join(assignees, ";"),
+		strconv.Itoa(issue.Comments.TotalCount),
+		issue.Body,
+	})
+}
+
+func (s *CSV) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	return s.write(CSVPullRequestsFile, []string{
+		repositoryOwner + "/" + repositoryName,
+		strconv.Itoa(pr.Number),
+		pr.Title,
+		pr.Author.Login,
+		pr.State,
+		csvTime(pr.CreatedAt),
+		pr.UpdatedAt,
+		csvTime(pr.ClosedAt),
+		csvTime(pr.MergedAt),
+		strings.Join(labels, ";"),
+		strings.Join(assignees, ";"),
+		pr.BaseRef.Name,
+		pr.HeadRef.Name,
+		refSHA(pr.BaseRefOid, pr.BaseRef),
+		refSHA(pr.HeadRefOid, pr.HeadRef),
+		pr.Body,
+	})
+}
+
+func (s *CSV) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	return s.saveComment(repositoryOwner, repositoryName, issueNumber, comment)
+}
+
+func (s *CSV) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	return s.saveComment(repositoryOwner, repositoryName, pullRequestNumber, comment)
+}
+
+func (s *CSV) saveComment(repositoryOwner, repositoryName string, number int, comment *graphql.IssueComment) error {
+	return s.write(CSVCommentsFile, []string{
+		repositoryOwner + "/" + repositoryName,
+		strconv.Itoa(number),
+		comment.Id,
+		comment.Author.LoginOrGhost(),
+		comment.AuthorAssociation,
+		csvTime(comment.CreatedAt),
+		comment.UpdatedAt,
+		comment.Body,
+	})
+}
+
+func (s *CSV) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	var submittedAt string
+	if review.SubmittedAt != nil {
+		submittedAt = csvTime(*review.SubmittedAt)
+	}
+
+	return s.write(CSVReviewsFile, []string{
+		repositoryOwner + "/" + repositoryName,
+		strconv.Itoa(pullRequestNumber),
+		review.Id,
+		review.Author.LoginOrGhost(),
+		review.State,
+		submittedAt,
+		review.Body,
+	})
+}
+
+// The other entities are not written
+
+func (s *CSV) SaveOrganization(organization *graphql.Organization) error {
+	return nil
+}
+
+func (s *CSV) SaveUser(user *graphql.UserExtended) error {
+	return nil
+}
+
+func (s *CSV) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	return nil
+}
+
+func (s *CSV) SaveTopic(name string) error {
+	return nil
+}
+
+func (s *CSV) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	return nil
+}
+
+func (s *CSV) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	return nil
+}
+
+func (s *CSV) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	return nil
+}
+
+func (s *CSV) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	return nil
+}
+
+func (s *CSV) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	return nil
+}
+
+func (s *CSV) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	return nil
+}
+
+func (s *CSV) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	return nil
+}
+
+func (s *CSV) SaveMention(subjectID, mentionedLogin string) error {
+	return nil
+}
+
+func (s *CSV) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	return nil
+}
+
+func (s *CSV) SaveClosingReference(reference *ClosingReference) error {
+	return nil
+}
+
+func (s *CSV) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	return nil
+}
+
+func (s *CSV) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	return nil
+}
+
+func (s *CSV) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	return nil
+}
+
+func (s *CSV) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	return nil
+}
+
+func (s *CSV) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	return nil
+}
+
+func (s *CSV) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	return nil
+}
+
+func (s *CSV) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	return nil
+}
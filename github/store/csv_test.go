@@ -0,0 +1,136 @@
+package store
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCSV(t *testing.T) {
+	require := require.New(t)
+
+	m := NewMem()
+	saveRepo(t, m, "src-d", "go-git", 10)
+
+	issue := &graphql.Issue{}
+	issue.Number = 2
+	issue.Title = `Fix "git log", again`
+	issue.Author.Login = "alice"
+	issue.State = "CLOSED"
+	issue.CreatedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	issue.ClosedAt = time.Date(2019, 10, 2, 12, 30, 0, 0, time.UTC)
+	require.NoError(m.SaveIssue("src-d", "go-git", issue, []string{"alice", "bob"}, []string{"bug", "help wanted"}))
+	require.NoError(m.SaveIssueComment("src-d", "go-git", 2, &graphql.IssueComment{Id: "c1"}))
+	require.NoError(m.SaveIssueComment("src-d", "go-git", 2, &graphql.IssueComment{Id: "c2"}))
+
+	issue = &graphql.Issue{}
+	issue.Number = 1
+	issue.Title = "Multi-line\ntitle"
+	issue.State = "OPEN"
+	require.NoError(m.SaveIssue("src-d", "go-git", issue, nil, nil))
+
+	var buf bytes.Buffer
+	require.NoError(m.ExportCSV(&buf, CSVIssues))
+	require.Equal(
+		"repository,number,title,author,state,created_at,closed_at,labels,assignees,comments\r\n"+
+			"src-d/go-git,1,\"Multi-line\r\ntitle\",,OPEN,,,,,0\r\n"+
+			"src-d/go-git,2,\"Fix \"\"git log\"\", again\",alice,CLOSED,2019-10-01T10:00:00Z,2019-10-02T12:30:00Z,\"bug,help wanted\",\"alice,bob\",2\r\n",
+		buf.String())
+
+	buf.Reset()
+	require.NoError(m.ExportCSV(&buf, CSVPullRequests))
+	require.Equal(
+		"repository,number,title,author,state,created_at,closed_at,labels,assignees,comments\r\n"+
+			"src-d/go-git,10,,,,,,,,0\r\n",
+		buf.String())
+
+	require.Error(m.ExportCSV(&buf, "commits"))
+}
+
+func TestCSV(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "csv")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	s := NewCSV(dir)
+	require.Error(s.SaveIssue("src-d", "go-git", &graphql.Issue{}, nil, nil))
+
+	require.NoError(s.Begin())
+	issue := &graphql.Issue{}
+	issue.Number = 1
+	issue.Title = "Stale"
+	require.NoError(s.SaveIssue("src-d", "go-git", issue, nil, nil))
+	require.NoError(s.Commit())
+
+	// a new transaction truncates the files
+	require.NoError(s.Begin())
+	require.Error(s.Begin())
+
+	issue = &graphql.Issue{}
+	issue.Number = 2
+	issue.Title = "Fix git log"
+	issue.Body = "It fails with a, b\nand c"
+	issue.Author.Login = "alice"
+	issue.State = "CLOSED"
+	issue.CreatedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(s.SaveIssue("src-d", "go-git", issue, []string{"alice", "bob"}, []string{"bug", "help wanted"}))
+
+	pr := &graphql.PullRequest{}
+	pr.Number = 3
+	pr.BaseRef.Name = "master"
+	pr.HeadRef.Name = "fix"
+	pr.HeadRefOid = "h1"
+	pr.BaseRef.Target.Oid = "b1"
+	require.NoError(s.SavePullRequest("src-d", "go-git", pr, nil, []string{"bug"}))
+
+	comment := &graphql.IssueComment{Id: "c1", Body: `"Quoted", twice`}
+	require.NoError(s.SaveIssueComment("src-d", "go-git", 2, comment))
+	require.NoError(s.SavePullRequestComment("src-d", "go-git", 3, &graphql.IssueComment{Id: "c2"}))
+
+	review := &graphql.PullRequestReview{}
+	review.Id = "r1"
+	review.State = "APPROVED"
+	require.NoError(s.SavePullRequestReview("src-d", "go-git", 3, review))
+	require.NoError(s.SaveUser(&graphql.UserExtended{}))
+	require.NoError(s.Commit())
+	require.Error(s.Commit())
+
+	read := func(name string) [][]string {
+		f, err := os.Open(filepath.Join(dir, name))
+		require.NoError(err)
+		defer f.Close()
+
+		records, err := csv.NewReader(f).ReadAll()
+		require.NoError(err)
+		return records
+	}
+
+	require.Equal([][]string{
+		{"repository", "number", "title", "author", "state", "created_at", "updated_at", "closed_at", "labels", "assignees", "comments", "body"},
+		{"src-d/go-git", "2", "Fix git log", "alice", "CLOSED", "2019-10-01T10:00:00Z", "", "", "bug;help wanted", "alice;bob", "0", "It fails with a, b\nand c"},
+	}, read(CSVIssuesFile))
+
+	prs := read(CSVPullRequestsFile)
+	require.Len(prs, 2)
+	require.Equal([]string{"src-d/go-git", "3", "", "", "", "", "", "", "", "bug", "", "master", "fix", "b1", "h1", ""}, prs[1])
+
+	comments := read(CSVCommentsFile)
+	require.Len(comments, 3)
+	require.Equal([]string{"src-d/go-git", "2", "c1", "ghost", "", "", "", `"Quoted", twice`}, comments[1])
+	require.Equal("3", comments[2][1])
+
+	require.Equal([][]string{
+		{"repository", "pull_request_number", "node_id", "author", "state", "submitted_at", "body"},
+		{"src-d/go-git", "3", "r1", "ghost", "APPROVED", "", ""},
+	}, read(CSVReviewsFile))
+}
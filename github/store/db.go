@@ -4,24 +4,56 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/src-d/metadata-retrieval/github/graphql"
 
 	"github.com/lib/pq"
+	"gopkg.in/src-d/go-log.v1"
 )
 
+// defaultBatchSize is the number of rows affected by each Cleanup statement
+// when DB.BatchSize is not set
+const defaultBatchSize = 10000
+
 type DB struct {
 	*sql.DB
-	tx *sql.Tx
-	v  int
+	// BatchSize is the maximum number of rows deleted or updated by each
+	// statement issued by Cleanup. Zero means defaultBatchSize
+	BatchSize int
+	// MaxBodyLength is the maximum length in bytes of the stored bodies of
+	// issues, PRs, comments and reviews. Longer bodies are truncated, without
+	// splitting UTF-8 characters, and their body_truncated column is set.
+	// Zero means unlimited
+	MaxBodyLength int
+	// Fields, if set, selects the columns saved in each table, the rest are
+	// saved as NULL. Begin fails if it is not valid
+	Fields Fields
+
+	tx       *sql.Tx
+	v        int
+	excluded map[string][]bool
 }
 
 func (s *DB) Begin() error {
-	var err error
+	err := s.Fields.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid fields: %v", err)
+	}
+	s.excluded = s.Fields.excluded()
+
 	s.tx, err = s.DB.Begin()
 	return err
 }
 
+// exec runs an insert statement in the given table, saving the excluded
+// Fields as NULL
+func (s *DB) exec(table string, statement string, args ...interface{}) (sql.Result, error) {
+	return s.tx.Exec(statement, filterColumns(s.excluded[table], args)...)
+}
+
 func (s *DB) Commit() error {
 	return s.tx.Commit()
 }
@@ -30,19 +62,113 @@ func (s *DB) Rollback() error {
 	return s.tx.Rollback()
 }
 
+// Savepoint marks the current state of the transaction, so the saves after
+// it can be undone with RollbackToSavepoint
+func (s *DB) Savepoint() error {
+	_, err := s.tx.Exec("SAVEPOINT item")
+	return err
+}
+
+// RollbackToSavepoint undoes the saves since the last Savepoint, and makes
+// the transaction usable again after a failed save
+func (s *DB) RollbackToSavepoint() error {
+	_, err := s.tx.Exec("ROLLBACK TO SAVEPOINT item")
+	if err != nil {
+		return err
+	}
+
+	return s.ReleaseSavepoint()
+}
+
+// ReleaseSavepoint keeps the saves since the last Savepoint, and forgets it
+func (s *DB) ReleaseSavepoint() error {
+	_, err := s.tx.Exec("RELEASE SAVEPOINT item")
+	return err
+}
+
 func (s *DB) Version(v int) {
 	s.v = v
 }
 
+// NextVersion returns the version after the greatest one saved in any table,
+// or 1 if the tables are empty
+func (s *DB) NextVersion() (int, error) {
+	selects := make([]string, len(tables))
+	for i, table := range tables {
+		selects[i] = fmt.Sprintf("SELECT unnest(versions) AS version FROM %s", table)
+	}
+
+	var next int
+	err := s.DB.QueryRow(fmt.Sprintf(
+		`SELECT COALESCE(MAX(version), 0) + 1 FROM (%s) AS all_versions`,
+		strings.Join(selects, " UNION ALL "))).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the next version: %v", err)
+	}
+
+	return next, nil
+}
+
+// SavedComments returns the update time of the issue or PR with the given
+// number saved in the current version, and the number of its comments saved
+// in it. If the issue or PR is saved several times in the version, the latest
+// update time is returned. The update time is zero if it is not saved. It must
+// be called inside a transaction
+func (s *DB) SavedComments(repositoryOwner, repositoryName string, number int) (time.Time, int, error) {
+	var (
+		updatedAt pq.NullTime
+		comments  int
+	)
+	err := s.tx.QueryRow(`SELECT updated_at, (
+			SELECT count(DISTINCT node_id) FROM issue_comments_versioned
+			WHERE repository_owner = $1 AND repository_name = $2 AND issue_number = $3 AND $4 = ANY(versions)
+		)
+		FROM (
+			SELECT updated_at FROM issues_versioned
+			WHERE repository_owner = $1 AND repository_name = $2 AND number = $3 AND $4 = ANY(versions)
+			UNION ALL
+			SELECT updated_at FROM pull_requests_versioned
+			WHERE repository_owner = $1 AND repository_name = $2 AND number = $3 AND $4 = ANY(versions)
+		) AS saved
+		ORDER BY updated_at DESC NULLS LAST
+		LIMIT 1`, repositoryOwner, repositoryName, number, s.v).Scan(&updatedAt, &comments)
+	if err == sql.ErrNoRows {
+		return time.Time{}, 0, nil
+	}
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to query the saved comments of %v/%v #%v: %v", repositoryOwner, repositoryName, number, err)
+	}
+
+	return updatedAt.Time, comments, nil
+}
+
 const (
 	organizationsCols             = "avatar_url, billing_email, collaborators, created_at, description, email, htmlurl, id, location, login, name, node_id, owned_private_repos, public_repos, total_private_repos, two_factor_requirement_enabled, updated_at"
 	usersCols                     = "avatar_url, bio, company, created_at, email, followers, following, hireable, htmlurl, id, location, login, name, node_id, owned_private_repos, private_gists, public_gists, public_repos, site_admin, total_private_repos, updated_at"
-	repositoriesCols              = "allow_merge_commit, allow_rebase_merge, allow_squash_merge, archived, clone_url, created_at, default_branch, description, disabled, fork, forks_count, full_name, has_issues, has_wiki, homepage, htmlurl, id, language, mirror_url, name, node_id, open_issues_count, owner_id, owner_login, owner_type, private, pushed_at, sshurl, stargazers_count, topics, updated_at, watchers_count"
-	issuesCols                    = "assignees, body, closed_at, closed_by_id, closed_by_login, comments, created_at, htmlurl, id, labels, locked, milestone_id, milestone_title, node_id, number, repository_name, repository_owner, state, title, updated_at, user_id, user_login"
-	issueCommentsCols             = "author_association, body, created_at, htmlurl, id, issue_number, node_id, repository_name, repository_owner, updated_at, user_id, user_login"
-	pullRequestsCol               = "additions, assignees, author_association, base_ref, base_repository_name, base_repository_owner, base_sha, base_user, body, changed_files, closed_at, comments, commits, created_at, deletions, head_ref, head_repository_name, head_repository_owner, head_sha, head_user, htmlurl, id, labels, maintainer_can_modify, merge_commit_sha, mergeable, merged, merged_at, merged_by_id, merged_by_login, milestone_id, milestone_title, node_id, number, repository_name, repository_owner, review_comments, state, title, updated_at, user_id, user_login"
-	pullRequestReviewsCols        = "body, commit_id, htmlurl, id, node_id, pull_request_number, repository_name, repository_owner, state, submitted_at, user_id, user_login"
-	pullRequestReviewCommentsCols = "author_association, body, commit_id, created_at, diff_hunk, htmlurl, id, in_reply_to, node_id, original_commit_id, original_position, path, position, pull_request_number, pull_request_review_id, repository_name, repository_owner, updated_at, user_id, user_login"
+	repositoriesCols              = "allow_merge_commit, allow_rebase_merge, allow_squash_merge, archived, clone_url, created_at, default_branch, description, disabled, fork, forks_count, full_name, has_issues, has_wiki, homepage, htmlurl, id, language, mirror_url, name, node_id, open_issues_count, owner_id, owner_login, owner_type, private, pushed_at, sshurl, stargazers_count, topics, updated_at, watchers_count, default_branch_sha, default_branch_committed_at, open_graph_image_url, uses_custom_open_graph_image"
+	issuesCols                    = "assignees, body, closed_at, closed_by_id, closed_by_login, comments, created_at, htmlurl, id, labels, locked, milestone_id, milestone_title, node_id, number, repository_name, repository_owner, state, title, updated_at, user_id, user_login, body_truncated, tasks_done, tasks_total, milestone_due_on, milestone_state, last_edited_at, last_edited_by_id, last_edited_by_login"
+	issueCommentsCols             = "author_association, body, created_at, htmlurl, id, issue_number, node_id, repository_name, repository_owner, updated_at, user_id, user_login, body_truncated, last_edited_at, last_edited_by_id, last_edited_by_login"
+	pullRequestsCol               = "additions, assignees, author_association, base_ref, base_repository_name, base_repository_owner, base_sha, base_user, body, changed_files, closed_at, comments, commits, created_at, deletions, head_ref, head_repository_name, head_repository_owner, head_sha, head_user, htmlurl, id, labels, maintainer_can_modify, merge_commit_sha, mergeable, merged, merged_at, merged_by_id, merged_by_login, milestone_id, milestone_title, node_id, number, repository_name, repository_owner, review_comments, state, title, updated_at, user_id, user_login, mergeable_state, potential_merge_commit_sha, body_truncated, tasks_done, tasks_total, merge_method, milestone_due_on, milestone_state, last_edited_at, last_edited_by_id, last_edited_by_login"
+	pullRequestReviewsCols        = "body, commit_id, htmlurl, id, node_id, pull_request_number, repository_name, repository_owner, state, submitted_at, user_id, user_login, body_truncated, last_edited_at, last_edited_by_id, last_edited_by_login"
+	pullRequestReviewCommentsCols = "author_association, body, commit_id, created_at, diff_hunk, htmlurl, id, in_reply_to, node_id, original_commit_id, original_position, path, position, pull_request_number, pull_request_review_id, repository_name, repository_owner, updated_at, user_id, user_login, body_truncated, last_edited_at, last_edited_by_id, last_edited_by_login"
+	topicsCols                    = "name"
+	repositoryTopicsCols          = "repository_name, repository_owner, topic"
+	reviewTransitionsCols         = "from_state, pull_request_number, pull_request_review_id, repository_name, repository_owner, sequence, submitted_at, to_state, user_login"
+	pullRequestCommitsCols        = "author_email, author_login, author_name, authored_at, committed_at, committer_email, committer_login, committer_name, message, oid, pull_request_number, repository_name, repository_owner"
+	assignmentEventsCols          = "actor_login, assignee_login, created_at, event, node_id, number, repository_name, repository_owner"
+	issueEventsCols               = "actor_login, created_at, event, issue_number, node_id, repository_name, repository_owner"
+	pullRequestEventsCols         = "actor_login, created_at, event, node_id, pull_request_number, repository_name, repository_owner"
+	mentionsCols                  = "mentioned_login, subject_id"
+	reactionsCols                 = "content, subject_id, total_count"
+	closingReferencesCols         = "issue_number, issue_repository_name, issue_repository_owner, pull_request_number, pull_request_repository_name, pull_request_repository_owner"
+	locksCols                     = "lock_reason, locked_at, locked_by_id, locked_by_login, node_id, number, repository_name, repository_owner"
+	projectStatusesCols           = "node_id, project_node_id, project_number, project_title, pull_request_number, pull_request_updated_at, repository_name, repository_owner, status, status_updated_at"
+	auditLogEntriesCols           = "action, actor_ip, actor_login, created_at, entry_type, node_id, operation_type, organization_login, user_login"
+	templatesCols                 = "about, body, filename, kind, name, repository_name, repository_owner, title"
+	readmesCols                   = "body, byte_size, filename, repository_name, repository_owner"
+	orgProjectsCols               = "closed, created_at, field_data_types, field_names, node_id, number, organization_login, public, short_description, title, updated_at, url"
+	orgProjectItemsCols           = "archived, content_node_id, content_number, content_repository, content_title, created_at, item_type, node_id, organization_login, project_node_id, project_number, status, status_updated_at, updated_at"
+	rulesetsCols                  = "conditions_exclude, conditions_include, created_at, enforcement, id, name, node_id, repository_name, repository_owner, rule_types, target, updated_at"
 )
 
 var tables = []string{
@@ -54,6 +180,24 @@ var tables = []string{
 	"pull_requests_versioned",
 	"pull_request_reviews_versioned",
 	"pull_request_comments_versioned",
+	"topics_versioned",
+	"repository_topics_versioned",
+	"pull_request_review_transitions_versioned",
+	"pull_request_commits_versioned",
+	"assignment_events_versioned",
+	"issue_events_versioned",
+	"pull_request_events_versioned",
+	"mentions_versioned",
+	"reactions_versioned",
+	"repository_rulesets_versioned",
+	"audit_log_entries_versioned",
+	"closing_references_versioned",
+	"locks_versioned",
+	"templates_versioned",
+	"project_statuses_versioned",
+	"readmes_versioned",
+	"org_projects_versioned",
+	"org_project_items_versioned",
 }
 
 func (s *DB) SetActiveVersion(v int) error {
@@ -116,28 +260,220 @@ func (s *DB) SetActiveVersion(v int) error {
 		return fmt.Errorf("failed to create VIEW pull_request_comments: %v", err)
 	}
 
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW topics AS
+	SELECT %s
+	FROM topics_versioned WHERE %v = ANY(versions)`, topicsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW topics: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW repository_topics AS
+	SELECT %s
+	FROM repository_topics_versioned WHERE %v = ANY(versions)`, repositoryTopicsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW repository_topics: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW pull_request_review_transitions AS
+	SELECT %s
+	FROM pull_request_review_transitions_versioned WHERE %v = ANY(versions)`, reviewTransitionsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW pull_request_review_transitions: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW pull_request_commits AS
+	SELECT %s
+	FROM pull_request_commits_versioned WHERE %v = ANY(versions)`, pullRequestCommitsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW pull_request_commits: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW assignment_events AS
+	SELECT %s
+	FROM assignment_events_versioned WHERE %v = ANY(versions)`, assignmentEventsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW assignment_events: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW issue_events AS
+	SELECT %s
+	FROM issue_events_versioned WHERE %v = ANY(versions)`, issueEventsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW issue_events: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW pull_request_events AS
+	SELECT %s
+	FROM pull_request_events_versioned WHERE %v = ANY(versions)`, pullRequestEventsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW pull_request_events: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW mentions AS
+	SELECT %s
+	FROM mentions_versioned WHERE %v = ANY(versions)`, mentionsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW mentions: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW reactions AS
+	SELECT %s
+	FROM reactions_versioned WHERE %v = ANY(versions)`, reactionsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW reactions: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW repository_rulesets AS
+	SELECT %s
+	FROM repository_rulesets_versioned WHERE %v = ANY(versions)`, rulesetsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW repository_rulesets: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW audit_log_entries AS
+	SELECT %s
+	FROM audit_log_entries_versioned WHERE %v = ANY(versions)`, auditLogEntriesCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW audit_log_entries: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW closing_references AS
+	SELECT %s
+	FROM closing_references_versioned WHERE %v = ANY(versions)`, closingReferencesCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW closing_references: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW locks AS
+	SELECT %s
+	FROM locks_versioned WHERE %v = ANY(versions)`, locksCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW locks: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW templates AS
+	SELECT %s
+	FROM templates_versioned WHERE %v = ANY(versions)`, templatesCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW templates: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW project_statuses AS
+	SELECT %s
+	FROM project_statuses_versioned WHERE %v = ANY(versions)`, projectStatusesCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW project_statuses: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW readmes AS
+	SELECT %s
+	FROM readmes_versioned WHERE %v = ANY(versions)`, readmesCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW readmes: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW org_projects AS
+	SELECT %s
+	FROM org_projects_versioned WHERE %v = ANY(versions)`, orgProjectsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW org_projects: %v", err)
+	}
+
+	_, err = s.DB.Exec(fmt.Sprintf(`CREATE OR REPLACE VIEW org_project_items AS
+	SELECT %s
+	FROM org_project_items_versioned WHERE %v = ANY(versions)`, orgProjectItemsCols, v))
+	if err != nil {
+		return fmt.Errorf("failed to create VIEW org_project_items: %v", err)
+	}
+
 	return nil
 }
 
+// Cleanup deletes all the rows that do not belong to currentVersion, and
+// sets currentVersion as the only version of the remaining ones.
+// Rows are deleted and updated in batches of at most BatchSize rows, each one
+// in its own statement, to avoid locking the tables for a long time. If the
+// cleanup is interrupted it can be safely called again to resume it
 func (s *DB) Cleanup(currentVersion int) error {
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
 	for _, table := range tables {
+		logger := log.With(log.Fields{"table": table, "version": currentVersion})
+
 		// Delete all entries that do not belong to currentVersion
-		_, err := s.DB.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %v <> ALL(versions)`, table, currentVersion))
+		deleted, err := s.batchExec(fmt.Sprintf(
+			`DELETE FROM %[1]s WHERE sum256 IN (
+				SELECT sum256 FROM %[1]s WHERE %[2]v <> ALL(versions) LIMIT %[3]v
+			)`, table, currentVersion, batchSize), logger, "deleted")
 		if err != nil {
 			return fmt.Errorf("failed in cleanup method, delete: %v", err)
 		}
 
 		// All remaining entries belong to currentVersion, replace the list of versions
 		// with an array of 1 entry
-		_, err = s.DB.Exec(fmt.Sprintf(`UPDATE %s SET versions = array[%v]`, table, currentVersion))
+		updated, err := s.batchExec(fmt.Sprintf(
+			`UPDATE %[1]s SET versions = array[%[2]v] WHERE sum256 IN (
+				SELECT sum256 FROM %[1]s WHERE versions <> array[%[2]v] LIMIT %[3]v
+			)`, table, currentVersion, batchSize), logger, "updated")
 		if err != nil {
 			return fmt.Errorf("failed in cleanup method, update: %v", err)
 		}
+
+		logger.With(log.Fields{"deleted": deleted, "updated": updated}).Infof("cleanup finished")
 	}
 
 	return nil
 }
 
+// batchExec runs the given statement until it does not affect any more rows,
+// and returns the total number of affected rows
+func (s *DB) batchExec(statement string, logger log.Logger, action string) (int64, error) {
+	var total int64
+	for {
+		res, err := s.DB.Exec(statement)
+		if err != nil {
+			return total, err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+
+		if n == 0 {
+			return total, nil
+		}
+
+		total += n
+		logger.With(log.Fields{action: total}).Debugf("cleanup in progress")
+	}
+}
+
+// truncateBody returns body truncated to MaxBodyLength, and whether it was
+// truncated
+func (s *DB) truncateBody(body string) (string, bool) {
+	return truncateUTF8(body, s.MaxBodyLength)
+}
+
+// truncateUTF8 returns the longest prefix of str of at most max bytes that
+// does not split a UTF-8 character, and whether str was truncated. A max of
+// zero means unlimited
+func truncateUTF8(str string, max int) (string, bool) {
+	if max <= 0 || len(str) <= max {
+		return str, false
+	}
+
+	n := max
+	for n > 0 && !utf8.RuneStart(str[n]) {
+		n--
+	}
+
+	return str[:n], true
+}
+
 func (s *DB) SaveOrganization(organization *graphql.Organization) error {
 	statement := fmt.Sprintf(
 		`INSERT INTO organizations_versioned
@@ -146,14 +482,15 @@ func (s *DB) SaveOrganization(organization *graphql.Organization) error {
 			$15, $16, $17, $18, $19)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(organizations_versioned.versions, $20)`,
+		SET versions = array_append(organizations_versioned.versions, $20)
+		WHERE NOT $20 = ANY(organizations_versioned.versions)`,
 		organizationsCols)
 
 	st := fmt.Sprintf("%+v", organization)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	_, err := s.exec("organizations", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
@@ -195,14 +532,15 @@ func (s *DB) SaveUser(user *graphql.UserExtended) error {
 			$15, $16, $17, $18, $19, $20, $21, $22, $23)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(users_versioned.versions, $24)`,
+		SET versions = array_append(users_versioned.versions, $24)
+		WHERE NOT $24 = ANY(users_versioned.versions)`,
 		usersCols)
 
 	st := fmt.Sprintf("%+v", user)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	_, err := s.exec("users", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
@@ -245,17 +583,18 @@ func (s *DB) SaveRepository(repository *graphql.RepositoryFields, topics []strin
 		(sum256, versions, %s)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
 			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29,
-			$30, $31, $32, $33, $34)
+			$30, $31, $32, $33, $34, $35, $36, $37, $38)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(repositories_versioned.versions, $35)`,
+		SET versions = array_append(repositories_versioned.versions, $39)
+		WHERE NOT $39 = ANY(repositories_versioned.versions)`,
 		repositoriesCols)
 
 	st := fmt.Sprintf("%+v %v", repository, topics)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	_, err := s.exec("repositories", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
@@ -292,6 +631,12 @@ func (s *DB) SaveRepository(repository *graphql.RepositoryFields, topics []strin
 		repository.UpdatedAt,             // updated_at timestamptz
 		repository.Watchers.TotalCount,   // watchers_count bigint
 
+		repository.DefaultBranchRef.Target.Commit.Oid,           // default_branch_sha text
+		repository.DefaultBranchRef.Target.Commit.CommittedDate, // default_branch_committed_at timestamptz
+
+		repository.OpenGraphImageUrl,        // open_graph_image_url text
+		repository.UsesCustomOpenGraphImage, // uses_custom_open_graph_image boolean
+
 		s.v,
 	)
 
@@ -301,6 +646,93 @@ func (s *DB) SaveRepository(repository *graphql.RepositoryFields, topics []strin
 	return nil
 }
 
+// SaveTopic stores a topic as an entity of its own. The same topic is usually
+// shared by several repositories, so saving it again in the same version
+// is a noop
+func (s *DB) SaveTopic(name string) error {
+	statement := fmt.Sprintf(
+		`INSERT INTO topics_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(topics_versioned.versions, $4)
+		WHERE NOT $4 = ANY(topics_versioned.versions)`,
+		topicsCols)
+
+	hash := sha256.Sum256([]byte(name))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("topics", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		name, // name text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveTopic: %v", err)
+	}
+	return nil
+}
+
+// SaveRepositoryTopic stores the relation between a repository and one of
+// its topics
+func (s *DB) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	statement := fmt.Sprintf(
+		`INSERT INTO repository_topics_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(repository_topics_versioned.versions, $6)
+		WHERE NOT $6 = ANY(repository_topics_versioned.versions)`,
+		repositoryTopicsCols)
+
+	st := fmt.Sprintf("%v %v %v", repositoryOwner, repositoryName, topic)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("repository_topics", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		repositoryName,  // repository_name text NOT NULL,
+		repositoryOwner, // repository_owner text NOT NULL,
+		topic,           // topic text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveRepositoryTopic: %v", err)
+	}
+	return nil
+}
+
+// nullTime returns nil for the zero time, so it is saved as NULL, e.g. the
+// merge time of a PR that is not merged
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+// refSHA returns the commit of a PR branch recorded by the PR, oid, or the
+// current commit of the branch if it is not set, e.g. in data saved before
+// oid was requested. The branch may be deleted, or moved since the PR
+func refSHA(oid string, ref graphql.Ref) string {
+	if oid != "" {
+		return oid
+	}
+
+	return ref.Target.Oid
+}
+
 func repoOwnerID(repository *graphql.RepositoryFields) int {
 	switch repository.Owner.Typename {
 	case "Orgazation":
@@ -317,10 +749,12 @@ func (s *DB) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Is
 		`INSERT INTO issues_versioned
 		(sum256, versions, %s)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
-			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29,
+			$30, $31, $32)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(issues_versioned.versions, $25)`,
+		SET versions = array_append(issues_versioned.versions, $33)
+		WHERE NOT $33 = ANY(issues_versioned.versions)`,
 		issuesCols)
 
 	st := fmt.Sprintf("%v %v %+v %v %v", repositoryOwner, repositoryName, issue, assignees, labels)
@@ -335,32 +769,42 @@ func (s *DB) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Is
 		closedByLogin = issue.ClosedBy.Nodes[0].ClosedEvent.Actor.Login
 	}
 
-	_, err := s.tx.Exec(statement,
+	body, truncated := s.truncateBody(issue.Body)
+	tasksDone, tasksTotal := ParseTaskList(issue.Body)
+	_, err := s.exec("issues", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
-		pq.Array(assignees),          // assignees text[] NOT NULL,
-		issue.Body,                   // body text,
-		issue.ClosedAt,               // closed_at timestamptz,
-		closedById,                   // closed_by_id bigint NOT NULL
-		closedByLogin,                // closed_by_login text NOT NULL,
-		issue.Comments.TotalCount,    // comments bigint,
-		issue.CreatedAt,              // created_at timestamptz,
-		issue.Url,                    // htmlurl text,
-		issue.DatabaseId,             // id bigint,
-		pq.Array(labels),             // labels text[] NOT NULL,
-		issue.Locked,                 // locked boolean,
-		issue.Milestone.Id,           // milestone_id text NOT NULL,
-		issue.Milestone.Title,        // milestone_title text NOT NULL,
-		issue.Id,                     // node_id text,
-		issue.Number,                 // number bigint,
-		repositoryName,               // repository_name text NOT NULL,
-		repositoryOwner,              // repository_owner text NOT NULL,
-		issue.State,                  // state text,
-		issue.Title,                  // title text,
-		issue.UpdatedAt,              // updated_at timestamptz,
-		issue.Author.User.DatabaseId, // user_id bigint NOT NULL,
-		issue.Author.Login,           // user_login text NOT NULL,
+		pq.Array(assignees),                // assignees text[] NOT NULL,
+		body,                               // body text,
+		nullTime(issue.ClosedAt),           // closed_at timestamptz,
+		closedById,                         // closed_by_id bigint NOT NULL
+		closedByLogin,                      // closed_by_login text NOT NULL,
+		issue.Comments.TotalCount,          // comments bigint,
+		issue.CreatedAt,                    // created_at timestamptz,
+		issue.Url,                          // htmlurl text,
+		issue.DatabaseId,                   // id bigint,
+		pq.Array(labels),                   // labels text[] NOT NULL,
+		issue.Locked,                       // locked boolean,
+		issue.Milestone.Id,                 // milestone_id text NOT NULL,
+		issue.Milestone.Title,              // milestone_title text NOT NULL,
+		issue.Id,                           // node_id text,
+		issue.Number,                       // number bigint,
+		repositoryName,                     // repository_name text NOT NULL,
+		repositoryOwner,                    // repository_owner text NOT NULL,
+		issue.State,                        // state text,
+		issue.Title,                        // title text,
+		issue.UpdatedAt,                    // updated_at timestamptz,
+		issue.Author.User.DatabaseId,       // user_id bigint NOT NULL,
+		issue.Author.Login,                 // user_login text NOT NULL,
+		truncated,                          // body_truncated boolean NOT NULL,
+		tasksDone,                          // tasks_done bigint NOT NULL,
+		tasksTotal,                         // tasks_total bigint NOT NULL,
+		nullTime(issue.Milestone.DueOn),    // milestone_due_on timestamptz,
+		issue.Milestone.State,              // milestone_state text NOT NULL,
+		nullTime(issue.EditedAt),           // last_edited_at timestamptz,
+		issue.LastEditedBy.User.DatabaseId, // last_edited_by_id bigint NOT NULL,
+		issue.EditorLogin(),                // last_edited_by_login text NOT NULL,
 
 		s.v,
 	)
@@ -374,32 +818,39 @@ func (s *DB) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Is
 func (s *DB) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
 	statement := fmt.Sprintf(`INSERT INTO issue_comments_versioned
 		(sum256, versions, %s)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+			$16, $17, $18)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(issue_comments_versioned.versions, $15)`,
+		SET versions = array_append(issue_comments_versioned.versions, $19)
+		WHERE NOT $19 = ANY(issue_comments_versioned.versions)`,
 		issueCommentsCols)
 
 	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, issueNumber, comment)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	body, truncated := s.truncateBody(comment.Body)
+	_, err := s.exec("issue_comments", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
-		comment.AuthorAssociation,      // author_association text,
-		comment.Body,                   // body text,
-		comment.CreatedAt,              // created_at timestamptz,
-		comment.Url,                    // htmlurl text,
-		comment.DatabaseId,             // id bigint,
-		issueNumber,                    // issue_number bigint NOT NULL,
-		comment.Id,                     // node_id text,
-		repositoryName,                 // repository_name text NOT NULL,
-		repositoryOwner,                // repository_owner text NOT NULL,
-		comment.UpdatedAt,              // updated_at timestamptz,
-		comment.Author.User.DatabaseId, // user_id bigint NOT NULL,
-		comment.Author.Login,           // user_login text NOT NULL,
+		comment.AuthorAssociation,            // author_association text,
+		body,                                 // body text,
+		comment.CreatedAt,                    // created_at timestamptz,
+		comment.Url,                          // htmlurl text,
+		comment.DatabaseId,                   // id bigint,
+		issueNumber,                          // issue_number bigint NOT NULL,
+		comment.Id,                           // node_id text,
+		repositoryName,                       // repository_name text NOT NULL,
+		repositoryOwner,                      // repository_owner text NOT NULL,
+		comment.UpdatedAt,                    // updated_at timestamptz,
+		comment.Author.User.DatabaseId,       // user_id bigint NOT NULL,
+		comment.Author.LoginOrGhost(),        // user_login text NOT NULL,
+		truncated,                            // body_truncated boolean NOT NULL,
+		nullTime(comment.EditedAt),           // last_edited_at timestamptz,
+		comment.LastEditedBy.User.DatabaseId, // last_edited_by_id bigint NOT NULL,
+		comment.EditorLogin(),                // last_edited_by_login text NOT NULL,
 
 		s.v,
 	)
@@ -416,17 +867,21 @@ func (s *DB) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql
 		(sum256, versions, %s)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
 			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29,
-			$30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44)
+			$30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44,
+			$45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(pull_requests_versioned.versions, $45)`,
+		SET versions = array_append(pull_requests_versioned.versions, $56)
+		WHERE NOT $56 = ANY(pull_requests_versioned.versions)`,
 		pullRequestsCol)
 
 	st := fmt.Sprintf("%v %v %+v %v %v", repositoryOwner, repositoryName, pr, assignees, labels)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	body, truncated := s.truncateBody(pr.Body)
+	tasksDone, tasksTotal := ParseTaskList(pr.Body)
+	_, err := s.exec("pull_requests", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
@@ -436,11 +891,11 @@ func (s *DB) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql
 		pr.BaseRef.Name,                            // base_ref text NOT NULL,
 		pr.BaseRef.Repository.Name,                 // base_repository_name text NOT NULL,
 		pr.BaseRef.Repository.Owner.Login,          // base_repository_owner text NOT NULL,
-		pr.BaseRef.Target.Oid,                      // base_sha text NOT NULL,
+		refSHA(pr.BaseRefOid, pr.BaseRef),          // base_sha text NOT NULL,
 		pr.BaseRef.Target.Commit.Author.User.Login, // base_user text NOT NULL,
-		pr.Body,                           // body text,
+		body,                              // body text,
 		pr.ChangedFiles,                   // changed_files bigint,
-		pr.ClosedAt,                       // closed_at timestamptz,
+		nullTime(pr.ClosedAt),             // closed_at timestamptz,
 		pr.Comments.TotalCount,            // comments bigint,
 		pr.Commits.TotalCount,             // commits bigint,
 		pr.CreatedAt,                      // created_at timestamptz,
@@ -448,30 +903,41 @@ func (s *DB) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql
 		pr.HeadRef.Name,                   // head_ref text NOT NULL,
 		pr.HeadRef.Repository.Name,        // head_repository_name text NOT NULL,
 		pr.HeadRef.Repository.Owner.Login, // head_repository_owner text NOT NULL,
-		pr.HeadRef.Target.Oid,             // head_sha text NOT NULL,
+		refSHA(pr.HeadRefOid, pr.HeadRef), // head_sha text NOT NULL,
 		pr.HeadRef.Target.Commit.Author.User.Login, // head_user text NOT NULL,
-		pr.Url,                      // htmlurl text,
-		pr.DatabaseId,               // id bigint,
-		pq.Array(labels),            // labels text[] NOT NULL,
-		pr.MaintainerCanModify,      // maintainer_can_modify boolean,
-		pr.MergeCommit.Oid,          // merge_commit_sha text,
-		pr.Mergeable == "MERGEABLE", // mergeable boolean,
-		pr.Merged,                   // merged boolean,
-		pr.MergedAt,                 // merged_at timestamptz,
-		pr.MergedBy.DatabaseId,      // merged_by_id bigint NOT NULL,
-		pr.MergedBy.Login,           // merged_by_login text NOT NULL,
-		pr.Milestone.Id,             // milestone_id text NOT NULL,
-		pr.Milestone.Title,          // milestone_title text NOT NULL,
-		pr.Id,                       // node_id text,
-		pr.Number,                   // number bigint,
-		repositoryName,              // repository_name text NOT NULL,
-		repositoryOwner,             // repository_owner text NOT NULL,
-		pr.ReviewThreads.TotalCount, // review_comments bigint,
-		pr.State,                    // state text,
-		pr.Title,                    // title text,
-		pr.UpdatedAt,                // updated_at timestamptz,
-		pr.Author.DatabaseId,        // user_id bigint NOT NULL,
-		pr.Author.Login,             // user_login text NOT NULL,
+		pr.Url,                          // htmlurl text,
+		pr.DatabaseId,                   // id bigint,
+		pq.Array(labels),                // labels text[] NOT NULL,
+		pr.MaintainerCanModify,          // maintainer_can_modify boolean,
+		pr.MergeCommit.Oid,              // merge_commit_sha text,
+		pr.Mergeable == "MERGEABLE",     // mergeable boolean,
+		pr.Merged,                       // merged boolean,
+		nullTime(pr.MergedAt),           // merged_at timestamptz,
+		pr.MergedBy.DatabaseId,          // merged_by_id bigint NOT NULL,
+		pr.MergedBy.Login,               // merged_by_login text NOT NULL,
+		pr.Milestone.Id,                 // milestone_id text NOT NULL,
+		pr.Milestone.Title,              // milestone_title text NOT NULL,
+		pr.Id,                           // node_id text,
+		pr.Number,                       // number bigint,
+		repositoryName,                  // repository_name text NOT NULL,
+		repositoryOwner,                 // repository_owner text NOT NULL,
+		pr.ReviewThreads.TotalCount,     // review_comments bigint,
+		pr.State,                        // state text,
+		pr.Title,                        // title text,
+		pr.UpdatedAt,                    // updated_at timestamptz,
+		pr.Author.DatabaseId,            // user_id bigint NOT NULL,
+		pr.Author.Login,                 // user_login text NOT NULL,
+		pr.Mergeable,                    // mergeable_state text,
+		pr.PotentialMergeCommit.Oid,     // potential_merge_commit_sha text,
+		truncated,                       // body_truncated boolean NOT NULL,
+		tasksDone,                       // tasks_done bigint NOT NULL,
+		tasksTotal,                      // tasks_total bigint NOT NULL,
+		MergeMethod(pr),                 // merge_method text NOT NULL,
+		nullTime(pr.Milestone.DueOn),    // milestone_due_on timestamptz,
+		pr.Milestone.State,              // milestone_state text NOT NULL,
+		nullTime(pr.EditedAt),           // last_edited_at timestamptz,
+		pr.LastEditedBy.User.DatabaseId, // last_edited_by_id bigint NOT NULL,
+		pr.EditorLogin(),                // last_edited_by_login text NOT NULL,
 
 		s.v,
 	)
@@ -490,32 +956,39 @@ func (s *DB) SavePullRequestComment(repositoryOwner, repositoryName string, pull
 func (s *DB) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
 	statement := fmt.Sprintf(`INSERT INTO pull_request_reviews_versioned
 		(sum256, versions, %s)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+			$16, $17, $18)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(pull_request_reviews_versioned.versions, $15)`,
+		SET versions = array_append(pull_request_reviews_versioned.versions, $19)
+		WHERE NOT $19 = ANY(pull_request_reviews_versioned.versions)`,
 		pullRequestReviewsCols)
 
 	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, pullRequestNumber, review)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	body, truncated := s.truncateBody(review.Body)
+	_, err := s.exec("pull_request_reviews", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
-		review.Body,                   // body text,
-		review.Commit.Oid,             // commit_id text,
-		review.Url,                    // htmlurl text,
-		review.DatabaseId,             // id bigint,
-		review.Id,                     // node_id text,
-		pullRequestNumber,             // pull_request_number bigint NOT NULL,
-		repositoryName,                // repository_name text NOT NULL,
-		repositoryOwner,               // repository_owner text NOT NULL,
-		review.State,                  // state text,
-		review.SubmittedAt,            // submitted_at timestamptz,
-		review.Author.User.DatabaseId, // user_id bigint NOT NULL,
-		review.Author.Login,           // user_login text NOT NULL,
+		body,                                // body text,
+		review.Commit.Oid,                   // commit_id text,
+		review.Url,                          // htmlurl text,
+		review.DatabaseId,                   // id bigint,
+		review.Id,                           // node_id text,
+		pullRequestNumber,                   // pull_request_number bigint NOT NULL,
+		repositoryName,                      // repository_name text NOT NULL,
+		repositoryOwner,                     // repository_owner text NOT NULL,
+		review.State,                        // state text,
+		review.SubmittedAt,                  // submitted_at timestamptz,
+		review.Author.User.DatabaseId,       // user_id bigint NOT NULL,
+		review.Author.LoginOrGhost(),        // user_login text NOT NULL,
+		truncated,                           // body_truncated boolean NOT NULL,
+		nullTime(review.EditedAt),           // last_edited_at timestamptz,
+		review.LastEditedBy.User.DatabaseId, // last_edited_by_id bigint NOT NULL,
+		review.EditorLogin(),                // last_edited_by_login text NOT NULL,
 
 		s.v,
 	)
@@ -530,41 +1003,47 @@ func (s *DB) SavePullRequestReviewComment(repositoryOwner, repositoryName string
 	statement := fmt.Sprintf(`INSERT INTO pull_request_comments_versioned
 		(sum256, versions, %s)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
-			$15, $16, $17, $18, $19, $20, $21, $22)
+			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
 		ON CONFLICT (sum256)
 		DO UPDATE
-		SET versions = array_append(pull_request_comments_versioned.versions, $23)`,
+		SET versions = array_append(pull_request_comments_versioned.versions, $27)
+		WHERE NOT $27 = ANY(pull_request_comments_versioned.versions)`,
 		pullRequestReviewCommentsCols)
 
 	st := fmt.Sprintf("%v %v %v %v %+v", repositoryOwner, repositoryName, pullRequestNumber, pullRequestReviewId, comment)
 	hash := sha256.Sum256([]byte(st))
 	hashString := fmt.Sprintf("%x", hash)
 
-	_, err := s.tx.Exec(statement,
+	body, truncated := s.truncateBody(comment.Body)
+	_, err := s.exec("pull_request_comments", statement,
 		hashString,
 		pq.Array([]int{s.v}),
 
 		comment.AuthorAssociation, // author_association text,
-		comment.Body,              // body text,
+		body,                      // body text,
 		comment.Commit.Oid,        // commit_id text,
 		comment.CreatedAt,         // created_at timestamptz,
 		comment.DiffHunk,          // diff_hunk text,
 		comment.Url,               // htmlurl text,
 		comment.DatabaseId,        // id bigint,
 		// TODO
-		0,                          // in_reply_to bigint,
-		comment.Id,                 // node_id text,
-		comment.OriginalCommit.Oid, // original_commit_id text,
-		comment.OriginalPosition,   // original_position bigint,
-		comment.Path,               // path text,
-		comment.Position,           // position bigint,
-		pullRequestNumber,          // pull_request_number bigint NOT NULL,
-		pullRequestReviewId,        // pull_request_review_id bigint,
-		repositoryName,             // repository_name text NOT NULL,
-		repositoryOwner,            // repository_owner text NOT NULL,
-		comment.UpdatedAt,          // updated_at timestamptz,
-		comment.Author.DatabaseId,  // user_id bigint NOT NULL,
-		comment.Author.Login,       // user_login text NOT NULL,
+		0,                                    // in_reply_to bigint,
+		comment.Id,                           // node_id text,
+		comment.OriginalCommit.Oid,           // original_commit_id text,
+		comment.OriginalPosition,             // original_position bigint,
+		comment.Path,                         // path text,
+		comment.Position,                     // position bigint,
+		pullRequestNumber,                    // pull_request_number bigint NOT NULL,
+		pullRequestReviewId,                  // pull_request_review_id bigint,
+		repositoryName,                       // repository_name text NOT NULL,
+		repositoryOwner,                      // repository_owner text NOT NULL,
+		comment.UpdatedAt,                    // updated_at timestamptz,
+		comment.Author.DatabaseId,            // user_id bigint NOT NULL,
+		comment.Author.LoginOrGhost(),        // user_login text NOT NULL,
+		truncated,                            // body_truncated boolean NOT NULL,
+		nullTime(comment.EditedAt),           // last_edited_at timestamptz,
+		comment.LastEditedBy.User.DatabaseId, // last_edited_by_id bigint NOT NULL,
+		comment.EditorLogin(),                // last_edited_by_login text NOT NULL,
 
 		s.v,
 	)
@@ -574,3 +1053,735 @@ func (s *DB) SavePullRequestReviewComment(repositoryOwner, repositoryName string
 	}
 	return nil
 }
+
+func (s *DB) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	statement := fmt.Sprintf(`INSERT INTO pull_request_review_transitions_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(pull_request_review_transitions_versioned.versions, $12)
+		WHERE NOT $12 = ANY(pull_request_review_transitions_versioned.versions)`,
+		reviewTransitionsCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, pullRequestNumber, transition)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("pull_request_review_transitions", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		transition.FromState,   // from_state text NOT NULL,
+		pullRequestNumber,      // pull_request_number bigint NOT NULL,
+		transition.ReviewId,    // pull_request_review_id bigint,
+		repositoryName,         // repository_name text NOT NULL,
+		repositoryOwner,        // repository_owner text NOT NULL,
+		transition.Sequence,    // sequence bigint NOT NULL,
+		transition.SubmittedAt, // submitted_at timestamptz,
+		transition.ToState,     // to_state text NOT NULL,
+		transition.UserLogin,   // user_login text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveReviewStateTransition: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	statement := fmt.Sprintf(`INSERT INTO pull_request_commits_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(pull_request_commits_versioned.versions, $16)
+		WHERE NOT $16 = ANY(pull_request_commits_versioned.versions)`,
+		pullRequestCommitsCols)
+
+	c := commit.Commit
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, pullRequestNumber, c)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("pull_request_commits", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		c.Author.Email,      // author_email text NOT NULL,
+		c.Author.Login(),    // author_login text NOT NULL,
+		c.Author.Name,       // author_name text NOT NULL,
+		c.AuthoredDate,      // authored_at timestamptz,
+		c.CommittedDate,     // committed_at timestamptz,
+		c.Committer.Email,   // committer_email text NOT NULL,
+		c.Committer.Login(), // committer_login text NOT NULL,
+		c.Committer.Name,    // committer_name text NOT NULL,
+		c.Message,           // message text,
+		c.Oid,               // oid text NOT NULL,
+		pullRequestNumber,   // pull_request_number bigint NOT NULL,
+		repositoryName,      // repository_name text NOT NULL,
+		repositoryOwner,     // repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("savePullRequestCommit: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	statement := fmt.Sprintf(`INSERT INTO assignment_events_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(assignment_events_versioned.versions, $11)
+		WHERE NOT $11 = ANY(assignment_events_versioned.versions)`,
+		assignmentEventsCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, number, event)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	fields := event.Fields()
+	_, err := s.exec("assignment_events", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		fields.Actor.Login,     // actor_login text NOT NULL,
+		fields.AssigneeLogin(), // assignee_login text NOT NULL,
+		fields.CreatedAt,       // created_at timestamptz,
+		event.Typename,         // event text NOT NULL,
+		fields.Id,              // node_id text,
+		number,                 // number bigint NOT NULL,
+		repositoryName,         // repository_name text NOT NULL,
+		repositoryOwner,        // repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveAssignmentEvent: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	statement := fmt.Sprintf(`INSERT INTO issue_events_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(issue_events_versioned.versions, $10)
+		WHERE NOT $10 = ANY(issue_events_versioned.versions)`,
+		issueEventsCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, issueNumber, event)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	fields := event.Fields()
+	_, err := s.exec("issue_events", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		fields.Actor.Login, // actor_login text NOT NULL,
+		fields.CreatedAt,   // created_at timestamptz,
+		event.Typename,     // event text NOT NULL,
+		issueNumber,        // issue_number bigint NOT NULL,
+		fields.Id,          // node_id text,
+		repositoryName,     // repository_name text NOT NULL,
+		repositoryOwner,    // repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveIssueEvent: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	statement := fmt.Sprintf(`INSERT INTO pull_request_events_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(pull_request_events_versioned.versions, $10)
+		WHERE NOT $10 = ANY(pull_request_events_versioned.versions)`,
+		pullRequestEventsCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, pullRequestNumber, event)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	fields := event.Fields()
+	_, err := s.exec("pull_request_events", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		fields.Actor.Login, // actor_login text NOT NULL,
+		fields.CreatedAt,   // created_at timestamptz,
+		event.Typename,     // event text NOT NULL,
+		fields.Id,          // node_id text,
+		pullRequestNumber,  // pull_request_number bigint NOT NULL,
+		repositoryName,     // repository_name text NOT NULL,
+		repositoryOwner,    // repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("savePullRequestEvent: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	statement := fmt.Sprintf(`INSERT INTO locks_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(locks_versioned.versions, $11)
+		WHERE NOT $11 = ANY(locks_versioned.versions)`,
+		locksCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, number, lock)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("locks", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		lock.Reason,        // lock_reason text NOT NULL,
+		lock.LockedAt,      // locked_at timestamptz,
+		lock.LockedByID,    // locked_by_id bigint NOT NULL,
+		lock.LockedByLogin, // locked_by_login text NOT NULL,
+		lock.NodeID,        // node_id text NOT NULL,
+		number,             // number bigint NOT NULL,
+		repositoryName,     // repository_name text NOT NULL,
+		repositoryOwner,    // repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveLock: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	statement := fmt.Sprintf(`INSERT INTO project_statuses_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(project_statuses_versioned.versions, $13)
+		WHERE NOT $13 = ANY(project_statuses_versioned.versions)`,
+		projectStatusesCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", repositoryOwner, repositoryName, pullRequestNumber, status)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("project_statuses", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		status.NodeID,               // node_id text NOT NULL,
+		status.ProjectNodeID,        // project_node_id text NOT NULL,
+		status.ProjectNumber,        // project_number bigint NOT NULL,
+		status.ProjectTitle,         // project_title text NOT NULL,
+		pullRequestNumber,           // pull_request_number bigint NOT NULL,
+		status.PullRequestUpdatedAt, // pull_request_updated_at timestamptz,
+		repositoryName,              // repository_name text NOT NULL,
+		repositoryOwner,             // repository_owner text NOT NULL,
+		status.Status,               // status text NOT NULL,
+		status.StatusUpdatedAt,      // status_updated_at timestamptz,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveProjectStatus: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveMention(subjectID, mentionedLogin string) error {
+	statement := fmt.Sprintf(`INSERT INTO mentions_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(mentions_versioned.versions, $5)
+		WHERE NOT $5 = ANY(mentions_versioned.versions)`,
+		mentionsCols)
+
+	st := fmt.Sprintf("%v %v", subjectID, mentionedLogin)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("mentions", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		mentionedLogin, // mentioned_login text NOT NULL,
+		subjectID,      // subject_id text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveMention: %v", err)
+	}
+	return nil
+}
+
+// SaveReactionGroup saves the number of reactions of one kind to an issue, PR,
+// comment or review
+func (s *DB) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	statement := fmt.Sprintf(`INSERT INTO reactions_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(reactions_versioned.versions, $6)
+		WHERE NOT $6 = ANY(reactions_versioned.versions)`,
+		reactionsCols)
+
+	st := fmt.Sprintf("%v %v %v", subjectID, group.Content, group.Reactors.TotalCount)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("reactions", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		group.Content,             // content text NOT NULL,
+		subjectID,                 // subject_id text NOT NULL,
+		group.Reactors.TotalCount, // total_count bigint NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveReactionGroup: %v", err)
+	}
+	return nil
+}
+
+// SaveClosingReference saves the reference once per version, it can be found
+// from both the PR and the issue
+func (s *DB) SaveClosingReference(reference *ClosingReference) error {
+	statement := fmt.Sprintf(`INSERT INTO closing_references_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(closing_references_versioned.versions, $9)
+		WHERE NOT $9 = ANY(closing_references_versioned.versions)`,
+		closingReferencesCols)
+
+	st := fmt.Sprintf("%+v", *reference)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("closing_references", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		reference.IssueNumber,                // issue_number bigint NOT NULL,
+		reference.IssueRepositoryName,        // issue_repository_name text NOT NULL,
+		reference.IssueRepositoryOwner,       // issue_repository_owner text NOT NULL,
+		reference.PullRequestNumber,          // pull_request_number bigint NOT NULL,
+		reference.PullRequestRepositoryName,  // pull_request_repository_name text NOT NULL,
+		reference.PullRequestRepositoryOwner, // pull_request_repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveClosingReference: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	statement := fmt.Sprintf(`INSERT INTO templates_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(templates_versioned.versions, $11)
+		WHERE NOT $11 = ANY(templates_versioned.versions)`,
+		templatesCols)
+
+	st := fmt.Sprintf("%v %v %+v", repositoryOwner, repositoryName, template)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("templates", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		template.About,    // about text NOT NULL,
+		template.Body,     // body text NOT NULL,
+		template.Filename, // filename text NOT NULL,
+		template.Kind,     // kind text NOT NULL,
+		template.Name,     // name text NOT NULL,
+		repositoryName,    // repository_name text NOT NULL,
+		repositoryOwner,   // repository_owner text NOT NULL,
+		template.Title,    // title text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveTemplate: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	statement := fmt.Sprintf(`INSERT INTO readmes_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(readmes_versioned.versions, $8)
+		WHERE NOT $8 = ANY(readmes_versioned.versions)`,
+		readmesCols)
+
+	st := fmt.Sprintf("%v %v %+v", repositoryOwner, repositoryName, readme)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("readmes", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		readme.Body,     // body text NOT NULL,
+		readme.ByteSize, // byte_size bigint NOT NULL,
+		readme.Filename, // filename text NOT NULL,
+		repositoryName,  // repository_name text NOT NULL,
+		repositoryOwner, // repository_owner text NOT NULL,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveReadme: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	statement := fmt.Sprintf(`INSERT INTO repository_rulesets_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(repository_rulesets_versioned.versions, $15)
+		WHERE NOT $15 = ANY(repository_rulesets_versioned.versions)`,
+		rulesetsCols)
+
+	st := fmt.Sprintf("%v %v %+v", repositoryOwner, repositoryName, ruleset)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	// the conditions are null for rulesets that do not target refs, but the
+	// columns are not nullable
+	exclude := append([]string{}, ruleset.Conditions.RefName.Exclude...)
+	include := append([]string{}, ruleset.Conditions.RefName.Include...)
+
+	_, err := s.exec("repository_rulesets", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		pq.Array(exclude),             // conditions_exclude text[] NOT NULL,
+		pq.Array(include),             // conditions_include text[] NOT NULL,
+		ruleset.CreatedAt,             // created_at timestamptz,
+		ruleset.Enforcement,           // enforcement text,
+		ruleset.DatabaseId,            // id bigint,
+		ruleset.Name,                  // name text,
+		ruleset.Id,                    // node_id text,
+		repositoryName,                // repository_name text NOT NULL,
+		repositoryOwner,               // repository_owner text NOT NULL,
+		pq.Array(ruleset.RuleTypes()), // rule_types text[] NOT NULL,
+		ruleset.Target,                // target text,
+		ruleset.UpdatedAt,             // updated_at timestamptz,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveRuleset: %v", err)
+	}
+	return nil
+}
+
+func (s *DB) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	statement := fmt.Sprintf(`INSERT INTO audit_log_entries_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(audit_log_entries_versioned.versions, $12)
+		WHERE NOT $12 = ANY(audit_log_entries_versioned.versions)`,
+		auditLogEntriesCols)
+
+	st := fmt.Sprintf("%v %+v", organization, entry)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("audit_log_entries", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		entry.AuditEntry.Action,        // action text NOT NULL,
+		entry.AuditEntry.ActorIp,       // actor_ip text,
+		entry.AuditEntry.ActorLogin,    // actor_login text,
+		entry.AuditEntry.CreatedAt,     // created_at timestamptz,
+		entry.Typename,                 // entry_type text NOT NULL,
+		entry.Node.Id,                  // node_id text,
+		entry.AuditEntry.OperationType, // operation_type text,
+		organization,                   // organization_login text NOT NULL,
+		entry.AuditEntry.UserLogin,     // user_login text,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveAuditLogEntry: %v", err)
+	}
+	return nil
+}
+
+// SaveOrgProject saves the project in org_projects, and each of its items in
+// org_project_items
+func (s *DB) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	statement := fmt.Sprintf(`INSERT INTO org_projects_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(org_projects_versioned.versions, $15)
+		WHERE NOT $15 = ANY(org_projects_versioned.versions)`,
+		orgProjectsCols)
+
+	// the items are saved in their own table, the project is the same
+	// regardless of them
+	p := *project
+	p.Items = graphql.OrgProjectItemConnection{}
+	st := fmt.Sprintf("%v %+v", organization, p)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("org_projects", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		project.Closed,                     // closed boolean,
+		project.CreatedAt,                  // created_at timestamptz,
+		pq.Array(project.FieldDataTypes()), // field_data_types text[] NOT NULL,
+		pq.Array(project.FieldNames()),     // field_names text[] NOT NULL,
+		project.Id,                         // node_id text NOT NULL,
+		project.Number,                     // number bigint NOT NULL,
+		organization,                       // organization_login text NOT NULL,
+		project.Public,                     // public boolean,
+		project.ShortDescription,           // short_description text,
+		project.Title,                      // title text NOT NULL,
+		project.UpdatedAt,                  // updated_at timestamptz,
+		project.Url,                        // url text,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveOrgProject: %v", err)
+	}
+
+	for i := range project.Items.Nodes {
+		err := s.saveOrgProjectItem(organization, project, &project.Items.Nodes[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *DB) saveOrgProjectItem(organization string, project *graphql.OrgProject, item *graphql.OrgProjectItem) error {
+	statement := fmt.Sprintf(`INSERT INTO org_project_items_versioned
+		(sum256, versions, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (sum256)
+		DO UPDATE
+		SET versions = array_append(org_project_items_versioned.versions, $17)
+		WHERE NOT $17 = ANY(org_project_items_versioned.versions)`,
+		orgProjectItemsCols)
+
+	st := fmt.Sprintf("%v %v %v %+v", organization, project.Id, project.Number, item)
+	hash := sha256.Sum256([]byte(st))
+	hashString := fmt.Sprintf("%x", hash)
+
+	_, err := s.exec("org_project_items", statement,
+		hashString,
+		pq.Array([]int{s.v}),
+
+		item.IsArchived,                    // archived boolean,
+		item.ContentNodeID(),               // content_node_id text,
+		item.ContentNumber(),               // content_number bigint,
+		item.ContentRepository(),           // content_repository text,
+		item.ContentTitle(),                // content_title text,
+		item.CreatedAt,                     // created_at timestamptz,
+		item.Type,                          // item_type text NOT NULL,
+		item.Id,                            // node_id text NOT NULL,
+		organization,                       // organization_login text NOT NULL,
+		project.Id,                         // project_node_id text NOT NULL,
+		project.Number,                     // project_number bigint NOT NULL,
+		item.Status.SingleSelect.Name,      // status text,
+		item.Status.SingleSelect.UpdatedAt, // status_updated_at timestamptz,
+		item.UpdatedAt,                     // updated_at timestamptz,
+
+		s.v,
+	)
+
+	if err != nil {
+		return fmt.Errorf("saveOrgProjectItem: %v", err)
+	}
+	return nil
+}
+
+// AssociationChange is a change of the association of a user with a
+// repository between two versions, e.g. from FIRST_TIME_CONTRIBUTOR to
+// CONTRIBUTOR
+type AssociationChange struct {
+	RepositoryOwner string
+	RepositoryName  string
+	UserLogin       string
+	From            string
+	To              string
+}
+
+// AssociationChanges returns the users whose association with a repository
+// changed from version from to version to, sorted by repository and login.
+// The association of a user in a version is the author association of their
+// latest issue, PR or review comment in the repository. Users without comments
+// in one of the versions are not reported
+func (s *DB) AssociationChanges(from, to int) ([]AssociationChange, error) {
+	rows, err := s.DB.Query(`WITH comments AS (
+		SELECT repository_owner, repository_name, user_login, author_association, created_at, versions
+		FROM issue_comments_versioned
+		UNION ALL
+		SELECT repository_owner, repository_name, user_login, author_association, created_at, versions
+		FROM pull_request_comments_versioned
+	),
+	before AS (
+		SELECT DISTINCT ON (repository_owner, repository_name, user_login)
+			repository_owner, repository_name, user_login, author_association
+		FROM comments WHERE $1 = ANY(versions)
+		ORDER BY repository_owner, repository_name, user_login, created_at DESC
+	),
+	after AS (
+		SELECT DISTINCT ON (repository_owner, repository_name, user_login)
+			repository_owner, repository_name, user_login, author_association
+		FROM comments WHERE $2 = ANY(versions)
+		ORDER BY repository_owner, repository_name, user_login, created_at DESC
+	)
+	SELECT repository_owner, repository_name, user_login,
+		before.author_association, after.author_association
+	FROM before JOIN after USING (repository_owner, repository_name, user_login)
+	WHERE before.author_association <> after.author_association
+	ORDER BY repository_owner, repository_name, user_login`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query association changes from version %v to %v: %v", from, to, err)
+	}
+	defer rows.Close()
+
+	var changes []AssociationChange
+	for rows.Next() {
+		var c AssociationChange
+		err := rows.Scan(&c.RepositoryOwner, &c.RepositoryName, &c.UserLogin, &c.From, &c.To)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read association change: %v", err)
+		}
+
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query association changes from version %v to %v: %v", from, to, err)
+	}
+
+	return changes, nil
+}
+
+// Gap is an issue or PR of a version with fewer comments saved than its
+// comment count, e.g. because of a pagination bug
+type Gap struct {
+	// Table is issues or pull_requests
+	Table  string
+	Number int
+	// Expected is the comment count of the issue or PR, and Saved the number
+	// of its comments saved in the version
+	Expected int
+	Saved    int
+}
+
+// VerifyComplete returns the gaps of the repository in the given version,
+// sorted by number. It is empty if every issue and PR has all its comments.
+// The downloads that skip comments on purpose, like the ones with
+// Downloader.CommentWatermarks or Downloader.CommentsUpdatedSince, report
+// the skipped ones as gaps
+func (s *DB) VerifyComplete(owner, name string, version int) ([]Gap, error) {
+	rows, err := s.DB.Query(`WITH saved AS (
+		SELECT issue_number, count(*) AS saved
+		FROM issue_comments_versioned
+		WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)
+		GROUP BY issue_number
+	),
+	expected AS (
+		SELECT 'issues' AS kind, number, comments
+		FROM issues_versioned
+		WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)
+		UNION ALL
+		SELECT 'pull_requests' AS kind, number, comments
+		FROM pull_requests_versioned
+		WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)
+	)
+	SELECT kind, number, comments, COALESCE(saved, 0)
+	FROM expected LEFT JOIN saved ON saved.issue_number = expected.number
+	WHERE COALESCE(saved, 0) < comments
+	ORDER BY number`, owner, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gaps of %v/%v in version %v: %v", owner, name, version, err)
+	}
+	defer rows.Close()
+
+	var gaps []Gap
+	for rows.Next() {
+		var g Gap
+		err := rows.Scan(&g.Table, &g.Number, &g.Expected, &g.Saved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gap: %v", err)
+		}
+
+		gaps = append(gaps, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query gaps of %v/%v in version %v: %v", owner, name, version, err)
+	}
+
+	return gaps, nil
+}
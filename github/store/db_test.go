@@ -0,0 +1,545 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/database"
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// getDB returns a DB store connected to the PostgreSQL database set in
+// DATABASE_URL, with the schema migrated to the latest version
+func getDB(t *testing.T) *DB {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	require.NoError(t, err)
+	require.NoError(t, db.Ping())
+
+	err = database.Migrate(url)
+	if err != migrate.ErrNoChange {
+		require.NoError(t, err)
+	}
+
+	return &DB{DB: db}
+}
+
+// TestRepositoriesByTopic saves repositories with overlapping topics and
+// queries the repositories that have a given topic
+func TestRepositoriesByTopic(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const version = 206
+	s.Version(version)
+	require.NoError(s.Begin())
+
+	repos := map[string][]string{
+		"topics-a": {"go", "github"},
+		"topics-b": {"go"},
+		"topics-c": {"sql"},
+	}
+
+	for name, topics := range repos {
+		repository := &graphql.RepositoryFields{Name: name, NameWithOwner: "src-d/" + name}
+		repository.Owner.Login = "src-d"
+
+		require.NoError(s.SaveRepository(repository, topics))
+		for _, topic := range topics {
+			require.NoError(s.SaveTopic(topic))
+			require.NoError(s.SaveRepositoryTopic("src-d", name, topic))
+		}
+	}
+
+	require.NoError(s.Commit())
+	require.NoError(s.SetActiveVersion(version))
+
+	var count int
+	err := s.QueryRow(`SELECT COUNT(*) FROM topics WHERE name = 'go'`).Scan(&count)
+	require.NoError(err)
+	require.Equal(1, count)
+
+	rows, err := s.Query(`SELECT repository_name FROM repository_topics
+		WHERE repository_owner = 'src-d' AND topic = 'go'
+		ORDER BY repository_name`)
+	require.NoError(err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(rows.Err())
+	require.Equal([]string{"topics-a", "topics-b"}, names)
+}
+
+// TestCleanupInBatches saves many rows in an old version and checks that a
+// Cleanup with a small batch size deletes all of them
+func TestCleanupInBatches(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	save := func(version int, topics ...string) {
+		s.Version(version)
+		require.NoError(s.Begin())
+		for _, topic := range topics {
+			require.NoError(s.SaveTopic(topic))
+		}
+		require.NoError(s.Commit())
+	}
+
+	var stale []string
+	for i := 0; i < 50; i++ {
+		stale = append(stale, fmt.Sprintf("stale-%v", i))
+	}
+
+	save(1, stale...)
+	save(1, "kept-a", "kept-b")
+	save(2, "kept-a", "kept-b", "kept-c")
+
+	s.BatchSize = 7
+	require.NoError(s.Cleanup(2))
+
+	rows, err := s.Query(`SELECT name FROM topics_versioned ORDER BY name`)
+	require.NoError(err)
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(rows.Err())
+	require.Equal([]string{"kept-a", "kept-b", "kept-c"}, names)
+
+	var count int
+	err = s.QueryRow(`SELECT COUNT(*) FROM topics_versioned WHERE versions <> array[2]`).Scan(&count)
+	require.NoError(err)
+	require.Zero(count)
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	require := require.New(t)
+
+	// "é" is 2 bytes and "😀" 4 bytes
+	body := "abé😀"
+
+	for max, expected := range map[int]string{
+		1: "a",
+		2: "ab",
+		3: "ab",
+		4: "abé",
+		5: "abé",
+		7: "abé",
+	} {
+		truncated, ok := truncateUTF8(body, max)
+		require.True(ok, "max %v", max)
+		require.Equal(expected, truncated, "max %v", max)
+	}
+
+	for _, max := range []int{0, 8, 100} {
+		truncated, ok := truncateUTF8(body, max)
+		require.False(ok, "max %v", max)
+		require.Equal(body, truncated, "max %v", max)
+	}
+}
+
+// TestMaxBodyLength saves an issue with a body longer than MaxBodyLength and
+// checks the stored body and flag
+func TestMaxBodyLength(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const version = 223
+	s.Version(version)
+	s.MaxBodyLength = 5
+	require.NoError(s.Begin())
+
+	issue := &graphql.Issue{}
+	issue.Number = 223
+	issue.Body = "abcd😀"
+	require.NoError(s.SaveIssue("src-d", "max-body-length", issue, nil, nil))
+	require.NoError(s.Commit())
+	require.NoError(s.SetActiveVersion(version))
+
+	var body string
+	var truncated bool
+	err := s.QueryRow(`SELECT body, body_truncated FROM issues
+		WHERE repository_owner = 'src-d' AND repository_name = 'max-body-length'`).Scan(&body, &truncated)
+	require.NoError(err)
+	require.Equal("abcd", body)
+	require.True(truncated)
+}
+
+// TestSaveSameVersion checks that saving the same rows again in the same
+// version does not add the version to them twice
+func TestSaveSameVersion(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const version = 266
+	s.Version(version)
+
+	issue := &graphql.Issue{}
+	issue.Number = 266
+	issue.Title = "crawled twice"
+	for i := 0; i < 2; i++ {
+		require.NoError(s.Begin())
+		require.NoError(s.SaveIssue("src-d", "same-version", issue, nil, nil))
+		require.NoError(s.Commit())
+	}
+
+	var versions pq.Int64Array
+	err := s.QueryRow(`SELECT versions FROM issues_versioned
+		WHERE repository_owner = 'src-d' AND repository_name = 'same-version'`).Scan(&versions)
+	require.NoError(err)
+	require.Equal(pq.Int64Array{version}, versions)
+}
+
+// TestPullRequestNullTimes checks that the merge and close times of the PRs
+// that are not merged or not closed are saved as null, not as the zero time
+func TestPullRequestNullTimes(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const version = 265
+	s.Version(version)
+	require.NoError(s.Begin())
+
+	merged := &graphql.PullRequest{}
+	merged.Number = 1
+	merged.Merged = true
+	merged.MergedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	merged.ClosedAt = merged.MergedAt
+	merged.MergedBy.Login = "alice"
+	merged.MergeCommit.Oid = "cafe"
+	require.NoError(s.SavePullRequest("src-d", "null-times", merged, nil, nil))
+
+	closed := &graphql.PullRequest{}
+	closed.Number = 2
+	closed.ClosedAt = time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC)
+	require.NoError(s.SavePullRequest("src-d", "null-times", closed, nil, nil))
+
+	open := &graphql.PullRequest{}
+	open.Number = 3
+	require.NoError(s.SavePullRequest("src-d", "null-times", open, nil, nil))
+
+	require.NoError(s.Commit())
+	require.NoError(s.SetActiveVersion(version))
+
+	rows, err := s.Query(`SELECT number, merged_at, closed_at, merged_by_login, merge_commit_sha FROM pull_requests
+		WHERE repository_owner = 'src-d' AND repository_name = 'null-times' ORDER BY number`)
+	require.NoError(err)
+	defer rows.Close()
+
+	var saved []string
+	for rows.Next() {
+		var number int
+		var mergedAt, closedAt pq.NullTime
+		var mergedBy, mergeCommit string
+		require.NoError(rows.Scan(&number, &mergedAt, &closedAt, &mergedBy, &mergeCommit))
+		saved = append(saved, fmt.Sprintf("#%v %v %v %v %q %q", number, mergedAt.Valid, closedAt.Valid, mergedAt.Time.UTC().Format(time.RFC3339), mergedBy, mergeCommit))
+	}
+	require.NoError(rows.Err())
+	require.Equal([]string{
+		`#1 true true 2019-10-01T10:00:00Z "alice" "cafe"`,
+		`#2 false true 0001-01-01T00:00:00Z "" ""`,
+		`#3 false false 0001-01-01T00:00:00Z "" ""`,
+	}, saved)
+}
+
+// TestAssociationChanges saves the comments of two versions, where the
+// association of a user changed, and checks the reported changes
+func TestAssociationChanges(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	comment := func(id int, login, association string) *graphql.IssueComment {
+		createdAt := time.Date(2019, 10, 1, id, 0, 0, 0, time.UTC)
+		c := &graphql.IssueComment{
+			AuthorAssociation: association,
+			CreatedAt:         createdAt,
+			DatabaseId:        id,
+			UpdatedAt:         createdAt.Format(time.RFC3339),
+		}
+		c.Author.Login = login
+		return c
+	}
+
+	save := func(version int, comments ...*graphql.IssueComment) {
+		s.Version(version)
+		require.NoError(s.Begin())
+		for _, c := range comments {
+			require.NoError(s.SaveIssueComment("src-d", "association-changes", 1, c))
+		}
+		require.NoError(s.Commit())
+	}
+
+	save(230,
+		comment(1, "alice", "FIRST_TIME_CONTRIBUTOR"),
+		comment(2, "bob", "MEMBER"),
+		comment(3, "carol", "NONE"),
+	)
+	save(231,
+		comment(1, "alice", "CONTRIBUTOR"),
+		comment(2, "bob", "MEMBER"),
+		comment(4, "dave", "NONE"),
+	)
+
+	changes, err := s.AssociationChanges(230, 231)
+	require.NoError(err)
+	require.Equal([]AssociationChange{{
+		RepositoryOwner: "src-d",
+		RepositoryName:  "association-changes",
+		UserLogin:       "alice",
+		From:            "FIRST_TIME_CONTRIBUTOR",
+		To:              "CONTRIBUTOR",
+	}}, changes)
+
+	changes, err = s.AssociationChanges(231, 231)
+	require.NoError(err)
+	require.Empty(changes)
+}
+
+func TestFields(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	s.Fields = Fields{"issues": {"Number"}}
+	require.Error(s.Begin())
+
+	s.Fields = Fields{"issues": {"number", "title"}}
+	s.Version(242)
+	require.NoError(s.Begin())
+
+	issue := &graphql.Issue{}
+	issue.Number = 1
+	issue.Title = "Fix the parser"
+	issue.Body = "It fails with empty files"
+	issue.Url = "https://github.com/src-d/fields/issues/1"
+	require.NoError(s.SaveIssue("src-d", "fields", issue, nil, nil))
+	require.NoError(s.Commit())
+
+	var title string
+	var body, url sql.NullString
+	err := s.QueryRow(`SELECT title, body, htmlurl FROM issues_versioned
+		WHERE repository_owner = 'src-d' AND repository_name = 'fields' AND 242 = ANY(versions)`).Scan(&title, &body, &url)
+	require.NoError(err)
+	require.Equal("Fix the parser", title)
+	require.False(body.Valid)
+	require.False(url.Valid)
+}
+
+// TestVerifyComplete saves an issue with all its comments, and a PR with
+// some of them missing, and checks that only the PR is reported
+func TestVerifyComplete(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const version = 247
+	s.Version(version)
+	require.NoError(s.Begin())
+
+	issue := &graphql.Issue{}
+	issue.Number = 1
+	issue.Comments.TotalCount = 2
+	require.NoError(s.SaveIssue("src-d", "verify-complete", issue, nil, nil))
+
+	pr := &graphql.PullRequest{}
+	pr.Number = 2
+	pr.Comments.TotalCount = 3
+	require.NoError(s.SavePullRequest("src-d", "verify-complete", pr, nil, nil))
+
+	for id, number := range []int{1, 1, 2} {
+		comment := &graphql.IssueComment{DatabaseId: id}
+		require.NoError(s.SaveIssueComment("src-d", "verify-complete", number, comment))
+	}
+	require.NoError(s.Commit())
+
+	gaps, err := s.VerifyComplete("src-d", "verify-complete", version)
+	require.NoError(err)
+	require.Equal([]Gap{{Table: "pull_requests", Number: 2, Expected: 3, Saved: 1}}, gaps)
+
+	gaps, err = s.VerifyComplete("src-d", "verify-complete", version+1)
+	require.NoError(err)
+	require.Empty(gaps)
+}
+
+func TestExport(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const version = 256
+	s.Version(version)
+	require.NoError(s.Begin())
+
+	repository := &graphql.RepositoryFields{Name: "export"}
+	repository.Owner.Login = "src-d"
+	require.NoError(s.SaveRepository(repository, nil))
+
+	issue := &graphql.Issue{}
+	issue.Number = 1
+	issue.Title = "exported"
+	require.NoError(s.SaveIssue("src-d", "export", issue, nil, nil))
+
+	for id := 1; id <= 2; id++ {
+		comment := &graphql.IssueComment{DatabaseId: id}
+		require.NoError(s.SaveIssueComment("src-d", "export", 1, comment))
+	}
+	require.NoError(s.Commit())
+
+	var buf bytes.Buffer
+	require.NoError(s.Export(version, &buf))
+
+	counts := make(map[string]int)
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e Event
+		require.NoError(dec.Decode(&e))
+		require.Equal(version, e.Version)
+		require.Zero(e.Sequence)
+		counts[e.Type]++
+
+		if e.Type == "issues" {
+			require.Equal("src-d", e.RepositoryOwner)
+			require.Equal("export", e.RepositoryName)
+			require.Equal(1, e.Number)
+			require.Contains(string(e.Data), `"title":"exported"`)
+		}
+	}
+
+	require.Equal(map[string]int{"repositories": 1, "issues": 1, "issue_comments": 2}, counts)
+
+	buf.Reset()
+	require.NoError(s.Export(version+1, &buf))
+	require.Empty(buf.String())
+}
+
+// TestCopyRepositoryForward checks that the latest version of a repository is
+// found with its times, and that it is copied forward with its issues
+func TestCopyRepositoryForward(t *testing.T) {
+	s := getDB(t)
+	defer s.Close()
+
+	require := require.New(t)
+
+	const from = 268
+	s.Version(from)
+	require.NoError(s.Begin())
+
+	repository := &graphql.RepositoryFields{}
+	repository.Owner.Login = "src-d"
+	repository.Name = "unchanged"
+	repository.PushedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	repository.UpdatedAt = time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC)
+	require.NoError(s.SaveRepository(repository, []string{"go"}))
+
+	issue := &graphql.Issue{}
+	issue.Number = 1
+	require.NoError(s.SaveIssue("src-d", "unchanged", issue, nil, nil))
+	require.NoError(s.Commit())
+
+	version, pushedAt, updatedAt, err := s.LatestRepository("src-d", "unchanged")
+	require.NoError(err)
+	require.Equal(from, version)
+	require.True(repository.PushedAt.Equal(pushedAt))
+	require.True(repository.UpdatedAt.Equal(updatedAt))
+
+	s.Version(from + 1)
+	require.NoError(s.Begin())
+	require.NoError(s.CopyRepositoryForward("src-d", "unchanged", from))
+	require.NoError(s.Commit())
+
+	version, _, _, err = s.LatestRepository("src-d", "unchanged")
+	require.NoError(err)
+	require.Equal(from+1, version)
+
+	for _, query := range []string{
+		`SELECT versions FROM repository_topics_versioned WHERE repository_owner = 'src-d' AND repository_name = 'unchanged'`,
+		`SELECT versions FROM issues_versioned WHERE repository_owner = 'src-d' AND repository_name = 'unchanged'`,
+	} {
+		var versions pq.Int64Array
+		require.NoError(s.QueryRow(query).Scan(&versions))
+		require.Equal(pq.Int64Array{from, from + 1}, versions)
+	}
+
+	version, _, _, err = s.LatestRepository("src-d", "never-saved")
+	require.NoError(err)
+	require.Zero(version)
+}
+
+// TestPostgres saves the same version twice, and cleans up the other
+// versions with the single statements of Postgres.Cleanup
+func TestPostgres(t *testing.T) {
+	db := getDB(t)
+	defer db.Close()
+
+	require := require.New(t)
+
+	s := &Postgres{DB: db}
+	save := func(version int, topics ...string) {
+		s.Version(version)
+		require.NoError(s.Begin())
+		for _, topic := range topics {
+			require.NoError(s.SaveTopic(topic))
+		}
+		require.NoError(s.Commit())
+	}
+
+	save(1, "pg-stale", "pg-kept")
+	save(2, "pg-kept", "pg-new")
+	save(2, "pg-kept", "pg-new")
+
+	require.NoError(s.Cleanup(2))
+
+	rows, err := s.Query(`SELECT name, versions FROM topics_versioned WHERE name LIKE 'pg-%' ORDER BY name`)
+	require.NoError(err)
+	defer rows.Close()
+
+	saved := map[string]pq.Int64Array{}
+	for rows.Next() {
+		var name string
+		var versions pq.Int64Array
+		require.NoError(rows.Scan(&name, &versions))
+		saved[name] = versions
+	}
+	require.NoError(rows.Err())
+	require.Equal(map[string]pq.Int64Array{
+		"pg-kept": {2},
+		"pg-new":  {2},
+	}, saved)
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// deltaTables are the tables of the issues and PRs of a repository, and the
+// column with their number. The rows of the unchanged issues and PRs are
+// copied forward by CopyForward. mentioned is set for the tables whose node_id
+// can be the subject of a mention or a reaction
+var deltaTables = []struct {
+	table     string
+	number    string
+	mentioned bool
+}{
+	{"issues_versioned", "number", true},
+	{"issue_comments_versioned", "issue_number", true},
+	{"pull_requests_versioned", "number", true},
+	{"pull_request_reviews_versioned", "pull_request_number", true},
+	{"pull_request_comments_versioned", "pull_request_number", true},
+	{"pull_request_review_transitions_versioned", "pull_request_number", false},
+	{"pull_request_commits_versioned", "pull_request_number", false},
+	{"assignment_events_versioned", "number", false},
+	{"issue_events_versioned", "issue_number", false},
+	{"pull_request_events_versioned", "pull_request_number", false},
+	{"locks_versioned", "number", false},
+	{"project_statuses_versioned", "pull_request_number", false},
+}
+
+// LatestUpdate returns the latest update time of the issues and PRs of the
+// repository saved in the given version, or zero if there is none
+func (s *DB) LatestUpdate(repositoryOwner, repositoryName string, version int) (time.Time, error) {
+	var updatedAt pq.NullTime
+	err := s.DB.QueryRow(`SELECT MAX(updated_at) FROM (
+			SELECT updated_at FROM issues_versioned
+			WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)
+			UNION ALL
+			SELECT updated_at FROM pull_requests_versioned
+			WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)
+		) AS saved`, repositoryOwner, repositoryName, version).Scan(&updatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query the latest update of %v/%v in version %v: %v", repositoryOwner, repositoryName, version, err)
+	}
+
+	return updatedAt.Time, nil
+}
+
+// CopyForward adds the current version to the rows of the issues and PRs of
+// the repository saved in version from, and to their comments, reviews,
+// mentions, reactions and the rest of their resources, unless the issue or PR
+// is already saved in the current version. The users saved in version from
+// are copied forward too, they are not tied to a repository. It must be
+// called inside a transaction, after saving the changed issues and PRs
+func (s *DB) CopyForward(repositoryOwner, repositoryName string, from int) error {
+	changed, err := s.savedNumbers(repositoryOwner, repositoryName)
+	if err != nil {
+		return err
+	}
+
+	// the mentions and reactions are keyed by the node id of their subject,
+	// the unchanged issues, PRs, comments and reviews
+	var subjects []string
+	for _, t := range deltaTables {
+		if t.mentioned {
+			subjects = append(subjects, fmt.Sprintf(`SELECT node_id FROM %s
+				WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions) AND %s <> ALL($5)`,
+				t.table, t.number))
+		}
+	}
+
+	type update struct {
+		table     string
+		statement string
+	}
+
+	var updates []update
+	for _, table := range []string{"mentions", "reactions"} {
+		updates = append(updates, update{table, fmt.Sprintf(`UPDATE %s_versioned SET versions = array_append(versions, $4)
+			WHERE $3 = ANY(versions) AND $4 <> ALL(versions) AND subject_id IN (%s)`,
+			table, strings.Join(subjects, " UNION ALL "))})
+	}
+
+	for _, t := range deltaTables {
+		updates = append(updates, update{t.table, fmt.Sprintf(`UPDATE %s SET versions = array_append(versions, $4)
+			WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions) AND $4 <> ALL(versions)
+			AND %s <> ALL($5)`, t.table, t.number)})
+	}
+
+	updates = append(updates, update{"closing_references", `UPDATE closing_references_versioned SET versions = array_append(versions, $4)
+		WHERE $3 = ANY(versions) AND $4 <> ALL(versions) AND (
+			(pull_request_repository_owner = $1 AND pull_request_repository_name = $2 AND pull_request_number <> ALL($5))
+			OR (issue_repository_owner = $1 AND issue_repository_name = $2 AND issue_number <> ALL($5)))`})
+
+	for _, u := range updates {
+		_, err := s.tx.Exec(u.statement, repositoryOwner, repositoryName, from, s.v, pq.Array(changed))
+		if err != nil {
+			return fmt.Errorf("failed to copy %v of %v/%v forward from version %v: %v", u.table, repositoryOwner, repositoryName, from, err)
+		}
+	}
+
+	_, err = s.tx.Exec(`UPDATE users_versioned SET versions = array_append(versions, $2)
+		WHERE $1 = ANY(versions) AND $2 <> ALL(versions)`, from, s.v)
+	if err != nil {
+		return fmt.Errorf("failed to copy users forward from version %v: %v", from, err)
+	}
+
+	return nil
+}
+
+// savedNumbers returns the numbers of the issues and PRs of the repository
+// saved in the current version
+func (s *DB) savedNumbers(repositoryOwner, repositoryName string) ([]int64, error) {
+	rows, err := s.tx.Query(`SELECT number FROM issues_versioned
+		WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)
+		UNION
+		SELECT number FROM pull_requests_versioned
+		WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions)`,
+		repositoryOwner, repositoryName, s.v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the saved issues and PRs of %v/%v: %v", repositoryOwner, repositoryName, err)
+	}
+	defer rows.Close()
+
+	numbers := []int64{}
+	for rows.Next() {
+		var number int64
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to query the saved issues and PRs of %v/%v: %v", repositoryOwner, repositoryName, err)
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	return numbers, rows.Err()
+}
+
+// LatestRepository returns the greatest version the repository is saved in,
+// with its push and update times in that version, or a zero version if it is
+// not saved in any
+func (s *DB) LatestRepository(repositoryOwner, repositoryName string) (int, time.Time, time.Time, error) {
+	var version int
+	var pushedAt, updatedAt pq.NullTime
+	err := s.DB.QueryRow(`SELECT v, pushed_at, updated_at FROM repositories_versioned, unnest(versions) AS v
+		WHERE owner_login = $1 AND name = $2
+		ORDER BY v DESC LIMIT 1`, repositoryOwner, repositoryName).Scan(&version, &pushedAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, time.Time{}, nil
+	}
+
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, fmt.Errorf("failed to query the latest version of %v/%v: %v", repositoryOwner, repositoryName, err)
+	}
+
+	return version, pushedAt.Time, updatedAt.Time, nil
+}
+
+// repositoryTables are the tables of the resources of a repository other than
+// its issues and PRs, and the columns with its owner and name
+var repositoryTables = []struct {
+	table string
+	owner string
+	name  string
+}{
+	{"repositories_versioned", "owner_login", "name"},
+	{"repository_topics_versioned", "repository_owner", "repository_name"},
+	{"repository_rulesets_versioned", "repository_owner", "repository_name"},
+	{"templates_versioned", "repository_owner", "repository_name"},
+	{"readmes_versioned", "repository_owner", "repository_name"},
+}
+
+// CopyRepositoryForward adds the current version to the rows of the
+// repository saved in version from, its topics and the rest of its resources,
+// and then copies its issues and PRs forward, see CopyForward. It must be
+// called inside a transaction
+func (s *DB) CopyRepositoryForward(repositoryOwner, repositoryName string, from int) error {
+	_, err := s.tx.Exec(`UPDATE topics_versioned SET versions = array_append(versions, $4)
+		WHERE $3 = ANY(versions) AND $4 <> ALL(versions) AND name IN (
+			SELECT topic FROM repository_topics_versioned
+			WHERE repository_owner = $1 AND repository_name = $2 AND $3 = ANY(versions))`,
+		repositoryOwner, repositoryName, from, s.v)
+	if err != nil {
+		return fmt.Errorf("failed to copy topics of %v/%v forward from version %v: %v", repositoryOwner, repositoryName, from, err)
+	}
+
+	for _, t := range repositoryTables {
+		_, err := s.tx.Exec(fmt.Sprintf(`UPDATE %s SET versions = array_append(versions, $4)
+			WHERE %s = $1 AND %s = $2 AND $3 = ANY(versions) AND $4 <> ALL(versions)`, t.table, t.owner, t.name),
+			repositoryOwner, repositoryName, from, s.v)
+		if err != nil {
+			return fmt.Errorf("failed to copy %v of %v/%v forward from version %v: %v", t.table, repositoryOwner, repositoryName, from, err)
+		}
+	}
+
+	return s.CopyForward(repositoryOwner, repositoryName, from)
+}
@@ -0,0 +1,134 @@
+package store
+
+import "github.com/src-d/metadata-retrieval/github/graphql"
+
+// Discard is a Storer that saves nothing, e.g. to estimate the cost of a
+// download without keeping its data
+type Discard struct{}
+
+func (Discard) SaveOrganization(organization *graphql.Organization) error {
+	return nil
+}
+
+func (Discard) SaveUser(user *graphql.UserExtended) error {
+	return nil
+}
+
+func (Discard) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	return nil
+}
+
+func (Discard) SaveTopic(name string) error {
+	return nil
+}
+
+func (Discard) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	return nil
+}
+
+func (Discard) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	return nil
+}
+
+func (Discard) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	return nil
+}
+
+func (Discard) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	return nil
+}
+
+func (Discard) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	return nil
+}
+
+func (Discard) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	return nil
+}
+
+func (Discard) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	return nil
+}
+
+func (Discard) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	return nil
+}
+
+func (Discard) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	return nil
+}
+
+func (Discard) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	return nil
+}
+
+func (Discard) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	return nil
+}
+
+func (Discard) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	return nil
+}
+
+func (Discard) SaveMention(subjectID, mentionedLogin string) error {
+	return nil
+}
+
+func (Discard) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	return nil
+}
+
+func (Discard) SaveClosingReference(reference *ClosingReference) error {
+	return nil
+}
+
+func (Discard) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	return nil
+}
+
+func (Discard) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	return nil
+}
+
+func (Discard) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	return nil
+}
+
+func (Discard) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	return nil
+}
+
+func (Discard) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	return nil
+}
+
+func (Discard) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	return nil
+}
+
+func (Discard) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	return nil
+}
+
+func (Discard) Begin() error {
+	return nil
+}
+
+func (Discard) Commit() error {
+	return nil
+}
+
+func (Discard) Rollback() error {
+	return nil
+}
+
+func (Discard) Version(v int) {
+}
+
+func (Discard) SetActiveVersion(v int) error {
+	return nil
+}
+
+func (Discard) Cleanup(currentVersion int) error {
+	return nil
+}
@@ -0,0 +1,350 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// Event is an entry of the EventLog. Every call to the store, including
+// Begin, Commit and Rollback, is appended as an event; consumers replay the
+// events between a "begin" and a "commit" to rebuild the state, and discard
+// the ones followed by a "rollback"
+type Event struct {
+	// Sequence is strictly increasing across all the events of a log. It is
+	// omitted in the records of a JSONL
+	Sequence uint64 `json:"seq,omitempty"`
+	Version  int    `json:"version"`
+	// Type is the saved entity, e.g. "issue", or the transaction operation
+	Type string `json:"type"`
+
+	RepositoryOwner string `json:"repository_owner,omitempty"`
+	RepositoryName  string `json:"repository_name,omitempty"`
+	// Number of the issue or PR the entity belongs to
+	Number int `json:"number,omitempty"`
+	// ReviewId of the review a review comment belongs to
+	ReviewId int `json:"review_id,omitempty"`
+
+	// Data is the JSON encoding of the saved entity
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// EventLog appends every call to the store as a JSON encoded Event to a
+// writer, one per line. Events are never overwritten: a new download to the
+// same log appends its events after the existing ones, continuing their
+// sequence. It is safe for concurrent use
+type EventLog struct {
+	mu  sync.Mutex
+	w   io.Writer
+	seq uint64
+	v   int
+	// unsequenced leaves the sequence of the events unset, see JSONL
+	unsequenced bool
+}
+
+// NewEventLog returns an EventLog that appends its events to w. The sequence
+// of the first event is lastSequence+1; use LastSequence to resume a log
+func NewEventLog(w io.Writer, lastSequence uint64) *EventLog {
+	return &EventLog{w: w, seq: lastSequence}
+}
+
+// LastSequence reads the events of a log and returns the greatest sequence,
+// or 0 if the log is empty
+func LastSequence(r io.Reader) (uint64, error) {
+	var last uint64
+	dec := json.NewDecoder(r)
+	for {
+		var e Event
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read event after sequence %v: %v", last, err)
+		}
+
+		if e.Sequence > last {
+			last = e.Sequence
+		}
+	}
+}
+
+func (s *EventLog) append(e Event, data interface{}) error {
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encode %v event: %v", e.Type, err)
+		}
+		e.Data = b
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.unsequenced {
+		e.Sequence = s.seq + 1
+	}
+	e.Version = s.v
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode %v event: %v", e.Type, err)
+	}
+
+	_, err = s.w.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to append %v event: %v", e.Type, err)
+	}
+
+	// the sequence only advances when the event was written, so the log has
+	// no gaps
+	s.seq = e.Sequence
+	return nil
+}
+
+func (s *EventLog) SaveOrganization(organization *graphql.Organization) error {
+	return s.append(Event{Type: "organization"}, organization.OrganizationFields)
+}
+
+func (s *EventLog) SaveUser(user *graphql.UserExtended) error {
+	return s.append(Event{Type: "user"}, user)
+}
+
+func (s *EventLog) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	return s.append(Event{
+		Type:            "repository",
+		RepositoryOwner: repository.Owner.Login,
+		RepositoryName:  repository.Name,
+	}, repository)
+}
+
+func (s *EventLog) SaveTopic(name string) error {
+	return s.append(Event{Type: "topic"}, name)
+}
+
+func (s *EventLog) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	return s.append(Event{
+		Type:            "repository_topic",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+	}, topic)
+}
+
+func (s *EventLog) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	return s.append(Event{
+		Type:            "issue",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          issue.Number,
+	}, struct {
+		graphql.IssueFields
+		Assignees []string
+		Labels    []string
+	}{issue.IssueFields, assignees, labels})
+}
+
+func (s *EventLog) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	return s.append(Event{
+		Type:            "issue_comment",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          issueNumber,
+	}, comment)
+}
+
+func (s *EventLog) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	return s.append(Event{
+		Type:            "pull_request",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pr.Number,
+	}, struct {
+		graphql.PullRequestFields
+		Assignees []string
+		Labels    []string
+	}{pr.PullRequestFields, assignees, labels})
+}
+
+func (s *EventLog) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	return s.append(Event{
+		Type:            "pull_request_comment",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+	}, comment)
+}
+
+func (s *EventLog) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	return s.append(Event{
+		Type:            "pull_request_review",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+	}, review.PullRequestReviewFields)
+}
+
+func (s *EventLog) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	return s.append(Event{
+		Type:            "pull_request_review_comment",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+		ReviewId:        pullRequestReviewId,
+	}, comment)
+}
+
+func (s *EventLog) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	return s.append(Event{
+		Type:            "pull_request_review_transition",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+		ReviewId:        transition.ReviewId,
+	}, transition)
+}
+
+func (s *EventLog) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	return s.append(Event{
+		Type:            "pull_request_commit",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+	}, commit)
+}
+
+func (s *EventLog) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	return s.append(Event{
+		Type:            "assignment_event",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          number,
+	}, event)
+}
+
+func (s *EventLog) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	return s.append(Event{
+		Type:            "issue_event",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          issueNumber,
+	}, event)
+}
+
+func (s *EventLog) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	return s.append(Event{
+		Type:            "pull_request_event",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+	}, event)
+}
+
+func (s *EventLog) SaveMention(subjectID, mentionedLogin string) error {
+	return s.append(Event{Type: "mention"}, struct {
+		SubjectID      string
+		MentionedLogin string
+	}{subjectID, mentionedLogin})
+}
+
+func (s *EventLog) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	return s.append(Event{Type: "reaction_group"}, struct {
+		SubjectID  string
+		Content    string
+		TotalCount int
+	}{subjectID, group.Content, group.Reactors.TotalCount})
+}
+
+func (s *EventLog) SaveClosingReference(reference *ClosingReference) error {
+	return s.append(Event{
+		Type:            "closing_reference",
+		RepositoryOwner: reference.PullRequestRepositoryOwner,
+		RepositoryName:  reference.PullRequestRepositoryName,
+		Number:          reference.PullRequestNumber,
+	}, reference)
+}
+
+func (s *EventLog) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	return s.append(Event{
+		Type:            "lock",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          number,
+	}, lock)
+}
+
+func (s *EventLog) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	return s.append(Event{
+		Type:            "project_status",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+		Number:          pullRequestNumber,
+	}, status)
+}
+
+func (s *EventLog) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	return s.append(Event{
+		Type:            "template",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+	}, template)
+}
+
+func (s *EventLog) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	return s.append(Event{
+		Type:            "readme",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+	}, readme)
+}
+
+func (s *EventLog) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	return s.append(Event{
+		Type:            "repository_ruleset",
+		RepositoryOwner: repositoryOwner,
+		RepositoryName:  repositoryName,
+	}, ruleset)
+}
+
+func (s *EventLog) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	return s.append(Event{Type: "audit_log_entry"}, struct {
+		Organization string
+		graphql.AuditLogEntry
+	}{organization, *entry})
+}
+
+func (s *EventLog) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	return s.append(Event{Type: "org_project"}, struct {
+		Organization string
+		graphql.OrgProject
+	}{organization, *project})
+}
+
+func (s *EventLog) Begin() error {
+	return s.append(Event{Type: "begin"}, nil)
+}
+
+func (s *EventLog) Commit() error {
+	return s.append(Event{Type: "commit"}, nil)
+}
+
+func (s *EventLog) Rollback() error {
+	return s.append(Event{Type: "rollback"}, nil)
+}
+
+func (s *EventLog) Version(v int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.v = v
+}
+
+func (s *EventLog) SetActiveVersion(v int) error {
+	return s.append(Event{Type: "set_active_version"}, v)
+}
+
+func (s *EventLog) Cleanup(currentVersion int) error {
+	return s.append(Event{Type: "cleanup"}, currentVersion)
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Export writes the rows of every table of the given version to w as JSON
+// lines, in the envelope of the JSONL records: the type is the table name,
+// e.g. "issues", and the data the row, keyed by column name. The DB does not
+// keep the GraphQL entities, so the data is not the same as the one of the
+// records written by JSONL during a download. The tables are written in
+// alphabetical order
+func (s *DB) Export(version int, w io.Writer) error {
+	var names []string
+	for name := range dbTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := s.exportTable(name, version, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *DB) exportTable(name string, version int, w io.Writer) error {
+	rows, err := s.DB.Query(fmt.Sprintf(`SELECT row_to_json(t) FROM (
+		SELECT %s FROM %s_versioned WHERE $1 = ANY(versions) ORDER BY sum256
+	) t`, dbTables[name].cols, name), version)
+	if err != nil {
+		return fmt.Errorf("failed to export %v: %v", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		err = rows.Scan(&data)
+		if err != nil {
+			return fmt.Errorf("failed to export %v: %v", name, err)
+		}
+
+		var key struct {
+			RepositoryOwner string `json:"repository_owner"`
+			RepositoryName  string `json:"repository_name"`
+			Number          int    `json:"number"`
+		}
+		err = json.Unmarshal(data, &key)
+		if err != nil {
+			return fmt.Errorf("failed to export %v: %v", name, err)
+		}
+
+		b, err := json.Marshal(Event{
+			Version:         version,
+			Type:            name,
+			RepositoryOwner: key.RepositoryOwner,
+			RepositoryName:  key.RepositoryName,
+			Number:          key.Number,
+			Data:            data,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export %v: %v", name, err)
+		}
+
+		_, err = w.Write(append(b, '\n'))
+		if err != nil {
+			return fmt.Errorf("failed to write %v: %v", name, err)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dbTable describes a table of the DB store, for Fields
+type dbTable struct {
+	cols string
+	// required are the NOT NULL columns, they can not be excluded
+	required []string
+}
+
+// dbTables are the tables of the DB store, keyed by the name of their view
+var dbTables = map[string]dbTable{
+	"organizations":                   {organizationsCols, nil},
+	"users":                           {usersCols, nil},
+	"repositories":                    {repositoriesCols, []string{"owner_id", "owner_login", "owner_type", "topics"}},
+	"topics":                          {topicsCols, []string{"name"}},
+	"repository_topics":               {repositoryTopicsCols, []string{"repository_name", "repository_owner", "topic"}},
+	"issues":                          {issuesCols, []string{"assignees", "closed_by_id", "closed_by_login", "labels", "milestone_id", "milestone_title", "repository_name", "repository_owner", "user_id", "user_login", "body_truncated", "tasks_done", "tasks_total", "milestone_state", "last_edited_by_id", "last_edited_by_login"}},
+	"issue_comments":                  {issueCommentsCols, []string{"issue_number", "repository_name", "repository_owner", "user_id", "user_login", "body_truncated", "last_edited_by_id", "last_edited_by_login"}},
+	"pull_requests":                   {pullRequestsCol, []string{"assignees", "base_ref", "base_repository_name", "base_repository_owner", "base_sha", "base_user", "head_ref", "head_repository_name", "head_repository_owner", "head_sha", "head_user", "labels", "merged_by_id", "merged_by_login", "milestone_id", "milestone_title", "repository_name", "repository_owner", "user_id", "user_login", "body_truncated", "tasks_done", "tasks_total", "merge_method", "milestone_state", "last_edited_by_id", "last_edited_by_login"}},
+	"pull_request_reviews":            {pullRequestReviewsCols, []string{"pull_request_number", "repository_name", "repository_owner", "user_id", "user_login", "body_truncated", "last_edited_by_id", "last_edited_by_login"}},
+	"pull_request_comments":           {pullRequestReviewCommentsCols, []string{"pull_request_number", "repository_name", "repository_owner", "user_id", "user_login", "body_truncated", "last_edited_by_id", "last_edited_by_login"}},
+	"pull_request_review_transitions": {reviewTransitionsCols, []string{"from_state", "pull_request_number", "repository_name", "repository_owner", "sequence", "to_state", "user_login"}},
+	"pull_request_commits":            {pullRequestCommitsCols, []string{"author_email", "author_login", "author_name", "committer_email", "committer_login", "committer_name", "oid", "pull_request_number", "repository_name", "repository_owner"}},
+	"assignment_events":               {assignmentEventsCols, []string{"actor_login", "assignee_login", "event", "number", "repository_name", "repository_owner"}},
+	"issue_events":                    {issueEventsCols, []string{"actor_login", "event", "issue_number", "repository_name", "repository_owner"}},
+	"pull_request_events":             {pullRequestEventsCols, []string{"actor_login", "event", "pull_request_number", "repository_name", "repository_owner"}},
+	"project_statuses":                {projectStatusesCols, []string{"node_id", "project_node_id", "project_number", "project_title", "pull_request_number", "repository_name", "repository_owner", "status"}},
+	"locks":                           {locksCols, []string{"lock_reason", "locked_by_id", "locked_by_login", "node_id", "number", "repository_name", "repository_owner"}},
+	"mentions":                        {mentionsCols, []string{"mentioned_login", "subject_id"}},
+	"reactions":                       {reactionsCols, []string{"content", "subject_id", "total_count"}},
+	"closing_references":              {closingReferencesCols, []string{"issue_number", "issue_repository_name", "issue_repository_owner", "pull_request_number", "pull_request_repository_name", "pull_request_repository_owner"}},
+	"repository_rulesets":             {rulesetsCols, []string{"conditions_exclude", "conditions_include", "repository_name", "repository_owner", "rule_types"}},
+	"templates":                       {templatesCols, []string{"about", "body", "filename", "kind", "name", "repository_name", "repository_owner", "title"}},
+	"readmes":                         {readmesCols, []string{"body", "byte_size", "filename", "repository_name", "repository_owner"}},
+	"audit_log_entries":               {auditLogEntriesCols, []string{"action", "entry_type", "organization_login"}},
+	"org_projects":                    {orgProjectsCols, []string{"field_data_types", "field_names", "node_id", "number", "organization_login", "title"}},
+	"org_project_items":               {orgProjectItemsCols, []string{"item_type", "node_id", "organization_login", "project_node_id", "project_number"}},
+}
+
+// Fields selects the columns saved by DB in each table, to store only the
+// ones a downstream schema needs. The keys are the names of the tables
+// without the _versioned suffix, e.g. "issues", and the values their columns
+// to save. The tables not in Fields keep all their columns. The NOT NULL
+// columns, like the repository of an issue, are always saved. The rest of the
+// columns are saved as NULL
+type Fields map[string][]string
+
+// Validate returns an error if a table or a column of f is unknown
+func (f Fields) Validate() error {
+	for table, columns := range f {
+		t, ok := dbTables[table]
+		if !ok {
+			return fmt.Errorf("unknown table %q", table)
+		}
+
+		known := strings.Split(t.cols, ", ")
+		for _, column := range columns {
+			if !contains(known, column) {
+				return fmt.Errorf("unknown column %q of table %q, it must be one of: %v",
+					column, table, t.cols)
+			}
+		}
+	}
+
+	return nil
+}
+
+// excluded returns, for each table of f, whether each column of the table is
+// excluded, in the order of the columns. f must be valid
+func (f Fields) excluded() map[string][]bool {
+	if len(f) == 0 {
+		return nil
+	}
+
+	res := make(map[string][]bool, len(f))
+	for table, columns := range f {
+		t := dbTables[table]
+		known := strings.Split(t.cols, ", ")
+
+		excluded := make([]bool, len(known))
+		for i, column := range known {
+			excluded[i] = !contains(columns, column) && !contains(t.required, column)
+		}
+
+		res[table] = excluded
+	}
+
+	return res
+}
+
+// Excluded returns the columns of the table that are saved as NULL, sorted
+func (f Fields) Excluded(table string) []string {
+	known := strings.Split(dbTables[table].cols, ", ")
+
+	var res []string
+	for i, excluded := range f.excluded()[table] {
+		if excluded {
+			res = append(res, known[i])
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+// filterColumns sets to nil the args of the excluded columns. The args are the
+// ones of an insert in the table: the sum256 and the versions first, then the
+// columns in order, and then the rest
+func filterColumns(excluded []bool, args []interface{}) []interface{} {
+	if excluded == nil {
+		return args
+	}
+
+	filtered := append([]interface{}(nil), args...)
+	for i, e := range excluded {
+		if e {
+			filtered[2+i] = nil
+		}
+	}
+
+	return filtered
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
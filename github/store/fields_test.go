@@ -0,0 +1,48 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsValidate(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError(Fields(nil).Validate())
+	require.NoError(Fields{"issues": {"number", "title"}, "users": {}}.Validate())
+
+	require.EqualError(Fields{"commits": {"sha"}}.Validate(), `unknown table "commits"`)
+
+	err := Fields{"issues": {"number", "Title"}}.Validate()
+	require.Error(err)
+	require.Contains(err.Error(), `unknown column "Title" of table "issues"`)
+}
+
+func TestFieldsFilter(t *testing.T) {
+	require := require.New(t)
+
+	f := Fields{"repository_topics": {"topic"}, "mentions": {"subject_id"}, "topics": {}}
+
+	// NOT NULL columns are kept
+	require.Empty(f.Excluded("repository_topics"))
+	require.Empty(f.Excluded("topics"))
+	require.Empty(f.Excluded("issues"))
+
+	f = Fields{"issues": {"number", "title", "state"}}
+	require.Equal([]string{
+		"body", "closed_at", "comments", "created_at", "htmlurl", "id",
+		"last_edited_at", "locked", "milestone_due_on", "node_id", "updated_at",
+	}, f.Excluded("issues"))
+
+	// the args of an insert in assignment_events_versioned
+	args := []interface{}{"sum256", "versions",
+		"alice", "bob", "2019-10-01", "AssignedEvent", "node", 1, "basic", "git-fixtures",
+		10}
+
+	excluded := Fields{"assignment_events": {"event"}}.excluded()
+	require.Equal([]interface{}{"sum256", "versions",
+		"alice", "bob", nil, "AssignedEvent", nil, 1, "basic", "git-fixtures",
+		10}, filterColumns(excluded["assignment_events"], args))
+	require.Equal(args, filterColumns(excluded["issues"], args))
+}
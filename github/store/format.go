@@ -0,0 +1,92 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// reviewVerbs describe the states of the submitted reviews, for FormatReview
+var reviewVerbs = map[string]string{
+	"APPROVED":          "approved",
+	"CHANGES_REQUESTED": "requested changes",
+	"COMMENTED":         "reviewed",
+	"DISMISSED":         "reviewed, dismissed later,",
+}
+
+// FormatComment renders an issue or PR comment as Markdown, to migrate it to
+// another system: a line with its original author and time, and its body
+// quoted
+func FormatComment(comment *graphql.IssueComment) string {
+	return formatQuoted(formatAttribution(&comment.Author, "commented", comment.CreatedAt), comment.Body)
+}
+
+// FormatReview renders a PR review as Markdown like FormatComment, with the
+// state of the review. Pending reviews have no time
+func FormatReview(review *graphql.PullRequestReviewFields) string {
+	if review.SubmittedAt == nil {
+		header := fmt.Sprintf("**@%s** started a pending review", review.Author.LoginOrGhost())
+		return formatQuoted(header, review.Body)
+	}
+
+	verb, ok := reviewVerbs[review.State]
+	if !ok {
+		verb = "reviewed"
+	}
+
+	return formatQuoted(formatAttribution(&review.Author, verb, *review.SubmittedAt), review.Body)
+}
+
+// FormatReviewComment renders a PR review comment as Markdown like
+// FormatComment, with the file it comments and its diff hunk
+func FormatReviewComment(comment *graphql.PullRequestReviewComment) string {
+	header := formatAttribution(&comment.Author, fmt.Sprintf("commented on `%s`", comment.Path), comment.CreatedAt)
+	if comment.DiffHunk == "" {
+		return formatQuoted(header, comment.Body)
+	}
+
+	res := header + ":\n\n```diff\n" + strings.TrimSuffix(comment.DiffHunk, "\n") + "\n```"
+	if quoted := quote(comment.Body); quoted != "" {
+		res += "\n\n" + quoted
+	}
+
+	return res
+}
+
+// formatAttribution returns who did what and when, in bold the login
+func formatAttribution(author *graphql.Actor, verb string, at time.Time) string {
+	return fmt.Sprintf("**@%s** %s on %s", author.LoginOrGhost(), verb, at.UTC().Format("2006-01-02 15:04 MST"))
+}
+
+// formatQuoted returns the header followed by the body quoted, or only the
+// header if the body is empty
+func formatQuoted(header, body string) string {
+	quoted := quote(body)
+	if quoted == "" {
+		return header
+	}
+
+	return header + ":\n\n" + quoted
+}
+
+// quote returns the Markdown body as a blockquote, line by line, or an empty
+// string if the body is empty
+func quote(body string) string {
+	body = strings.TrimRight(body, " \t\r\n")
+	if body == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.Replace(body, "\r\n", "\n", -1), "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatComment(t *testing.T) {
+	require := require.New(t)
+
+	comment := &graphql.IssueComment{Body: "lgtm\n\nbut fix the `%+v`\r\n"}
+	comment.Author.Login = "alice"
+	comment.CreatedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	require.Equal("**@alice** commented on 2019-10-01 08:00 UTC:\n\n> lgtm\n>\n> but fix the `%+v`", FormatComment(comment))
+
+	// deleted accounts and empty bodies
+	comment = &graphql.IssueComment{CreatedAt: time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)}
+	require.Equal("**@ghost** commented on 2019-10-01 10:00 UTC", FormatComment(comment))
+}
+
+func TestFormatReview(t *testing.T) {
+	require := require.New(t)
+
+	submittedAt := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	review := &graphql.PullRequestReviewFields{State: "CHANGES_REQUESTED", SubmittedAt: &submittedAt, Body: "needs tests"}
+	review.Author.Login = "bob"
+	require.Equal("**@bob** requested changes on 2019-10-01 10:00 UTC:\n\n> needs tests", FormatReview(review))
+
+	review = &graphql.PullRequestReviewFields{State: "APPROVED", SubmittedAt: &submittedAt}
+	review.Author.Login = "bob"
+	require.Equal("**@bob** approved on 2019-10-01 10:00 UTC", FormatReview(review))
+
+	review = &graphql.PullRequestReviewFields{State: "PENDING", Body: "draft"}
+	review.Author.Login = "bob"
+	require.Equal("**@bob** started a pending review:\n\n> draft", FormatReview(review))
+}
+
+func TestFormatReviewComment(t *testing.T) {
+	comment := &graphql.PullRequestReviewComment{
+		Body:      "typo",
+		CreatedAt: time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC),
+		DiffHunk:  "@@ -1 +1 @@\n-foo\n+fo\n",
+		Path:      "main.go",
+	}
+	comment.Author.Login = "alice"
+
+	require.Equal(t, "**@alice** commented on `main.go` on 2019-10-01 10:00 UTC:\n\n"+
+		"```diff\n@@ -1 +1 @@\n-foo\n+fo\n```\n\n> typo", FormatReviewComment(comment))
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// JSONL writes the downloaded metadata to a writer as JSON lines, one record
+// per saved entity, e.g. to load it into a data lake. The records are Events
+// without a sequence: the type and version of each one are in the envelope,
+// and the entity in its data.
+//
+// Unlike the EventLog, transactions are not written as records. The records
+// saved between Begin and Commit are buffered and written at once on Commit,
+// so they are all written or none of them; Rollback discards them. Records
+// saved outside a transaction are written immediately. It is safe for
+// concurrent use
+type JSONL struct {
+	*EventLog
+
+	mu  sync.Mutex
+	w   io.Writer
+	tx  bool
+	buf bytes.Buffer
+}
+
+// NewJSONL returns a JSONL that writes its records to w
+func NewJSONL(w io.Writer) *JSONL {
+	s := &JSONL{w: w}
+	s.EventLog = &EventLog{w: jsonlWriter{s}, unsequenced: true}
+	return s
+}
+
+// jsonlWriter receives the records of the EventLog of a JSONL
+type jsonlWriter struct {
+	s *JSONL
+}
+
+func (w jsonlWriter) Write(p []byte) (int, error) {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+
+	if w.s.tx {
+		return w.s.buf.Write(p)
+	}
+
+	return w.s.w.Write(p)
+}
+
+func (s *JSONL) Begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx {
+		return fmt.Errorf("transaction already started")
+	}
+
+	s.tx = true
+	s.buf.Reset()
+	return nil
+}
+
+func (s *JSONL) Commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.tx {
+		return fmt.Errorf("no transaction started")
+	}
+
+	s.tx = false
+	_, err := s.w.Write(s.buf.Bytes())
+	s.buf.Reset()
+	if err != nil {
+		return fmt.Errorf("failed to write the records of the transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (s *JSONL) Rollback() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tx = false
+	s.buf.Reset()
+	return nil
+}
+
+// SetActiveVersion is a noop, the records of every version are written
+func (s *JSONL) SetActiveVersion(v int) error {
+	return nil
+}
+
+// Cleanup is a noop, written records cannot be deleted
+func (s *JSONL) Cleanup(currentVersion int) error {
+	return nil
+}
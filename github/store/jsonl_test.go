@@ -0,0 +1,61 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONL(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	s := NewJSONL(&buf)
+	s.Version(3)
+
+	repository := &graphql.RepositoryFields{Name: "gitbase"}
+	repository.Owner.Login = "src-d"
+	require.NoError(s.SaveRepository(repository, nil))
+	require.Equal(1, strings.Count(buf.String(), "\n"))
+
+	// the records of a transaction are written on commit
+	require.NoError(s.Begin())
+	issue := &graphql.Issue{}
+	issue.Number = 12
+	require.NoError(s.SaveIssue("src-d", "gitbase", issue, nil, nil))
+	require.NoError(s.SaveIssueComment("src-d", "gitbase", 12, &graphql.IssueComment{Body: "lgtm"}))
+	require.Equal(1, strings.Count(buf.String(), "\n"))
+	require.NoError(s.Commit())
+
+	// and discarded on rollback
+	require.NoError(s.Begin())
+	require.NoError(s.SavePullRequest("src-d", "gitbase", &graphql.PullRequest{}, nil, nil))
+	require.NoError(s.Rollback())
+
+	var records []Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		require.NotContains(line, `"seq"`)
+
+		var e Event
+		require.NoError(json.Unmarshal([]byte(line), &e))
+		require.Equal(3, e.Version)
+		records = append(records, e)
+	}
+
+	require.Len(records, 3)
+	require.Equal("repository", records[0].Type)
+	require.Equal("issue", records[1].Type)
+	require.Equal(12, records[1].Number)
+	require.Equal("issue_comment", records[2].Type)
+
+	var comment graphql.IssueComment
+	require.NoError(json.Unmarshal(records[2].Data, &comment))
+	require.Equal("lgtm", comment.Body)
+
+	require.Error(s.Commit())
+}
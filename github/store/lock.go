@@ -0,0 +1,16 @@
+package store
+
+import "time"
+
+// Lock is the lock of a locked issue or PR, from its last locked event
+type Lock struct {
+	// NodeID is the node id of the locked event
+	NodeID   string
+	LockedAt time.Time
+	// LockedByID is 0 if the actor is not a user, e.g. a bot
+	LockedByID    int
+	LockedByLogin string
+	// Reason is OFF_TOPIC, RESOLVED, SPAM, TOO_HEATED, or empty if the lock
+	// has no reason
+	Reason string
+}
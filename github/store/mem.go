@@ -0,0 +1,850 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// Mem keeps the downloaded metadata in memory. It is safe for concurrent use.
+// Mem does not keep track of versions, Begin, Commit and Rollback are noops
+type Mem struct {
+	sync.Mutex
+
+	Organizations map[string]*graphql.OrganizationFields
+	// AuditLogs are keyed by organization login
+	AuditLogs map[string][]graphql.AuditLogEntry
+	// OrgProjects are keyed by organization login
+	OrgProjects map[string][]graphql.OrgProject
+	// Users are keyed by login
+	Users map[string]*graphql.UserExtended
+	// Repos are keyed by owner and name
+	Repos map[string]map[string]*Repo
+	// Mentions are the logins mentioned in each issue, PR, comment or review,
+	// keyed by their node id
+	Mentions map[string][]string
+	// ClosingReferences are saved once, even if they are found from both the
+	// PR and the issue. Use ClosingIssues and ClosingPullRequests to follow
+	// them from either side
+	ClosingReferences []ClosingReference
+}
+
+// Repo is a repository and all its resources
+type Repo struct {
+	graphql.RepositoryFields
+	Topics   []string
+	Rulesets []graphql.RepositoryRuleset
+	// Templates are the issue and PR templates
+	Templates []Template
+	// Readme is nil if the README was not downloaded or the repository has
+	// none
+	Readme *Readme
+	// Issues are keyed by number
+	Issues map[int]*Issue
+	// PRs are keyed by number
+	PRs map[int]*PullRequest
+}
+
+// Issue is an issue and its comments
+type Issue struct {
+	graphql.IssueFields
+	ClosedBy         string
+	Assignees        []string
+	Labels           []string
+	Comments         []graphql.IssueComment
+	AssignmentEvents []graphql.AssignmentEvent
+	// Events are the timeline events, see graphql.IssueEvent
+	Events []graphql.IssueEvent
+	// TasksDone and TasksTotal count the task list items of the body, see
+	// ParseTaskList
+	TasksDone  int
+	TasksTotal int
+	// Lock is nil if the issue is not locked
+	Lock *Lock
+}
+
+// PullRequest is a PR and its comments and reviews
+type PullRequest struct {
+	graphql.PullRequestFields
+	Assignees         []string
+	Labels            []string
+	Comments          []graphql.IssueComment
+	Reviews           []Review
+	ReviewTransitions []ReviewStateTransition
+	// Commits are the commits of the PR, only downloaded with
+	// PullRequestCommitsIncluded
+	Commits          []graphql.PullRequestCommit
+	AssignmentEvents []graphql.AssignmentEvent
+	// Events are the timeline events, see graphql.PullRequestEvent
+	Events []graphql.PullRequestEvent
+	// TasksDone and TasksTotal count the task list items of the body, see
+	// ParseTaskList
+	TasksDone  int
+	TasksTotal int
+	// MergeMethod is the method used to merge the PR, see MergeMethod
+	MergeMethod string
+	// Lock is nil if the PR is not locked
+	Lock *Lock
+	// ProjectStatuses are the statuses of the PR in its projects
+	ProjectStatuses []ProjectStatus
+}
+
+// Review is a PR review and its comments
+type Review struct {
+	graphql.PullRequestReviewFields
+	Comments []graphql.PullRequestReviewComment
+}
+
+// NewMem returns an empty Mem
+func NewMem() *Mem {
+	return &Mem{
+		Organizations: make(map[string]*graphql.OrganizationFields),
+		AuditLogs:     make(map[string][]graphql.AuditLogEntry),
+		OrgProjects:   make(map[string][]graphql.OrgProject),
+		Users:         make(map[string]*graphql.UserExtended),
+		Repos:         make(map[string]map[string]*Repo),
+		Mentions:      make(map[string][]string),
+	}
+}
+
+// repo returns the repository with the given owner and name, creating it if
+// it does not exist yet. It must be called with the lock held
+func (s *Mem) repo(owner, name string) *Repo {
+	if s.Repos == nil {
+		s.Repos = make(map[string]map[string]*Repo)
+	}
+
+	if s.Repos[owner] == nil {
+		s.Repos[owner] = make(map[string]*Repo)
+	}
+
+	r, ok := s.Repos[owner][name]
+	if !ok {
+		r = &Repo{
+			Issues: make(map[int]*Issue),
+			PRs:    make(map[int]*PullRequest),
+		}
+		s.Repos[owner][name] = r
+	}
+
+	return r
+}
+
+// issue returns the issue with the given number, creating it if it does not
+// exist yet. It must be called with the lock held
+func (s *Mem) issue(owner, name string, number int) *Issue {
+	r := s.repo(owner, name)
+	i, ok := r.Issues[number]
+	if !ok {
+		i = &Issue{}
+		i.Number = number
+		r.Issues[number] = i
+	}
+
+	return i
+}
+
+// pr returns the PR with the given number, creating it if it does not exist
+// yet. It must be called with the lock held
+func (s *Mem) pr(owner, name string, number int) *PullRequest {
+	r := s.repo(owner, name)
+	pr, ok := r.PRs[number]
+	if !ok {
+		pr = &PullRequest{}
+		pr.Number = number
+		r.PRs[number] = pr
+	}
+
+	return pr
+}
+
+func (s *Mem) SaveOrganization(organization *graphql.Organization) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Organizations == nil {
+		s.Organizations = make(map[string]*graphql.OrganizationFields)
+	}
+
+	o := organization.OrganizationFields
+	s.Organizations[o.Login] = &o
+	return nil
+}
+
+func (s *Mem) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.AuditLogs == nil {
+		s.AuditLogs = make(map[string][]graphql.AuditLogEntry)
+	}
+
+	s.AuditLogs[organization] = append(s.AuditLogs[organization], *entry)
+	return nil
+}
+
+func (s *Mem) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.OrgProjects == nil {
+		s.OrgProjects = make(map[string][]graphql.OrgProject)
+	}
+
+	s.OrgProjects[organization] = append(s.OrgProjects[organization], *project)
+	return nil
+}
+
+func (s *Mem) SaveUser(user *graphql.UserExtended) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Users == nil {
+		s.Users = make(map[string]*graphql.UserExtended)
+	}
+
+	u := *user
+	s.Users[u.Login] = &u
+	return nil
+}
+
+func (s *Mem) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	r := s.repo(repository.Owner.Login, repository.Name)
+	r.RepositoryFields = *repository
+	r.Topics = append([]string(nil), topics...)
+	return nil
+}
+
+func (s *Mem) SaveTopic(name string) error {
+	// topics are kept in each Repo
+	return nil
+}
+
+func (s *Mem) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	// topics are kept in each Repo, saved by SaveRepository
+	return nil
+}
+
+func (s *Mem) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	s.Lock()
+	defer s.Unlock()
+
+	r := s.repo(repositoryOwner, repositoryName)
+	r.Rulesets = append(r.Rulesets, *ruleset)
+	return nil
+}
+
+func (s *Mem) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	s.Lock()
+	defer s.Unlock()
+
+	r := s.repo(repositoryOwner, repositoryName)
+	r.Templates = append(r.Templates, *template)
+	return nil
+}
+
+func (s *Mem) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	s.Lock()
+	defer s.Unlock()
+
+	r := s.repo(repositoryOwner, repositoryName)
+	rd := *readme
+	r.Readme = &rd
+	return nil
+}
+
+func (s *Mem) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	i := s.issue(repositoryOwner, repositoryName, issue.Number)
+	i.IssueFields = issue.IssueFields
+	if i.Comments == nil {
+		// the comments are saved next, allocate them all at once
+		i.Comments = make([]graphql.IssueComment, 0, issue.Comments.TotalCount)
+	}
+	i.Assignees = append([]string(nil), assignees...)
+	i.Labels = append([]string(nil), labels...)
+	if len(issue.ClosedBy.Nodes) > 0 {
+		i.ClosedBy = issue.ClosedBy.Nodes[0].ClosedEvent.Actor.Login
+	}
+	i.TasksDone, i.TasksTotal = ParseTaskList(issue.Body)
+
+	return nil
+}
+
+// SavedComments returns the update time of the issue or PR with the given
+// number, and the number of its saved comments. The update time is zero if it
+// is not saved
+func (s *Mem) SavedComments(repositoryOwner, repositoryName string, number int) (time.Time, int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	r, ok := s.Repos[repositoryOwner][repositoryName]
+	if !ok {
+		return time.Time{}, 0, nil
+	}
+
+	if p, ok := r.PRs[number]; ok {
+		updatedAt, err := time.Parse(time.RFC3339, p.UpdatedAt)
+		if err != nil {
+			return time.Time{}, 0, nil
+		}
+
+		return updatedAt, len(p.Comments), nil
+	}
+
+	if i, ok := r.Issues[number]; ok {
+		return i.UpdatedAt, len(i.Comments), nil
+	}
+
+	return time.Time{}, 0, nil
+}
+
+func (s *Mem) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	s.Lock()
+	defer s.Unlock()
+
+	i := s.issue(repositoryOwner, repositoryName, issueNumber)
+	i.Comments = append(i.Comments, *comment)
+	return nil
+}
+
+func (s *Mem) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pr.Number)
+	p.PullRequestFields = pr.PullRequestFields
+	if p.Comments == nil {
+		// the comments and reviews are saved next, allocate them all at once
+		p.Comments = make([]graphql.IssueComment, 0, pr.Comments.TotalCount)
+	}
+	if p.Reviews == nil {
+		p.Reviews = make([]Review, 0, pr.Reviews.TotalCount)
+	}
+	p.Assignees = append([]string(nil), assignees...)
+	p.Labels = append([]string(nil), labels...)
+	p.TasksDone, p.TasksTotal = ParseTaskList(pr.Body)
+	p.MergeMethod = MergeMethod(pr)
+	return nil
+}
+
+func (s *Mem) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	p.Comments = append(p.Comments, *comment)
+	return nil
+}
+
+func (s *Mem) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	p.Reviews = append(p.Reviews, Review{
+		PullRequestReviewFields: review.PullRequestReviewFields,
+		Comments:                make([]graphql.PullRequestReviewComment, 0, review.Comments.TotalCount),
+	})
+	return nil
+}
+
+func (s *Mem) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	for i := range p.Reviews {
+		if p.Reviews[i].DatabaseId == pullRequestReviewId {
+			p.Reviews[i].Comments = append(p.Reviews[i].Comments, *comment)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("review %v not found in PR %v/%v #%v", pullRequestReviewId, repositoryOwner, repositoryName, pullRequestNumber)
+}
+
+func (s *Mem) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	p.ReviewTransitions = append(p.ReviewTransitions, *transition)
+	return nil
+}
+
+func (s *Mem) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	p.Commits = append(p.Commits, *commit)
+	return nil
+}
+
+func (s *Mem) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	s.Lock()
+	defer s.Unlock()
+
+	// issues and PRs share the numbering, the event belongs to a PR if there
+	// is one with that number
+	r := s.repo(repositoryOwner, repositoryName)
+	if p, ok := r.PRs[number]; ok {
+		p.AssignmentEvents = append(p.AssignmentEvents, *event)
+		return nil
+	}
+
+	i := s.issue(repositoryOwner, repositoryName, number)
+	i.AssignmentEvents = append(i.AssignmentEvents, *event)
+	return nil
+}
+
+func (s *Mem) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	s.Lock()
+	defer s.Unlock()
+
+	i := s.issue(repositoryOwner, repositoryName, issueNumber)
+	i.Events = append(i.Events, *event)
+	return nil
+}
+
+func (s *Mem) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	p.Events = append(p.Events, *event)
+	return nil
+}
+
+func (s *Mem) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	s.Lock()
+	defer s.Unlock()
+
+	l := *lock
+
+	// issues and PRs share the numbering, the lock belongs to a PR if there
+	// is one with that number
+	r := s.repo(repositoryOwner, repositoryName)
+	if p, ok := r.PRs[number]; ok {
+		p.Lock = &l
+		return nil
+	}
+
+	s.issue(repositoryOwner, repositoryName, number).Lock = &l
+	return nil
+}
+
+func (s *Mem) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	s.Lock()
+	defer s.Unlock()
+
+	p := s.pr(repositoryOwner, repositoryName, pullRequestNumber)
+	p.ProjectStatuses = append(p.ProjectStatuses, *status)
+	return nil
+}
+
+func (s *Mem) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	// reaction groups are kept in the issues, PRs, comments and reviews
+	return nil
+}
+
+func (s *Mem) SaveMention(subjectID, mentionedLogin string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Mentions == nil {
+		s.Mentions = make(map[string][]string)
+	}
+
+	s.Mentions[subjectID] = append(s.Mentions[subjectID], mentionedLogin)
+	return nil
+}
+
+func (s *Mem) SaveClosingReference(reference *ClosingReference) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, r := range s.ClosingReferences {
+		if r == *reference {
+			return nil
+		}
+	}
+
+	s.ClosingReferences = append(s.ClosingReferences, *reference)
+	return nil
+}
+
+// ClosingIssues returns the references to the issues closed by the given PR
+func (s *Mem) ClosingIssues(owner, name string, number int) []ClosingReference {
+	s.Lock()
+	defer s.Unlock()
+
+	var res []ClosingReference
+	for _, r := range s.ClosingReferences {
+		if r.PullRequestRepositoryOwner == owner && r.PullRequestRepositoryName == name && r.PullRequestNumber == number {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+// ClosingPullRequests returns the references to the PRs that close the given
+// issue
+func (s *Mem) ClosingPullRequests(owner, name string, number int) []ClosingReference {
+	s.Lock()
+	defer s.Unlock()
+
+	var res []ClosingReference
+	for _, r := range s.ClosingReferences {
+		if r.IssueRepositoryOwner == owner && r.IssueRepositoryName == name && r.IssueNumber == number {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+// Querier reads back the metadata saved in a store. The returned values are
+// copies, changing them does not change the store
+type Querier interface {
+	GetRepo(owner, name string) (Repo, bool)
+	ListIssues(owner, name string) []Issue
+	GetIssue(owner, name string, number int) (Issue, bool)
+	ListPRs(owner, name string) []PullRequest
+	GetPR(owner, name string, number int) (PullRequest, bool)
+}
+
+var _ Querier = (*Mem)(nil)
+
+// GetRepo returns a copy of the repository with the given owner and name,
+// including its issues and PRs
+func (s *Mem) GetRepo(owner, name string) (Repo, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	r, ok := s.Repos[owner][name]
+	if !ok {
+		return Repo{}, false
+	}
+
+	return r.copy(), true
+}
+
+// ListIssues returns copies of the issues of the given repository, sorted by
+// number
+func (s *Mem) ListIssues(owner, name string) []Issue {
+	s.Lock()
+	defer s.Unlock()
+
+	r, ok := s.Repos[owner][name]
+	if !ok {
+		return nil
+	}
+
+	res := make([]Issue, 0, len(r.Issues))
+	for _, i := range r.Issues {
+		res = append(res, i.copy())
+	}
+
+	sort.Slice(res, func(a, b int) bool { return res[a].Number < res[b].Number })
+	return res
+}
+
+// GetIssue returns a copy of the issue with the given number
+func (s *Mem) GetIssue(owner, name string, number int) (Issue, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	i, ok := s.Repos[owner][name].issueByNumber(number)
+	if !ok {
+		return Issue{}, false
+	}
+
+	return i.copy(), true
+}
+
+// ListPRs returns copies of the PRs of the given repository, sorted by number
+func (s *Mem) ListPRs(owner, name string) []PullRequest {
+	s.Lock()
+	defer s.Unlock()
+
+	r, ok := s.Repos[owner][name]
+	if !ok {
+		return nil
+	}
+
+	res := make([]PullRequest, 0, len(r.PRs))
+	for _, p := range r.PRs {
+		res = append(res, p.copy())
+	}
+
+	sort.Slice(res, func(a, b int) bool { return res[a].Number < res[b].Number })
+	return res
+}
+
+// GetPR returns a copy of the PR with the given number
+func (s *Mem) GetPR(owner, name string, number int) (PullRequest, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	p, ok := s.Repos[owner][name].prByNumber(number)
+	if !ok {
+		return PullRequest{}, false
+	}
+
+	return p.copy(), true
+}
+
+// issueByNumber is nil safe, for repositories that were not saved
+func (r *Repo) issueByNumber(number int) (*Issue, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	i, ok := r.Issues[number]
+	return i, ok
+}
+
+// prByNumber is nil safe, for repositories that were not saved
+func (r *Repo) prByNumber(number int) (*PullRequest, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	p, ok := r.PRs[number]
+	return p, ok
+}
+
+// copy returns a copy of the repository that does not share its slices and
+// maps. The graphql fields are copied shallowly
+func (r *Repo) copy() Repo {
+	c := *r
+	c.Topics = append([]string(nil), r.Topics...)
+	c.Rulesets = append([]graphql.RepositoryRuleset(nil), r.Rulesets...)
+	c.Templates = append([]Template(nil), r.Templates...)
+	if r.Readme != nil {
+		rd := *r.Readme
+		c.Readme = &rd
+	}
+
+	c.Issues = make(map[int]*Issue, len(r.Issues))
+	for number, i := range r.Issues {
+		ic := i.copy()
+		c.Issues[number] = &ic
+	}
+
+	c.PRs = make(map[int]*PullRequest, len(r.PRs))
+	for number, p := range r.PRs {
+		pc := p.copy()
+		c.PRs[number] = &pc
+	}
+
+	return c
+}
+
+func (i *Issue) copy() Issue {
+	c := *i
+	c.Assignees = append([]string(nil), i.Assignees...)
+	c.Labels = append([]string(nil), i.Labels...)
+	c.Comments = append([]graphql.IssueComment(nil), i.Comments...)
+	c.AssignmentEvents = append([]graphql.AssignmentEvent(nil), i.AssignmentEvents...)
+	c.Events = append([]graphql.IssueEvent(nil), i.Events...)
+	c.ReactionGroups = append([]graphql.ReactionGroup(nil), i.ReactionGroups...)
+	if i.Lock != nil {
+		l := *i.Lock
+		c.Lock = &l
+	}
+
+	return c
+}
+
+func (p *PullRequest) copy() PullRequest {
+	c := *p
+	c.Assignees = append([]string(nil), p.Assignees...)
+	c.Labels = append([]string(nil), p.Labels...)
+	c.Comments = append([]graphql.IssueComment(nil), p.Comments...)
+	c.ReviewTransitions = append([]ReviewStateTransition(nil), p.ReviewTransitions...)
+	c.Commits = append([]graphql.PullRequestCommit(nil), p.Commits...)
+	c.AssignmentEvents = append([]graphql.AssignmentEvent(nil), p.AssignmentEvents...)
+	c.Events = append([]graphql.PullRequestEvent(nil), p.Events...)
+	c.ReactionGroups = append([]graphql.ReactionGroup(nil), p.ReactionGroups...)
+	c.ProjectStatuses = append([]ProjectStatus(nil), p.ProjectStatuses...)
+	if p.Lock != nil {
+		l := *p.Lock
+		c.Lock = &l
+	}
+
+	c.Reviews = nil
+	for _, r := range p.Reviews {
+		r.Comments = append([]graphql.PullRequestReviewComment(nil), r.Comments...)
+		c.Reviews = append(c.Reviews, r)
+	}
+
+	return c
+}
+
+func (s *Mem) Begin() error {
+	return nil
+}
+
+func (s *Mem) Commit() error {
+	return nil
+}
+
+func (s *Mem) Rollback() error {
+	return nil
+}
+
+func (s *Mem) Version(v int) {
+}
+
+func (s *Mem) SetActiveVersion(v int) error {
+	return nil
+}
+
+func (s *Mem) Cleanup(currentVersion int) error {
+	return nil
+}
+
+// MergeMem adds the organizations, users and repositories from src to dst.
+// A repository downloaded into both stores is a conflict: the one in dst is
+// kept and the conflict is reported in the returned error, after merging the
+// rest of the data. Organizations, their audit logs and projects, users and
+// mentions are the same regardless of the repository they were found in, so
+// the ones already in dst are kept.
+// The merged data is moved, not copied, so src should not be used afterwards
+func MergeMem(dst, src *Mem) error {
+	if dst == src {
+		return nil
+	}
+
+	// src is locked first and released before locking dst, so concurrent
+	// merges in opposite directions do not deadlock
+	src.Lock()
+	orgs := make(map[string]*graphql.OrganizationFields, len(src.Organizations))
+	for login, o := range src.Organizations {
+		orgs[login] = o
+	}
+	auditLogs := make(map[string][]graphql.AuditLogEntry, len(src.AuditLogs))
+	for login, entries := range src.AuditLogs {
+		auditLogs[login] = entries
+	}
+	orgProjects := make(map[string][]graphql.OrgProject, len(src.OrgProjects))
+	for login, projects := range src.OrgProjects {
+		orgProjects[login] = projects
+	}
+	users := make(map[string]*graphql.UserExtended, len(src.Users))
+	for login, u := range src.Users {
+		users[login] = u
+	}
+	mentions := make(map[string][]string, len(src.Mentions))
+	for id, logins := range src.Mentions {
+		mentions[id] = logins
+	}
+	references := append([]ClosingReference(nil), src.ClosingReferences...)
+	repos := make(map[string]map[string]*Repo, len(src.Repos))
+	for owner, byName := range src.Repos {
+		repos[owner] = make(map[string]*Repo, len(byName))
+		for name, r := range byName {
+			repos[owner][name] = r
+		}
+	}
+	src.Unlock()
+
+	dst.Lock()
+	defer dst.Unlock()
+
+	if dst.Organizations == nil {
+		dst.Organizations = make(map[string]*graphql.OrganizationFields)
+	}
+	for login, o := range orgs {
+		if _, ok := dst.Organizations[login]; !ok {
+			dst.Organizations[login] = o
+		}
+	}
+
+	if dst.AuditLogs == nil {
+		dst.AuditLogs = make(map[string][]graphql.AuditLogEntry)
+	}
+	for login, entries := range auditLogs {
+		if _, ok := dst.AuditLogs[login]; !ok {
+			dst.AuditLogs[login] = entries
+		}
+	}
+
+	if dst.OrgProjects == nil {
+		dst.OrgProjects = make(map[string][]graphql.OrgProject)
+	}
+	for login, projects := range orgProjects {
+		if _, ok := dst.OrgProjects[login]; !ok {
+			dst.OrgProjects[login] = projects
+		}
+	}
+
+	if dst.Users == nil {
+		dst.Users = make(map[string]*graphql.UserExtended)
+	}
+	for login, u := range users {
+		if _, ok := dst.Users[login]; !ok {
+			dst.Users[login] = u
+		}
+	}
+
+	if dst.Mentions == nil {
+		dst.Mentions = make(map[string][]string)
+	}
+	for id, logins := range mentions {
+		if _, ok := dst.Mentions[id]; !ok {
+			dst.Mentions[id] = logins
+		}
+	}
+
+	known := make(map[ClosingReference]bool, len(dst.ClosingReferences))
+	for _, r := range dst.ClosingReferences {
+		known[r] = true
+	}
+	for _, r := range references {
+		if !known[r] {
+			known[r] = true
+			dst.ClosingReferences = append(dst.ClosingReferences, r)
+		}
+	}
+
+	if dst.Repos == nil {
+		dst.Repos = make(map[string]map[string]*Repo)
+	}
+
+	var conflicts []string
+	for owner, byName := range repos {
+		if dst.Repos[owner] == nil {
+			dst.Repos[owner] = make(map[string]*Repo)
+		}
+
+		for name, r := range byName {
+			if _, ok := dst.Repos[owner][name]; ok {
+				conflicts = append(conflicts, owner+"/"+name)
+				continue
+			}
+
+			dst.Repos[owner][name] = r
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("repositories found in both stores: %v", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
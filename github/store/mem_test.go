@@ -0,0 +1,134 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func saveRepo(t *testing.T, m *Mem, owner, name string, prs ...int) {
+	repository := &graphql.RepositoryFields{Name: name}
+	repository.Owner.Login = owner
+	require.NoError(t, m.SaveRepository(repository, nil))
+
+	for _, number := range prs {
+		pr := &graphql.PullRequest{}
+		pr.Number = number
+		require.NoError(t, m.SavePullRequest(owner, name, pr, nil, nil))
+	}
+}
+
+func TestMergeMem(t *testing.T) {
+	require := require.New(t)
+
+	dst := NewMem()
+	saveRepo(t, dst, "src-d", "go-git", 1, 2)
+	require.NoError(dst.SaveUser(&graphql.UserExtended{Login: "alice", Name: "Alice"}))
+
+	src := NewMem()
+	saveRepo(t, src, "src-d", "gitbase", 3)
+	saveRepo(t, src, "git-fixtures", "basic", 4)
+	require.NoError(src.SaveUser(&graphql.UserExtended{Login: "alice", Name: "Alice B."}))
+	require.NoError(src.SaveUser(&graphql.UserExtended{Login: "bob"}))
+
+	// disjoint repositories
+	require.NoError(MergeMem(dst, src))
+	require.Len(dst.Repos["src-d"], 2)
+	require.Len(dst.Repos["git-fixtures"], 1)
+	require.Contains(dst.Repos["src-d"]["gitbase"].PRs, 3)
+	require.Len(dst.Users, 2)
+	require.Equal("Alice", dst.Users["alice"].Name)
+
+	// overlapping repositories
+	other := NewMem()
+	saveRepo(t, other, "src-d", "go-git", 5)
+	saveRepo(t, other, "src-d", "enry", 6)
+
+	err := MergeMem(dst, other)
+	require.EqualError(err, "repositories found in both stores: src-d/go-git")
+	require.Len(dst.Repos["src-d"], 3)
+	require.Contains(dst.Repos["src-d"], "enry")
+	require.Len(dst.Repos["src-d"]["go-git"].PRs, 2)
+	require.NotContains(dst.Repos["src-d"]["go-git"].PRs, 5)
+}
+
+func TestMemQuerier(t *testing.T) {
+	require := require.New(t)
+
+	m := NewMem()
+	saveRepo(t, m, "src-d", "go-git", 3, 1, 2)
+	require.NoError(m.SavePullRequestComment("src-d", "go-git", 1, &graphql.IssueComment{Body: "lgtm"}))
+
+	issue := &graphql.Issue{}
+	issue.Number = 4
+	require.NoError(m.SaveIssue("src-d", "go-git", issue, []string{"alice"}, nil))
+
+	_, ok := m.GetRepo("src-d", "gitbase")
+	require.False(ok)
+	require.Empty(m.ListPRs("src-d", "gitbase"))
+	_, ok = m.GetPR("src-d", "gitbase", 1)
+	require.False(ok)
+
+	repo, ok := m.GetRepo("src-d", "go-git")
+	require.True(ok)
+	require.Equal("go-git", repo.Name)
+	require.Len(repo.PRs, 3)
+	require.Len(repo.Issues, 1)
+
+	var numbers []int
+	for _, pr := range m.ListPRs("src-d", "go-git") {
+		numbers = append(numbers, pr.Number)
+	}
+	require.Equal([]int{1, 2, 3}, numbers)
+
+	pr, ok := m.GetPR("src-d", "go-git", 1)
+	require.True(ok)
+	require.Len(pr.Comments, 1)
+	_, ok = m.GetPR("src-d", "go-git", 4)
+	require.False(ok)
+
+	i, ok := m.GetIssue("src-d", "go-git", 4)
+	require.True(ok)
+	require.Equal([]string{"alice"}, i.Assignees)
+	require.Len(m.ListIssues("src-d", "go-git"), 1)
+
+	// the returned values are copies
+	pr.Comments[0].Body = "changed"
+	repo.PRs[1].Comments = nil
+	delete(repo.PRs, 2)
+	i.Assignees[0] = "bob"
+
+	pr, _ = m.GetPR("src-d", "go-git", 1)
+	require.Equal("lgtm", pr.Comments[0].Body)
+	require.Len(m.ListPRs("src-d", "go-git"), 3)
+	i, _ = m.GetIssue("src-d", "go-git", 4)
+	require.Equal([]string{"alice"}, i.Assignees)
+}
+
+// BenchmarkMemSavePullRequestComments compares saving the comments of a PR
+// when the total number of comments is known in advance and when it is not
+func BenchmarkMemSavePullRequestComments(b *testing.B) {
+	const comments = 100
+
+	for _, known := range []bool{false, true} {
+		b.Run(fmt.Sprintf("totalCount=%v", known), func(b *testing.B) {
+			pr := &graphql.PullRequest{}
+			if known {
+				pr.Comments.TotalCount = comments
+			}
+			comment := &graphql.IssueComment{Body: "LGTM"}
+
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				m := NewMem()
+				m.SavePullRequest("src-d", "go-git", pr, nil, nil)
+				for i := 0; i < comments; i++ {
+					m.SavePullRequestComment("src-d", "go-git", 0, comment)
+				}
+			}
+		})
+	}
+}
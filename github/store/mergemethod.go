@@ -0,0 +1,42 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// The methods returned by MergeMethod
+const (
+	MergeMethodMerge   = "MERGE"
+	MergeMethodSquash  = "SQUASH"
+	MergeMethodRebase  = "REBASE"
+	MergeMethodUnknown = "UNKNOWN"
+)
+
+// MergeMethod guesses the method used to merge the PR, the GitHub API does not
+// report it. It returns an empty string if the PR is not merged, otherwise:
+//   - MERGE if the merge commit has two parents
+//   - UNKNOWN if there is no merge commit, or it is the PR head commit, e.g.
+//     the commits were pushed to the base branch instead of merging the PR
+//   - SQUASH if the merge commit headline ends with the PR number, "(#N)", as
+//     the default message of the squash merges does
+//   - REBASE otherwise
+func MergeMethod(pr *graphql.PullRequest) string {
+	if !pr.Merged {
+		return ""
+	}
+
+	commit := pr.MergeCommit
+	switch {
+	case commit.Parents.TotalCount > 1:
+		return MergeMethodMerge
+	case commit.Oid == "" || commit.Oid == pr.HeadRefOid:
+		return MergeMethodUnknown
+	case strings.HasSuffix(strings.TrimSpace(commit.MessageHeadline), fmt.Sprintf("(#%v)", pr.Number)):
+		return MergeMethodSquash
+	}
+
+	return MergeMethodRebase
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeMethod(t *testing.T) {
+	pr := func(merged bool, oid, headline string, parents int) *graphql.PullRequest {
+		var pr graphql.PullRequest
+		pr.Number = 12
+		pr.Merged = merged
+		pr.HeadRefOid = "head"
+		pr.MergeCommit.Oid = oid
+		pr.MergeCommit.MessageHeadline = headline
+		pr.MergeCommit.Parents.TotalCount = parents
+		return &pr
+	}
+
+	tests := []struct {
+		name     string
+		pr       *graphql.PullRequest
+		expected string
+	}{
+		{"open", pr(false, "", "", 0), ""},
+		{"merge commit", pr(true, "m", "Merge pull request #12 from alice/fix", 2), MergeMethodMerge},
+		{"squash", pr(true, "m", "Fix the parser (#12)", 1), MergeMethodSquash},
+		{"squash of another PR number", pr(true, "m", "Fix the parser (#1)", 1), MergeMethodRebase},
+		{"rebase", pr(true, "m", "Fix the parser", 1), MergeMethodRebase},
+		{"pushed to the base branch", pr(true, "head", "Fix the parser", 1), MergeMethodUnknown},
+		{"without merge commit", pr(true, "", "", 0), MergeMethodUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, MergeMethod(test.pr))
+		})
+	}
+}
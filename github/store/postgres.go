@@ -0,0 +1,68 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// Postgres is a DB store that leaves the whole cleanup to PostgreSQL. Its
+// saves are the upserts of DB, ON CONFLICT on the sum256 of each row, adding
+// the version once, so saving the same version again is idempotent
+type Postgres struct {
+	*DB
+}
+
+// NewPostgres returns a Postgres store that saves in the given database
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{DB: &DB{DB: db}}
+}
+
+// Cleanup deletes all the rows that do not belong to currentVersion, and
+// sets currentVersion as the only version of the remaining ones. Unlike
+// DB.Cleanup, each table is cleaned with a single DELETE and UPDATE, all of
+// them in one transaction, so an interrupted cleanup changes nothing, but the
+// tables are locked until it is done
+func (s *Postgres) Cleanup(currentVersion int) error {
+	tx, err := s.DB.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed in cleanup method, begin: %v", err)
+	}
+
+	for _, table := range tables {
+		logger := log.With(log.Fields{"table": table, "version": currentVersion})
+
+		deleted, err := rowsAffected(tx.Exec(fmt.Sprintf(
+			`DELETE FROM %[1]s WHERE %[2]v <> ALL(versions)`, table, currentVersion)))
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed in cleanup method, delete: %v", err)
+		}
+
+		updated, err := rowsAffected(tx.Exec(fmt.Sprintf(
+			`UPDATE %[1]s SET versions = array[%[2]v] WHERE versions <> array[%[2]v]`, table, currentVersion)))
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed in cleanup method, update: %v", err)
+		}
+
+		logger.With(log.Fields{"deleted": deleted, "updated": updated}).Infof("cleanup finished")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed in cleanup method, commit: %v", err)
+	}
+
+	return nil
+}
+
+// rowsAffected returns the number of rows affected by a statement
+func rowsAffected(res sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,19 @@
+package store
+
+import "time"
+
+// ProjectStatus is the status of a PR in a project, from the Status field of
+// its project item, as it was when the PR was last updated
+type ProjectStatus struct {
+	// NodeID is the node id of the project item
+	NodeID        string
+	ProjectNodeID string
+	ProjectNumber int
+	ProjectTitle  string
+	// Status is empty if the item has no status, StatusUpdatedAt is zero then
+	Status          string
+	StatusUpdatedAt time.Time
+	// PullRequestUpdatedAt is the updated_at of the PR, the time of the
+	// snapshot
+	PullRequestUpdatedAt string
+}
@@ -0,0 +1,10 @@
+package store
+
+// Readme is the README file at the root of the default branch of a
+// repository, e.g. README.md or README.rst
+type Readme struct {
+	Filename string
+	// Body is empty if the file is binary
+	Body     string
+	ByteSize int
+}
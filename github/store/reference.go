@@ -0,0 +1,13 @@
+package store
+
+// ClosingReference links a PR to an issue that it closes when it is merged. A
+// PR can close many issues, and an issue can be closed by many PRs. The PR and
+// the issue can belong to different repositories
+type ClosingReference struct {
+	IssueRepositoryOwner       string
+	IssueRepositoryName        string
+	IssueNumber                int
+	PullRequestRepositoryOwner string
+	PullRequestRepositoryName  string
+	PullRequestNumber          int
+}
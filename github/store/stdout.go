@@ -2,54 +2,190 @@ package store
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/src-d/metadata-retrieval/github/graphql"
 )
 
-type Stdout struct{}
+// Stdout prints a line for each saved entity. It does not keep track of
+// versions, Begin, Commit and Rollback are noops
+type Stdout struct {
+	// Out is where the lines are printed, os.Stdout if nil
+	Out io.Writer
+
+	// SortKeys prefixes each line with a key made of the entity type and its
+	// ids, e.g. "issue_comment:src-d/gitbase/0000000012/0000001234". Sorting
+	// the lines gives the same output regardless of the download order, so it
+	// can be compared against a golden file
+	SortKeys bool
+}
+
+// sortKey returns the key for an entity. Numbers are zero padded so the keys
+// sort in numeric order
+func sortKey(entity string, ids ...interface{}) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		switch v := id.(type) {
+		case int:
+			parts[i] = fmt.Sprintf("%010d", v)
+		case time.Time:
+			parts[i] = v.UTC().Format("2006-01-02T15:04:05.000000000Z")
+		default:
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+
+	return entity + ":" + strings.Join(parts, "/")
+}
+
+func (s *Stdout) printf(key string, format string, a ...interface{}) {
+	out := s.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	if s.SortKeys {
+		// the indentation of nested entities is meaningless once sorted
+		format = key + " " + strings.TrimLeft(format, " ")
+	}
+
+	fmt.Fprintf(out, format, a...)
+}
 
 func (s *Stdout) SaveOrganization(organization *graphql.Organization) error {
-	fmt.Printf("organization data fetched for %s\n", organization.Login)
+	s.printf(sortKey("organization", organization.Login), "organization data fetched for %s\n", organization.Login)
 	return nil
 }
 
 func (s *Stdout) SaveUser(user *graphql.UserExtended) error {
-	fmt.Printf("user data fetched for %s\n", user.Login)
+	s.printf(sortKey("user", user.Login), "user data fetched for %s\n", user.Login)
 	return nil
 }
 
 func (s *Stdout) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
-	fmt.Printf("repository data fetched for %s/%s\n", repository.Owner.Login, repository.Name)
+	s.printf(sortKey("repository", repository.Owner.Login, repository.Name), "repository data fetched for %s/%s\n", repository.Owner.Login, repository.Name)
+	return nil
+}
+
+func (s *Stdout) SaveTopic(name string) error {
+	s.printf(sortKey("topic", name), "topic data fetched for %s\n", name)
+	return nil
+}
+
+func (s *Stdout) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	s.printf(sortKey("repository_topic", repositoryOwner, repositoryName, topic), "  repository topic data fetched for %s/%s: %s\n", repositoryOwner, repositoryName, topic)
 	return nil
 }
 
 func (s *Stdout) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
-	fmt.Printf("issue data fetched for #%v %s\n", issue.Number, issue.Title)
+	s.printf(sortKey("issue", repositoryOwner, repositoryName, issue.Number), "issue data fetched for #%v %s\n", issue.Number, issue.Title)
 	return nil
 }
 
 func (s *Stdout) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
-	fmt.Printf("  issue comment data fetched by %s at %v: %q\n", comment.Author.Login, comment.CreatedAt, trim(comment.Body))
+	s.printf(sortKey("issue_comment", repositoryOwner, repositoryName, issueNumber, comment.DatabaseId), "  issue comment data fetched by %s at %v: %q\n", comment.Author.LoginOrGhost(), comment.CreatedAt, trim(comment.Body))
 	return nil
 }
 
 func (s *Stdout) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
-	fmt.Printf("PR data fetched for #%v %s\n", pr.Number, pr.Title)
+	s.printf(sortKey("pull_request", repositoryOwner, repositoryName, pr.Number), "PR data fetched for #%v %s%s\n", pr.Number, pr.Title, pullRequestOutcome(pr))
 	return nil
 }
 
 func (s *Stdout) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
-	fmt.Printf("  pr comment data fetched by %s at %v: %q\n", comment.Author.Login, comment.CreatedAt, trim(comment.Body))
+	s.printf(sortKey("pull_request_comment", repositoryOwner, repositoryName, pullRequestNumber, comment.DatabaseId), "  pr comment data fetched by %s at %v: %q\n", comment.Author.LoginOrGhost(), comment.CreatedAt, trim(comment.Body))
 	return nil
 }
 
 func (s *Stdout) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
-	fmt.Printf("  PR Review data fetched by %s at %v: %q\n", review.Author.Login, review.SubmittedAt, trim(review.Body))
+	s.printf(sortKey("pull_request_review", repositoryOwner, repositoryName, pullRequestNumber, review.DatabaseId), "  PR Review data fetched by %s at %v: %q\n", review.Author.LoginOrGhost(), submittedAt(review.SubmittedAt), trim(review.Body))
 	return nil
 }
 
 func (s *Stdout) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
-	fmt.Printf("    PR review comment data fetched by %s at %v: %q\n", comment.Author.Login, comment.CreatedAt, trim(comment.Body))
+	s.printf(sortKey("pull_request_review_comment", repositoryOwner, repositoryName, pullRequestNumber, pullRequestReviewId, comment.DatabaseId), "    PR review comment data fetched by %s at %v: %q\n", comment.Author.LoginOrGhost(), comment.CreatedAt, trim(comment.Body))
+	return nil
+}
+
+func (s *Stdout) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	s.printf(sortKey("pull_request_review_transition", repositoryOwner, repositoryName, pullRequestNumber, transition.UserLogin, transition.Sequence), "  PR Review state of %s changed from %q to %q at %v\n", transition.UserLogin, transition.FromState, transition.ToState, submittedAt(transition.SubmittedAt))
+	return nil
+}
+
+func (s *Stdout) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	c := commit.Commit
+	s.printf(sortKey("pull_request_commit", repositoryOwner, repositoryName, pullRequestNumber, c.Oid), "  PR commit %s authored by %s at %v, committed by %s at %v\n", c.Oid, c.Author.Name, c.AuthoredDate, c.Committer.Name, c.CommittedDate)
+	return nil
+}
+
+func (s *Stdout) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	fields := event.Fields()
+	s.printf(sortKey("assignment_event", repositoryOwner, repositoryName, number, fields.CreatedAt, fields.Id), "  %s: %s by %s at %v\n", event.Typename, fields.AssigneeLogin(), fields.Actor.Login, fields.CreatedAt)
+	return nil
+}
+
+func (s *Stdout) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	fields := event.Fields()
+	s.printf(sortKey("issue_event", repositoryOwner, repositoryName, issueNumber, fields.CreatedAt, fields.Id), "  Issue event %s by %s at %v\n", event.Typename, fields.Actor.Login, fields.CreatedAt)
+	return nil
+}
+
+func (s *Stdout) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	fields := event.Fields()
+	s.printf(sortKey("pull_request_event", repositoryOwner, repositoryName, pullRequestNumber, fields.CreatedAt, fields.Id), "  PR event %s by %s at %v\n", event.Typename, fields.Actor.Login, fields.CreatedAt)
+	return nil
+}
+
+func (s *Stdout) SaveMention(subjectID, mentionedLogin string) error {
+	s.printf(sortKey("mention", subjectID, mentionedLogin), "  %s mentioned in %s\n", mentionedLogin, subjectID)
+	return nil
+}
+
+func (s *Stdout) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	s.printf(sortKey("reaction", subjectID, group.Content), "  %v %s reactions to %s\n", group.Reactors.TotalCount, group.Content, subjectID)
+	return nil
+}
+
+func (s *Stdout) SaveClosingReference(reference *ClosingReference) error {
+	s.printf(sortKey("closing_reference", reference.PullRequestRepositoryOwner, reference.PullRequestRepositoryName, reference.PullRequestNumber, reference.IssueRepositoryOwner, reference.IssueRepositoryName, reference.IssueNumber), "  PR %s/%s #%d closes issue %s/%s #%d\n", reference.PullRequestRepositoryOwner, reference.PullRequestRepositoryName, reference.PullRequestNumber, reference.IssueRepositoryOwner, reference.IssueRepositoryName, reference.IssueNumber)
+	return nil
+}
+
+func (s *Stdout) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	s.printf(sortKey("lock", repositoryOwner, repositoryName, number), "  locked by %s at %v, reason %q\n", lock.LockedByLogin, lock.LockedAt, lock.Reason)
+	return nil
+}
+
+func (s *Stdout) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	s.printf(sortKey("project_status", repositoryOwner, repositoryName, pullRequestNumber, status.NodeID), "  project %q status %q\n", status.ProjectTitle, status.Status)
+	return nil
+}
+
+func (s *Stdout) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	s.printf(sortKey("repository_ruleset", repositoryOwner, repositoryName, ruleset.DatabaseId), "  ruleset data fetched for %s/%s: %s %v\n", repositoryOwner, repositoryName, ruleset.Name, ruleset.RuleTypes())
+	return nil
+}
+
+func (s *Stdout) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	s.printf(sortKey("template", repositoryOwner, repositoryName, template.Kind, template.Filename), "  %s template fetched for %s/%s: %s\n", template.Kind, repositoryOwner, repositoryName, template.Filename)
+	return nil
+}
+
+func (s *Stdout) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	s.printf(sortKey("readme", repositoryOwner, repositoryName), "  readme fetched for %s/%s: %s (%d bytes)\n", repositoryOwner, repositoryName, readme.Filename, readme.ByteSize)
+	return nil
+}
+
+func (s *Stdout) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	s.printf(sortKey("audit_log_entry", organization, entry.AuditEntry.CreatedAt, entry.Node.Id), "  audit log entry fetched for %s: %s by %s at %v\n", organization, entry.AuditEntry.Action, entry.AuditEntry.ActorLogin, entry.AuditEntry.CreatedAt)
+	return nil
+}
+
+func (s *Stdout) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	s.printf(sortKey("org_project", organization, project.Number), "  project fetched for %s: #%v %s (%d items)\n", organization, project.Number, project.Title, len(project.Items.Nodes))
 	return nil
 }
 
@@ -83,3 +219,27 @@ func trim(s string) string {
 
 	return s
 }
+
+// pullRequestOutcome describes who merged the PR, when, and its merge commit,
+// or when it was closed without merge. It is empty for open PRs
+func pullRequestOutcome(pr *graphql.PullRequest) string {
+	if pr.Merged {
+		return fmt.Sprintf(", merged by %s at %v as %s", pr.MergedBy.Login, pr.MergedAt, pr.MergeCommit.Oid)
+	}
+
+	if !pr.ClosedAt.IsZero() {
+		return fmt.Sprintf(", closed without merge at %v", pr.ClosedAt)
+	}
+
+	return ""
+}
+
+// submittedAt returns the time a review was submitted, or "pending" for the
+// reviews not submitted yet
+func submittedAt(t *time.Time) interface{} {
+	if t == nil {
+		return "pending"
+	}
+
+	return *t
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdoutSortKeys(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	s := &Stdout{Out: &buf, SortKeys: true}
+
+	comment := &graphql.IssueComment{DatabaseId: 1234}
+	comment.Author.Login = "alice"
+	comment.CreatedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	comment.Body = "lgtm"
+
+	require.NoError(s.SaveIssueComment("src-d", "gitbase", 12, comment))
+	require.Equal(
+		"issue_comment:src-d/gitbase/0000000012/0000001234 issue comment data fetched by alice at 2019-10-01 10:00:00 +0000 UTC: \"lgtm\"\n",
+		buf.String())
+}
+
+func TestStdoutPullRequestOutcome(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	s := &Stdout{Out: &buf}
+
+	merged := &graphql.PullRequest{}
+	merged.Number = 1
+	merged.Title = "fix"
+	merged.Merged = true
+	merged.MergedAt = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	merged.ClosedAt = merged.MergedAt
+	merged.MergedBy.Login = "alice"
+	merged.MergeCommit.Oid = "cafe"
+
+	closed := &graphql.PullRequest{}
+	closed.Number = 2
+	closed.Title = "wontfix"
+	closed.ClosedAt = time.Date(2019, 10, 2, 10, 0, 0, 0, time.UTC)
+
+	open := &graphql.PullRequest{}
+	open.Number = 3
+	open.Title = "wip"
+
+	require.NoError(s.SavePullRequest("src-d", "gitbase", merged, nil, nil))
+	require.NoError(s.SavePullRequest("src-d", "gitbase", closed, nil, nil))
+	require.NoError(s.SavePullRequest("src-d", "gitbase", open, nil, nil))
+	require.Equal(
+		"PR data fetched for #1 fix, merged by alice at 2019-10-01 10:00:00 +0000 UTC as cafe\n"+
+			"PR data fetched for #2 wontfix, closed without merge at 2019-10-02 10:00:00 +0000 UTC\n"+
+			"PR data fetched for #3 wip\n",
+		buf.String())
+}
+
+func TestStdoutSortKeysStable(t *testing.T) {
+	require := require.New(t)
+
+	issue := func(number int) *graphql.Issue {
+		i := &graphql.Issue{}
+		i.Number = number
+		i.Title = "issue"
+		return i
+	}
+	comment := func(id int) *graphql.IssueComment {
+		return &graphql.IssueComment{DatabaseId: id}
+	}
+
+	// the same entities saved in two different orders, e.g. #9 is listed
+	// after #10 in one of the downloads
+	saves := []func(s *Stdout) error{
+		func(s *Stdout) error { return s.SaveIssue("src-d", "gitbase", issue(9), nil, nil) },
+		func(s *Stdout) error { return s.SaveIssueComment("src-d", "gitbase", 9, comment(2)) },
+		func(s *Stdout) error { return s.SaveIssueComment("src-d", "gitbase", 9, comment(10)) },
+		func(s *Stdout) error { return s.SaveIssue("src-d", "gitbase", issue(10), nil, nil) },
+		func(s *Stdout) error { return s.SaveTopic("go") },
+	}
+
+	output := func(order []int) []string {
+		var buf bytes.Buffer
+		s := &Stdout{Out: &buf, SortKeys: true}
+		for _, i := range order {
+			require.NoError(saves[i](s))
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		sort.Strings(lines)
+		return lines
+	}
+
+	sorted := output([]int{0, 1, 2, 3, 4})
+	require.Equal(sorted, output([]int{4, 3, 2, 0, 1}))
+
+	var keys []string
+	for _, line := range sorted {
+		keys = append(keys, strings.SplitN(line, " ", 2)[0])
+	}
+	require.Equal([]string{
+		"issue:src-d/gitbase/0000000009",
+		"issue:src-d/gitbase/0000000010",
+		"issue_comment:src-d/gitbase/0000000009/0000000002",
+		"issue_comment:src-d/gitbase/0000000009/0000000010",
+		"topic:go",
+	}, keys)
+}
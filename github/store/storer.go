@@ -0,0 +1,53 @@
+package store
+
+import "github.com/src-d/metadata-retrieval/github/graphql"
+
+// Storer saves the downloaded metadata. It is implemented by all the stores
+// of this package. The saves of a download happen between Begin and Commit,
+// or Rollback, and belong to the version set with Version
+type Storer interface {
+	SaveOrganization(organization *graphql.Organization) error
+	SaveUser(user *graphql.UserExtended) error
+	SaveRepository(repository *graphql.RepositoryFields, topics []string) error
+	SaveTopic(name string) error
+	SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error
+	SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error
+	SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error
+	SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error
+	SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error
+	SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error
+	SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error
+	SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error
+	SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error
+	SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error
+	SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error
+	SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error
+	SaveMention(subjectID, mentionedLogin string) error
+	SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error
+	SaveClosingReference(reference *ClosingReference) error
+	SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error
+	SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error
+	SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error
+	SaveTemplate(repositoryOwner, repositoryName string, template *Template) error
+	SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error
+	SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error
+	SaveOrgProject(organization string, project *graphql.OrgProject) error
+
+	Begin() error
+	Commit() error
+	Rollback() error
+	Version(v int)
+	SetActiveVersion(v int) error
+	Cleanup(currentVersion int) error
+}
+
+var (
+	_ Storer = (*CSV)(nil)
+	_ Storer = (*DB)(nil)
+	_ Storer = Discard{}
+	_ Storer = (*EventLog)(nil)
+	_ Storer = (*Mem)(nil)
+	_ Storer = (*Postgres)(nil)
+	_ Storer = (*Stdout)(nil)
+	_ Storer = (*Tee)(nil)
+)
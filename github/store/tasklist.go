@@ -0,0 +1,58 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+)
+
+// taskRegexp matches a task list item, a bullet or ordered list item starting
+// with a [ ] or [x] checkbox. The first group is the checkbox mark
+var taskRegexp = regexp.MustCompile(`^(?:[-*+]|[0-9]{1,9}[.)])[ \t]+\[([ xX])\](?:[ \t]|$)`)
+
+// ParseTaskList returns the number of checked task list items in a Markdown
+// body, and the total number of them. Items of nested lists are counted too.
+// Items inside fenced code blocks are not tasks
+func ParseTaskList(body string) (done, total int) {
+	var fence string
+	for _, line := range strings.Split(body, "\n") {
+		// fences can be indented, e.g. inside a list item
+		trimmed := strings.TrimLeft(line, " \t")
+		if fence != "" {
+			// the closing fence is at least as long as the opening one
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]+" \t") == "" {
+				fence = ""
+			}
+			continue
+		}
+
+		if f := openingFence(trimmed); f != "" {
+			fence = f
+			continue
+		}
+
+		m := taskRegexp.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+
+		total++
+		if m[1] != " " {
+			done++
+		}
+	}
+
+	return done, total
+}
+
+// openingFence returns the fence (``` or ~~~, or longer) that opens a code
+// block at the start of line, or an empty string
+func openingFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+
+	return ""
+}
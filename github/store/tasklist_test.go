@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskList(t *testing.T) {
+	body := "Release checklist:\n" +
+		"\n" +
+		"- [x] update the changelog\n" +
+		"- [ ] tag the release\n" +
+		"  * [X] nested and checked\n" +
+		"    1. [ ] nested ordered item\n" +
+		"+ [x]\n" +
+		"\n" +
+		"```markdown\n" +
+		"- [x] inside a code block\n" +
+		"- [ ] inside a code block\n" +
+		"```\n" +
+		"  ~~~~\n" +
+		"  - [ ] inside an indented tilde fence\n" +
+		"  ~~~\n" +
+		"  - [x] still in the block\n" +
+		"  ~~~~\n" +
+		"- [y] not a checkbox\n" +
+		"- [x]not a task, no space\n" +
+		"[x] not a list item\n" +
+		"- [ ] last"
+
+	done, total := ParseTaskList(body)
+	require.Equal(t, 3, done)
+	require.Equal(t, 6, total)
+
+	done, total = ParseTaskList("")
+	require.Equal(t, 0, done)
+	require.Equal(t, 0, total)
+
+	done, total = ParseTaskList("```\n- [x] unclosed fence\n")
+	require.Equal(t, 0, done)
+	require.Equal(t, 0, total)
+}
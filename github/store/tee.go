@@ -0,0 +1,372 @@
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+)
+
+// defaultTeeBuffer is the number of calls a Tee queues for its secondary store
+// when NewTee is given a buffer of zero
+const defaultTeeBuffer = 1000
+
+// Tee saves the metadata in a primary store, and mirrors it to a secondary
+// one in the background. The calls to the primary store are synchronous, and
+// their errors are returned as usual. The calls to the secondary store are
+// queued, and made in the same order by a goroutine, so a slow secondary, e.g.
+// a remote one, does not slow down the download until the queue is full.
+//
+// Commit, Rollback, SetActiveVersion and Cleanup wait until the secondary has
+// caught up, and return the errors of the secondary since the previous wait.
+// The primary is authoritative: a failure of the secondary does not undo the
+// changes in the primary. Close must be called to stop the goroutine
+type Tee struct {
+	Primary   Storer
+	Secondary Storer
+
+	calls   chan func(Storer) error
+	pending sync.WaitGroup
+	done    chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewTee returns a Tee that mirrors the primary store to the secondary one,
+// queuing up to buffer calls. A buffer of zero means defaultTeeBuffer
+func NewTee(primary, secondary Storer, buffer int) *Tee {
+	if buffer <= 0 {
+		buffer = defaultTeeBuffer
+	}
+
+	t := &Tee{
+		Primary:   primary,
+		Secondary: secondary,
+		calls:     make(chan func(Storer) error, buffer),
+		done:      make(chan struct{}),
+	}
+
+	go t.mirror()
+	return t
+}
+
+// mirror makes the queued calls to the secondary store, until Close
+func (t *Tee) mirror() {
+	defer close(t.done)
+
+	for call := range t.calls {
+		err := call(t.Secondary)
+		if err != nil {
+			t.mu.Lock()
+			t.errs = append(t.errs, err)
+			t.mu.Unlock()
+		}
+
+		t.pending.Done()
+	}
+}
+
+// enqueue queues a call to the secondary store. It blocks while the queue is
+// full
+func (t *Tee) enqueue(call func(Storer) error) {
+	t.pending.Add(1)
+	t.calls <- call
+}
+
+// save makes the call to the primary store and, if it succeeds, queues it for
+// the secondary one. The call must not use the caller's values but a clone of
+// them, the downloader can reuse them once the save returns
+func (t *Tee) save(call func(Storer) error) error {
+	err := call(t.Primary)
+	if err != nil {
+		return err
+	}
+
+	t.enqueue(call)
+	return nil
+}
+
+// Flush waits until the secondary store has made all the queued calls, and
+// returns their errors since the previous Flush
+func (t *Tee) Flush() error {
+	t.pending.Wait()
+
+	t.mu.Lock()
+	errs := t.errs
+	t.errs = nil
+	t.mu.Unlock()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("secondary store: %v", errs[0])
+	}
+
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Errorf("secondary store: %v errors: %v", len(errs), strings.Join(msgs, "; "))
+}
+
+// Close flushes the secondary store and stops mirroring to it. The Tee must
+// not be used after Close
+func (t *Tee) Close() error {
+	err := t.Flush()
+	close(t.calls)
+	<-t.done
+	return err
+}
+
+// sync makes the call to the primary store, and then to the secondary one
+// once it has caught up. The error of the primary is returned first
+func (t *Tee) sync(call func(Storer) error) error {
+	err := call(t.Primary)
+
+	t.enqueue(call)
+	flushErr := t.Flush()
+	if err != nil {
+		return err
+	}
+
+	return flushErr
+}
+
+func (t *Tee) SaveOrganization(organization *graphql.Organization) error {
+	o := clone(organization).(*graphql.Organization)
+	return t.save(func(s Storer) error { return s.SaveOrganization(o) })
+}
+
+func (t *Tee) SaveUser(user *graphql.UserExtended) error {
+	u := clone(user).(*graphql.UserExtended)
+	return t.save(func(s Storer) error { return s.SaveUser(u) })
+}
+
+func (t *Tee) SaveRepository(repository *graphql.RepositoryFields, topics []string) error {
+	r := clone(repository).(*graphql.RepositoryFields)
+	topics = append([]string(nil), topics...)
+	return t.save(func(s Storer) error { return s.SaveRepository(r, topics) })
+}
+
+func (t *Tee) SaveTopic(name string) error {
+	return t.save(func(s Storer) error { return s.SaveTopic(name) })
+}
+
+func (t *Tee) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	return t.save(func(s Storer) error { return s.SaveRepositoryTopic(repositoryOwner, repositoryName, topic) })
+}
+
+func (t *Tee) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	i := clone(issue).(*graphql.Issue)
+	assignees = append([]string(nil), assignees...)
+	labels = append([]string(nil), labels...)
+	return t.save(func(s Storer) error { return s.SaveIssue(repositoryOwner, repositoryName, i, assignees, labels) })
+}
+
+func (t *Tee) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	c := clone(comment).(*graphql.IssueComment)
+	return t.save(func(s Storer) error { return s.SaveIssueComment(repositoryOwner, repositoryName, issueNumber, c) })
+}
+
+func (t *Tee) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	p := clone(pr).(*graphql.PullRequest)
+	assignees = append([]string(nil), assignees...)
+	labels = append([]string(nil), labels...)
+	return t.save(func(s Storer) error { return s.SavePullRequest(repositoryOwner, repositoryName, p, assignees, labels) })
+}
+
+func (t *Tee) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	c := clone(comment).(*graphql.IssueComment)
+	return t.save(func(s Storer) error {
+		return s.SavePullRequestComment(repositoryOwner, repositoryName, pullRequestNumber, c)
+	})
+}
+
+func (t *Tee) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	r := clone(review).(*graphql.PullRequestReview)
+	return t.save(func(s Storer) error {
+		return s.SavePullRequestReview(repositoryOwner, repositoryName, pullRequestNumber, r)
+	})
+}
+
+func (t *Tee) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	c := clone(comment).(*graphql.PullRequestReviewComment)
+	return t.save(func(s Storer) error {
+		return s.SavePullRequestReviewComment(repositoryOwner, repositoryName, pullRequestNumber, pullRequestReviewId, c)
+	})
+}
+
+func (t *Tee) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *ReviewStateTransition) error {
+	tr := clone(transition).(*ReviewStateTransition)
+	return t.save(func(s Storer) error {
+		return s.SaveReviewStateTransition(repositoryOwner, repositoryName, pullRequestNumber, tr)
+	})
+}
+
+func (t *Tee) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	c := clone(commit).(*graphql.PullRequestCommit)
+	return t.save(func(s Storer) error {
+		return s.SavePullRequestCommit(repositoryOwner, repositoryName, pullRequestNumber, c)
+	})
+}
+
+func (t *Tee) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	e := clone(event).(*graphql.AssignmentEvent)
+	return t.save(func(s Storer) error { return s.SaveAssignmentEvent(repositoryOwner, repositoryName, number, e) })
+}
+
+func (t *Tee) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	e := clone(event).(*graphql.IssueEvent)
+	return t.save(func(s Storer) error { return s.SaveIssueEvent(repositoryOwner, repositoryName, issueNumber, e) })
+}
+
+func (t *Tee) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	e := clone(event).(*graphql.PullRequestEvent)
+	return t.save(func(s Storer) error {
+		return s.SavePullRequestEvent(repositoryOwner, repositoryName, pullRequestNumber, e)
+	})
+}
+
+func (t *Tee) SaveMention(subjectID, mentionedLogin string) error {
+	return t.save(func(s Storer) error { return s.SaveMention(subjectID, mentionedLogin) })
+}
+
+func (t *Tee) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	g := clone(group).(*graphql.ReactionGroup)
+	return t.save(func(s Storer) error { return s.SaveReactionGroup(subjectID, g) })
+}
+
+func (t *Tee) SaveClosingReference(reference *ClosingReference) error {
+	r := clone(reference).(*ClosingReference)
+	return t.save(func(s Storer) error { return s.SaveClosingReference(r) })
+}
+
+func (t *Tee) SaveLock(repositoryOwner, repositoryName string, number int, lock *Lock) error {
+	l := clone(lock).(*Lock)
+	return t.save(func(s Storer) error { return s.SaveLock(repositoryOwner, repositoryName, number, l) })
+}
+
+func (t *Tee) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *ProjectStatus) error {
+	st := clone(status).(*ProjectStatus)
+	return t.save(func(s Storer) error {
+		return s.SaveProjectStatus(repositoryOwner, repositoryName, pullRequestNumber, st)
+	})
+}
+
+func (t *Tee) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	r := clone(ruleset).(*graphql.RepositoryRuleset)
+	return t.save(func(s Storer) error { return s.SaveRuleset(repositoryOwner, repositoryName, r) })
+}
+
+func (t *Tee) SaveTemplate(repositoryOwner, repositoryName string, template *Template) error {
+	tp := clone(template).(*Template)
+	return t.save(func(s Storer) error { return s.SaveTemplate(repositoryOwner, repositoryName, tp) })
+}
+
+func (t *Tee) SaveReadme(repositoryOwner, repositoryName string, readme *Readme) error {
+	r := clone(readme).(*Readme)
+	return t.save(func(s Storer) error { return s.SaveReadme(repositoryOwner, repositoryName, r) })
+}
+
+func (t *Tee) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	e := clone(entry).(*graphql.AuditLogEntry)
+	return t.save(func(s Storer) error { return s.SaveAuditLogEntry(organization, e) })
+}
+
+func (t *Tee) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	p := clone(project).(*graphql.OrgProject)
+	return t.save(func(s Storer) error { return s.SaveOrgProject(organization, p) })
+}
+
+func (t *Tee) Begin() error {
+	return t.save(func(s Storer) error { return s.Begin() })
+}
+
+func (t *Tee) Commit() error {
+	return t.sync(func(s Storer) error { return s.Commit() })
+}
+
+func (t *Tee) Rollback() error {
+	return t.sync(func(s Storer) error { return s.Rollback() })
+}
+
+func (t *Tee) Version(v int) {
+	t.Primary.Version(v)
+	t.enqueue(func(s Storer) error {
+		s.Version(v)
+		return nil
+	})
+}
+
+func (t *Tee) SetActiveVersion(v int) error {
+	return t.sync(func(s Storer) error { return s.SetActiveVersion(v) })
+}
+
+func (t *Tee) Cleanup(currentVersion int) error {
+	return t.sync(func(s Storer) error { return s.Cleanup(currentVersion) })
+}
+
+// clone returns a deep copy of v, a pointer to a struct, so the nested slices
+// of the connections, e.g. the labels of an issue, are not shared with the
+// caller while the secondary store reads them
+func clone(v interface{}) interface{} {
+	src := reflect.ValueOf(v)
+	dst := reflect.New(src.Type()).Elem()
+	cloneValue(dst, src)
+	return dst.Interface()
+}
+
+func cloneValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+
+		dst.Set(reflect.New(src.Type().Elem()))
+		cloneValue(dst.Elem(), src.Elem())
+	case reflect.Struct:
+		// the unexported fields, e.g. of a time.Time, are copied as they are
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				cloneValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			cloneValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for _, key := range src.MapKeys() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			cloneValue(value, src.MapIndex(key))
+			dst.SetMapIndex(key, value)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+
+		value := reflect.New(src.Elem().Type()).Elem()
+		cloneValue(value, src.Elem())
+		dst.Set(value)
+	default:
+		dst.Set(src)
+	}
+}
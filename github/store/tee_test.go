@@ -0,0 +1,130 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/src-d/metadata-retrieval/github/graphql"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowMem is a Mem that takes some time to save each issue, and fails to save
+// the issue with number fail
+type slowMem struct {
+	*Mem
+	fail int
+}
+
+func (s *slowMem) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	time.Sleep(time.Millisecond)
+	if issue.Number == s.fail {
+		return fmt.Errorf("issue #%v failed", issue.Number)
+	}
+
+	return s.Mem.SaveIssue(repositoryOwner, repositoryName, issue, assignees, labels)
+}
+
+func TestTee(t *testing.T) {
+	require := require.New(t)
+
+	primary := NewMem()
+	secondary := &slowMem{Mem: NewMem(), fail: 7}
+
+	// a buffer smaller than the number of saves blocks the saves until the
+	// secondary catches up
+	tee := NewTee(primary, secondary, 2)
+	defer tee.Close()
+
+	tee.Version(1)
+	require.NoError(tee.Begin())
+
+	repository := &graphql.RepositoryFields{Name: "go-git"}
+	repository.Owner.Login = "src-d"
+	require.NoError(tee.SaveRepository(repository, []string{"git"}))
+
+	// the same variable is reused for every issue, like the downloader does
+	var issue graphql.Issue
+	for number := 1; number <= 10; number++ {
+		issue.Number = number
+		issue.Title = fmt.Sprintf("issue %v", number)
+		require.NoError(tee.SaveIssue("src-d", "go-git", &issue, []string{"alice"}, nil))
+	}
+
+	err := tee.Commit()
+	require.Error(err)
+	require.Contains(err.Error(), "issue #7 failed")
+
+	require.Len(primary.Repos["src-d"]["go-git"].Issues, 10)
+
+	issues := secondary.Repos["src-d"]["go-git"].Issues
+	require.Len(issues, 9)
+	for number, i := range issues {
+		require.Equal(fmt.Sprintf("issue %v", number), i.Title)
+		require.Equal([]string{"alice"}, i.Assignees)
+	}
+	require.Equal([]string{"git"}, secondary.Repos["src-d"]["go-git"].Topics)
+
+	// the errors are returned once
+	require.NoError(tee.Flush())
+	require.NoError(tee.SetActiveVersion(1))
+}
+
+// labelsMem is a slow Mem that records the labels of the issues as they are
+// when it saves them, from the nested connection
+type labelsMem struct {
+	*Mem
+	labels []string
+}
+
+func (s *labelsMem) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	time.Sleep(time.Millisecond)
+	for _, label := range issue.Labels.Nodes {
+		s.labels = append(s.labels, label.Name)
+	}
+
+	return s.Mem.SaveIssue(repositoryOwner, repositoryName, issue, assignees, labels)
+}
+
+// TestTeeClone is meant to be run with -race too, the secondary store reads
+// the nested slices while the caller reuses them
+func TestTeeClone(t *testing.T) {
+	require := require.New(t)
+
+	secondary := &labelsMem{Mem: NewMem()}
+	tee := NewTee(NewMem(), secondary, 10)
+	defer tee.Close()
+
+	tee.Version(1)
+	require.NoError(tee.Begin())
+
+	// the nodes of the connection are overwritten in place for each issue
+	var issue graphql.Issue
+	issue.Labels.Nodes = make([]graphql.Label, 1)
+	for number := 1; number <= 5; number++ {
+		issue.Number = number
+		issue.Labels.Nodes[0].Name = fmt.Sprintf("label %v", number)
+		require.NoError(tee.SaveIssue("src-d", "go-git", &issue, nil, nil))
+	}
+
+	require.NoError(tee.Commit())
+	require.Equal([]string{"label 1", "label 2", "label 3", "label 4", "label 5"}, secondary.labels)
+}
+
+func TestClone(t *testing.T) {
+	require := require.New(t)
+
+	submittedAt := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	review := &graphql.PullRequestReview{}
+	review.SubmittedAt = &submittedAt
+	review.ReactionGroups = []graphql.ReactionGroup{{Content: "HEART"}}
+
+	c := clone(review).(*graphql.PullRequestReview)
+	require.Equal(review, c)
+
+	*review.SubmittedAt = time.Time{}
+	review.ReactionGroups[0].Content = "ROCKET"
+	require.Equal(time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC), *c.SubmittedAt)
+	require.Equal("HEART", c.ReactionGroups[0].Content)
+}
@@ -0,0 +1,20 @@
+package store
+
+// The kinds of Template
+const (
+	IssueTemplate       = "issue"
+	PullRequestTemplate = "pull_request"
+)
+
+// Template is an issue or PR template of a repository, e.g. from the
+// .github/ISSUE_TEMPLATE directory
+type Template struct {
+	// Kind is IssueTemplate or PullRequestTemplate
+	Kind     string
+	Filename string
+	// Name, Title and About are only set for issue templates
+	Name  string
+	Title string
+	About string
+	Body  string
+}
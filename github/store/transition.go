@@ -0,0 +1,18 @@
+package store
+
+import "time"
+
+// ReviewStateTransition is a change in the state of the reviews submitted by
+// a user to a PR, e.g. from COMMENTED to APPROVED
+type ReviewStateTransition struct {
+	UserLogin string
+	// Sequence is the position of the transition among the ones of the same
+	// user in the PR, starting at 0
+	Sequence int
+	// FromState is empty for the first review of the user
+	FromState string
+	ToState   string
+	ReviewId  int
+	// SubmittedAt is nil for pending reviews
+	SubmittedAt *time.Time
+}
@@ -0,0 +1,57 @@
+package github
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// clock is the source of time of the throttle, it is replaced in tests
+type clock interface {
+	Now() time.Time
+	// Sleep waits for the given duration, or until ctx is done
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the clock of the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// throttle spaces successive queries by a minimum delay. It is safe for
+// concurrent use, the callers wait their turn one after the other
+type throttle struct {
+	mu    sync.Mutex
+	clock clock
+	delay time.Duration
+	last  time.Time
+}
+
+// wait blocks until delay has passed since the previous call returned
+func (t *throttle) wait(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.last.IsZero() {
+		if d := t.delay - t.clock.Now().Sub(t.last); d > 0 {
+			err := t.clock.Sleep(ctx, d)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	t.last = t.clock.Now()
+	return nil
+}
@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock only advances when Sleep is called, or when advanced by the test
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWithBaseDelay(t *testing.T) {
+	require := require.New(t)
+
+	clock := &fakeClock{now: time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)}
+
+	var times []time.Time
+	d, _ := newTestDownloader(t, func(query string, variables map[string]interface{}) string {
+		times = append(times, clock.Now())
+		// each query takes some time to be answered
+		clock.advance(300 * time.Millisecond)
+
+		return `{"data": {"repository": {
+			"name": "basic",
+			"nameWithOwner": "git-fixtures/basic",
+			"owner": {"login": "git-fixtures", "__typename": "Organization"},
+			"repositoryTopics": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"issues": {"pageInfo": {"hasNextPage": false}, "nodes": []},
+			"pullRequests": {"pageInfo": {"hasNextPage": false}, "nodes": []}
+		}}}`
+	})
+
+	require.Nil(d.WithBaseDelay(0).throttle)
+
+	d.WithBaseDelay(time.Second)
+	d.throttle.clock = clock
+
+	for i := 0; i < 3; i++ {
+		require.NoError(d.DownloadRepository(context.TODO(), "git-fixtures", "basic", 0))
+	}
+
+	require.Len(times, 3)
+	for i := 1; i < len(times); i++ {
+		require.True(times[i].Sub(times[i-1]) >= time.Second, "query %v after %v", i, times[i].Sub(times[i-1]))
+	}
+
+	// the first query is not delayed. The time the previous query took to be
+	// answered is subtracted from the delay of the next one, the rulesets
+	// queries are answered without the handler, immediately
+	require.Equal([]time.Duration{
+		700 * time.Millisecond, time.Second,
+		700 * time.Millisecond, time.Second,
+		700 * time.Millisecond,
+	}, clock.sleeps)
+}
@@ -0,0 +1,58 @@
+package github
+
+import "github.com/src-d/metadata-retrieval/github/graphql"
+
+// transformStorer applies a transformation to the body of the issues, PRs,
+// comments, reviews and review comments before saving them, see
+// Downloader.BodyTransformer. The saved objects are copies, the downloader
+// keeps the original bodies, e.g. to parse the mentions
+type transformStorer struct {
+	storer
+	transform func(body string) string
+}
+
+// transformedStorer returns the storer of the Downloader, wrapped in a
+// transformStorer if BodyTransformer is set
+func (d Downloader) transformedStorer() storer {
+	if d.BodyTransformer == nil {
+		return d.storer
+	}
+
+	return &transformStorer{storer: d.storer, transform: d.BodyTransformer}
+}
+
+func (s *transformStorer) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
+	i := *issue
+	i.Body = s.transform(i.Body)
+	return s.storer.SaveIssue(repositoryOwner, repositoryName, &i, assignees, labels)
+}
+
+func (s *transformStorer) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
+	c := *comment
+	c.Body = s.transform(c.Body)
+	return s.storer.SaveIssueComment(repositoryOwner, repositoryName, issueNumber, &c)
+}
+
+func (s *transformStorer) SavePullRequest(repositoryOwner, repositoryName string, pr *graphql.PullRequest, assignees []string, labels []string) error {
+	p := *pr
+	p.Body = s.transform(p.Body)
+	return s.storer.SavePullRequest(repositoryOwner, repositoryName, &p, assignees, labels)
+}
+
+func (s *transformStorer) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
+	c := *comment
+	c.Body = s.transform(c.Body)
+	return s.storer.SavePullRequestComment(repositoryOwner, repositoryName, pullRequestNumber, &c)
+}
+
+func (s *transformStorer) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
+	r := *review
+	r.Body = s.transform(r.Body)
+	return s.storer.SavePullRequestReview(repositoryOwner, repositoryName, pullRequestNumber, &r)
+}
+
+func (s *transformStorer) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewId int, comment *graphql.PullRequestReviewComment) error {
+	c := *comment
+	c.Body = s.transform(c.Body)
+	return s.storer.SavePullRequestReviewComment(repositoryOwner, repositoryName, pullRequestNumber, pullRequestReviewId, &c)
+}
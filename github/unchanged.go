@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/src-d/go-log.v1"
+)
+
+// skipUnchanged returns true if the repository was not pushed nor updated
+// since the latest version saved in the store, comparing their push and
+// update times. With UnchangedCopied, the saved version is copied forward to
+// the given version in its own transaction
+func (d Downloader) skipUnchanged(ctx context.Context, owner string, name string, version int) (bool, error) {
+	c, ok := d.storer.(repositoryCopier)
+	if !ok {
+		return false, fmt.Errorf("UnchangedSkipped is not supported by the store %T", d.storer)
+	}
+
+	saved, pushedAt, updatedAt, err := c.LatestRepository(owner, name)
+	if err != nil {
+		return false, err
+	}
+
+	if saved == 0 {
+		return false, nil
+	}
+
+	var q struct {
+		Repository struct {
+			PushedAt  time.Time
+			UpdatedAt time.Time
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+
+	err = d.query(ctx, &q, map[string]interface{}{
+		"owner": githubv4.String(owner),
+		"name":  githubv4.String(name),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to query the push and update times of %v/%v: %v", owner, name, err)
+	}
+
+	if !q.Repository.PushedAt.Equal(pushedAt) || !q.Repository.UpdatedAt.Equal(updatedAt) {
+		return false, nil
+	}
+
+	log.Infof("skipping %v/%v, unchanged since version %v", owner, name, saved)
+	if !d.UnchangedCopied {
+		return true, nil
+	}
+
+	version, err = d.downloadVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	if version == saved {
+		return true, nil
+	}
+
+	d.storer.Version(version)
+	err = d.storer.Begin()
+	if err != nil {
+		return false, fmt.Errorf("could not call Begin(): %v", err)
+	}
+
+	err = c.CopyRepositoryForward(owner, name, saved)
+	if err != nil {
+		d.storer.Rollback()
+		return false, err
+	}
+
+	err = d.storer.Commit()
+	if err != nil {
+		return false, fmt.Errorf("could not call Commit(): %v", err)
+	}
+
+	return true, nil
+}
@@ -1,7 +1,10 @@
 package testutils
 
 import (
+	"time"
+
 	"github.com/src-d/metadata-retrieval/github/graphql"
+	"github.com/src-d/metadata-retrieval/github/store"
 
 	"gopkg.in/src-d/go-log.v1"
 )
@@ -14,6 +17,33 @@ type Memory struct {
 	Users        []*graphql.UserExtended
 	PRs          []*graphql.PullRequest
 	PRComments   []*graphql.IssueComment
+	// ReviewTransitions are keyed by PR number
+	ReviewTransitions map[int][]*store.ReviewStateTransition
+	// PRCommits are keyed by PR number
+	PRCommits map[int][]graphql.PullRequestCommit
+	// AssignmentEvents are keyed by issue or PR number
+	AssignmentEvents map[int][]*graphql.AssignmentEvent
+	// IssueEvents are keyed by issue number
+	IssueEvents map[int][]*graphql.IssueEvent
+	// PullRequestEvents are keyed by PR number
+	PullRequestEvents map[int][]*graphql.PullRequestEvent
+	// Mentions are keyed by the node id of the subject
+	Mentions map[string][]string
+	// ReactionGroups are keyed by the node id of the subject
+	ReactionGroups map[string][]*graphql.ReactionGroup
+	// ClosingReferences are in the order they were saved, found from the PR
+	// or the issue side
+	ClosingReferences []store.ClosingReference
+	// Locks are keyed by issue or PR number
+	Locks map[int]*store.Lock
+	// ProjectStatuses are keyed by PR number
+	ProjectStatuses map[int][]store.ProjectStatus
+	Rulesets        []*graphql.RepositoryRuleset
+	Templates       []store.Template
+	// Readme is nil until SaveReadme is called
+	Readme      *store.Readme
+	AuditLog    []*graphql.AuditLogEntry
+	OrgProjects []*graphql.OrgProject
 }
 
 // SaveOrganization stores an organization in memory,
@@ -42,11 +72,27 @@ func (s *Memory) SaveRepository(repository *graphql.RepositoryFields, topics []s
 	// Initialize prs and comments to 0 for each repo
 	s.PRs = make([]*graphql.PullRequest, 0)
 	s.PRComments = make([]*graphql.IssueComment, 0)
+	s.ReviewTransitions = make(map[int][]*store.ReviewStateTransition)
+	s.AssignmentEvents = make(map[int][]*graphql.AssignmentEvent)
+	s.IssueEvents = make(map[int][]*graphql.IssueEvent)
+	s.PullRequestEvents = make(map[int][]*graphql.PullRequestEvent)
 	return nil
 }
 
 // TODO(kyrcha): add memory in noop methods as the tests expand
 
+// SaveTopic noop
+func (s *Memory) SaveTopic(name string) error {
+	log.Infof("topic data fetched for %s\n", name)
+	return nil
+}
+
+// SaveRepositoryTopic noop
+func (s *Memory) SaveRepositoryTopic(repositoryOwner, repositoryName string, topic string) error {
+	log.Infof("\trepository topic data fetched for %s/%s: %s\n", repositoryOwner, repositoryName, topic)
+	return nil
+}
+
 // SaveIssue noop
 func (s *Memory) SaveIssue(repositoryOwner, repositoryName string, issue *graphql.Issue, assignees []string, labels []string) error {
 	log.Infof("issue data fetched for #%v %s\n", issue.Number, issue.Title)
@@ -55,7 +101,7 @@ func (s *Memory) SaveIssue(repositoryOwner, repositoryName string, issue *graphq
 
 // SaveIssueComment noop
 func (s *Memory) SaveIssueComment(repositoryOwner, repositoryName string, issueNumber int, comment *graphql.IssueComment) error {
-	log.Infof(" \tissue comment data fetched by %s at %v: %q\n", comment.Author.Login, comment.CreatedAt, trim(comment.Body))
+	log.Infof(" \tissue comment data fetched by %s at %v: %q\n", comment.Author.LoginOrGhost(), comment.CreatedAt, trim(comment.Body))
 	return nil
 }
 
@@ -68,20 +114,160 @@ func (s *Memory) SavePullRequest(repositoryOwner, repositoryName string, pr *gra
 
 // SavePullRequestComment appends an PR comment to the PR comment list in memory
 func (s *Memory) SavePullRequestComment(repositoryOwner, repositoryName string, pullRequestNumber int, comment *graphql.IssueComment) error {
-	log.Infof("\tpr comment data fetched by %s at %v: %q\n", comment.Author.Login, comment.CreatedAt, trim(comment.Body))
+	log.Infof("\tpr comment data fetched by %s at %v: %q\n", comment.Author.LoginOrGhost(), comment.CreatedAt, trim(comment.Body))
 	s.PRComments = append(s.PRComments, comment)
 	return nil
 }
 
 // SavePullRequestReview noop
 func (s *Memory) SavePullRequestReview(repositoryOwner, repositoryName string, pullRequestNumber int, review *graphql.PullRequestReview) error {
-	log.Infof(" \tPR Review data fetched by %s at %v: %q\n", review.Author.Login, review.SubmittedAt, trim(review.Body))
+	log.Infof(" \tPR Review data fetched by %s at %v: %q\n", review.Author.LoginOrGhost(), submittedAt(review.SubmittedAt), trim(review.Body))
 	return nil
 }
 
 // SavePullRequestReviewComment noop
 func (s *Memory) SavePullRequestReviewComment(repositoryOwner, repositoryName string, pullRequestNumber int, pullRequestReviewID int, comment *graphql.PullRequestReviewComment) error {
-	log.Infof("\t\tPR review comment data fetched by %s at %v: %q\n", comment.Author.Login, comment.CreatedAt, trim(comment.Body))
+	log.Infof("\t\tPR review comment data fetched by %s at %v: %q\n", comment.Author.LoginOrGhost(), comment.CreatedAt, trim(comment.Body))
+	return nil
+}
+
+// SaveReviewStateTransition appends a review state transition to the list of
+// transitions of the PR in memory
+func (s *Memory) SaveReviewStateTransition(repositoryOwner, repositoryName string, pullRequestNumber int, transition *store.ReviewStateTransition) error {
+	log.Infof(" \tPR Review state of %s changed from %q to %q at %v\n", transition.UserLogin, transition.FromState, transition.ToState, submittedAt(transition.SubmittedAt))
+	s.ReviewTransitions[pullRequestNumber] = append(s.ReviewTransitions[pullRequestNumber], transition)
+	return nil
+}
+
+// SavePullRequestCommit appends a commit to the list of commits of the PR in
+// memory
+func (s *Memory) SavePullRequestCommit(repositoryOwner, repositoryName string, pullRequestNumber int, commit *graphql.PullRequestCommit) error {
+	log.Infof(" \tPR commit %s authored by %s at %v\n", commit.Commit.Oid, commit.Commit.Author.Name, commit.Commit.AuthoredDate)
+	if s.PRCommits == nil {
+		s.PRCommits = make(map[int][]graphql.PullRequestCommit)
+	}
+	s.PRCommits[pullRequestNumber] = append(s.PRCommits[pullRequestNumber], *commit)
+	return nil
+}
+
+// SaveAssignmentEvent appends an assignment event to the list of events of the
+// issue or PR in memory
+func (s *Memory) SaveAssignmentEvent(repositoryOwner, repositoryName string, number int, event *graphql.AssignmentEvent) error {
+	log.Infof(" \t%s %s by %s\n", event.Typename, event.Fields().AssigneeLogin(), event.Fields().Actor.Login)
+	// the event is reused by the caller's loop, keep a copy
+	e := *event
+	s.AssignmentEvents[number] = append(s.AssignmentEvents[number], &e)
+	return nil
+}
+
+// SaveIssueEvent appends a timeline event to the list of events of the issue
+// in memory
+func (s *Memory) SaveIssueEvent(repositoryOwner, repositoryName string, issueNumber int, event *graphql.IssueEvent) error {
+	log.Infof(" \t%s by %s\n", event.Typename, event.Fields().Actor.Login)
+	e := *event
+	s.IssueEvents[issueNumber] = append(s.IssueEvents[issueNumber], &e)
+	return nil
+}
+
+// SavePullRequestEvent appends a timeline event to the list of events of the
+// PR in memory
+func (s *Memory) SavePullRequestEvent(repositoryOwner, repositoryName string, pullRequestNumber int, event *graphql.PullRequestEvent) error {
+	log.Infof(" \t%s by %s\n", event.Typename, event.Fields().Actor.Login)
+	e := *event
+	s.PullRequestEvents[pullRequestNumber] = append(s.PullRequestEvents[pullRequestNumber], &e)
+	return nil
+}
+
+// SaveMention appends a mentioned login to the list of mentions of the
+// subject in memory
+func (s *Memory) SaveMention(subjectID, mentionedLogin string) error {
+	log.Infof(" \t%s mentioned in %s\n", mentionedLogin, subjectID)
+	if s.Mentions == nil {
+		s.Mentions = make(map[string][]string)
+	}
+	s.Mentions[subjectID] = append(s.Mentions[subjectID], mentionedLogin)
+	return nil
+}
+
+// SaveReactionGroup appends a reaction group to the list of reaction groups
+// of the subject in memory
+func (s *Memory) SaveReactionGroup(subjectID string, group *graphql.ReactionGroup) error {
+	log.Infof(" 	%v %s reactions to %s\n", group.Reactors.TotalCount, group.Content, subjectID)
+	if s.ReactionGroups == nil {
+		s.ReactionGroups = make(map[string][]*graphql.ReactionGroup)
+	}
+	s.ReactionGroups[subjectID] = append(s.ReactionGroups[subjectID], group)
+	return nil
+}
+
+// SaveLock sets the lock of the issue or PR in memory
+func (s *Memory) SaveLock(repositoryOwner, repositoryName string, number int, lock *store.Lock) error {
+	log.Infof(" \tlocked by %s at %v\n", lock.LockedByLogin, lock.LockedAt)
+	if s.Locks == nil {
+		s.Locks = make(map[int]*store.Lock)
+	}
+	l := *lock
+	s.Locks[number] = &l
+	return nil
+}
+
+// SaveProjectStatus appends a project status to the statuses of the PR in
+// memory
+func (s *Memory) SaveProjectStatus(repositoryOwner, repositoryName string, pullRequestNumber int, status *store.ProjectStatus) error {
+	log.Infof(" 	PR #%d status in project %s: %s\n", pullRequestNumber, status.ProjectTitle, status.Status)
+	if s.ProjectStatuses == nil {
+		s.ProjectStatuses = make(map[int][]store.ProjectStatus)
+	}
+	s.ProjectStatuses[pullRequestNumber] = append(s.ProjectStatuses[pullRequestNumber], *status)
+	return nil
+}
+
+// SaveClosingReference appends a closing reference to the list of references
+// in memory
+func (s *Memory) SaveClosingReference(reference *store.ClosingReference) error {
+	log.Infof(" 	PR #%d closes issue %s/%s #%d\n", reference.PullRequestNumber, reference.IssueRepositoryOwner, reference.IssueRepositoryName, reference.IssueNumber)
+	s.ClosingReferences = append(s.ClosingReferences, *reference)
+	return nil
+}
+
+// SaveRuleset appends a ruleset to the list of rulesets in memory
+func (s *Memory) SaveRuleset(repositoryOwner, repositoryName string, ruleset *graphql.RepositoryRuleset) error {
+	log.Infof(" \truleset data fetched for %s/%s: %s\n", repositoryOwner, repositoryName, ruleset.Name)
+	// the ruleset is reused by the caller's loop, keep a copy
+	r := *ruleset
+	s.Rulesets = append(s.Rulesets, &r)
+	return nil
+}
+
+// SaveTemplate appends a template to the list of templates in memory
+func (s *Memory) SaveTemplate(repositoryOwner, repositoryName string, template *store.Template) error {
+	log.Infof(" \t%s template fetched for %s/%s: %s\n", template.Kind, repositoryOwner, repositoryName, template.Filename)
+	s.Templates = append(s.Templates, *template)
+	return nil
+}
+
+// SaveReadme keeps the README in memory
+func (s *Memory) SaveReadme(repositoryOwner, repositoryName string, readme *store.Readme) error {
+	log.Infof(" \treadme fetched for %s/%s: %s\n", repositoryOwner, repositoryName, readme.Filename)
+	r := *readme
+	s.Readme = &r
+	return nil
+}
+
+// SaveAuditLogEntry appends an entry to the audit log in memory
+func (s *Memory) SaveAuditLogEntry(organization string, entry *graphql.AuditLogEntry) error {
+	log.Infof("audit log entry fetched for %s: %s\n", organization, entry.AuditEntry.Action)
+	// the entry is reused by the caller's loop, keep a copy
+	e := *entry
+	s.AuditLog = append(s.AuditLog, &e)
+	return nil
+}
+
+// SaveOrgProject appends a project to the organization projects in memory
+func (s *Memory) SaveOrgProject(organization string, project *graphql.OrgProject) error {
+	log.Infof("project fetched for %s: #%v %s\n", organization, project.Number, project.Title)
+	p := *project
+	s.OrgProjects = append(s.OrgProjects, &p)
 	return nil
 }
 
@@ -121,3 +307,13 @@ func trim(s string) string {
 
 	return s
 }
+
+// submittedAt returns the time a review was submitted, or "pending" for the
+// reviews not submitted yet
+func submittedAt(t *time.Time) interface{} {
+	if t == nil {
+		return "pending"
+	}
+
+	return *t
+}